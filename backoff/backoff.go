@@ -0,0 +1,142 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+// Package backoff computes exponential backoff delays so that client and
+// gateway retry logic waits the same amount of time between attempts.
+package backoff
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Jitter describes how randomness is applied to a backoff delay.
+type Jitter uint8
+
+const (
+	// NoJitter uses the exact exponential delay.
+	NoJitter Jitter = iota
+
+	// FullJitter picks a delay uniformly between zero and the exponential
+	// delay.
+	FullJitter
+
+	// EqualJitter picks a delay uniformly between half the exponential delay
+	// and the exponential delay.
+	EqualJitter
+)
+
+// String returns the string representation of the Jitter. This functions
+// adheres to the fmt.Stringer interface.
+func (j Jitter) String() string {
+	switch j {
+	case NoJitter:
+		return "NoJitter"
+	case FullJitter:
+		return "FullJitter"
+	case EqualJitter:
+		return "EqualJitter"
+	default:
+		return "UNKNOWN JITTER: " + strconv.FormatUint(uint64(j), 10)
+	}
+}
+
+// Config describes a backoff schedule. This structure can be JSON marshalled
+// and unmarshalled; durations are in nanoseconds.
+//
+// JSON example:
+//
+//	{
+//	  "base": 1000000000,
+//	  "max": 60000000000,
+//	  "multiplier": 2,
+//	  "jitter": 1
+//	}
+type Config struct {
+	// Base is the delay before the first retry
+	Base time.Duration `json:"base"`
+
+	// Max caps the delay of any retry
+	Max time.Duration `json:"max"`
+
+	// Multiplier is the factor the delay grows by on each attempt
+	Multiplier float64 `json:"multiplier"`
+
+	// Jitter selects how randomness is applied to each delay
+	Jitter Jitter `json:"jitter"`
+}
+
+// DefaultConfig returns a Config that starts at one second, doubles on each
+// attempt up to one minute, and uses FullJitter.
+func DefaultConfig() Config {
+	return Config{
+		Base:       time.Second,
+		Max:        time.Minute,
+		Multiplier: 2,
+		Jitter:     FullJitter,
+	}
+}
+
+// Validate returns an error if the Config cannot produce a valid schedule.
+func (c Config) Validate() error {
+	if c.Base <= 0 {
+		return errors.Errorf("base delay %s must be positive", c.Base)
+	} else if c.Max < c.Base {
+		return errors.Errorf(
+			"max delay %s must not be less than base delay %s", c.Max, c.Base)
+	} else if c.Multiplier < 1 || math.IsNaN(c.Multiplier) ||
+		math.IsInf(c.Multiplier, 0) {
+		return errors.Errorf("multiplier %f must be at least 1", c.Multiplier)
+	} else if c.Jitter > EqualJitter {
+		return errors.Errorf("unknown jitter %s", c.Jitter)
+	}
+	return nil
+}
+
+// NextDelay returns the delay to wait before the given attempt, starting at
+// zero, using the global random source for jitter.
+func NextDelay(attempt int, cfg Config) time.Duration {
+	return NextDelayRand(attempt, cfg, nil)
+}
+
+// NextDelayRand returns the delay to wait before the given attempt, starting
+// at zero. The delay is Base*Multiplier^attempt capped at Max, with jitter
+// drawn from rng. If rng is nil, the global random source is used.
+func NextDelayRand(attempt int, cfg Config, rng *rand.Rand) time.Duration {
+	if attempt < 0 {
+		attempt = 0
+	}
+
+	delay := float64(cfg.Base) * math.Pow(cfg.Multiplier, float64(attempt))
+	if delay > float64(cfg.Max) || math.IsInf(delay, 0) || math.IsNaN(delay) {
+		delay = float64(cfg.Max)
+	}
+
+	d := time.Duration(delay)
+	switch cfg.Jitter {
+	case FullJitter:
+		return time.Duration(int63n(rng, int64(d)+1))
+	case EqualJitter:
+		return d/2 + time.Duration(int63n(rng, int64(d-d/2)+1))
+	default:
+		return d
+	}
+}
+
+// int63n returns a random number in [0, n) from rng or the global source.
+func int63n(rng *rand.Rand, n int64) int64 {
+	if n <= 0 {
+		return 0
+	} else if rng == nil {
+		return rand.Int63n(n)
+	}
+	return rng.Int63n(n)
+}
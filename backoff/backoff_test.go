@@ -0,0 +1,102 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package backoff
+
+import (
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Tests that NextDelay without jitter grows exponentially up to the cap.
+func TestNextDelay_NoJitter(t *testing.T) {
+	cfg := Config{
+		Base:       100 * time.Millisecond,
+		Max:        time.Second,
+		Multiplier: 2,
+		Jitter:     NoJitter,
+	}
+	expected := []time.Duration{100 * time.Millisecond,
+		200 * time.Millisecond, 400 * time.Millisecond,
+		800 * time.Millisecond, time.Second, time.Second}
+
+	for attempt, d := range expected {
+		if received := NextDelay(attempt, cfg); received != d {
+			t.Errorf("Unexpected delay for attempt %d."+
+				"\nexpected: %s\nreceived: %s", attempt, d, received)
+		}
+	}
+
+	if received := NextDelay(10000, cfg); received != cfg.Max {
+		t.Errorf("Large attempt not capped.\nexpected: %s\nreceived: %s",
+			cfg.Max, received)
+	}
+}
+
+// Tests that jittered delays stay within their bounds.
+func TestNextDelayRand_Jitter(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	cfg := DefaultConfig()
+
+	for attempt := 0; attempt < 20; attempt++ {
+		exact := NextDelay(attempt, Config{cfg.Base, cfg.Max, cfg.Multiplier,
+			NoJitter})
+
+		cfg.Jitter = FullJitter
+		if d := NextDelayRand(attempt, cfg, rng); d < 0 || d > exact {
+			t.Errorf("Full jitter delay %s outside [0, %s].", d, exact)
+		}
+
+		cfg.Jitter = EqualJitter
+		if d := NextDelayRand(attempt, cfg, rng); d < exact/2 || d > exact {
+			t.Errorf("Equal jitter delay %s outside [%s, %s].",
+				d, exact/2, exact)
+		}
+	}
+}
+
+// Tests that Config.Validate accepts the default config and rejects invalid
+// configs.
+func TestConfig_Validate(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Errorf("Default config is invalid: %+v", err)
+	}
+
+	invalid := []Config{
+		{0, time.Second, 2, NoJitter},
+		{time.Second, time.Millisecond, 2, NoJitter},
+		{time.Second, time.Minute, 0.5, NoJitter},
+		{time.Second, time.Minute, 2, 7},
+	}
+	for i, cfg := range invalid {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("No error for invalid config %+v (%d).", cfg, i)
+		}
+	}
+}
+
+// Tests that a Config JSON marshalled and unmarshalled matches the original.
+func TestConfig_JSON(t *testing.T) {
+	expected := DefaultConfig()
+	data, err := json.Marshal(expected)
+	if err != nil {
+		t.Fatalf("Failed to JSON marshal: %+v", err)
+	}
+
+	var cfg Config
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("Failed to JSON unmarshal: %+v", err)
+	}
+
+	if !reflect.DeepEqual(expected, cfg) {
+		t.Errorf("Unmarshalled config does not match original."+
+			"\nexpected: %+v\nreceived: %+v", expected, cfg)
+	}
+}
@@ -0,0 +1,170 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+// Package contact defines Contact, the information users exchange to start
+// communicating, and its binary wire format.
+package contact
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/elixxir/primitives/fact"
+	"gitlab.com/xx_network/primitives/id"
+)
+
+const (
+	// MaxSize is the maximum size, in bytes, of a marshalled Contact.
+	MaxSize = 4096
+
+	// contactVersion is the version of the binary format written by
+	// Contact.Marshal.
+	contactVersion = 0
+
+	// lenSize is the size of the length prefixes in the binary format.
+	lenSize = 2
+)
+
+// Contact describes a user that can be contacted: their ID, their
+// Diffie–Hellman public key, a proof that they own the key, and the facts they
+// have chosen to share.
+type Contact struct {
+	ID             *id.ID
+	DhPubKey       []byte
+	OwnershipProof []byte
+	Facts          fact.FactList
+}
+
+// Marshal serialises the Contact into the following structure, with all
+// integers big-endian:
+//
+//	+---------+----------+----------+----------------+------------+-------+
+//	| version |    ID    | DhPubKey | OwnershipProof | fact count | facts |
+//	| 1 byte  | 33 bytes | variable |    variable    |  2 bytes   |       |
+//	+---------+----------+----------+----------------+------------+-------+
+//
+// DhPubKey, OwnershipProof, and each fact, stringified with
+// fact.Fact.StringifyV2, are prefixed with their 2-byte length. Returns an
+// error if the ID is nil or the result exceeds MaxSize.
+func (c Contact) Marshal() ([]byte, error) {
+	if c.ID == nil {
+		return nil, errors.New("cannot marshal contact with nil ID")
+	} else if len(c.Facts) > math.MaxUint16 {
+		return nil, errors.Errorf("contact has %d facts; maximum is %d",
+			len(c.Facts), math.MaxUint16)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(contactVersion)
+	buf.Write(c.ID.Marshal())
+	writeField(&buf, c.DhPubKey)
+	writeField(&buf, c.OwnershipProof)
+
+	b := make([]byte, lenSize)
+	binary.BigEndian.PutUint16(b, uint16(len(c.Facts)))
+	buf.Write(b)
+	for _, f := range c.Facts {
+		writeField(&buf, []byte(f.StringifyV2()))
+	}
+
+	if buf.Len() > MaxSize {
+		return nil, errors.Errorf("marshalled contact of %d bytes exceeds "+
+			"maximum size of %d bytes", buf.Len(), MaxSize)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Unmarshal deserialises a Contact marshalled with Contact.Marshal. Returns an
+// error if the data exceeds MaxSize, is malformed, or contains an invalid
+// fact.
+func Unmarshal(data []byte) (Contact, error) {
+	if len(data) > MaxSize {
+		return Contact{}, errors.Errorf("contact of %d bytes exceeds maximum "+
+			"size of %d bytes", len(data), MaxSize)
+	} else if len(data) < 1+id.ArrIDLen {
+		return Contact{}, errors.Errorf("contact of %d bytes is shorter than "+
+			"minimum of %d bytes", len(data), 1+id.ArrIDLen)
+	} else if data[0] != contactVersion {
+		return Contact{}, errors.Errorf(
+			"unsupported contact version %d", data[0])
+	}
+
+	buf := bytes.NewBuffer(data[1:])
+	contactID, err := id.Unmarshal(buf.Next(id.ArrIDLen))
+	if err != nil {
+		return Contact{}, errors.Wrap(err, "failed to unmarshal contact ID")
+	}
+
+	c := Contact{ID: contactID}
+	if c.DhPubKey, err = readField(buf); err != nil {
+		return Contact{}, errors.WithMessage(err, "failed to read DhPubKey")
+	} else if c.OwnershipProof, err = readField(buf); err != nil {
+		return Contact{}, errors.WithMessage(err,
+			"failed to read OwnershipProof")
+	}
+
+	countBytes := buf.Next(lenSize)
+	if len(countBytes) != lenSize {
+		return Contact{}, errors.New("contact fact count is truncated")
+	}
+	count := int(binary.BigEndian.Uint16(countBytes))
+
+	for i := 0; i < count; i++ {
+		factBytes, err := readField(buf)
+		if err != nil {
+			return Contact{}, errors.WithMessagef(err,
+				"failed to read fact %d of %d", i, count)
+		}
+
+		f, err := fact.UnstringifyFact(string(factBytes))
+		if err != nil {
+			return Contact{}, errors.WithMessagef(err,
+				"failed to unstringify fact %d of %d", i, count)
+		}
+		c.Facts = append(c.Facts, f)
+	}
+
+	if buf.Len() > 0 {
+		return Contact{}, errors.Errorf(
+			"contact has %d bytes of trailing data", buf.Len())
+	}
+
+	return c, nil
+}
+
+// writeField writes the data to the buffer prefixed with its length. The
+// length of the data must fit in lenSize bytes; Contact.Marshal enforces this
+// through MaxSize.
+func writeField(buf *bytes.Buffer, data []byte) {
+	b := make([]byte, lenSize)
+	binary.BigEndian.PutUint16(b, uint16(len(data)))
+	buf.Write(b)
+	buf.Write(data)
+}
+
+// readField reads a field written by writeField from the buffer. The returned
+// slice is a copy, or nil if the field is empty.
+func readField(buf *bytes.Buffer) ([]byte, error) {
+	lenBytes := buf.Next(lenSize)
+	if len(lenBytes) != lenSize {
+		return nil, errors.New("field length is truncated")
+	}
+
+	fieldLen := int(binary.BigEndian.Uint16(lenBytes))
+	if buf.Len() < fieldLen {
+		return nil, errors.Errorf("field of %d bytes is truncated to %d bytes",
+			fieldLen, buf.Len())
+	} else if fieldLen == 0 {
+		return nil, nil
+	}
+
+	return append([]byte{}, buf.Next(fieldLen)...), nil
+}
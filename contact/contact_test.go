@@ -0,0 +1,108 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package contact
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"gitlab.com/elixxir/primitives/fact"
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// newTestContact returns a Contact with random ID, key, and proof, and the
+// given facts.
+func newTestContact(
+	prng *rand.Rand, facts fact.FactList, t *testing.T) Contact {
+	dhPubKey := make([]byte, 256)
+	prng.Read(dhPubKey)
+	proof := make([]byte, 32)
+	prng.Read(proof)
+
+	return Contact{
+		ID:             id.NewRandomTestID(prng, id.User, t),
+		DhPubKey:       dhPubKey,
+		OwnershipProof: proof,
+		Facts:          facts,
+	}
+}
+
+// Tests that a Contact marshalled with Contact.Marshal and unmarshalled with
+// Unmarshal matches the original.
+func TestContact_Marshal_Unmarshal(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	contacts := []Contact{
+		newTestContact(prng, nil, t),
+		newTestContact(prng, fact.FactList{
+			{Fact: "myUsername", T: fact.Username},
+			{Fact: "john@example.com", T: fact.Email,
+				Status: fact.Unverified, Locale: "en-US"},
+		}, t),
+		newTestContact(prng, fact.FactList{
+			{Fact: "1legacyÜsername", T: fact.Username},
+		}, t),
+		{ID: &id.ID{}},
+	}
+
+	for i, c := range contacts {
+		data, err := c.Marshal()
+		if err != nil {
+			t.Fatalf("Failed to marshal contact %d: %+v", i, err)
+		}
+
+		unmarshalled, err := Unmarshal(data)
+		if err != nil {
+			t.Fatalf("Failed to unmarshal contact %d: %+v", i, err)
+		}
+		if !reflect.DeepEqual(c, unmarshalled) {
+			t.Errorf("Unmarshalled contact %d does not match original."+
+				"\nexpected: %+v\nreceived: %+v", i, c, unmarshalled)
+		}
+	}
+}
+
+// Error path: Tests that Contact.Marshal returns an error for a nil ID and for
+// a Contact larger than MaxSize.
+func TestContact_Marshal_Error(t *testing.T) {
+	if _, err := (Contact{}).Marshal(); err == nil {
+		t.Error("No error for nil ID.")
+	}
+
+	c := Contact{ID: &id.ID{}, DhPubKey: make([]byte, MaxSize)}
+	if _, err := c.Marshal(); err == nil {
+		t.Error("No error for contact exceeding MaxSize.")
+	}
+}
+
+// Error path: Tests that Unmarshal returns an error for malformed data.
+func TestUnmarshal_Error(t *testing.T) {
+	c := newTestContact(rand.New(rand.NewSource(42)),
+		fact.FactList{{Fact: "myUsername", T: fact.Username}}, t)
+	data, _ := c.Marshal()
+
+	invalidFact := Contact{ID: &id.ID{}, Facts: fact.FactList{
+		{Fact: "x", T: fact.Nickname}}}
+	invalidFactData, _ := invalidFact.Marshal()
+
+	tests := map[string][]byte{
+		"empty":     nil,
+		"version":   append([]byte{1}, data[1:]...),
+		"truncated": data[:len(data)-1],
+		"noCount":   data[:1+id.ArrIDLen+2+256+2+32],
+		"trailing":  append(append([]byte{}, data...), 0),
+		"tooLarge": append(
+			append([]byte{}, data...), make([]byte, MaxSize)...),
+		"invalidFact": invalidFactData,
+	}
+	for name, data := range tests {
+		if _, err := Unmarshal(data); err == nil {
+			t.Errorf("No error for %s data.", name)
+		}
+	}
+}
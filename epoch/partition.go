@@ -0,0 +1,141 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+// Package epoch maps timestamps onto fixed-length, consecutive time blocks
+// (epochs), such as ephemeral ID address space rotation periods or retention
+// buckets.
+package epoch
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// PartitionLen is the length of a marshalled Partition.
+const PartitionLen = 16
+
+// Partition divides time into consecutive epochs of equal length starting at
+// an origin. Epoch zero starts at the origin.
+type Partition struct {
+	origin time.Time
+	period time.Duration
+}
+
+// partitionDisk is the JSON representation of a Partition.
+type partitionDisk struct {
+	Origin int64 `json:"origin"`
+	Period int64 `json:"period"`
+}
+
+// NewPartition creates a new Partition with epochs of the given length
+// starting at origin. Returns an error if the period is not positive.
+func NewPartition(origin time.Time, period time.Duration) (Partition, error) {
+	if period <= 0 {
+		return Partition{}, errors.Errorf(
+			"epoch period must be positive; received %s", period)
+	}
+
+	return Partition{origin: time.Unix(0, origin.UnixNano()), period: period}, nil
+}
+
+// Origin returns the start of epoch zero.
+func (p Partition) Origin() time.Time {
+	return p.origin
+}
+
+// Period returns the length of each epoch.
+func (p Partition) Period() time.Duration {
+	return p.period
+}
+
+// Epoch returns the epoch that contains the given time. Times before the
+// origin are in epoch zero and times past the last representable epoch are in
+// epoch math.MaxUint32.
+func (p Partition) Epoch(t time.Time) uint32 {
+	delta := t.UnixNano() - p.origin.UnixNano()
+	if delta < 0 {
+		return 0
+	}
+
+	e := delta / int64(p.period)
+	if e > math.MaxUint32 {
+		return math.MaxUint32
+	}
+	return uint32(e)
+}
+
+// Bounds returns the start (inclusive) and end (exclusive) of the epoch.
+func (p Partition) Bounds(epoch uint32) (start, end time.Time) {
+	start = p.origin.Add(time.Duration(epoch) * p.period)
+	return start, start.Add(p.period)
+}
+
+// Contains determines if the given time falls within the epoch.
+func (p Partition) Contains(epoch uint32, t time.Time) bool {
+	start, end := p.Bounds(epoch)
+	return !t.Before(start) && t.Before(end)
+}
+
+// MarshalBinary serialises the Partition into a byte slice of length
+// PartitionLen: the origin in Unix nanoseconds followed by the period in
+// nanoseconds, both big-endian. This function adheres to the
+// encoding.BinaryMarshaler interface.
+func (p Partition) MarshalBinary() ([]byte, error) {
+	b := make([]byte, PartitionLen)
+	binary.BigEndian.PutUint64(b[:8], uint64(p.origin.UnixNano()))
+	binary.BigEndian.PutUint64(b[8:], uint64(p.period))
+	return b, nil
+}
+
+// UnmarshalBinary deserializes the byte slice into the Partition. This
+// function adheres to the encoding.BinaryUnmarshaler interface.
+func (p *Partition) UnmarshalBinary(data []byte) error {
+	if len(data) != PartitionLen {
+		return errors.Errorf("partition data must be %d bytes; received %d",
+			PartitionLen, len(data))
+	}
+
+	newP, err := NewPartition(
+		time.Unix(0, int64(binary.BigEndian.Uint64(data[:8]))),
+		time.Duration(binary.BigEndian.Uint64(data[8:])))
+	if err != nil {
+		return err
+	}
+
+	*p = newP
+	return nil
+}
+
+// MarshalJSON marshals the Partition into valid JSON. This function adheres to
+// the json.Marshaler interface.
+func (p Partition) MarshalJSON() ([]byte, error) {
+	return json.Marshal(partitionDisk{
+		Origin: p.origin.UnixNano(),
+		Period: int64(p.period),
+	})
+}
+
+// UnmarshalJSON unmarshalls the JSON into the Partition. This function adheres
+// to the json.Unmarshaler interface.
+func (p *Partition) UnmarshalJSON(data []byte) error {
+	var pd partitionDisk
+	if err := json.Unmarshal(data, &pd); err != nil {
+		return err
+	}
+
+	newP, err := NewPartition(time.Unix(0, pd.Origin), time.Duration(pd.Period))
+	if err != nil {
+		return err
+	}
+
+	*p = newP
+	return nil
+}
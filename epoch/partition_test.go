@@ -0,0 +1,138 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package epoch
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Tests that NewPartition returns an error for non-positive periods.
+func TestNewPartition_InvalidPeriodError(t *testing.T) {
+	for _, period := range []time.Duration{0, -time.Second} {
+		if _, err := NewPartition(time.Unix(0, 0), period); err == nil {
+			t.Errorf("Expected error for period %s.", period)
+		}
+	}
+}
+
+// Tests that Partition.Epoch returns the expected epoch for times around the
+// epoch boundaries.
+func TestPartition_Epoch(t *testing.T) {
+	origin := time.Unix(1_000_000, 0)
+	p, err := NewPartition(origin, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to create partition: %+v", err)
+	}
+
+	tests := []struct {
+		t        time.Time
+		expected uint32
+	}{
+		{origin.Add(-time.Nanosecond), 0},
+		{origin, 0},
+		{origin.Add(time.Hour - time.Nanosecond), 0},
+		{origin.Add(time.Hour), 1},
+		{origin.Add(25 * time.Hour), 25},
+	}
+
+	for i, tt := range tests {
+		if e := p.Epoch(tt.t); e != tt.expected {
+			t.Errorf("Unexpected epoch for %s (%d).\nexpected: %d\nreceived: %d",
+				tt.t, i, tt.expected, e)
+		}
+	}
+}
+
+// Tests that Partition.Epoch saturates at math.MaxUint32.
+func TestPartition_Epoch_Saturate(t *testing.T) {
+	p, _ := NewPartition(time.Unix(0, 0), time.Nanosecond)
+
+	if e := p.Epoch(time.Unix(0, 1<<33)); e != math.MaxUint32 {
+		t.Errorf("Epoch did not saturate.\nexpected: %d\nreceived: %d",
+			uint32(math.MaxUint32), e)
+	}
+}
+
+// Tests that every time within the bounds of an epoch maps back to that epoch.
+func TestPartition_Bounds(t *testing.T) {
+	p, _ := NewPartition(time.Unix(500, 0), 10*time.Minute)
+
+	for epoch := uint32(0); epoch < 100; epoch++ {
+		start, end := p.Bounds(epoch)
+		if end.Sub(start) != p.Period() {
+			t.Errorf("Bounds of epoch %d span %s; expected %s",
+				epoch, end.Sub(start), p.Period())
+		}
+		if p.Epoch(start) != epoch || !p.Contains(epoch, start) {
+			t.Errorf("Start of epoch %d is not in the epoch.", epoch)
+		}
+		last := end.Add(-time.Nanosecond)
+		if p.Epoch(last) != epoch || !p.Contains(epoch, last) {
+			t.Errorf("End of epoch %d is not in the epoch.", epoch)
+		}
+		if p.Contains(epoch, end) {
+			t.Errorf("Epoch %d contains the start of the next epoch.", epoch)
+		}
+	}
+}
+
+// Tests that a Partition marshalled via Partition.MarshalBinary and
+// unmarshalled via Partition.UnmarshalBinary matches the original.
+func TestPartition_MarshalBinary_UnmarshalBinary(t *testing.T) {
+	p, _ := NewPartition(time.Unix(12345, 6789), 3*time.Second)
+
+	data, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Failed to marshal: %+v", err)
+	}
+
+	var newP Partition
+	if err = newP.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Failed to unmarshal: %+v", err)
+	}
+
+	if !reflect.DeepEqual(p, newP) {
+		t.Errorf("Unmarshalled partition does not match original."+
+			"\nexpected: %+v\nreceived: %+v", p, newP)
+	}
+}
+
+// Tests that Partition.UnmarshalBinary returns an error for invalid data.
+func TestPartition_UnmarshalBinary_Error(t *testing.T) {
+	var p Partition
+	if err := p.UnmarshalBinary(make([]byte, PartitionLen-1)); err == nil {
+		t.Error("Expected error for data of the wrong length.")
+	}
+	if err := p.UnmarshalBinary(make([]byte, PartitionLen)); err == nil {
+		t.Error("Expected error for a zero period.")
+	}
+}
+
+// Tests that a Partition JSON marshalled and unmarshalled matches the original.
+func TestPartition_JSON(t *testing.T) {
+	p, _ := NewPartition(time.Unix(98765, 4321), 24*time.Hour)
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Failed to JSON marshal: %+v", err)
+	}
+
+	var newP Partition
+	if err = json.Unmarshal(data, &newP); err != nil {
+		t.Fatalf("Failed to JSON unmarshal: %+v", err)
+	}
+
+	if !reflect.DeepEqual(p, newP) {
+		t.Errorf("Unmarshalled partition does not match original."+
+			"\nexpected: %+v\nreceived: %+v", p, newP)
+	}
+}
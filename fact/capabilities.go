@@ -0,0 +1,63 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+// capabilities describes how user discovery and clients treat a FactType.
+type capabilities struct {
+	// The fact can be used to look up a user
+	searchable bool
+
+	// The user must prove ownership of the fact, such as by a code sent to it,
+	// before it is registered
+	requiresVerification bool
+
+	// A message is sent to the fact when it is registered
+	notifiableOnRegistration bool
+}
+
+// factTypeCapabilities is the capability matrix of every FactType. Types not in
+// the table have no capabilities.
+var factTypeCapabilities = map[FactType]capabilities{
+	Username: {
+		searchable:               true,
+		requiresVerification:     false,
+		notifiableOnRegistration: false,
+	},
+	Email: {
+		searchable:               true,
+		requiresVerification:     true,
+		notifiableOnRegistration: true,
+	},
+	Phone: {
+		searchable:               true,
+		requiresVerification:     true,
+		notifiableOnRegistration: true,
+	},
+	Nickname: {
+		searchable:               false,
+		requiresVerification:     false,
+		notifiableOnRegistration: false,
+	},
+}
+
+// Searchable determines if facts of this type can be used to look up a user.
+func (t FactType) Searchable() bool {
+	return factTypeCapabilities[t].searchable
+}
+
+// RequiresVerification determines if the user must prove ownership of facts of
+// this type before they are registered.
+func (t FactType) RequiresVerification() bool {
+	return factTypeCapabilities[t].requiresVerification
+}
+
+// NotifiableOnRegistration determines if a message is sent to facts of this
+// type when they are registered.
+func (t FactType) NotifiableOnRegistration() bool {
+	return factTypeCapabilities[t].notifiableOnRegistration
+}
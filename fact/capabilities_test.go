@@ -0,0 +1,52 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import "testing"
+
+// Consistency test of FactType.Searchable, FactType.RequiresVerification, and
+// FactType.NotifiableOnRegistration.
+func TestFactType_Capabilities(t *testing.T) {
+	tests := []struct {
+		t                                  FactType
+		searchable, verification, notified bool
+	}{
+		{Username, true, false, false},
+		{Email, true, true, true},
+		{Phone, true, true, true},
+		{Nickname, false, false, false},
+		{99, false, false, false},
+	}
+
+	for _, tt := range tests {
+		if tt.t.Searchable() != tt.searchable {
+			t.Errorf("Unexpected Searchable for %s.\nexpected: %t"+
+				"\nreceived: %t", tt.t, tt.searchable, tt.t.Searchable())
+		}
+		if tt.t.RequiresVerification() != tt.verification {
+			t.Errorf("Unexpected RequiresVerification for %s.\nexpected: %t"+
+				"\nreceived: %t", tt.t, tt.verification,
+				tt.t.RequiresVerification())
+		}
+		if tt.t.NotifiableOnRegistration() != tt.notified {
+			t.Errorf("Unexpected NotifiableOnRegistration for %s."+
+				"\nexpected: %t\nreceived: %t", tt.t, tt.notified,
+				tt.t.NotifiableOnRegistration())
+		}
+	}
+}
+
+// Tests that every valid FactType has an entry in the capability matrix.
+func TestFactTypeCapabilities_Complete(t *testing.T) {
+	for ft := FactType(0); ft < 255; ft++ {
+		if _, exists := factTypeCapabilities[ft]; exists != ft.IsValid() {
+			t.Errorf("Capability entry for %s exists: %t, valid: %t",
+				ft, exists, ft.IsValid())
+		}
+	}
+}
@@ -0,0 +1,206 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CompactAlphabet is the alphabet of the compact fact encoding. It is the
+// character set of the QR code alphanumeric mode, so compact facts are encoded
+// in QR codes at 5.5 bits per character instead of 8.
+const CompactAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+const (
+	// compactBase is the number of characters in CompactAlphabet.
+	compactBase = len(CompactAlphabet)
+
+	// compactLocaleFlag is set in the header of a compact fact that includes a
+	// locale.
+	compactLocaleFlag = 0x08
+
+	// compactDisplayFlag is set in the header of a compact fact that holds the
+	// display value in place of the fact.
+	compactDisplayFlag = 0x04
+
+	// compactStatusMask masks the FactStatus in the header of a compact fact.
+	compactStatusMask = 0x03
+)
+
+// CompactEncode marshals the Fact into a short string made only of characters
+// in CompactAlphabet, which minimises the size of a QR code holding it.
+//
+// The fact is first serialised as a header byte, containing the FactType in
+// the high nibble and the FactStatus, a locale flag, and a display flag in the
+// low nibble, followed by the locale and a colon, if there is a locale, and
+// then the fact. If the fact has a display value, it is written instead of the
+// fact, since the fact is its canonical form.
+// The bytes are encoded in base 45 as in RFC 9285, where every two bytes become
+// three characters, and a mod 45 check digit is appended to detect mistyped or
+// misread characters.
+func (f Fact) CompactEncode() string {
+	header := byte(f.T)<<4 | byte(f.Status)&compactStatusMask
+	data := []byte{header}
+	if f.Locale != "" {
+		data[0] |= compactLocaleFlag
+		data = append(data, f.Locale+localeTerminator...)
+	}
+	if f.Display != "" {
+		data[0] |= compactDisplayFlag
+		data = append(data, f.Display...)
+	} else {
+		data = append(data, f.Fact...)
+	}
+
+	encoded := encodeBase45(data)
+	return encoded + string(CompactAlphabet[compactCheckDigit(encoded)])
+}
+
+// CompactDecode unmarshalls a Fact encoded with Fact.CompactEncode. Returns
+// ErrInvalidCheckDigit if the check digit does not match and ErrMalformed if
+// the string cannot otherwise be decoded. The decoded fact is validated with
+// ValidateFact, except that usernames are not checked against ValidateUsername,
+// usernames and nicknames are not checked against the Policy, and emails are
+// not checked against the EmailDomainConfig.
+func CompactDecode(s string) (Fact, error) {
+	if len(s) < 2 {
+		return Fact{}, errors.WithMessagef(ErrMalformed,
+			"compact fact %q is too short", s)
+	}
+
+	encoded := s[:len(s)-1]
+	check := strings.IndexByte(CompactAlphabet, s[len(s)-1])
+	if check < 0 || check != compactCheckDigit(encoded) {
+		return Fact{}, errors.WithMessagef(ErrInvalidCheckDigit,
+			"compact fact %q", s)
+	}
+
+	data, err := decodeBase45(encoded)
+	if err != nil {
+		return Fact{}, errors.WithMessagef(ErrMalformed,
+			"compact fact %q: %v", s, err)
+	} else if len(data) < 1 {
+		return Fact{}, errors.WithMessagef(ErrMalformed,
+			"compact fact %q has no header", s)
+	}
+
+	f := Fact{
+		T:      FactType(data[0] >> 4),
+		Status: FactStatus(data[0] & compactStatusMask),
+		Fact:   string(data[1:]),
+	}
+	if data[0]&compactLocaleFlag != 0 {
+		parts := strings.SplitN(f.Fact, localeTerminator, 2)
+		if len(parts) != 2 {
+			return Fact{}, errors.WithMessagef(ErrMalformed,
+				"compact fact %q is missing locale terminator", s)
+		}
+		f.Locale, f.Fact = parts[0], parts[1]
+	}
+	if data[0]&compactDisplayFlag != 0 {
+		f.Display, f.Fact = f.Fact, canonicalize(f.T, f.Fact)
+		if f.Display == f.Fact {
+			return Fact{}, errors.WithMessagef(ErrMalformed,
+				"compact fact %q has a display value equal to the fact", s)
+		}
+	}
+
+	if len(f.Fact) > maxFactLen {
+		return Fact{}, errors.WithMessagef(ErrTooLong, "Fact (%s) exceeds "+
+			"maximum character limit for a fact (%d characters)",
+			f.Fact, maxFactLen)
+	} else if err = validateFact(f, false); err != nil {
+		return Fact{}, err
+	}
+
+	return f, nil
+}
+
+// encodeBase45 encodes the data in base 45 using CompactAlphabet as specified
+// in RFC 9285.
+func encodeBase45(data []byte) string {
+	var sb strings.Builder
+	sb.Grow((len(data)*3 + 1) / 2)
+	for i := 0; i+1 < len(data); i += 2 {
+		n := int(data[i])<<8 | int(data[i+1])
+		sb.WriteByte(CompactAlphabet[n%compactBase])
+		sb.WriteByte(CompactAlphabet[n/compactBase%compactBase])
+		sb.WriteByte(CompactAlphabet[n/(compactBase*compactBase)])
+	}
+	if len(data)%2 == 1 {
+		n := int(data[len(data)-1])
+		sb.WriteByte(CompactAlphabet[n%compactBase])
+		sb.WriteByte(CompactAlphabet[n/compactBase])
+	}
+
+	return sb.String()
+}
+
+// decodeBase45 decodes a string encoded with encodeBase45.
+func decodeBase45(s string) ([]byte, error) {
+	if len(s)%3 == 1 {
+		return nil, errors.Errorf("invalid base 45 length %d", len(s))
+	}
+
+	data := make([]byte, 0, len(s)*2/3+1)
+	for i := 0; i < len(s); i += 3 {
+		chunk := s[i:]
+		if len(chunk) > 3 {
+			chunk = chunk[:3]
+		}
+
+		// The least significant digit is first
+		n, weight := 0, 1
+		for j := range chunk {
+			digit := strings.IndexByte(CompactAlphabet, chunk[j])
+			if digit < 0 {
+				return nil, errors.Errorf(
+					"invalid base 45 character %q", chunk[j])
+			}
+			n += digit * weight
+			weight *= compactBase
+		}
+
+		if len(chunk) == 3 {
+			if n > 0xFFFF {
+				return nil, errors.Errorf("base 45 chunk %q overflows", chunk)
+			}
+			data = append(data, byte(n>>8), byte(n))
+		} else {
+			if n > 0xFF {
+				return nil, errors.Errorf("base 45 chunk %q overflows", chunk)
+			}
+			data = append(data, byte(n))
+		}
+	}
+
+	return data, nil
+}
+
+// compactCheckDigit returns the index in CompactAlphabet of the check digit of
+// the string. The check digit makes the weighted sum of the digits of the
+// string and the check digit, with weights alternating between 1 and 2 from the
+// check digit backwards, a multiple of 45. Because both weights and their
+// difference are coprime with 45, it detects every single character error and
+// every transposition of adjacent characters. Characters outside of the
+// alphabet are treated as zero; they are rejected when decoding.
+func compactCheckDigit(s string) int {
+	weight, sum := 2, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		digit := strings.IndexByte(CompactAlphabet, s[i])
+		if digit < 0 {
+			digit = 0
+		}
+		sum += weight * digit
+		weight = 3 - weight
+	}
+
+	return (compactBase - sum%compactBase) % compactBase
+}
@@ -0,0 +1,148 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// Tests that a Fact encoded with Fact.CompactEncode and decoded with
+// CompactDecode matches the original and only uses CompactAlphabet.
+func TestFact_CompactEncode_CompactDecode(t *testing.T) {
+	facts := []Fact{
+		{Fact: "john@example.com", T: Email},
+		{Fact: "myUsername", T: Username, Status: Unverified},
+		{Fact: "6502530000", T: Phone, Status: Revoked, Locale: "en-US"},
+		{Fact: "Nick", T: Nickname, Locale: "zh-Hant-TW"},
+	}
+
+	for i, f := range facts {
+		encoded := f.CompactEncode()
+		for _, c := range encoded {
+			if !strings.ContainsRune(CompactAlphabet, c) {
+				t.Errorf("Compact fact %q (%d) contains character %q not in "+
+					"the alphabet.", encoded, i, c)
+			}
+		}
+
+		decoded, err := CompactDecode(encoded)
+		if err != nil {
+			t.Errorf("Failed to decode compact fact %q (%d): %+v",
+				encoded, i, err)
+		} else if decoded != f {
+			t.Errorf("Decoded fact does not match original (%d)."+
+				"\nexpected: %+v\nreceived: %+v", i, f, decoded)
+		}
+	}
+}
+
+// Consistency test of Fact.CompactEncode.
+func TestFact_CompactEncode_Consistency(t *testing.T) {
+	expected := "H32Z3E9.DB$CBECP9ERZCUPCJ2Q"
+	encoded := Fact{Fact: "john@example.com", T: Email}.CompactEncode()
+	if encoded != expected {
+		t.Errorf("Unexpected compact fact.\nexpected: %q\nreceived: %q",
+			expected, encoded)
+	}
+}
+
+// Tests that encodeBase45 matches the examples in RFC 9285 and that
+// decodeBase45 reverses it.
+func Test_encodeBase45_decodeBase45(t *testing.T) {
+	tests := map[string]string{
+		"AB":      "BB8",
+		"Hello!!": "%69 VD92EX0",
+		"base-45": "UJCLQE7W581",
+		"":        "",
+	}
+
+	for data, expected := range tests {
+		encoded := encodeBase45([]byte(data))
+		if encoded != expected {
+			t.Errorf("Unexpected encoding of %q.\nexpected: %q\nreceived: %q",
+				data, expected, encoded)
+		}
+
+		decoded, err := decodeBase45(encoded)
+		if err != nil {
+			t.Errorf("Failed to decode %q: %+v", encoded, err)
+		} else if string(decoded) != data {
+			t.Errorf("Unexpected decoding of %q.\nexpected: %q\nreceived: %q",
+				encoded, data, decoded)
+		}
+	}
+}
+
+// Error path: Tests that CompactDecode returns ErrInvalidCheckDigit for every
+// single character substitution and every transposition of adjacent
+// characters.
+func TestCompactDecode_CheckDigit(t *testing.T) {
+	encoded := Fact{Fact: "john@example.com", T: Email}.CompactEncode()
+
+	for i := 0; i+1 < len(encoded); i++ {
+		if encoded[i] == encoded[i+1] {
+			continue
+		}
+
+		transposed := encoded[:i] + encoded[i+1:i+2] + encoded[i:i+1] +
+			encoded[i+2:]
+		_, err := CompactDecode(transposed)
+		if !errors.Is(err, ErrInvalidCheckDigit) {
+			t.Errorf("Transposition at %d not detected: %v", i, err)
+		}
+	}
+
+	for i := range encoded {
+		for _, c := range []byte(CompactAlphabet) {
+			if c == encoded[i] {
+				continue
+			}
+
+			modified := encoded[:i] + string(c) + encoded[i+1:]
+			_, err := CompactDecode(modified)
+			if !errors.Is(err, ErrInvalidCheckDigit) {
+				t.Errorf("Substitution of %q at %d not detected: %v",
+					c, i, err)
+			}
+		}
+	}
+}
+
+// Error path: Tests that CompactDecode returns an error for malformed compact
+// facts that have a valid check digit.
+func TestCompactDecode_Malformed(t *testing.T) {
+	withCheckDigit := func(encoded string) string {
+		return encoded + string(CompactAlphabet[compactCheckDigit(encoded)])
+	}
+
+	tests := []struct {
+		s        string
+		expected error
+	}{
+		{"", ErrMalformed},
+		{"A", ErrMalformed},
+		{withCheckDigit("ABCD"), ErrMalformed},
+		{withCheckDigit("abc"), ErrMalformed},
+		{withCheckDigit(":::"), ErrMalformed},
+		{withCheckDigit(encodeBase45([]byte{0x18, 'e', 'n'})), ErrMalformed},
+		{withCheckDigit(encodeBase45([]byte{0x10, 'x'})), ErrInvalidEmail},
+		{withCheckDigit(encodeBase45(
+			append([]byte{0x00}, strings.Repeat("a", 65)...))), ErrTooLong},
+	}
+
+	for i, tt := range tests {
+		_, err := CompactDecode(tt.s)
+		if !errors.Is(err, tt.expected) {
+			t.Errorf("Unexpected error for %q (%d).\nexpected: %v"+
+				"\nreceived: %v", tt.s, i, tt.expected, err)
+		}
+	}
+}
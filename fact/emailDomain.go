@@ -0,0 +1,92 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// EmailDomainConfig restricts the domains of email facts accepted by NewFact
+// and ValidateFact, allowing deployments to limit registrations to, for
+// example, corporate domains. It is not applied when decoding stored or
+// received facts (e.g., with UnstringifyFact or DecodeFactListCSV) so that the
+// facts of users registered elsewhere remain readable.
+//
+// A domain in either list matches the domain itself and all of its subdomains,
+// ignoring case; "example.com" matches both "john@example.com" and
+// "john@mail.example.com". An email matching DenyDomains is always rejected.
+// If AllowDomains is not empty, an email must also match one of its domains.
+// The zero value allows all domains.
+type EmailDomainConfig struct {
+	// AllowDomains lists the only domains that are accepted. If empty, all
+	// domains not in DenyDomains are accepted.
+	AllowDomains []string
+
+	// DenyDomains lists domains that are rejected.
+	DenyDomains []string
+}
+
+// ValidateEmailDomain returns ErrEmailDomainNotAllowed if the domain of the
+// email is denied or is not allowed by the EmailDomainConfig. The email is
+// expected to already be a validly formatted address.
+func (c EmailDomainConfig) ValidateEmailDomain(email string) error {
+	at := strings.LastIndexByte(email, '@')
+	domain := strings.ToLower(email[at+1:])
+
+	if matchesDomain(domain, c.DenyDomains) {
+		return errors.WithMessagef(ErrEmailDomainNotAllowed,
+			"domain of email %q is denied", email)
+	} else if len(c.AllowDomains) > 0 &&
+		!matchesDomain(domain, c.AllowDomains) {
+		return errors.WithMessagef(ErrEmailDomainNotAllowed,
+			"domain of email %q is not in the allowed domains", email)
+	}
+
+	return nil
+}
+
+// matchesDomain returns true if the lowercase domain is equal to or is a
+// subdomain of any of the domains in the list.
+func matchesDomain(domain string, list []string) bool {
+	for _, d := range list {
+		d = strings.ToLower(strings.TrimPrefix(d, "."))
+		if domain == d || strings.HasSuffix(domain, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	globalEmailDomainConfig    EmailDomainConfig
+	globalEmailDomainConfigMux sync.RWMutex
+)
+
+// SetEmailDomainConfig sets the EmailDomainConfig used by ValidateFact for
+// email facts. The lists are copied, so later changes to them have no effect.
+// Passing the zero value restores the default, which allows all domains.
+func SetEmailDomainConfig(c EmailDomainConfig) {
+	c = EmailDomainConfig{
+		AllowDomains: append([]string(nil), c.AllowDomains...),
+		DenyDomains:  append([]string(nil), c.DenyDomains...),
+	}
+
+	globalEmailDomainConfigMux.Lock()
+	defer globalEmailDomainConfigMux.Unlock()
+	globalEmailDomainConfig = c
+}
+
+// getEmailDomainConfig returns the current EmailDomainConfig.
+func getEmailDomainConfig() EmailDomainConfig {
+	globalEmailDomainConfigMux.RLock()
+	defer globalEmailDomainConfigMux.RUnlock()
+	return globalEmailDomainConfig
+}
@@ -0,0 +1,120 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// Tests that EmailDomainConfig.ValidateEmailDomain allows and denies domains
+// and their subdomains, ignoring case, and that denied domains take precedence
+// over allowed domains.
+func TestEmailDomainConfig_ValidateEmailDomain(t *testing.T) {
+	c := EmailDomainConfig{
+		AllowDomains: []string{"corp.com", "Example.org"},
+		DenyDomains:  []string{"guest.corp.com"},
+	}
+
+	tests := map[string]bool{
+		"john@corp.com":           true,
+		"john@CORP.com":           true,
+		"john@eng.corp.com":       true,
+		"john@example.org":        true,
+		"john@guest.corp.com":     false,
+		"john@eng.guest.corp.com": false,
+		"john@notcorp.com":        false,
+		"john@corp.com.evil.net":  false,
+		"john@example.com":        false,
+	}
+
+	for email, allowed := range tests {
+		err := c.ValidateEmailDomain(email)
+		if allowed && err != nil {
+			t.Errorf("Email %q not allowed: %+v", email, err)
+		} else if !allowed && !errors.Is(err, ErrEmailDomainNotAllowed) {
+			t.Errorf("Unexpected error for email %q.\nexpected: %v"+
+				"\nreceived: %v", email, ErrEmailDomainNotAllowed, err)
+		}
+	}
+
+	if err := (EmailDomainConfig{}).ValidateEmailDomain("a@b.com"); err != nil {
+		t.Errorf("Zero EmailDomainConfig did not allow email: %+v", err)
+	}
+}
+
+// Tests that ValidateFact applies the EmailDomainConfig set by
+// SetEmailDomainConfig to email facts only, and that the zero value restores
+// the default.
+func TestSetEmailDomainConfig(t *testing.T) {
+	deny := []string{"example.com"}
+	SetEmailDomainConfig(EmailDomainConfig{DenyDomains: deny})
+	defer SetEmailDomainConfig(EmailDomainConfig{})
+
+	// Modifying the list after setting it has no effect
+	deny[0] = "example.org"
+
+	f := Fact{Fact: "john@example.com", T: Email}
+	if err := ValidateFact(f); !errors.Is(err, ErrEmailDomainNotAllowed) {
+		t.Errorf("Unexpected error for denied email.\nexpected: %v"+
+			"\nreceived: %v", ErrEmailDomainNotAllowed, err)
+	}
+	if err := ValidateFact(Fact{Fact: "example.com", T: Nickname}); err != nil {
+		t.Errorf("ValidateFact denied nickname: %+v", err)
+	}
+
+	SetEmailDomainConfig(EmailDomainConfig{})
+	if err := ValidateFact(f); err != nil {
+		t.Errorf("ValidateFact denied email with default config: %+v", err)
+	}
+}
+
+// Tests that the EmailDomainConfig set by SetEmailDomainConfig is applied by
+// NewFact but not when decoding facts received from other users.
+func TestSetEmailDomainConfig_Decode(t *testing.T) {
+	SetEmailDomainConfig(EmailDomainConfig{AllowDomains: []string{"corp.com"}})
+	defer SetEmailDomainConfig(EmailDomainConfig{})
+
+	expected := Fact{Fact: "john@example.com", T: Email}
+	if _, err := NewFact(Email, expected.Fact); !errors.Is(
+		err, ErrEmailDomainNotAllowed) {
+		t.Errorf("Unexpected error for email not in allow list."+
+			"\nexpected: %v\nreceived: %v", ErrEmailDomainNotAllowed, err)
+	}
+
+	decoders := map[string]func() (Fact, error){
+		"UnstringifyFact": func() (Fact, error) {
+			return UnstringifyFact(expected.Stringify())
+		},
+		"FromURLValue": func() (Fact, error) {
+			return FromURLValue(expected.ToURLValue())
+		},
+		"CompactDecode": func() (Fact, error) {
+			return CompactDecode(expected.CompactEncode())
+		},
+		"DecodeFactListCSV": func() (Fact, error) {
+			fl, err := DecodeFactListCSV(FactList{expected}.EncodeCSV())
+			if err != nil {
+				return Fact{}, err
+			}
+			return fl[0], nil
+		},
+	}
+
+	for name, decode := range decoders {
+		f, err := decode()
+		if err != nil {
+			t.Errorf("%s failed to decode email not in allow list: %+v",
+				name, err)
+		} else if f != expected {
+			t.Errorf("%s decoded unexpected fact.\nexpected: %+v"+
+				"\nreceived: %+v", name, expected, f)
+		}
+	}
+}
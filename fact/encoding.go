@@ -0,0 +1,114 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// The number of columns in each record of a FactList CSV. Records without the
+// trailing locale and display columns, or without only the display column, are
+// also accepted.
+const factCSVColumns = 5
+
+// ToURLValue marshals the Fact into a string that can be safely embedded in a
+// URL query parameter or invite link. The fact is stringified in the v2 format
+// and then percent-escaped.
+func (f Fact) ToURLValue() string {
+	return url.QueryEscape(f.StringifyV2())
+}
+
+// FromURLValue unmarshalls a Fact encoded with Fact.ToURLValue.
+func FromURLValue(s string) (Fact, error) {
+	unescaped, err := url.QueryUnescape(s)
+	if err != nil {
+		return Fact{}, errors.Wrapf(err, "Failed to unescape fact %q", s)
+	}
+
+	return UnstringifyFact(unescaped)
+}
+
+// EncodeCSV marshals the FactList into a CSV with one fact per record. Each
+// record contains the stringified FactType, the stringified FactStatus, the
+// fact, the locale, and, if the fact has one, the display value. Unlike
+// FactList.Stringify, facts may contain commas, semicolons, and quotes.
+func (fl FactList) EncodeCSV() []byte {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	for _, f := range fl {
+		record := []string{
+			f.T.Stringify(), f.Status.Stringify(), f.Fact, f.Locale}
+		if f.Display != "" {
+			record = append(record, f.Display)
+		}
+
+		// Writes to a bytes.Buffer do not fail
+		_ = w.Write(record)
+	}
+	w.Flush()
+
+	return buf.Bytes()
+}
+
+// DecodeFactListCSV unmarshalls a FactList CSV produced by FactList.EncodeCSV.
+// Returns an error if any record is malformed or contains an invalid fact.
+func DecodeFactListCSV(data []byte) (FactList, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read fact list CSV records")
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	fl := make(FactList, len(records))
+	for i, record := range records {
+		if len(record) < factCSVColumns-2 || len(record) > factCSVColumns {
+			return nil, errors.Errorf("fact %d of %d has %d fields; "+
+				"expected %d", i, len(records), len(record), factCSVColumns)
+		}
+
+		ft, err := UnstringifyFactType(record[0])
+		if err != nil {
+			return nil, errors.WithMessagef(err,
+				"Failed to decode type of fact %d of %d", i, len(records))
+		}
+
+		status, err := UnstringifyFactStatus(record[1])
+		if err != nil {
+			return nil, errors.WithMessagef(err,
+				"Failed to decode status of fact %d of %d", i, len(records))
+		}
+
+		fl[i] = Fact{Fact: record[2], T: ft, Status: status}
+		if len(record) > factCSVColumns-2 {
+			fl[i].Locale = record[3]
+		}
+		if len(record) == factCSVColumns {
+			fl[i].Display = record[4]
+		}
+
+		if len(fl[i].Fact) > maxFactLen || len(fl[i].Display) > maxFactLen {
+			return nil, errors.WithMessagef(ErrTooLong, "fact %d of %d exceeds "+
+				"maximum character limit for a fact (%d characters)",
+				i, len(records), maxFactLen)
+		} else if err = validateFact(fl[i], false); err != nil {
+			return nil, errors.WithMessagef(err,
+				"Invalid fact %d of %d", i, len(records))
+		}
+	}
+
+	return fl, nil
+}
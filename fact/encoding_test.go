@@ -0,0 +1,102 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// Tests that a Fact encoded with Fact.ToURLValue can be embedded in a URL
+// query and decoded with FromURLValue.
+func TestFact_ToURLValue_FromURLValue(t *testing.T) {
+	facts := []Fact{
+		{Fact: "john@example.com", T: Email},
+		{Fact: "Bob, the builder & co?", T: Nickname, Status: Unverified},
+		{Fact: "a/b#c=d%20", T: Nickname, Status: Revoked},
+	}
+
+	for i, f := range facts {
+		u, err := url.Parse("https://example.com/invite?fact=" + f.ToURLValue())
+		if err != nil {
+			t.Fatalf("Failed to parse URL for fact %d: %+v", i, err)
+		}
+
+		received, err := FromURLValue(url.QueryEscape(u.Query().Get("fact")))
+		if err != nil {
+			t.Errorf("Failed to decode fact %d: %+v", i, err)
+		} else if !reflect.DeepEqual(f, received) {
+			t.Errorf("Unexpected fact (%d).\nexpected: %+v\nreceived: %+v",
+				i, f, received)
+		}
+	}
+}
+
+// Tests that FromURLValue returns an error for invalid escaping.
+func TestFromURLValue_InvalidEscapeError(t *testing.T) {
+	if _, err := FromURLValue("2NA%zz"); err == nil {
+		t.Error("Expected error for invalid percent-escaping.")
+	}
+}
+
+// Tests that a FactList encoded with FactList.EncodeCSV and decoded with
+// DecodeFactListCSV matches the original.
+func TestFactList_EncodeCSV_DecodeFactListCSV(t *testing.T) {
+	expected := FactList{
+		{Fact: "vivian@elixxir.io", T: Email},
+		{Fact: "Comma, \"quoted\"; nick", T: Nickname, Status: Unverified},
+		{Fact: "myUsername", T: Username, Status: Revoked},
+		{Fact: "6502530000", T: Phone, Locale: "en-US"},
+	}
+
+	fl, err := DecodeFactListCSV(expected.EncodeCSV())
+	if err != nil {
+		t.Fatalf("Failed to decode CSV: %+v", err)
+	}
+
+	if !reflect.DeepEqual(expected, fl) {
+		t.Errorf("Unexpected decoded FactList.\nexpected: %+v\nreceived: %+v",
+			expected, fl)
+	}
+}
+
+// Tests that DecodeFactListCSV accepts records without the locale column.
+func TestDecodeFactListCSV_NoLocale(t *testing.T) {
+	expected := FactList{{Fact: "nick", T: Nickname, Status: Unverified}}
+
+	fl, err := DecodeFactListCSV([]byte("N,U,nick\n"))
+	if err != nil {
+		t.Fatalf("Failed to decode CSV: %+v", err)
+	}
+
+	if !reflect.DeepEqual(expected, fl) {
+		t.Errorf("Unexpected decoded FactList.\nexpected: %+v\nreceived: %+v",
+			expected, fl)
+	}
+}
+
+// Tests that DecodeFactListCSV returns an error for malformed records and
+// invalid facts.
+func TestDecodeFactListCSV_Error(t *testing.T) {
+	tests := []string{
+		"E,A\n",
+		"E,A,a@b.com,en,A@b.com,extra\n",
+		"E,A,a@b.com,en,other@b.com\n",
+		"N,A,nick,not a locale\n",
+		"X,A,fact\n",
+		"N,X,nick\n",
+		"E,A,notAnEmail\n",
+	}
+
+	for i, data := range tests {
+		if _, err := DecodeFactListCSV([]byte(data)); err == nil {
+			t.Errorf("Expected error for CSV %q (%d).", data, i)
+		}
+	}
+}
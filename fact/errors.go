@@ -0,0 +1,128 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Errors returned, wrapped with details, by NewFact, ValidateFact,
+// UnstringifyFact, VerificationRequest.Verify, and related functions. Use
+// errors.Is to check the cause of a failure.
+var (
+	// ErrTooLong is returned when a fact exceeds the maximum length.
+	ErrTooLong = errors.New("fact exceeds maximum length")
+
+	// ErrEmpty is returned when a fact has no contents.
+	ErrEmpty = errors.New("fact is empty")
+
+	// ErrMalformed is returned when a stringified fact cannot be parsed.
+	ErrMalformed = errors.New("malformed stringified fact")
+
+	// ErrInvalidEmail is returned when an email fact is not a valid address.
+	ErrInvalidEmail = errors.New("invalid email address")
+
+	// ErrEmailDomainNotAllowed is returned when the domain of an email fact is
+	// rejected by the EmailDomainConfig set via SetEmailDomainConfig.
+	ErrEmailDomainNotAllowed = errors.New("email domain not allowed")
+
+	// ErrInvalidPhone is returned when a phone fact is not a valid number.
+	ErrInvalidPhone = errors.New("invalid phone number")
+
+	// ErrPhoneNotSMSCapable is returned by ValidateSMSCapable when a phone fact
+	// is a valid number that cannot receive SMS messages, such as a landline.
+	ErrPhoneNotSMSCapable = errors.New("phone number cannot receive SMS")
+
+	// ErrInvalidUsername is returned when a username fact breaks the username
+	// rules or is reserved; see ValidateUsername.
+	ErrInvalidUsername = errors.New("invalid username")
+
+	// ErrInvalidNickname is returned when a nickname fact is not valid.
+	ErrInvalidNickname = errors.New("invalid nickname")
+
+	// ErrInvalidCheckDigit is returned when the check digit of a compact fact
+	// does not match its contents.
+	ErrInvalidCheckDigit = errors.New("invalid check digit")
+
+	// ErrInvalidLocale is returned when a fact's locale is not a valid
+	// language tag.
+	ErrInvalidLocale = errors.New("invalid locale")
+
+	// ErrVerificationExpired is returned when a verification code is submitted
+	// after the VerificationRequest has expired.
+	ErrVerificationExpired = errors.New("verification request expired")
+
+	// ErrVerificationAttempts is returned when a verification code is
+	// submitted after the VerificationRequest has used all of its attempts.
+	ErrVerificationAttempts = errors.New("too many verification attempts")
+
+	// ErrVerificationFact is returned when a VerificationResponse is for a
+	// different fact than the VerificationRequest.
+	ErrVerificationFact = errors.New("verification fact does not match")
+
+	// ErrVerificationCode is returned when a verification code is incorrect.
+	ErrVerificationCode = errors.New("incorrect verification code")
+)
+
+// ErrUnknownType is returned when a fact has a FactType that is not defined.
+// Use errors.As to retrieve the type. Every ErrUnknownType matches any other
+// with errors.Is, so errors.Is(err, ErrUnknownType{}) detects all of them.
+type ErrUnknownType struct {
+	// Type is the unknown FactType
+	Type FactType
+
+	// Symbol is the unknown stringified FactType, if the type was parsed from
+	// a string.
+	Symbol string
+}
+
+// Error returns the ErrUnknownType as a string. This function adheres to the
+// error interface.
+func (e ErrUnknownType) Error() string {
+	if e.Symbol != "" {
+		return "Unknown Fact FactType: " + e.Symbol
+	}
+	return "Unknown fact type: " + strconv.FormatUint(uint64(e.Type), 10)
+}
+
+// Is determines if the target is an ErrUnknownType. This function is used by
+// errors.Is.
+func (e ErrUnknownType) Is(target error) bool {
+	_, ok := target.(ErrUnknownType)
+	return ok
+}
+
+// ErrUnknownStatus is returned when a fact has a FactStatus that is not
+// defined. Use errors.As to retrieve the status. Every ErrUnknownStatus matches
+// any other with errors.Is.
+type ErrUnknownStatus struct {
+	// Status is the unknown FactStatus
+	Status FactStatus
+
+	// Symbol is the unknown stringified FactStatus, if the status was parsed
+	// from a string.
+	Symbol string
+}
+
+// Error returns the ErrUnknownStatus as a string. This function adheres to the
+// error interface.
+func (e ErrUnknownStatus) Error() string {
+	if e.Symbol != "" {
+		return "Unknown Fact FactStatus: " + e.Symbol
+	}
+	return "Unknown fact status: " + strconv.FormatUint(uint64(e.Status), 10)
+}
+
+// Is determines if the target is an ErrUnknownStatus. This function is used by
+// errors.Is.
+func (e ErrUnknownStatus) Is(target error) bool {
+	_, ok := target.(ErrUnknownStatus)
+	return ok
+}
@@ -0,0 +1,107 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// Tests that the errors returned by NewFact, UnstringifyFact, and ValidateFact
+// match their sentinel error with errors.Is.
+func TestFact_SentinelErrors(t *testing.T) {
+	newFact := func(ft FactType, fact string) error {
+		_, err := NewFact(ft, fact)
+		return err
+	}
+	unstringify := func(s string) error {
+		_, err := UnstringifyFact(s)
+		return err
+	}
+	decodeCSV := func(s string) error {
+		_, err := DecodeFactListCSV([]byte(s))
+		return err
+	}
+
+	tests := []struct {
+		err      error
+		expected error
+	}{
+		{newFact(Email, strings.Repeat("a", 65)), ErrTooLong},
+		{unstringify("U" + strings.Repeat("a", 64)), ErrTooLong},
+		{decodeCSV("N,U," + strings.Repeat("a", 65) + "\n"), ErrTooLong},
+		{unstringify(""), ErrEmpty},
+		{unstringify("U"), ErrEmpty},
+		{unstringify("2U"), ErrMalformed},
+		{unstringify("2Ueen-US"), ErrMalformed},
+		{newFact(Email, "notAnEmail"), ErrInvalidEmail},
+		{newFact(Phone, "8005559486"), ErrInvalidPhone},
+		{newFact(Phone, "+343511234567ES"), ErrInvalidPhone},
+		{newFact(Nickname, "ab"), ErrInvalidNickname},
+		{ValidateFact(Fact{Fact: "john", T: Username, Locale: "e"}),
+			ErrInvalidLocale},
+	}
+
+	for i, tt := range tests {
+		if !errors.Is(tt.err, tt.expected) {
+			t.Errorf("Error %d does not match sentinel error."+
+				"\nexpected: %v\nreceived: %+v", i, tt.expected, tt.err)
+		}
+	}
+}
+
+// Tests that ErrUnknownType can be retrieved with errors.As from the errors
+// returned by UnstringifyFact and ValidateFact.
+func TestErrUnknownType_As(t *testing.T) {
+	_, err := UnstringifyFact("QA")
+	var e ErrUnknownType
+	if !errors.As(err, &e) {
+		t.Fatalf("Failed to get ErrUnknownType from error: %+v", err)
+	} else if e.Symbol != "Q" {
+		t.Errorf("Unexpected symbol.\nexpected: %q\nreceived: %q", "Q", e.Symbol)
+	}
+
+	err = ValidateFact(Fact{Fact: "fact", T: 99})
+	if !errors.As(err, &e) {
+		t.Fatalf("Failed to get ErrUnknownType from error: %+v", err)
+	} else if e.Type != 99 || e.Symbol != "" {
+		t.Errorf("Unexpected ErrUnknownType.\nexpected: %+v\nreceived: %+v",
+			ErrUnknownType{Type: 99}, e)
+	}
+
+	if !errors.Is(err, ErrUnknownType{}) {
+		t.Errorf("Error does not match ErrUnknownType: %+v", err)
+	} else if errors.Is(err, ErrUnknownStatus{}) || errors.Is(err, ErrTooLong) {
+		t.Errorf("Error matches unrelated errors: %+v", err)
+	}
+}
+
+// Tests that ErrUnknownStatus can be retrieved with errors.As from the errors
+// returned by UnstringifyFact and ValidateFact.
+func TestErrUnknownStatus_As(t *testing.T) {
+	_, err := UnstringifyFact("2UQjohn")
+	var e ErrUnknownStatus
+	if !errors.As(err, &e) {
+		t.Fatalf("Failed to get ErrUnknownStatus from error: %+v", err)
+	} else if e.Symbol != "Q" {
+		t.Errorf("Unexpected symbol.\nexpected: %q\nreceived: %q", "Q", e.Symbol)
+	}
+
+	err = ValidateFact(Fact{Fact: "john", T: Username, Status: 99})
+	if !errors.As(err, &e) {
+		t.Fatalf("Failed to get ErrUnknownStatus from error: %+v", err)
+	} else if e.Status != 99 {
+		t.Errorf("Unexpected status.\nexpected: %d\nreceived: %d", 99, e.Status)
+	}
+
+	if !errors.Is(err, ErrUnknownStatus{}) {
+		t.Errorf("Error does not match ErrUnknownStatus: %+v", err)
+	}
+}
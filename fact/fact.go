@@ -8,6 +8,7 @@
 package fact
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/badoux/checkmail"
@@ -21,36 +22,71 @@ const (
 
 	// The minimum character length of a nickname.
 	minNicknameLen = 3
+
+	// factV2Prefix marks a fact stringified in the v2 format. A v1 stringified
+	// fact always starts with a FactType, so the two cannot be confused.
+	factV2Prefix = "2"
+
+	// The length of the v2 header: the prefix, FactType, and FactStatus.
+	factV2HeaderLen = 3
+
+	// localeTerminator ends the locale in a v2 stringified fact.
+	localeTerminator = ":"
 )
 
 // Fact represents a piece of user-identifying information. This structure can
-// be JSON marshalled and unmarshalled.
+// be JSON marshalled and unmarshalled. The status is omitted when it is Active
+// and the locale and display value are omitted when they are not set.
 //
 // JSON example:
 //
 //	{
-//	  "Fact": "john@example.com",
-//	  "T": 1
+//	  "Fact": "john.doe@example.com",
+//	  "T": 1,
+//	  "S": 2,
+//	  "L": "en-US",
+//	  "D": "John.Doe@Example.com"
 //	}
 type Fact struct {
-	Fact string   `json:"Fact"`
-	T    FactType `json:"T"`
+	// Fact is the canonical form of the fact used for searching and
+	// comparison. See Fact.GetCanonical.
+	Fact   string     `json:"Fact"`
+	T      FactType   `json:"T"`
+	Status FactStatus `json:"S,omitempty"`
+
+	// Locale is an optional BCP 47 language tag (e.g., "en-US") describing
+	// the owner's language and region. See ValidateLocale.
+	Locale string `json:"L,omitempty"`
+
+	// Display is the fact as entered by the user. It is only set when it
+	// differs from the canonical form. See Fact.GetDisplay.
+	Display string `json:"D,omitempty"`
 }
 
 // NewFact checks if the inputted information is a valid fact on the
 // fact type. If so, it returns a new fact object. If not, it returns a
-// validation error.
+// validation error. The fact is stored in its canonical form and, if it
+// differs, the inputted fact is kept as the display value.
 func NewFact(ft FactType, fact string) (Fact, error) {
+	return newFact(ft, fact, true)
+}
+
+// newFact creates a new Fact as described in NewFact. If registration is false,
+// the registration rules are skipped as described in validateFact.
+func newFact(ft FactType, fact string, registration bool) (Fact, error) {
 	if len(fact) > maxFactLen {
-		return Fact{}, errors.Errorf("Fact (%s) exceeds maximum character limit "+
-			"for a fact (%d characters)", fact, maxFactLen)
+		return Fact{}, errors.WithMessagef(ErrTooLong, "Fact (%s) exceeds "+
+			"maximum character limit for a fact (%d characters)", fact, maxFactLen)
 	}
 
 	f := Fact{
-		Fact: fact,
+		Fact: canonicalize(ft, fact),
 		T:    ft,
 	}
-	if err := ValidateFact(f); err != nil {
+	if f.Fact != fact {
+		f.Display = fact
+	}
+	if err := validateFact(f, registration); err != nil {
 		return Fact{}, err
 	}
 
@@ -59,26 +95,79 @@ func NewFact(ft FactType, fact string) (Fact, error) {
 
 // Stringify marshals the Fact for transmission for UDB. It is not a part of the
 // fact interface.
+//
+// Only the canonical fact is included, so the display value is lost; use
+// Fact.StringifyV2 to keep it. Because NewFact canonicalizes emails to
+// lowercase and phone numbers to uppercase, a fact created from mixed-case
+// input is stringified in its canonical case (e.g., "EJohn@Example.com"
+// becomes "Ejohn@example.com").
 func (f Fact) Stringify() string {
 	return f.T.Stringify() + f.Fact
 }
 
-// UnstringifyFact unmarshalls the stringified fact into a Fact.
+// StringifyV2 marshals the Fact, including its FactStatus, locale, and
+// display value, for transmission for UDB. The v2 format is the v2 prefix,
+// followed by the stringified FactType, the stringified FactStatus, and the
+// fact. If the fact has a locale, the status is lowercase and is followed by
+// the locale and a colon. If the fact has a display value, the FactType is
+// lowercase and the display value, prefixed with its length and a colon, is
+// placed before the fact.
+//
+// Examples:
+//
+//	2ERjohn@example.com
+//	2Ern-US:john@example.com
+//	2eR20:John.Doe@Example.comjohn.doe@example.com
+func (f Fact) StringifyV2() string {
+	var sb strings.Builder
+	sb.WriteString(factV2Prefix)
+	if f.Display == "" {
+		sb.WriteString(f.T.Stringify())
+	} else {
+		sb.WriteString(strings.ToLower(f.T.Stringify()))
+	}
+
+	if f.Locale == "" {
+		sb.WriteString(f.Status.Stringify())
+	} else {
+		sb.WriteString(strings.ToLower(f.Status.Stringify()))
+		sb.WriteString(f.Locale + localeTerminator)
+	}
+
+	if f.Display != "" {
+		sb.WriteString(strconv.Itoa(len(f.Display)) + localeTerminator)
+		sb.WriteString(f.Display)
+	}
+	sb.WriteString(f.Fact)
+
+	return sb.String()
+}
+
+// UnstringifyFact unmarshalls the stringified fact into a Fact. Both the v1
+// format produced by Fact.Stringify and the v2 format produced by
+// Fact.StringifyV2 are accepted. Usernames are not checked against
+// ValidateUsername, usernames and nicknames are not checked against the Policy,
+// and emails are not checked against the EmailDomainConfig, so that facts
+// stored or received from other users can still be decoded.
 func UnstringifyFact(s string) (Fact, error) {
+	if strings.HasPrefix(s, factV2Prefix) {
+		return unstringifyFactV2(s)
+	}
+
 	if len(s) < 1 {
-		return Fact{}, errors.New("stringified facts must at least " +
-			"have a type at the start")
+		return Fact{}, errors.WithMessage(ErrEmpty, "stringified facts must at "+
+			"least have a type at the start")
 	}
 
 	if len(s) > maxFactLen {
-		return Fact{}, errors.Errorf("Fact (%s) exceeds maximum character limit "+
-			"for a fact (%d characters)", s, maxFactLen)
+		return Fact{}, errors.WithMessagef(ErrTooLong, "Fact (%s) exceeds "+
+			"maximum character limit for a fact (%d characters)", s, maxFactLen)
 	}
 
 	T := s[:1]
 	fact := s[1:]
 	if len(fact) == 0 {
-		return Fact{}, errors.New(
+		return Fact{}, errors.WithMessage(ErrEmpty,
 			"stringified facts must be at least 1 character long")
 	}
 	ft, err := UnstringifyFactType(T)
@@ -87,7 +176,70 @@ func UnstringifyFact(s string) (Fact, error) {
 			"Failed to unstringify fact type for %q", s)
 	}
 
-	return NewFact(ft, fact)
+	return newFact(ft, fact, false)
+}
+
+// unstringifyFactV2 unmarshalls a fact stringified in the v2 format.
+func unstringifyFactV2(s string) (Fact, error) {
+	if len(s) < factV2HeaderLen {
+		return Fact{}, errors.WithMessage(ErrMalformed, "v2 stringified facts must "+
+			"at least have a prefix, type, and status at the start")
+	}
+
+	// A lowercase type indicates that a display value precedes the fact
+	typeString, display := s[1:2], ""
+	hasDisplay := isLowercase(typeString)
+	typeString = strings.ToUpper(typeString)
+
+	// A lowercase status indicates that a locale follows the header
+	statusString, fact, locale := s[2:3], s[factV2HeaderLen:], ""
+	if isLowercase(statusString) {
+		parts := strings.SplitN(fact, localeTerminator, 2)
+		if len(parts) != 2 {
+			return Fact{}, errors.WithMessagef(ErrMalformed,
+				"v2 stringified fact %q is missing locale terminator", s)
+		}
+		statusString, locale, fact = strings.ToUpper(statusString),
+			parts[0], parts[1]
+	}
+
+	if hasDisplay {
+		parts := strings.SplitN(fact, localeTerminator, 2)
+		n, err := strconv.Atoi(parts[0])
+		if len(parts) != 2 || err != nil || n < 1 || n > len(parts[1]) {
+			return Fact{}, errors.WithMessagef(ErrMalformed,
+				"v2 stringified fact %q has an invalid display value", s)
+		}
+		display, fact = parts[1][:n], parts[1][n:]
+	}
+
+	status, err := UnstringifyFactStatus(statusString)
+	if err != nil {
+		return Fact{}, errors.WithMessagef(err,
+			"Failed to unstringify fact status for %q", s)
+	}
+
+	if len(fact) > maxFactLen || len(display) > maxFactLen {
+		return Fact{}, errors.WithMessagef(ErrTooLong, "Fact (%s) exceeds "+
+			"maximum character limit for a fact (%d characters)", s, maxFactLen)
+	}
+
+	f := Fact{Fact: fact, T: 99, Status: status, Locale: locale,
+		Display: display}
+	f.T, err = UnstringifyFactType(typeString)
+	if err != nil {
+		return Fact{}, errors.WithMessagef(err,
+			"Failed to unstringify fact type for %q", s)
+	} else if len(fact) == 0 {
+		return Fact{}, errors.WithMessage(ErrEmpty,
+			"stringified facts must be at least 1 character long")
+	}
+
+	if err = validateFact(f, false); err != nil {
+		return Fact{}, err
+	}
+
+	return f, nil
 }
 
 // Normalized returns the fact in all uppercase letters.
@@ -95,23 +247,94 @@ func (f Fact) Normalized() string {
 	return strings.ToUpper(f.Fact)
 }
 
-// ValidateFact checks the fact to see if it valid based on its type.
+// GetDisplay returns the fact as entered by the user, which is the form that
+// should be shown in user interfaces.
+func (f Fact) GetDisplay() string {
+	if f.Display != "" {
+		return f.Display
+	}
+	return f.Fact
+}
+
+// GetCanonical returns the canonical form of the fact, which is the form that
+// should be used for searching. Emails are lowercase and the country codes of
+// phone numbers are uppercase; usernames and nicknames are unchanged.
+func (f Fact) GetCanonical() string {
+	return canonicalize(f.T, f.Fact)
+}
+
+// canonicalize returns the canonical form of the fact of the given type.
+func canonicalize(t FactType, fact string) string {
+	switch t {
+	case Email:
+		return strings.ToLower(fact)
+	case Phone:
+		return strings.ToUpper(fact)
+	default:
+		return fact
+	}
+}
+
+// isLowercase determines if the string contains a letter and has no uppercase
+// letters.
+func isLowercase(s string) bool {
+	return s == strings.ToLower(s) && s != strings.ToUpper(s)
+}
+
+// ValidateFact checks the fact to see if it valid based on its type and that
+// its display value, if it has one, has the fact as its canonical form.
+// Usernames and nicknames are additionally checked against the Policy set via
+// SetGlobalFactPolicy and emails against the EmailDomainConfig set via
+// SetEmailDomainConfig.
 func ValidateFact(fact Fact) error {
+	return validateFact(fact, true)
+}
+
+// validateFact checks the fact as described in ValidateFact. If registration is
+// false, the deployment rules are skipped: usernames are not checked against
+// ValidateUsername, usernames and nicknames are not checked against the Policy,
+// and emails are not checked against the EmailDomainConfig. This is used when
+// decoding facts, which may have been registered before the username rules
+// were introduced or under a different deployment's configuration.
+func validateFact(fact Fact, registration bool) error {
+	if !fact.Status.IsValid() {
+		return ErrUnknownStatus{Status: fact.Status}
+	} else if err := ValidateLocale(fact.Locale); err != nil {
+		return err
+	} else if fact.Display != "" &&
+		canonicalize(fact.T, fact.Display) != fact.Fact {
+		return errors.WithMessagef(ErrMalformed, "display value %q is not a "+
+			"form of fact %q", fact.Display, fact.Fact)
+	}
+
 	switch fact.T {
 	case Username:
-		return nil
+		if !registration {
+			return nil
+		} else if err := ValidateUsername(fact.Fact); err != nil {
+			return err
+		}
+		return getGlobalFactPolicy().ValidateUsername(fact.Fact)
 	case Phone:
-		// Extract specific information for validating a number
-		// TODO: removes phone validation entirely. It is not used right now anyhow
-		number, code := extractNumberInfo(fact.Fact)
-		return validateNumber(number, code)
+		_, err := fact.ParsePhone()
+		return err
 	case Email:
 		// Check input of email inputted
-		return validateEmail(fact.Fact)
+		if err := validateEmail(fact.Fact); err != nil {
+			return err
+		} else if !registration {
+			return nil
+		}
+		return getEmailDomainConfig().ValidateEmailDomain(fact.Fact)
 	case Nickname:
-		return validateNickname(fact.Fact)
+		if err := validateNickname(fact.Fact); err != nil {
+			return err
+		} else if !registration {
+			return nil
+		}
+		return getGlobalFactPolicy().ValidateNickname(fact.Fact)
 	default:
-		return errors.Errorf("Unknown fact type: %d", fact.T)
+		return ErrUnknownType{Type: fact.T}
 	}
 }
 
@@ -119,8 +342,16 @@ func ValidateFact(fact Fact) error {
 // to the fact, with the rest of the information being a phone number
 // Example: 6502530000US is a valid US number with the country code
 // that would be the fact information for a phone number
-func extractNumberInfo(fact string) (number, countryCode string) {
+// If the fact does not end in a country code, the region of the locale, if it
+// has one, is used as the country code for the entire fact.
+func extractNumberInfo(fact, locale string) (number, countryCode string) {
 	factLen := len(fact)
+	if region := localeRegion(locale); region != "" &&
+		(factLen < 2 || !isAlpha(fact[factLen-2:])) {
+		return fact, region
+	} else if factLen < 2 {
+		return fact, ""
+	}
 	number = fact[:factLen-2]
 	countryCode = fact[factLen-2:]
 	return
@@ -130,7 +361,8 @@ func extractNumberInfo(fact string) (number, countryCode string) {
 func validateEmail(email string) error {
 	// Check that the input is validly formatted
 	if err := checkmail.ValidateFormat(email); err != nil {
-		return errors.Wrapf(err, "Could not validate format for email %q", email)
+		return errors.WithMessagef(ErrInvalidEmail,
+			"Could not validate format for email %q: %v", email, err)
 	}
 
 	return nil
@@ -142,22 +374,30 @@ func validateNumber(number, countryCode string) error {
 	catchPanic := func(number, countryCode string) (err error) {
 		defer func() {
 			if r := recover(); r != nil {
-				err = errors.Errorf("Crash occured on phone validation of: "+
-					"number: %s, country code: %s: %+v", number, countryCode, r)
+				err = errors.WithMessagef(ErrInvalidPhone, "Crash occured on "+
+					"phone validation of: number: %s, country code: %s: %+v",
+					number, countryCode, r)
 			}
 		}()
 
 		if len(number) == 0 || len(countryCode) == 0 {
-			err = errors.New("Number or input are of length 0")
+			err = errors.WithMessage(ErrInvalidPhone,
+				"Number or input are of length 0")
 			return err
 		}
 		num, err := libphonenumber.Parse(number, countryCode)
-		if err != nil || num == nil {
-			err = errors.Wrapf(err, "Could not parse number %q", number)
+		if err != nil {
+			err = errors.WithMessagef(ErrInvalidPhone,
+				"Could not parse number %q: %v", number, err)
+			return err
+		} else if num == nil {
+			err = errors.WithMessagef(ErrInvalidPhone,
+				"Could not parse number %q", number)
 			return err
 		}
 		if !libphonenumber.IsValidNumber(num) {
-			err = errors.Errorf("Could not validate number %q", number)
+			err = errors.WithMessagef(ErrInvalidPhone,
+				"Could not validate number %q", number)
 			return err
 		}
 
@@ -169,8 +409,9 @@ func validateNumber(number, countryCode string) error {
 
 func validateNickname(nickname string) error {
 	if len(nickname) < minNicknameLen {
-		return errors.Errorf("Could not validate nickname %s: "+
-			"too short (< %d characters)", nickname, minNicknameLen)
+		return errors.WithMessagef(ErrInvalidNickname, "Could not validate "+
+			"nickname %s: too short (< %d characters)", nickname,
+			minNicknameLen)
 	}
 	return nil
 }
@@ -17,9 +17,9 @@ import (
 // UnstringifyFactList matches the original.
 func TestFactList_Stringify_UnstringifyFactList(t *testing.T) {
 	expected := FactList{
-		Fact{"vivian@elixxir.io", Email},
-		Fact{"(270) 301-5797US", Phone},
-		Fact{"invalidFact", Phone},
+		Fact{Fact: "vivian@elixxir.io", T: Email},
+		Fact{Fact: "(270) 301-5797US", T: Phone},
+		Fact{Fact: "invalidFact", T: Phone},
 	}
 
 	flString := expected.Stringify()
@@ -64,10 +64,10 @@ func Test_UnstringifyFactList_MissingFactBreakError(t *testing.T) {
 // Tests that a FactList JSON marshalled and unmarshalled matches the original.
 func TestFactList_JsonMarshalUnmarshal(t *testing.T) {
 	expected := FactList{
-		{"devUsername", Username},
-		{"devinputvalidation@elixxir.io", Email},
-		{"6502530000US", Phone},
-		{"name", Nickname},
+		{Fact: "devUsername", T: Username},
+		{Fact: "devinputvalidation@elixxir.io", T: Email},
+		{Fact: "6502530000US", T: Phone},
+		{Fact: "name", T: Nickname},
 	}
 
 	data, err := json.Marshal(expected)
@@ -13,6 +13,8 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/pkg/errors"
 )
 
 // Tests that NewFact returns a correctly formatted Fact.
@@ -22,10 +24,10 @@ func TestNewFact(t *testing.T) {
 		fact     string
 		expected Fact
 	}{
-		{Username, "myUsername", Fact{"myUsername", Username}},
-		{Email, "email@example.com", Fact{"email@example.com", Email}},
-		{Phone, "8005559486US", Fact{"8005559486US", Phone}},
-		{Nickname, "myNickname", Fact{"myNickname", Nickname}},
+		{Username, "myUsername", Fact{Fact: "myUsername", T: Username}},
+		{Email, "email@example.com", Fact{Fact: "email@example.com", T: Email}},
+		{Phone, "8005559486US", Fact{Fact: "8005559486US", T: Phone}},
+		{Nickname, "myNickname", Fact{Fact: "myNickname", T: Nickname}},
 	}
 
 	for i, tt := range tests {
@@ -63,10 +65,10 @@ func TestNewFact_InvalidFactError(t *testing.T) {
 // UnstringifyFact matches the original.
 func TestFact_Stringify_UnstringifyFact(t *testing.T) {
 	facts := []Fact{
-		{"myUsername", Username},
-		{"email@example.com", Email},
-		{"8005559486US", Phone},
-		{"myNickname", Nickname},
+		{Fact: "myUsername", T: Username},
+		{Fact: "email@example.com", T: Email},
+		{Fact: "8005559486US", T: Phone},
+		{Fact: "myNickname", T: Nickname},
 	}
 
 	for i, expected := range facts {
@@ -89,10 +91,10 @@ func TestFact_Stringify(t *testing.T) {
 		fact     Fact
 		expected string
 	}{
-		{Fact{"myUsername", Username}, "UmyUsername"},
-		{Fact{"email@example.com", Email}, "Eemail@example.com"},
-		{Fact{"8005559486US", Phone}, "P8005559486US"},
-		{Fact{"myNickname", Nickname}, "NmyNickname"},
+		{Fact{Fact: "myUsername", T: Username}, "UmyUsername"},
+		{Fact{Fact: "email@example.com", T: Email}, "Eemail@example.com"},
+		{Fact{Fact: "8005559486US", T: Phone}, "P8005559486US"},
+		{Fact{Fact: "myNickname", T: Nickname}, "NmyNickname"},
 	}
 
 	for i, tt := range tests {
@@ -106,16 +108,53 @@ func TestFact_Stringify(t *testing.T) {
 	}
 }
 
+// Tests that Fact.Stringify of a fact created by NewFact from mixed-case input
+// is in the canonical case and that the display value is lost when
+// unstringified.
+func TestNewFact_Stringify_MixedCase(t *testing.T) {
+	tests := []struct {
+		t        FactType
+		fact     string
+		expected string
+	}{
+		{Email, "John.Doe@Example.com", "Ejohn.doe@example.com"},
+		{Phone, "6502530000us", "P6502530000US"},
+		{Username, "myUsername", "UmyUsername"},
+		{Nickname, "myNickname", "NmyNickname"},
+	}
+
+	for i, tt := range tests {
+		f, err := NewFact(tt.t, tt.fact)
+		if err != nil {
+			t.Fatalf("Failed to create fact %q (%d): %+v", tt.fact, i, err)
+		}
+
+		s := f.Stringify()
+		if s != tt.expected {
+			t.Errorf("Unexpected stringified fact (%d).\nexpected: %s"+
+				"\nreceived: %s", i, tt.expected, s)
+		}
+
+		received, err := UnstringifyFact(s)
+		if err != nil {
+			t.Errorf("Failed to unstringify %q (%d): %+v", s, i, err)
+		} else if received.Fact != f.Fact || received.Display != "" {
+			t.Errorf("Unexpected unstringified fact (%d).\nexpected: %s"+
+				"\nreceived: %+v", i, f.Fact, received)
+		}
+	}
+}
+
 // Consistency test of UnstringifyFact
 func TestUnstringifyFact(t *testing.T) {
 	tests := []struct {
 		factString string
 		expected   Fact
 	}{
-		{"UmyUsername", Fact{"myUsername", Username}},
-		{"Eemail@example.com", Fact{"email@example.com", Email}},
-		{"P8005559486US", Fact{"8005559486US", Phone}},
-		{"NmyNickname", Fact{"myNickname", Nickname}},
+		{"UmyUsername", Fact{Fact: "myUsername", T: Username}},
+		{"Eemail@example.com", Fact{Fact: "email@example.com", T: Email}},
+		{"P8005559486US", Fact{Fact: "8005559486US", T: Phone}},
+		{"NmyNickname", Fact{Fact: "myNickname", T: Nickname}},
 	}
 
 	for i, tt := range tests {
@@ -161,10 +200,10 @@ func TestFact_Normalized(t *testing.T) {
 		fact     Fact
 		expected string
 	}{
-		{Fact{"myUsername", Username}, "MYUSERNAME"},
-		{Fact{"email@example.com", Email}, "EMAIL@EXAMPLE.COM"},
-		{Fact{"8005559486US", Phone}, "8005559486US"},
-		{Fact{"myNickname", Nickname}, "MYNICKNAME"},
+		{Fact{Fact: "myUsername", T: Username}, "MYUSERNAME"},
+		{Fact{Fact: "email@example.com", T: Email}, "EMAIL@EXAMPLE.COM"},
+		{Fact{Fact: "8005559486US", T: Phone}, "8005559486US"},
+		{Fact{Fact: "myNickname", T: Nickname}, "MYNICKNAME"},
 	}
 
 	for i, tt := range tests {
@@ -179,10 +218,10 @@ func TestFact_Normalized(t *testing.T) {
 // Tests that ValidateFact correctly validates various facts.
 func TestValidateFact(t *testing.T) {
 	facts := []Fact{
-		{"myUsername", Username},
-		{"email@example.com", Email},
-		{"8005559486US", Phone},
-		{"myNickname", Nickname},
+		{Fact: "myUsername", T: Username},
+		{Fact: "email@example.com", T: Email},
+		{Fact: "8005559486US", T: Phone},
+		{Fact: "myNickname", T: Nickname},
 	}
 
 	for i, fact := range facts {
@@ -197,11 +236,11 @@ func TestValidateFact(t *testing.T) {
 // Error path: Tests that ValidateFact does not validate invalid facts
 func TestValidateFact_InvalidFactsError(t *testing.T) {
 	facts := []Fact{
-		{"test@gmail@gmail.com", Email},
-		{"US8005559486", Phone},
-		{"020 8743 8000135UK", Phone},
-		{"me", Nickname},
-		{"me", 99},
+		{Fact: "test@gmail@gmail.com", T: Email},
+		{Fact: "US8005559486", T: Phone},
+		{Fact: "020 8743 8000135UK", T: Phone},
+		{Fact: "me", T: Nickname},
+		{Fact: "me", T: 99},
 	}
 
 	for i, fact := range facts {
@@ -238,10 +277,10 @@ func Test_validateNumber_Error(t *testing.T) {
 // Tests that a Fact JSON marshalled and unmarshalled matches the original.
 func TestFact_JsonMarshalUnmarshal(t *testing.T) {
 	facts := []Fact{
-		{"myUsername", Username},
-		{"email@example.com", Email},
-		{"8005559486US", Phone},
-		{"myNickname", Nickname},
+		{Fact: "myUsername", T: Username},
+		{Fact: "email@example.com", T: Email},
+		{Fact: "8005559486US", T: Phone},
+		{Fact: "myNickname", T: Nickname},
 	}
 
 	for i, expected := range facts {
@@ -261,3 +300,148 @@ func TestFact_JsonMarshalUnmarshal(t *testing.T) {
 		}
 	}
 }
+
+// Tests that NewFact stores the canonical form of the fact and keeps the
+// inputted fact as the display value only when it differs.
+func TestNewFact_Display(t *testing.T) {
+	tests := []struct {
+		ft       FactType
+		fact     string
+		expected Fact
+	}{
+		{Email, "John.Doe@Example.com", Fact{Fact: "john.doe@example.com",
+			T: Email, Display: "John.Doe@Example.com"}},
+		{Email, "john.doe@example.com",
+			Fact{Fact: "john.doe@example.com", T: Email}},
+		{Phone, "8005559486us",
+			Fact{Fact: "8005559486US", T: Phone, Display: "8005559486us"}},
+		{Username, "myUsername", Fact{Fact: "myUsername", T: Username}},
+	}
+
+	for i, tt := range tests {
+		f, err := NewFact(tt.ft, tt.fact)
+		if err != nil {
+			t.Errorf("Failed to make new fact (%d): %+v", i, err)
+		} else if !reflect.DeepEqual(tt.expected, f) {
+			t.Errorf("Unexpected new Fact (%d).\nexpected: %+v\nreceived: %+v",
+				i, tt.expected, f)
+		} else if f.GetDisplay() != tt.fact {
+			t.Errorf("Unexpected display value (%d).\nexpected: %s"+
+				"\nreceived: %s", i, tt.fact, f.GetDisplay())
+		}
+	}
+}
+
+// Consistency test of Fact.GetDisplay and Fact.GetCanonical.
+func TestFact_GetDisplay_GetCanonical(t *testing.T) {
+	tests := []struct {
+		fact               Fact
+		display, canonical string
+	}{
+		{Fact{Fact: "john.doe@example.com", T: Email,
+			Display: "John.Doe@Example.com"},
+			"John.Doe@Example.com", "john.doe@example.com"},
+		{Fact{Fact: "John@Example.com", T: Email},
+			"John@Example.com", "john@example.com"},
+		{Fact{Fact: "8005559486us", T: Phone}, "8005559486us", "8005559486US"},
+		{Fact{Fact: "myNickname", T: Nickname}, "myNickname", "myNickname"},
+	}
+
+	for i, tt := range tests {
+		if d := tt.fact.GetDisplay(); d != tt.display {
+			t.Errorf("Unexpected display value (%d).\nexpected: %s"+
+				"\nreceived: %s", i, tt.display, d)
+		}
+		if c := tt.fact.GetCanonical(); c != tt.canonical {
+			t.Errorf("Unexpected canonical value (%d).\nexpected: %s"+
+				"\nreceived: %s", i, tt.canonical, c)
+		}
+	}
+}
+
+// Tests that a Fact with a display value survives Fact.StringifyV2 and
+// UnstringifyFact, Fact.CompactEncode and CompactDecode, FactList.EncodeCSV and
+// DecodeFactListCSV, and JSON marshalling.
+func TestFact_Display_Serialization(t *testing.T) {
+	tests := []struct {
+		fact     Fact
+		expected string
+	}{
+		{Fact{Fact: "john.doe@example.com", T: Email, Status: Revoked,
+			Display: "John.Doe@Example.com"},
+			"2eR20:John.Doe@Example.comjohn.doe@example.com"},
+		{Fact{Fact: "john.doe@example.com", T: Email, Locale: "en-US",
+			Display: "John.Doe@Example.com"},
+			"2eaen-US:20:John.Doe@Example.comjohn.doe@example.com"},
+		{Fact{Fact: "6502530000US", T: Phone, Display: "6502530000us"},
+			"2pA12:6502530000us6502530000US"},
+	}
+
+	for i, tt := range tests {
+		s := tt.fact.StringifyV2()
+		if s != tt.expected {
+			t.Errorf("Unexpected stringified fact (%d).\nexpected: %s"+
+				"\nreceived: %s", i, tt.expected, s)
+		}
+
+		f, err := UnstringifyFact(s)
+		if err != nil {
+			t.Errorf("Failed to unstringify %q (%d): %+v", s, i, err)
+		} else if !reflect.DeepEqual(tt.fact, f) {
+			t.Errorf("Unexpected unstringified fact (%d).\nexpected: %+v"+
+				"\nreceived: %+v", i, tt.fact, f)
+		}
+
+		f, err = CompactDecode(tt.fact.CompactEncode())
+		if err != nil {
+			t.Errorf("Failed to compact decode fact (%d): %+v", i, err)
+		} else if !reflect.DeepEqual(tt.fact, f) {
+			t.Errorf("Unexpected compact decoded fact (%d).\nexpected: %+v"+
+				"\nreceived: %+v", i, tt.fact, f)
+		}
+
+		fl, err := DecodeFactListCSV(FactList{tt.fact}.EncodeCSV())
+		if err != nil {
+			t.Errorf("Failed to decode CSV fact (%d): %+v", i, err)
+		} else if !reflect.DeepEqual(FactList{tt.fact}, fl) {
+			t.Errorf("Unexpected CSV decoded fact (%d).\nexpected: %+v"+
+				"\nreceived: %+v", i, tt.fact, fl)
+		}
+
+		data, err := json.Marshal(tt.fact)
+		if err != nil {
+			t.Fatalf("Failed to JSON marshal fact (%d): %+v", i, err)
+		}
+		var jsonFact Fact
+		if err = json.Unmarshal(data, &jsonFact); err != nil {
+			t.Errorf("Failed to JSON unmarshal fact (%d): %+v", i, err)
+		} else if !reflect.DeepEqual(tt.fact, jsonFact) {
+			t.Errorf("Unexpected JSON unmarshalled fact (%d)."+
+				"\nexpected: %+v\nreceived: %+v", i, tt.fact, jsonFact)
+		}
+	}
+}
+
+// Error path: Tests that UnstringifyFact rejects v2 facts with a malformed
+// display value and that ValidateFact rejects a display value that is not a
+// form of the fact.
+func TestFact_Display_Error(t *testing.T) {
+	for _, s := range []string{
+		"2eRjohn@example.com",
+		"2eRx:John@Example.comjohn@example.com",
+		"2eR0:john@example.com",
+		"2eR99:John@Example.comjohn@example.com",
+		"2eR16:John@Example.com",
+		"2eR16:Jane@Example.comjohn@example.com",
+	} {
+		if _, err := UnstringifyFact(s); err == nil {
+			t.Errorf("No error for invalid v2 fact %q.", s)
+		}
+	}
+
+	err := ValidateFact(Fact{Fact: "john@example.com", T: Email,
+		Display: "Jane@Example.com"})
+	if !errors.Is(err, ErrMalformed) {
+		t.Errorf("Unexpected error for mismatched display value: %+v", err)
+	}
+}
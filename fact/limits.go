@@ -0,0 +1,67 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"github.com/pkg/errors"
+)
+
+// FactLimits describes how many facts a single user may register. A type
+// missing from MaxPerType, or a limit of zero, means there is no limit.
+type FactLimits struct {
+	// MaxPerType is the maximum number of facts of each type.
+	MaxPerType map[FactType]int `json:"maxPerType"`
+
+	// MaxTotal is the maximum number of facts of all types.
+	MaxTotal int `json:"maxTotal"`
+}
+
+// DefaultFactLimits returns the limits used by user discovery registration:
+// one fact of each type.
+func DefaultFactLimits() FactLimits {
+	return FactLimits{
+		MaxPerType: map[FactType]int{
+			Username: 1,
+			Email:    1,
+			Phone:    1,
+			Nickname: 1,
+		},
+		MaxTotal: 4,
+	}
+}
+
+// Enforce returns an error if adding the new fact to the existing facts would
+// exceed the limits. Revoked facts do not count towards the limits.
+func (fl FactLimits) Enforce(existing []Fact, new Fact) error {
+	if new.Status == Revoked {
+		return nil
+	}
+
+	var total, ofType int
+	for _, f := range existing {
+		if f.Status == Revoked {
+			continue
+		}
+		total++
+		if f.T == new.T {
+			ofType++
+		}
+	}
+
+	if max := fl.MaxPerType[new.T]; max > 0 && ofType+1 > max {
+		return errors.Errorf("cannot add %s fact: limit of %d %s facts "+
+			"reached", new.T, max, new.T)
+	}
+
+	if fl.MaxTotal > 0 && total+1 > fl.MaxTotal {
+		return errors.Errorf("cannot add %s fact: limit of %d total facts "+
+			"reached", new.T, fl.MaxTotal)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"testing"
+)
+
+// Tests that FactLimits.Enforce allows facts within the limits and rejects
+// facts that exceed the per-type or total limits.
+func TestFactLimits_Enforce(t *testing.T) {
+	fl := FactLimits{
+		MaxPerType: map[FactType]int{Email: 2, Username: 1},
+		MaxTotal:   3,
+	}
+
+	tests := []struct {
+		existing []Fact
+		new      Fact
+		valid    bool
+	}{
+		{nil, Fact{Fact: "a@b.com", T: Email}, true},
+		{[]Fact{{Fact: "a@b.com", T: Email}},
+			Fact{Fact: "c@d.com", T: Email}, true},
+		{[]Fact{{Fact: "a@b.com", T: Email}, {Fact: "c@d.com", T: Email}},
+			Fact{Fact: "e@f.com", T: Email}, false},
+		{[]Fact{{Fact: "user", T: Username}},
+			Fact{Fact: "other", T: Username}, false},
+		{[]Fact{{Fact: "user", T: Username, Status: Revoked}},
+			Fact{Fact: "other", T: Username}, true},
+		{[]Fact{{Fact: "a", T: Nickname}, {Fact: "b", T: Nickname},
+			{Fact: "c", T: Nickname}}, Fact{Fact: "d", T: Nickname}, false},
+		{[]Fact{{Fact: "a", T: Nickname}, {Fact: "b", T: Nickname}},
+			Fact{Fact: "c", T: Nickname}, true},
+	}
+
+	for i, tt := range tests {
+		err := fl.Enforce(tt.existing, tt.new)
+		if tt.valid && err != nil {
+			t.Errorf("Unexpected error for fact %+v (%d): %+v", tt.new, i, err)
+		} else if !tt.valid && err == nil {
+			t.Errorf("Expected error for fact %+v (%d).", tt.new, i)
+		}
+	}
+}
+
+// Tests that the default limits allow only one fact of each type.
+func TestDefaultFactLimits(t *testing.T) {
+	fl := DefaultFactLimits()
+	existing := []Fact{{Fact: "user", T: Username}}
+
+	if err := fl.Enforce(existing, Fact{Fact: "a@b.com", T: Email}); err != nil {
+		t.Errorf("Failed to add first email: %+v", err)
+	}
+	if err := fl.Enforce(existing, Fact{Fact: "other", T: Username}); err == nil {
+		t.Error("Expected error when adding second username.")
+	}
+}
@@ -0,0 +1,85 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// The maximum character length of a locale.
+	maxLocaleLen = 35
+
+	// The separator between subtags of a locale.
+	localeSeparator = "-"
+)
+
+// ValidateLocale checks that the locale is either empty or a well-formed
+// BCP 47 language tag: a two or three letter language subtag followed by any
+// number of alphanumeric subtags of up to eight characters, separated by
+// hyphens (e.g., "en", "en-US", "zh-Hant-TW").
+func ValidateLocale(locale string) error {
+	if locale == "" {
+		return nil
+	} else if len(locale) > maxLocaleLen {
+		return errors.WithMessagef(ErrInvalidLocale, "Locale %q exceeds "+
+			"maximum character limit for a locale (%d characters)", locale, maxLocaleLen)
+	}
+
+	subtags := strings.Split(locale, localeSeparator)
+	if len(subtags[0]) < 2 || len(subtags[0]) > 3 || !isAlpha(subtags[0]) {
+		return errors.WithMessagef(ErrInvalidLocale, "Locale %q must start "+
+			"with a two or three letter language code", locale)
+	}
+
+	for _, subtag := range subtags[1:] {
+		if len(subtag) < 1 || len(subtag) > 8 || !isAlphanumeric(subtag) {
+			return errors.WithMessagef(ErrInvalidLocale,
+				"Locale %q contains invalid subtag %q",
+				locale, subtag)
+		}
+	}
+
+	return nil
+}
+
+// localeRegion returns the uppercase two-letter region subtag of the locale
+// (e.g., "US" for "en-US"). Returns an empty string if the locale has no
+// region.
+func localeRegion(locale string) string {
+	subtags := strings.Split(locale, localeSeparator)
+	for _, subtag := range subtags[1:] {
+		if len(subtag) == 2 && isAlpha(subtag) {
+			return strings.ToUpper(subtag)
+		}
+	}
+	return ""
+}
+
+// isAlpha determines if the string consists only of ASCII letters.
+func isAlpha(s string) bool {
+	for _, c := range s {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// isAlphanumeric determines if the string consists only of ASCII letters and
+// digits.
+func isAlphanumeric(s string) bool {
+	for _, c := range s {
+		if !isAlpha(string(c)) && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
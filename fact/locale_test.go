@@ -0,0 +1,116 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// Tests that ValidateLocale accepts well-formed locales and rejects malformed
+// ones.
+func TestValidateLocale(t *testing.T) {
+	valid := []string{"", "en", "en-US", "zh-Hant-TW", "es-419", "gsw"}
+	for _, locale := range valid {
+		if err := ValidateLocale(locale); err != nil {
+			t.Errorf("Unexpected error for locale %q: %+v", locale, err)
+		}
+	}
+
+	invalid := []string{"e", "english", "en-", "en_US", "en-US!", "12-US",
+		"en-abcdefghi", "en-US-US-US-US-US-US-US-US-US-US-US-US"}
+	for _, locale := range invalid {
+		if err := ValidateLocale(locale); err == nil {
+			t.Errorf("Expected error for locale %q.", locale)
+		}
+	}
+}
+
+// Tests that a Fact with a locale stringified via Fact.StringifyV2 and
+// unstringified via UnstringifyFact matches the original.
+func TestFact_StringifyV2_UnstringifyFact_Locale(t *testing.T) {
+	tests := []struct {
+		fact     Fact
+		expected string
+	}{
+		{Fact{Fact: "myUsername", T: Username, Locale: "en"},
+			"2Uaen:myUsername"},
+		{Fact{Fact: "nick:name", T: Nickname, Status: Revoked,
+			Locale: "de-DE"}, "2Nrde-DE:nick:name"},
+		{Fact{Fact: "6502530000", T: Phone, Status: Unverified,
+			Locale: "en-US"}, "2Puen-US:6502530000"},
+	}
+
+	for i, tt := range tests {
+		s := tt.fact.StringifyV2()
+		if s != tt.expected {
+			t.Errorf("Unexpected stringified fact (%d).\nexpected: %s"+
+				"\nreceived: %s", i, tt.expected, s)
+		}
+
+		f, err := UnstringifyFact(s)
+		if err != nil {
+			t.Errorf("Failed to unstringify %q (%d): %+v", s, i, err)
+		} else if !reflect.DeepEqual(tt.fact, f) {
+			t.Errorf("Unexpected unstringified fact (%d).\nexpected: %+v"+
+				"\nreceived: %+v", i, tt.fact, f)
+		}
+	}
+}
+
+// Error path: Tests that UnstringifyFact rejects v2 facts with a missing
+// locale terminator or an invalid locale.
+func TestUnstringifyFact_LocaleError(t *testing.T) {
+	for _, s := range []string{"2Uaen", "2Ua!!:name", "2Uzen:name"} {
+		if _, err := UnstringifyFact(s); err == nil {
+			t.Errorf("No error for invalid v2 fact %q.", s)
+		}
+	}
+}
+
+// Tests that the locale is included in the JSON form only when it is set.
+func TestFact_JSON_Locale(t *testing.T) {
+	f := Fact{Fact: "name", T: Nickname, Locale: "fr-CA"}
+	data, err := json.Marshal(f)
+	if err != nil {
+		t.Fatalf("Failed to JSON marshal fact: %+v", err)
+	}
+
+	expected := `{"Fact":"name","T":3,"L":"fr-CA"}`
+	if string(data) != expected {
+		t.Errorf("Unexpected JSON.\nexpected: %s\nreceived: %s", expected, data)
+	}
+
+	var newF Fact
+	if err = json.Unmarshal(data, &newF); err != nil {
+		t.Fatalf("Failed to JSON unmarshal fact: %+v", err)
+	} else if !reflect.DeepEqual(f, newF) {
+		t.Errorf("Unexpected fact.\nexpected: %+v\nreceived: %+v", f, newF)
+	}
+}
+
+// Tests that phone validation uses the region of the locale for numbers
+// without a country code.
+func TestValidateFact_PhoneLocaleRegion(t *testing.T) {
+	f := Fact{Fact: "6502530000", T: Phone}
+	if err := ValidateFact(f); err == nil {
+		t.Error("Expected error for phone number without a country code.")
+	}
+
+	f.Locale = "en-US"
+	if err := ValidateFact(f); err != nil {
+		t.Errorf("Unexpected error for phone number with locale region: %+v",
+			err)
+	}
+
+	f = Fact{Fact: "6502530000US", T: Phone, Locale: "de-DE"}
+	if err := ValidateFact(f); err != nil {
+		t.Errorf("Country code in the fact did not take precedence: %+v", err)
+	}
+}
@@ -0,0 +1,60 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// ObfuscatedStringify marshals the Fact into a portable string where the fact
+// itself is replaced by a salted hash. The FactType prefix is preserved so that
+// intermediary services can still route on the type without learning the
+// plaintext fact.
+//
+// The hash is computed over the length-prefixed salt, the fact type, and the
+// normalized fact, so the result is case-insensitive.
+func (f Fact) ObfuscatedStringify(salt []byte) string {
+	return f.T.Stringify() +
+		base64.StdEncoding.EncodeToString(obfuscateFact(f, salt))
+}
+
+// VerifyObfuscatedFact determines if the obfuscated string, as produced by
+// Fact.ObfuscatedStringify, was generated from the given Fact and salt. The
+// comparison of hashes is done in constant time.
+func VerifyObfuscatedFact(obfuscated string, f Fact, salt []byte) bool {
+	if len(obfuscated) < 1 || !f.T.IsValid() ||
+		obfuscated[:1] != f.T.Stringify() {
+		return false
+	}
+
+	hash, err := base64.StdEncoding.DecodeString(obfuscated[1:])
+	if err != nil {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(hash, obfuscateFact(f, salt)) == 1
+}
+
+// obfuscateFact returns the salted hash of the fact. The salt is prefixed with
+// its length so that bytes cannot be moved between the salt and the fact to
+// produce the same hash.
+func obfuscateFact(f Fact, salt []byte) []byte {
+	saltLen := make([]byte, 8)
+	binary.BigEndian.PutUint64(saltLen, uint64(len(salt)))
+
+	h, _ := blake2b.New256(nil)
+	h.Write(saltLen)
+	h.Write(salt)
+	h.Write([]byte{byte(f.T)})
+	h.Write([]byte(f.Normalized()))
+	return h.Sum(nil)
+}
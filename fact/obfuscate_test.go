@@ -0,0 +1,79 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"strings"
+	"testing"
+)
+
+// Tests that a Fact obfuscated by Fact.ObfuscatedStringify is verified by
+// VerifyObfuscatedFact and that the type prefix and case-insensitivity are
+// preserved.
+func TestFact_ObfuscatedStringify_VerifyObfuscatedFact(t *testing.T) {
+	salt := []byte("salt")
+	facts := []Fact{
+		{Fact: "myUsername", T: Username},
+		{Fact: "email@example.com", T: Email},
+		{Fact: "8005559486US", T: Phone},
+		{Fact: "myNickname", T: Nickname},
+	}
+
+	for i, f := range facts {
+		obfuscated := f.ObfuscatedStringify(salt)
+		if !strings.HasPrefix(obfuscated, f.T.Stringify()) {
+			t.Errorf("Obfuscated fact %q does not start with type prefix %q "+
+				"(%d).", obfuscated, f.T.Stringify(), i)
+		}
+		if strings.Contains(obfuscated, f.Fact) {
+			t.Errorf("Obfuscated fact %q contains plaintext (%d).",
+				obfuscated, i)
+		}
+		if !VerifyObfuscatedFact(obfuscated, f, salt) {
+			t.Errorf("Failed to verify obfuscated fact %s (%d).", f, i)
+		}
+
+		upper := Fact{Fact: strings.ToUpper(f.Fact), T: f.T}
+		if !VerifyObfuscatedFact(obfuscated, upper, salt) {
+			t.Errorf("Failed to verify obfuscated fact %s with different "+
+				"case (%d).", upper, i)
+		}
+	}
+}
+
+// Error path: Tests that VerifyObfuscatedFact fails for a different salt, fact,
+// type, bytes moved between the salt and fact, or an invalid encoding.
+func TestVerifyObfuscatedFact_Invalid(t *testing.T) {
+	salt := []byte("salt")
+	f := Fact{Fact: "email@example.com", T: Email}
+	obfuscated := f.ObfuscatedStringify(salt)
+
+	// Moving bytes from the fact into the salt must not produce the same hash
+	f2 := Fact{Fact: "A\x01B", T: Email}
+
+	tests := []struct {
+		obfuscated string
+		f          Fact
+		salt       []byte
+	}{
+		{obfuscated, f, []byte("other salt")},
+		{obfuscated, Fact{Fact: "other@example.com", T: Email}, salt},
+		{obfuscated, Fact{Fact: "email@example.com", T: Username}, salt},
+		{obfuscated, Fact{Fact: "email@example.com", T: 99}, salt},
+		{f2.ObfuscatedStringify([]byte("SALT")),
+			Fact{Fact: "B", T: Email}, []byte("SALT\x01A")},
+		{"E!!!", f, salt},
+		{"", f, salt},
+	}
+
+	for i, tt := range tests {
+		if VerifyObfuscatedFact(tt.obfuscated, tt.f, tt.salt) {
+			t.Errorf("Verified invalid obfuscated fact (%d).", i)
+		}
+	}
+}
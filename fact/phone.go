@@ -0,0 +1,192 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ttacon/libphonenumber"
+)
+
+const (
+	// phoneExtensionSeparator separates a phone number from its extension in a
+	// phone fact (e.g., "6502530000x123US"). Phone facts are stored in upper
+	// case, so it is matched in either case.
+	phoneExtensionSeparator = "X"
+
+	// maxPhoneExtensionLen is the maximum number of digits in a phone
+	// extension. It matches the limit used by libphonenumber.
+	maxPhoneExtensionLen = 20
+)
+
+// PhoneLineType is a hint of the kind of line a phone number belongs to, as
+// far as the numbering plan of its country can tell.
+type PhoneLineType uint8
+
+const (
+	// PhoneLineUnknown is used when the numbering plan does not distinguish
+	// mobile numbers from landlines, as in the US and Canada.
+	PhoneLineUnknown PhoneLineType = iota
+
+	// PhoneLineMobile is a mobile number.
+	PhoneLineMobile
+
+	// PhoneLineLandline is a fixed line number.
+	PhoneLineLandline
+
+	// PhoneLineOther is any other kind of number, such as toll free, premium
+	// rate, VoIP, or pager numbers.
+	PhoneLineOther
+)
+
+// String returns the string representation of the PhoneLineType. This
+// functions adheres to the fmt.Stringer interface.
+func (t PhoneLineType) String() string {
+	switch t {
+	case PhoneLineUnknown:
+		return "Unknown"
+	case PhoneLineMobile:
+		return "Mobile"
+	case PhoneLineLandline:
+		return "Landline"
+	case PhoneLineOther:
+		return "Other"
+	default:
+		return "Unknown PhoneLineType: " + strconv.FormatUint(uint64(t), 10)
+	}
+}
+
+// PhoneNumber is the parsed contents of a phone fact.
+type PhoneNumber struct {
+	e164      string
+	region    string
+	extension string
+	lineType  PhoneLineType
+}
+
+// ParsePhone parses the phone fact into a PhoneNumber. A phone fact is the
+// number, an optional extension, and the two-letter region, in that order
+// (e.g., "6502530000x123US"). The extension is an "x" followed by up to
+// maxPhoneExtensionLen digits. As in ValidateFact, the region of the locale is
+// used if the fact does not end in one. Returns an error if the fact is not a
+// valid phone number.
+func (f Fact) ParsePhone() (PhoneNumber, error) {
+	if f.T != Phone {
+		return PhoneNumber{}, errors.Errorf("cannot parse %s fact as a "+
+			"phone number", f.T)
+	}
+
+	number, region := extractNumberInfo(f.Fact, f.Locale)
+	number, extension, err := splitPhoneExtension(number)
+	if err != nil {
+		return PhoneNumber{}, err
+	} else if err = validateNumber(number, region); err != nil {
+		return PhoneNumber{}, err
+	}
+
+	// The number was validated above, so it parses without error
+	num, _ := libphonenumber.Parse(number, region)
+	return PhoneNumber{
+		e164:      libphonenumber.Format(num, libphonenumber.E164),
+		region:    region,
+		extension: extension,
+		lineType:  phoneLineType(libphonenumber.GetNumberType(num)),
+	}, nil
+}
+
+// E164 returns the number in E.164 format (e.g., "+16502530000"), without the
+// extension.
+func (pn PhoneNumber) E164() string {
+	return pn.e164
+}
+
+// Region returns the two-letter region the number was parsed with.
+func (pn PhoneNumber) Region() string {
+	return pn.region
+}
+
+// Extension returns the digits of the extension or an empty string if the
+// number has none.
+func (pn PhoneNumber) Extension() string {
+	return pn.extension
+}
+
+// LineType returns the hint of the kind of line the number belongs to.
+func (pn PhoneNumber) LineType() PhoneLineType {
+	return pn.lineType
+}
+
+// CanReceiveSMS determines if SMS messages can be sent to the number. Numbers
+// with an extension, landlines, and other non-mobile numbers are rejected.
+// Numbers whose line type is unknown are assumed to be capable.
+func (pn PhoneNumber) CanReceiveSMS() bool {
+	return pn.extension == "" &&
+		(pn.lineType == PhoneLineMobile || pn.lineType == PhoneLineUnknown)
+}
+
+// ValidateSMSCapable returns an error if verification codes cannot be sent to
+// the phone fact by SMS. The error wraps ErrPhoneNotSMSCapable if the number is
+// valid but cannot receive SMS messages; see PhoneNumber.CanReceiveSMS.
+func ValidateSMSCapable(f Fact) error {
+	pn, err := f.ParsePhone()
+	if err != nil {
+		return err
+	}
+
+	if pn.extension != "" {
+		return errors.WithMessagef(ErrPhoneNotSMSCapable,
+			"number %s has extension %s", pn.e164, pn.extension)
+	} else if !pn.CanReceiveSMS() {
+		return errors.WithMessagef(ErrPhoneNotSMSCapable,
+			"number %s is a %s number", pn.e164,
+			strings.ToLower(pn.lineType.String()))
+	}
+
+	return nil
+}
+
+// splitPhoneExtension splits the number into the number and the digits of its
+// extension, if it has one. Returns an error if the extension is empty, too
+// long, or not made of digits.
+func splitPhoneExtension(number string) (string, string, error) {
+	i := strings.LastIndex(strings.ToUpper(number), phoneExtensionSeparator)
+	if i < 0 {
+		return number, "", nil
+	}
+
+	extension := number[i+len(phoneExtensionSeparator):]
+	if len(extension) == 0 || len(extension) > maxPhoneExtensionLen {
+		return "", "", errors.WithMessagef(ErrInvalidPhone, "extension of "+
+			"%d digits must be between 1 and %d digits", len(extension),
+			maxPhoneExtensionLen)
+	}
+	for _, r := range extension {
+		if r < '0' || r > '9' {
+			return "", "", errors.WithMessagef(ErrInvalidPhone,
+				"extension %q must only contain digits", extension)
+		}
+	}
+
+	return number[:i], extension, nil
+}
+
+// phoneLineType returns the PhoneLineType for the libphonenumber type.
+func phoneLineType(t libphonenumber.PhoneNumberType) PhoneLineType {
+	switch t {
+	case libphonenumber.MOBILE:
+		return PhoneLineMobile
+	case libphonenumber.FIXED_LINE:
+		return PhoneLineLandline
+	case libphonenumber.FIXED_LINE_OR_MOBILE, libphonenumber.UNKNOWN:
+		return PhoneLineUnknown
+	default:
+		return PhoneLineOther
+	}
+}
@@ -0,0 +1,139 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// Tests that Fact.ParsePhone returns the expected number, region, extension,
+// and line type for numbers from countries whose numbering plans do and do
+// not distinguish mobile numbers.
+func TestFact_ParsePhone(t *testing.T) {
+	tests := []struct {
+		fact              Fact
+		e164, region, ext string
+		lineType          PhoneLineType
+		canReceiveSMS     bool
+	}{
+		{Fact{Fact: "6502530000US", T: Phone},
+			"+16502530000", "US", "", PhoneLineUnknown, true},
+		{Fact{Fact: "6502530000X123US", T: Phone},
+			"+16502530000", "US", "123", PhoneLineUnknown, false},
+		{Fact{Fact: "6502530000x42", T: Phone, Locale: "en-US"},
+			"+16502530000", "US", "42", PhoneLineUnknown, false},
+		{Fact{Fact: "8005559486US", T: Phone},
+			"+18005559486", "US", "", PhoneLineOther, false},
+		{Fact{Fact: "7911123456GB", T: Phone},
+			"+447911123456", "GB", "", PhoneLineMobile, true},
+		{Fact{Fact: "2079460000GB", T: Phone},
+			"+442079460000", "GB", "", PhoneLineLandline, false},
+		{Fact{Fact: "2079460000X7GB", T: Phone},
+			"+442079460000", "GB", "7", PhoneLineLandline, false},
+		{Fact{Fact: "015123456789DE", T: Phone},
+			"+4915123456789", "DE", "", PhoneLineMobile, true},
+	}
+
+	for i, tt := range tests {
+		pn, err := tt.fact.ParsePhone()
+		if err != nil {
+			t.Errorf("Failed to parse %q (%d): %+v", tt.fact.Fact, i, err)
+			continue
+		}
+
+		if pn.E164() != tt.e164 || pn.Region() != tt.region ||
+			pn.Extension() != tt.ext || pn.LineType() != tt.lineType {
+			t.Errorf("Unexpected PhoneNumber for %q (%d)."+
+				"\nexpected: %s %s %q %s\nreceived: %s %s %q %s", tt.fact.Fact,
+				i, tt.e164, tt.region, tt.ext, tt.lineType, pn.E164(),
+				pn.Region(), pn.Extension(), pn.LineType())
+		}
+		if pn.CanReceiveSMS() != tt.canReceiveSMS {
+			t.Errorf("Unexpected SMS capability for %q (%d)."+
+				"\nexpected: %t\nreceived: %t",
+				tt.fact.Fact, i, tt.canReceiveSMS, pn.CanReceiveSMS())
+		}
+	}
+}
+
+// Tests that NewFact accepts phone facts with extensions and stores them in
+// their canonical form.
+func TestNewFact_PhoneExtension(t *testing.T) {
+	f, err := NewFact(Phone, "6502530000x123US")
+	if err != nil {
+		t.Fatalf("Failed to create phone fact with extension: %+v", err)
+	}
+
+	expected := Fact{Fact: "6502530000X123US", T: Phone,
+		Display: "6502530000x123US"}
+	if f != expected {
+		t.Errorf("Unexpected fact.\nexpected: %+v\nreceived: %+v", expected, f)
+	}
+}
+
+// Error path: Tests that Fact.ParsePhone and ValidateFact reject invalid
+// extensions and numbers, and that ParsePhone rejects other fact types.
+func TestFact_ParsePhone_Error(t *testing.T) {
+	for i, f := range []Fact{
+		{Fact: "6502530000XUS", T: Phone},
+		{Fact: "6502530000X12AUS", T: Phone},
+		{Fact: "6502530000X123456789012345678901US", T: Phone},
+		{Fact: "65025X123US", T: Phone},
+		{Fact: "6502530000X1", T: Phone},
+	} {
+		if _, err := f.ParsePhone(); !errors.Is(err, ErrInvalidPhone) {
+			t.Errorf("Unexpected error parsing %q (%d): %+v", f.Fact, i, err)
+		}
+		if err := ValidateFact(f); !errors.Is(err, ErrInvalidPhone) {
+			t.Errorf("Unexpected error validating %q (%d): %+v", f.Fact, i, err)
+		}
+	}
+
+	if _, err := (Fact{Fact: "john", T: Username}).ParsePhone(); err == nil {
+		t.Error("No error parsing a username as a phone number.")
+	}
+}
+
+// Tests that ValidateSMSCapable accepts mobile numbers and numbers whose line
+// type is unknown and rejects landlines, other numbers, and extensions with
+// ErrPhoneNotSMSCapable.
+func TestValidateSMSCapable(t *testing.T) {
+	tests := []struct {
+		fact string
+		err  error
+	}{
+		{"7911123456GB", nil},
+		{"6502530000US", nil},
+		{"2079460000GB", ErrPhoneNotSMSCapable},
+		{"8005559486US", ErrPhoneNotSMSCapable},
+		{"7911123456X1GB", ErrPhoneNotSMSCapable},
+		{"123US", ErrInvalidPhone},
+	}
+
+	for i, tt := range tests {
+		err := ValidateSMSCapable(Fact{Fact: tt.fact, T: Phone})
+		if (tt.err == nil && err != nil) || !errors.Is(err, tt.err) {
+			t.Errorf("Unexpected error for %q (%d).\nexpected: %v"+
+				"\nreceived: %+v", tt.fact, i, tt.err, err)
+		}
+	}
+}
+
+// Tests that PhoneLineType.String returns the expected string for each type.
+func TestPhoneLineType_String(t *testing.T) {
+	expected := []string{"Unknown", "Mobile", "Landline", "Other",
+		"Unknown PhoneLineType: 4"}
+	for i, s := range expected {
+		if received := PhoneLineType(i).String(); received != s {
+			t.Errorf("Unexpected string.\nexpected: %s\nreceived: %s",
+				s, received)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"sync"
+)
+
+// Policy allows deployments to apply additional restrictions, such as charset
+// limitations or denylists, to user-chosen facts. The policy is checked by
+// NewFact and ValidateFact after the built-in validation passes. It is not
+// applied when decoding stored or received facts (e.g., with UnstringifyFact or
+// DecodeFactListCSV) so that the facts of other users remain readable.
+type Policy interface {
+	// ValidateUsername returns an error if the username is not allowed.
+	ValidateUsername(username string) error
+
+	// ValidateNickname returns an error if the nickname is not allowed.
+	ValidateNickname(nickname string) error
+}
+
+// PermissivePolicy is the default Policy. It allows all facts.
+type PermissivePolicy struct{}
+
+// ValidateUsername allows all usernames. This function adheres to the Policy
+// interface.
+func (PermissivePolicy) ValidateUsername(string) error { return nil }
+
+// ValidateNickname allows all nicknames. This function adheres to the Policy
+// interface.
+func (PermissivePolicy) ValidateNickname(string) error { return nil }
+
+var (
+	globalPolicy    Policy = PermissivePolicy{}
+	globalPolicyMux sync.RWMutex
+)
+
+// SetGlobalFactPolicy sets the Policy used by NewFact and ValidateFact. Passing
+// nil restores the default PermissivePolicy.
+func SetGlobalFactPolicy(p Policy) {
+	if p == nil {
+		p = PermissivePolicy{}
+	}
+
+	globalPolicyMux.Lock()
+	defer globalPolicyMux.Unlock()
+	globalPolicy = p
+}
+
+// getGlobalFactPolicy returns the current Policy.
+func getGlobalFactPolicy() Policy {
+	globalPolicyMux.RLock()
+	defer globalPolicyMux.RUnlock()
+	return globalPolicy
+}
@@ -0,0 +1,99 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// denyPolicy is a Policy that denies facts containing a denied word.
+type denyPolicy struct{ word string }
+
+func (d denyPolicy) ValidateUsername(username string) error {
+	if strings.Contains(username, d.word) {
+		return errors.Errorf("username %q is denied", username)
+	}
+	return nil
+}
+
+func (d denyPolicy) ValidateNickname(nickname string) error {
+	if strings.Contains(nickname, d.word) {
+		return errors.Errorf("nickname %q is denied", nickname)
+	}
+	return nil
+}
+
+// Tests that ValidateFact applies the Policy set by SetGlobalFactPolicy to
+// usernames and nicknames only, and that passing nil restores the default.
+func TestSetGlobalFactPolicy(t *testing.T) {
+	SetGlobalFactPolicy(denyPolicy{"bad"})
+	defer SetGlobalFactPolicy(nil)
+
+	denied := []Fact{
+		{Fact: "badUser", T: Username},
+		{Fact: "myBadbadNick", T: Nickname},
+	}
+	for _, f := range denied {
+		if err := ValidateFact(f); err == nil {
+			t.Errorf("ValidateFact did not deny %s.", f)
+		}
+	}
+
+	allowed := []Fact{
+		{Fact: "goodUser", T: Username},
+		{Fact: "goodNick", T: Nickname},
+		{Fact: "bad@example.com", T: Email},
+	}
+	for _, f := range allowed {
+		if err := ValidateFact(f); err != nil {
+			t.Errorf("ValidateFact denied %s: %+v", f, err)
+		}
+	}
+
+	SetGlobalFactPolicy(nil)
+	for _, f := range denied {
+		if err := ValidateFact(f); err != nil {
+			t.Errorf("ValidateFact denied %s with default policy: %+v", f, err)
+		}
+	}
+}
+
+// Tests that the Policy set by SetGlobalFactPolicy is applied by NewFact but
+// not when decoding facts received from other users.
+func TestSetGlobalFactPolicy_Decode(t *testing.T) {
+	SetGlobalFactPolicy(denyPolicy{"bad"})
+	defer SetGlobalFactPolicy(nil)
+
+	for _, expected := range []Fact{
+		{Fact: "baduser", T: Username},
+		{Fact: "myBadbadNick", T: Nickname},
+	} {
+		if _, err := NewFact(expected.T, expected.Fact); err == nil {
+			t.Errorf("NewFact did not deny %s.", expected)
+		}
+
+		f, err := UnstringifyFact(expected.Stringify())
+		if err != nil {
+			t.Errorf("UnstringifyFact denied %s: %+v", expected, err)
+		} else if f != expected {
+			t.Errorf("UnstringifyFact decoded unexpected fact."+
+				"\nexpected: %+v\nreceived: %+v", expected, f)
+		}
+
+		fl, err := DecodeFactListCSV(FactList{expected}.EncodeCSV())
+		if err != nil {
+			t.Errorf("DecodeFactListCSV denied %s: %+v", expected, err)
+		} else if fl[0] != expected {
+			t.Errorf("DecodeFactListCSV decoded unexpected fact."+
+				"\nexpected: %+v\nreceived: %+v", expected, fl[0])
+		}
+	}
+}
@@ -0,0 +1,82 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"strconv"
+
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+// FactStatus describes whether a Fact is still valid. It allows deletion and
+// verification state to travel with the stringified fact.
+type FactStatus uint8
+
+const (
+	// Active is a verified fact in good standing. This is the default status.
+	Active FactStatus = 0
+
+	// Unverified is a fact that has been registered but not yet verified.
+	Unverified FactStatus = 1
+
+	// Revoked is a fact that has been deleted by its owner and should no
+	// longer be used.
+	Revoked FactStatus = 2
+)
+
+// String returns the string representation of the FactStatus. This functions
+// adheres to the fmt.Stringer interface.
+func (s FactStatus) String() string {
+	switch s {
+	case Active:
+		return "Active"
+	case Unverified:
+		return "Unverified"
+	case Revoked:
+		return "Revoked"
+	default:
+		return "Unknown Fact FactStatus: " + strconv.FormatUint(uint64(s), 10)
+	}
+}
+
+// Stringify marshals the FactStatus into a portable single character string.
+func (s FactStatus) Stringify() string {
+	switch s {
+	case Active:
+		return "A"
+	case Unverified:
+		return "U"
+	case Revoked:
+		return "R"
+	}
+	jww.FATAL.Panicf("Unknown Fact FactStatus: %d", s)
+	return "error"
+}
+
+// UnstringifyFactStatus unmarshalls the stringified FactStatus.
+func UnstringifyFactStatus(s string) (FactStatus, error) {
+	switch s {
+	case "A":
+		return Active, nil
+	case "U":
+		return Unverified, nil
+	case "R":
+		return Revoked, nil
+	}
+	return 99, ErrUnknownStatus{Status: 99, Symbol: s}
+}
+
+// IsValid determines if the FactStatus is one of the defined statuses.
+func (s FactStatus) IsValid() bool {
+	switch s {
+	case Active, Unverified, Revoked:
+		return true
+	default:
+		return false
+	}
+}
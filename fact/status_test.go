@@ -0,0 +1,93 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Consistency test of FactStatus.String.
+func TestFactStatus_String(t *testing.T) {
+	tests := map[FactStatus]string{
+		Active:     "Active",
+		Unverified: "Unverified",
+		Revoked:    "Revoked",
+		99:         "Unknown Fact FactStatus: 99",
+	}
+
+	for s, expected := range tests {
+		if s.String() != expected {
+			t.Errorf("Unexpected string for FactStatus %d."+
+				"\nexpected: %s\nreceived: %s", s, expected, s.String())
+		}
+	}
+}
+
+// Tests that a FactStatus stringified via FactStatus.Stringify and
+// unstringified via UnstringifyFactStatus matches the original.
+func TestFactStatus_Stringify_UnstringifyFactStatus(t *testing.T) {
+	for _, expected := range []FactStatus{Active, Unverified, Revoked} {
+		s, err := UnstringifyFactStatus(expected.Stringify())
+		if err != nil {
+			t.Errorf("Failed to unstringify %s: %+v", expected, err)
+		} else if s != expected {
+			t.Errorf("Unexpected FactStatus.\nexpected: %s\nreceived: %s",
+				expected, s)
+		}
+	}
+
+	if _, err := UnstringifyFactStatus("Z"); err == nil {
+		t.Error("No error for unknown stringified FactStatus.")
+	}
+}
+
+// Tests that a Fact stringified via Fact.StringifyV2 and unstringified via
+// UnstringifyFact matches the original, including its status.
+func TestFact_StringifyV2_UnstringifyFact(t *testing.T) {
+	tests := []struct {
+		fact     Fact
+		expected string
+	}{
+		{Fact{Fact: "myUsername", T: Username}, "2UAmyUsername"},
+		{Fact{Fact: "email@example.com", T: Email, Status: Revoked},
+			"2ERemail@example.com"},
+		{Fact{Fact: "8005559486US", T: Phone, Status: Unverified},
+			"2PU8005559486US"},
+	}
+
+	for i, tt := range tests {
+		s := tt.fact.StringifyV2()
+		if s != tt.expected {
+			t.Errorf("Unexpected stringified fact (%d).\nexpected: %s"+
+				"\nreceived: %s", i, tt.expected, s)
+		}
+
+		f, err := UnstringifyFact(s)
+		if err != nil {
+			t.Errorf("Failed to unstringify %q (%d): %+v", s, i, err)
+		} else if !reflect.DeepEqual(tt.fact, f) {
+			t.Errorf("Unexpected unstringified fact (%d).\nexpected: %+v"+
+				"\nreceived: %+v", i, tt.fact, f)
+		}
+	}
+}
+
+// Error path: Tests that UnstringifyFact rejects invalid v2 facts and that
+// ValidateFact rejects an unknown status.
+func TestUnstringifyFact_V2Error(t *testing.T) {
+	for _, s := range []string{"2U", "2UZname", "2QAname", "2NAme"} {
+		if _, err := UnstringifyFact(s); err == nil {
+			t.Errorf("No error for invalid v2 fact %q.", s)
+		}
+	}
+
+	if err := ValidateFact(Fact{Fact: "name", T: Username, Status: 99}); err == nil {
+		t.Error("No error for unknown fact status.")
+	}
+}
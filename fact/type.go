@@ -10,7 +10,6 @@ package fact
 import (
 	"strconv"
 
-	"github.com/pkg/errors"
 	jww "github.com/spf13/jwalterweatherman"
 )
 
@@ -68,7 +67,7 @@ func UnstringifyFactType(s string) (FactType, error) {
 	case "N":
 		return Nickname, nil
 	}
-	return 99, errors.Errorf("Unknown Fact FactType: %s", s)
+	return 99, ErrUnknownType{Type: 99, Symbol: s}
 }
 
 // IsValid determines if the FactType is one of the defined types.
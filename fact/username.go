@@ -0,0 +1,115 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MinUsernameLen is the minimum character length of a username.
+	MinUsernameLen = 3
+
+	// MaxUsernameLen is the maximum character length of a username.
+	MaxUsernameLen = 32
+)
+
+// defaultReservedUsernames are the usernames reserved by default; see
+// DefaultReservedUsernames.
+var defaultReservedUsernames = []string{
+	"admin", "administrator", "root", "system", "support", "moderator",
+	"xxnetwork", "elixxir", "null", "undefined",
+}
+
+var (
+	reservedUsernames    = newReservedSet(defaultReservedUsernames)
+	reservedUsernamesMux sync.RWMutex
+)
+
+// DefaultReservedUsernames returns the usernames that are reserved unless
+// changed with SetReservedUsernames.
+func DefaultReservedUsernames() []string {
+	return append([]string{}, defaultReservedUsernames...)
+}
+
+// SetReservedUsernames sets the usernames rejected by ValidateUsername. Names
+// are compared ignoring case. Passing nil restores the default list and
+// passing an empty list reserves no usernames.
+func SetReservedUsernames(names []string) {
+	if names == nil {
+		names = defaultReservedUsernames
+	}
+	set := newReservedSet(names)
+
+	reservedUsernamesMux.Lock()
+	defer reservedUsernamesMux.Unlock()
+	reservedUsernames = set
+}
+
+// isReservedUsername determines if the username is reserved.
+func isReservedUsername(username string) bool {
+	reservedUsernamesMux.RLock()
+	defer reservedUsernamesMux.RUnlock()
+	_, exists := reservedUsernames[strings.ToLower(username)]
+	return exists
+}
+
+// newReservedSet returns the set of lowercase names.
+func newReservedSet(names []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		set[strings.ToLower(name)] = struct{}{}
+	}
+	return set
+}
+
+// ValidateUsername returns ErrInvalidUsername if the username breaks the rules
+// shared by user discovery and clients. A username must:
+//   - be between MinUsernameLen and MaxUsernameLen characters long;
+//   - only contain ASCII letters, digits, underscores, hyphens, and periods;
+//   - not start with a digit; and
+//   - not be reserved (see SetReservedUsernames), ignoring case.
+//
+// It is called by NewFact and ValidateFact for Username facts, before the
+// Policy set via SetGlobalFactPolicy, so clients can use it to pre-validate
+// usernames. It is not applied when decoding stored or received facts (e.g.,
+// with UnstringifyFact or DecodeFactListCSV) so that usernames registered
+// before these rules were introduced remain readable.
+func ValidateUsername(username string) error {
+	if len(username) < MinUsernameLen || len(username) > MaxUsernameLen {
+		return errors.WithMessagef(ErrInvalidUsername, "username %q must be "+
+			"between %d and %d characters", username, MinUsernameLen,
+			MaxUsernameLen)
+	}
+
+	for i := 0; i < len(username); i++ {
+		if c := username[i]; !isUsernameChar(c) {
+			return errors.WithMessagef(ErrInvalidUsername, "username %q "+
+				"contains invalid character %q at %d", username, c, i)
+		}
+	}
+
+	if username[0] >= '0' && username[0] <= '9' {
+		return errors.WithMessagef(ErrInvalidUsername,
+			"username %q must not start with a digit", username)
+	} else if isReservedUsername(username) {
+		return errors.WithMessagef(ErrInvalidUsername,
+			"username %q is reserved", username)
+	}
+
+	return nil
+}
+
+// isUsernameChar determines if the character is allowed in a username.
+func isUsernameChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+		(c >= '0' && c <= '9') || c == '_' || c == '-' || c == '.'
+}
@@ -0,0 +1,134 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// Tests that ValidateUsername accepts usernames that follow the rules.
+func TestValidateUsername(t *testing.T) {
+	valid := []string{"abc", "myUsername", "john.doe-99", "_under",
+		"a1b2c3", "administrator2", strings.Repeat("a", MaxUsernameLen)}
+
+	for _, username := range valid {
+		if err := ValidateUsername(username); err != nil {
+			t.Errorf("Valid username %q rejected: %+v", username, err)
+		}
+	}
+}
+
+// Error path: Tests that ValidateUsername returns ErrInvalidUsername for
+// usernames that break each rule.
+func TestValidateUsername_Invalid(t *testing.T) {
+	invalid := []string{"", "ab", strings.Repeat("a", MaxUsernameLen+1),
+		"john doe", "john@doe", "jöhn", "1john", "9", "admin", "ADMIN",
+		"Root"}
+
+	for _, username := range invalid {
+		err := ValidateUsername(username)
+		if !errors.Is(err, ErrInvalidUsername) {
+			t.Errorf("Unexpected error for username %q.\nexpected: %v"+
+				"\nreceived: %v", username, ErrInvalidUsername, err)
+		}
+	}
+}
+
+// Tests that SetReservedUsernames replaces the reserved list, that an empty
+// list reserves nothing, and that nil restores the default.
+func TestSetReservedUsernames(t *testing.T) {
+	defer SetReservedUsernames(nil)
+
+	SetReservedUsernames([]string{"Staff"})
+	if err := ValidateUsername("staff"); !errors.Is(err, ErrInvalidUsername) {
+		t.Errorf("Reserved username not rejected: %v", err)
+	}
+	if err := ValidateUsername("admin"); err != nil {
+		t.Errorf("Username not in the new list rejected: %+v", err)
+	}
+
+	SetReservedUsernames([]string{})
+	for _, username := range DefaultReservedUsernames() {
+		if err := ValidateUsername(username); err != nil {
+			t.Errorf("Username %q rejected with no reserved list: %+v",
+				username, err)
+		}
+	}
+
+	SetReservedUsernames(nil)
+	for _, username := range DefaultReservedUsernames() {
+		err := ValidateUsername(username)
+		if !errors.Is(err, ErrInvalidUsername) {
+			t.Errorf("Default reserved username %q not rejected: %v",
+				username, err)
+		}
+	}
+}
+
+// Tests that ValidateFact applies the username rules to Username facts only.
+func TestValidateFact_Username(t *testing.T) {
+	err := ValidateFact(Fact{Fact: "admin", T: Username})
+	if !errors.Is(err, ErrInvalidUsername) {
+		t.Errorf("Unexpected error for reserved username.\nexpected: %v"+
+			"\nreceived: %v", ErrInvalidUsername, err)
+	}
+
+	if err = ValidateFact(Fact{Fact: "admin", T: Nickname}); err != nil {
+		t.Errorf("Nickname rejected by username rules: %+v", err)
+	}
+}
+
+// Tests that usernames that break the rules of ValidateUsername, such as those
+// registered before the rules were introduced, are rejected by NewFact but can
+// still be decoded from every serialisation.
+func TestLegacyUsername_Decode(t *testing.T) {
+	legacy := []string{
+		"1alice", strings.Repeat("b", MaxUsernameLen+1), "çarol", "admin"}
+
+	for _, username := range legacy {
+		if _, err := NewFact(Username, username); err == nil {
+			t.Errorf("NewFact accepted legacy username %q.", username)
+		}
+
+		f := Fact{Fact: username, T: Username}
+		if received, err := UnstringifyFact(f.Stringify()); err != nil {
+			t.Errorf("Failed to unstringify v1 %q: %+v", username, err)
+		} else if received != f {
+			t.Errorf("Unexpected v1 fact.\nexpected: %+v\nreceived: %+v",
+				f, received)
+		}
+
+		if received, err := UnstringifyFact(f.StringifyV2()); err != nil {
+			t.Errorf("Failed to unstringify v2 %q: %+v", username, err)
+		} else if received != f {
+			t.Errorf("Unexpected v2 fact.\nexpected: %+v\nreceived: %+v",
+				f, received)
+		}
+
+		fl, _, err := UnstringifyFactList(FactList{f}.Stringify())
+		if err != nil || len(fl) != 1 || fl[0] != f {
+			t.Errorf("Legacy username %q dropped from fact list: %v (%+v)",
+				username, fl, err)
+		}
+
+		fl, err = DecodeFactListCSV(FactList{f}.EncodeCSV())
+		if err != nil || len(fl) != 1 || fl[0] != f {
+			t.Errorf("Failed to decode CSV of %q: %v (%+v)", username, fl, err)
+		}
+
+		if received, err := CompactDecode(f.CompactEncode()); err != nil {
+			t.Errorf("Failed to compact decode %q: %+v", username, err)
+		} else if received != f {
+			t.Errorf("Unexpected compact fact.\nexpected: %+v"+
+				"\nreceived: %+v", f, received)
+		}
+	}
+}
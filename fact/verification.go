@@ -0,0 +1,183 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MaxVerificationAttempts is the number of codes a user may submit for a single
+// VerificationRequest before it is exhausted.
+const MaxVerificationAttempts = 3
+
+// VerificationRequest is the record of a verification code sent by user
+// discovery to a fact, such as an email address or phone number, whose owner
+// must prove ownership of it. The code is delivered out of band and must never
+// be sent to the client over the network.
+//
+// JSON example:
+//
+//	{
+//	  "fact": {"Fact": "john@example.com", "T": 1},
+//	  "code": "482913",
+//	  "expiry": "2024-01-01T00:10:00Z",
+//	  "attempts": 1
+//	}
+type VerificationRequest struct {
+	// Fact is the fact being verified.
+	Fact Fact `json:"fact"`
+
+	// Code is the code that was sent to the fact.
+	Code string `json:"code"`
+
+	// Expiry is the time after which the code is no longer accepted.
+	Expiry time.Time `json:"expiry"`
+
+	// Attempts is the number of codes submitted so far.
+	Attempts uint32 `json:"attempts"`
+}
+
+// VerificationResponse is the message sent by the client to user discovery
+// containing the code it received for the fact.
+//
+// JSON example:
+//
+//	{
+//	  "fact": {"Fact": "john@example.com", "T": 1},
+//	  "code": "482913"
+//	}
+type VerificationResponse struct {
+	// Fact is the fact being verified.
+	Fact Fact `json:"fact"`
+
+	// Code is the code received by the fact's owner.
+	Code string `json:"code"`
+}
+
+// NewVerificationRequest returns a VerificationRequest for the fact with the
+// code that expires after the given duration. Returns an error if facts of the
+// type do not require verification or if the code is empty.
+func NewVerificationRequest(f Fact, code string, timeout time.Duration,
+	now time.Time) (VerificationRequest, error) {
+	if err := validateVerification(f, code); err != nil {
+		return VerificationRequest{}, err
+	}
+
+	return VerificationRequest{
+		Fact:   f,
+		Code:   code,
+		Expiry: now.Add(timeout),
+	}, nil
+}
+
+// Expired determines if the request has expired at the given time.
+func (vr VerificationRequest) Expired(now time.Time) bool {
+	return now.After(vr.Expiry)
+}
+
+// Exhausted determines if the request has used all of its attempts.
+func (vr VerificationRequest) Exhausted() bool {
+	return vr.Attempts >= MaxVerificationAttempts
+}
+
+// Verify checks the response against the request at the given time. Each call
+// made before the request has expired or is exhausted counts as an attempt,
+// whether or not the code is correct. Returns nil only if the response is for
+// the same fact and the code matches. The codes are compared in constant time.
+func (vr *VerificationRequest) Verify(
+	resp VerificationResponse, now time.Time) error {
+	if vr.Expired(now) {
+		return errors.WithMessagef(ErrVerificationExpired,
+			"request for %s fact expired at %s", vr.Fact.T, vr.Expiry)
+	} else if vr.Exhausted() {
+		return errors.WithMessagef(ErrVerificationAttempts,
+			"request for %s fact used all %d attempts", vr.Fact.T,
+			MaxVerificationAttempts)
+	}
+
+	vr.Attempts++
+
+	if resp.Fact.T != vr.Fact.T ||
+		resp.Fact.Normalized() != vr.Fact.Normalized() {
+		return errors.WithMessagef(ErrVerificationFact,
+			"response is for %s fact; request is for %s fact",
+			resp.Fact.T, vr.Fact.T)
+	}
+
+	if !VerifyCode(vr.Code, resp.Code) {
+		return errors.WithMessagef(ErrVerificationCode, "%d of %d attempts "+
+			"used", vr.Attempts, MaxVerificationAttempts)
+	}
+
+	return nil
+}
+
+// Marshal marshals the VerificationRequest into JSON.
+func (vr VerificationRequest) Marshal() ([]byte, error) {
+	return json.Marshal(vr)
+}
+
+// UnmarshalVerificationRequest unmarshalls the JSON into a
+// VerificationRequest and validates it.
+func UnmarshalVerificationRequest(data []byte) (VerificationRequest, error) {
+	var vr VerificationRequest
+	if err := json.Unmarshal(data, &vr); err != nil {
+		return VerificationRequest{}, errors.Wrap(err,
+			"failed to unmarshal VerificationRequest")
+	}
+
+	if err := validateVerification(vr.Fact, vr.Code); err != nil {
+		return VerificationRequest{}, err
+	}
+
+	return vr, nil
+}
+
+// Marshal marshals the VerificationResponse into JSON.
+func (vr VerificationResponse) Marshal() ([]byte, error) {
+	return json.Marshal(vr)
+}
+
+// UnmarshalVerificationResponse unmarshalls the JSON into a
+// VerificationResponse and validates it.
+func UnmarshalVerificationResponse(data []byte) (VerificationResponse, error) {
+	var vr VerificationResponse
+	if err := json.Unmarshal(data, &vr); err != nil {
+		return VerificationResponse{}, errors.Wrap(err,
+			"failed to unmarshal VerificationResponse")
+	}
+
+	if err := validateVerification(vr.Fact, vr.Code); err != nil {
+		return VerificationResponse{}, err
+	}
+
+	return vr, nil
+}
+
+// VerifyCode determines if the received code matches the expected code. The
+// comparison is done in constant time, so the time taken does not reveal how
+// much of the code is correct.
+func VerifyCode(expected, received string) bool {
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(received)) == 1
+}
+
+// validateVerification returns an error if the fact cannot be verified or the
+// code is empty.
+func validateVerification(f Fact, code string) error {
+	if !f.T.RequiresVerification() {
+		return errors.Errorf("%s facts do not require verification", f.T)
+	} else if code == "" {
+		return errors.WithMessage(ErrEmpty, "verification code is empty")
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package fact
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Tests that NewVerificationRequest returns a request with the expected expiry
+// that is neither expired nor exhausted.
+func TestNewVerificationRequest(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	f := Fact{Fact: "john@example.com", T: Email}
+	vr, err := NewVerificationRequest(f, "482913", 10*time.Minute, now)
+	if err != nil {
+		t.Fatalf("Failed to create VerificationRequest: %+v", err)
+	}
+
+	expected := VerificationRequest{
+		Fact:   f,
+		Code:   "482913",
+		Expiry: now.Add(10 * time.Minute),
+	}
+	if !reflect.DeepEqual(expected, vr) {
+		t.Errorf("Unexpected VerificationRequest."+
+			"\nexpected: %+v\nreceived: %+v", expected, vr)
+	}
+
+	if vr.Expired(now) || vr.Exhausted() {
+		t.Errorf("New VerificationRequest is expired or exhausted: %+v", vr)
+	}
+}
+
+// Error path: Tests that NewVerificationRequest rejects facts that do not
+// require verification and empty codes.
+func TestNewVerificationRequest_Error(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	_, err := NewVerificationRequest(
+		Fact{Fact: "john", T: Username}, "482913", time.Minute, now)
+	if err == nil {
+		t.Error("No error for fact that does not require verification.")
+	}
+
+	_, err = NewVerificationRequest(
+		Fact{Fact: "john@example.com", T: Email}, "", time.Minute, now)
+	if !errors.Is(err, ErrEmpty) {
+		t.Errorf("Unexpected error for empty code: %+v", err)
+	}
+}
+
+// Tests that VerificationRequest.Verify accepts the correct code for the same
+// fact, ignoring case, and counts the attempt.
+func TestVerificationRequest_Verify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	vr, _ := NewVerificationRequest(
+		Fact{Fact: "john@example.com", T: Email}, "482913", time.Minute, now)
+
+	resp := VerificationResponse{
+		Fact: Fact{Fact: "John@Example.com", T: Email},
+		Code: "482913",
+	}
+	if err := vr.Verify(resp, now.Add(time.Minute)); err != nil {
+		t.Errorf("Failed to verify correct code: %+v", err)
+	}
+
+	if vr.Attempts != 1 {
+		t.Errorf("Unexpected attempts.\nexpected: %d\nreceived: %d",
+			1, vr.Attempts)
+	}
+}
+
+// Error path: Tests that VerificationRequest.Verify returns the expected error
+// for each failure.
+func TestVerificationRequest_Verify_Error(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	f := Fact{Fact: "john@example.com", T: Email}
+	tests := []struct {
+		attempts uint32
+		resp     VerificationResponse
+		now      time.Time
+		err      error
+	}{
+		{0, VerificationResponse{f, "482914"}, now, ErrVerificationCode},
+		{0, VerificationResponse{f, "48291"}, now, ErrVerificationCode},
+		{0, VerificationResponse{Fact{Fact: "jane@example.com", T: Email},
+			"482913"}, now, ErrVerificationFact},
+		{0, VerificationResponse{Fact{Fact: "john@example.com", T: Phone},
+			"482913"}, now, ErrVerificationFact},
+		{0, VerificationResponse{f, "482913"}, now.Add(time.Hour),
+			ErrVerificationExpired},
+		{MaxVerificationAttempts, VerificationResponse{f, "482913"}, now,
+			ErrVerificationAttempts},
+	}
+
+	for i, tt := range tests {
+		vr, _ := NewVerificationRequest(f, "482913", time.Minute, now)
+		vr.Attempts = tt.attempts
+		err := vr.Verify(tt.resp, tt.now)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Unexpected error (%d).\nexpected: %v\nreceived: %+v",
+				i, tt.err, err)
+		}
+	}
+}
+
+// Tests that VerificationRequest.Verify stops accepting codes, even the correct
+// one, after MaxVerificationAttempts incorrect codes.
+func TestVerificationRequest_Verify_Exhausted(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	f := Fact{Fact: "+15555555555", T: Phone}
+	vr, _ := NewVerificationRequest(f, "482913", time.Minute, now)
+
+	for i := 0; i < MaxVerificationAttempts; i++ {
+		err := vr.Verify(VerificationResponse{f, "000000"}, now)
+		if !errors.Is(err, ErrVerificationCode) {
+			t.Errorf("Unexpected error (%d): %+v", i, err)
+		}
+	}
+
+	err := vr.Verify(VerificationResponse{f, "482913"}, now)
+	if !errors.Is(err, ErrVerificationAttempts) {
+		t.Errorf("Unexpected error after all attempts used: %+v", err)
+	}
+}
+
+// Tests that a VerificationRequest and VerificationResponse that are marshalled
+// and unmarshalled match the originals.
+func TestVerification_Marshal_Unmarshal(t *testing.T) {
+	f := Fact{Fact: "john@example.com", T: Email, Locale: "en-US"}
+	vr := VerificationRequest{
+		Fact:     f,
+		Code:     "482913",
+		Expiry:   time.Unix(1700000000, 0).UTC(),
+		Attempts: 2,
+	}
+
+	data, err := vr.Marshal()
+	if err != nil {
+		t.Fatalf("Failed to marshal VerificationRequest: %+v", err)
+	}
+	newVr, err := UnmarshalVerificationRequest(data)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal VerificationRequest: %+v", err)
+	}
+	if !reflect.DeepEqual(vr, newVr) {
+		t.Errorf("Unexpected VerificationRequest."+
+			"\nexpected: %+v\nreceived: %+v", vr, newVr)
+	}
+
+	resp := VerificationResponse{Fact: f, Code: "482913"}
+	data, err = resp.Marshal()
+	if err != nil {
+		t.Fatalf("Failed to marshal VerificationResponse: %+v", err)
+	}
+	newResp, err := UnmarshalVerificationResponse(data)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal VerificationResponse: %+v", err)
+	}
+	if !reflect.DeepEqual(resp, newResp) {
+		t.Errorf("Unexpected VerificationResponse."+
+			"\nexpected: %+v\nreceived: %+v", resp, newResp)
+	}
+}
+
+// Error path: Tests that UnmarshalVerificationRequest and
+// UnmarshalVerificationResponse reject invalid JSON and invalid contents.
+func TestVerification_Unmarshal_Error(t *testing.T) {
+	for i, data := range []string{
+		"invalid",
+		`{"fact":{"Fact":"john","T":0},"code":"482913"}`,
+		`{"fact":{"Fact":"john@example.com","T":1},"code":""}`,
+	} {
+		if _, err := UnmarshalVerificationRequest([]byte(data)); err == nil {
+			t.Errorf("No error unmarshalling VerificationRequest %q (%d).",
+				data, i)
+		}
+		if _, err := UnmarshalVerificationResponse([]byte(data)); err == nil {
+			t.Errorf("No error unmarshalling VerificationResponse %q (%d).",
+				data, i)
+		}
+	}
+}
+
+// Tests that VerifyCode only matches identical codes.
+func TestVerifyCode(t *testing.T) {
+	tests := []struct {
+		expected, received string
+		match              bool
+	}{
+		{"482913", "482913", true},
+		{"482913", "482914", false},
+		{"482913", "48291", false},
+		{"482913", "4829130", false},
+		{"482913", "", false},
+	}
+
+	for i, tt := range tests {
+		if VerifyCode(tt.expected, tt.received) != tt.match {
+			t.Errorf("Unexpected result for %q and %q (%d).\nexpected: %t",
+				tt.expected, tt.received, i, tt.match)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+)
+
+// KeyFingerprint is the key fingerprint stored in a Message. It is the same
+// type as Fingerprint so that no conversion is needed between the two.
+type KeyFingerprint = Fingerprint
+
+// Mac is the message authentication code stored in a Message.
+type Mac [MacLen]byte
+
+// EphemeralRID is the ephemeral recipient ID stored in a Message.
+type EphemeralRID [EphemeralRIDLen]byte
+
+// SIH is the service identification hash stored in a Message.
+type SIH [SIHLen]byte
+
+// AssociatedData holds a copy of every field of a Message that is not part of
+// the contents. The current layout has no timestamp field.
+type AssociatedData struct {
+	KeyFP        KeyFingerprint
+	Mac          Mac
+	EphemeralRID EphemeralRID
+	SIH          SIH
+}
+
+// Marshal returns the Fingerprint as a newly allocated byte slice.
+func (fp Fingerprint) Marshal() []byte { return copyByteSlice(fp[:]) }
+
+// Compare returns an integer comparing two Fingerprint lexicographically. The
+// result is 0 if fp == other, -1 if fp < other, and +1 if fp > other.
+func (fp Fingerprint) Compare(other Fingerprint) int {
+	return bytes.Compare(fp[:], other[:])
+}
+
+// IsZero determines if every byte of the Fingerprint is zero.
+func (fp Fingerprint) IsZero() bool { return fp == Fingerprint{} }
+
+// Marshal returns the Mac as a newly allocated byte slice.
+func (mac Mac) Marshal() []byte { return copyByteSlice(mac[:]) }
+
+// Compare returns an integer comparing two Mac lexicographically. The result
+// is 0 if mac == other, -1 if mac < other, and +1 if mac > other.
+func (mac Mac) Compare(other Mac) int { return bytes.Compare(mac[:], other[:]) }
+
+// IsZero determines if every byte of the Mac is zero.
+func (mac Mac) IsZero() bool { return mac == Mac{} }
+
+// Marshal returns the EphemeralRID as a newly allocated byte slice.
+func (rid EphemeralRID) Marshal() []byte { return copyByteSlice(rid[:]) }
+
+// Compare returns an integer comparing two EphemeralRID lexicographically. The
+// result is 0 if rid == other, -1 if rid < other, and +1 if rid > other.
+func (rid EphemeralRID) Compare(other EphemeralRID) int {
+	return bytes.Compare(rid[:], other[:])
+}
+
+// IsZero determines if every byte of the EphemeralRID is zero.
+func (rid EphemeralRID) IsZero() bool { return rid == EphemeralRID{} }
+
+// Marshal returns the SIH as a newly allocated byte slice.
+func (sih SIH) Marshal() []byte { return copyByteSlice(sih[:]) }
+
+// Compare returns an integer comparing two SIH lexicographically. The result
+// is 0 if sih == other, -1 if sih < other, and +1 if sih > other.
+func (sih SIH) Compare(other SIH) int { return bytes.Compare(sih[:], other[:]) }
+
+// IsZero determines if every byte of the SIH is zero.
+func (sih SIH) IsZero() bool { return sih == SIH{} }
+
+// Marshal serialises the AssociatedData into a byte slice of length
+// AssociatedDataSize in the order key fingerprint, MAC, ephemeral recipient
+// ID, and SIH.
+func (ad AssociatedData) Marshal() []byte {
+	b := make([]byte, 0, AssociatedDataSize)
+	b = append(b, ad.KeyFP[:]...)
+	b = append(b, ad.Mac[:]...)
+	b = append(b, ad.EphemeralRID[:]...)
+	return append(b, ad.SIH[:]...)
+}
+
+// UnmarshalAssociatedData deserializes AssociatedData marshalled with
+// AssociatedData.Marshal.
+func UnmarshalAssociatedData(b []byte) (AssociatedData, error) {
+	if len(b) != AssociatedDataSize {
+		return AssociatedData{}, errors.Errorf("associated data must be %d "+
+			"bytes; received %d bytes", AssociatedDataSize, len(b))
+	}
+
+	var ad AssociatedData
+	b = b[copy(ad.KeyFP[:], b):]
+	b = b[copy(ad.Mac[:], b):]
+	b = b[copy(ad.EphemeralRID[:], b):]
+	copy(ad.SIH[:], b)
+
+	return ad, nil
+}
+
+// GetAssociatedData returns a copy of all the associated data in the message.
+// As with Message.GetKeyFP and Message.GetMac, the group bits of the key
+// fingerprint and MAC are cleared.
+func (m Message) GetAssociatedData() AssociatedData {
+	var ad AssociatedData
+	ad.KeyFP = m.GetKeyFP()
+	copy(ad.Mac[:], m.GetMac())
+	copy(ad.EphemeralRID[:], m.ephemeralRID)
+	copy(ad.SIH[:], m.sih)
+	return ad
+}
+
+// SetAssociatedData sets all the associated data in the message. Panics if the
+// first bit of the key fingerprint or MAC is not zero.
+func (m Message) SetAssociatedData(ad AssociatedData) {
+	m.SetKeyFP(ad.KeyFP)
+	m.SetMac(ad.Mac[:])
+	m.SetEphemeralRID(ad.EphemeralRID[:])
+	m.SetSIH(ad.SIH[:])
+}
@@ -0,0 +1,110 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// newTestAssociatedData returns random AssociatedData with the group bits of
+// the key fingerprint and MAC cleared.
+func newTestAssociatedData(prng *rand.Rand) AssociatedData {
+	var ad AssociatedData
+	prng.Read(ad.KeyFP[:])
+	prng.Read(ad.Mac[:])
+	prng.Read(ad.EphemeralRID[:])
+	prng.Read(ad.SIH[:])
+	ad.KeyFP[0] &= 0x7F
+	ad.Mac[0] &= 0x7F
+	return ad
+}
+
+// Tests that AssociatedData set via Message.SetAssociatedData is returned by
+// Message.GetAssociatedData and matches the individual getters.
+func TestMessage_SetAssociatedData_GetAssociatedData(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	m := NewMessage(MinimumPrimeSize)
+	ad := newTestAssociatedData(prng)
+
+	m.SetAssociatedData(ad)
+	received := m.GetAssociatedData()
+
+	if received != ad {
+		t.Errorf("Unexpected associated data.\nexpected: %+v\nreceived: %+v",
+			ad, received)
+	}
+	if !bytes.Equal(received.Mac.Marshal(), m.GetMac()) ||
+		received.KeyFP != m.GetKeyFP() ||
+		!bytes.Equal(received.EphemeralRID.Marshal(), m.GetEphemeralRID()) ||
+		!bytes.Equal(received.SIH.Marshal(), m.GetSIH()) {
+		t.Error("Associated data does not match the individual getters.")
+	}
+}
+
+// Tests that AssociatedData marshalled with AssociatedData.Marshal and
+// unmarshalled with UnmarshalAssociatedData matches the original.
+func TestAssociatedData_Marshal_UnmarshalAssociatedData(t *testing.T) {
+	ad := newTestAssociatedData(rand.New(rand.NewSource(7)))
+
+	data := ad.Marshal()
+	if len(data) != AssociatedDataSize {
+		t.Errorf("Unexpected length.\nexpected: %d\nreceived: %d",
+			AssociatedDataSize, len(data))
+	}
+
+	newAd, err := UnmarshalAssociatedData(data)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal: %+v", err)
+	} else if newAd != ad {
+		t.Errorf("Unexpected associated data.\nexpected: %+v\nreceived: %+v",
+			ad, newAd)
+	}
+
+	if _, err = UnmarshalAssociatedData(data[1:]); err == nil {
+		t.Error("Expected error for data of the wrong length.")
+	}
+}
+
+// Tests the Compare and IsZero helpers of the associated data types.
+func TestAssociatedData_CompareIsZero(t *testing.T) {
+	var fp1, fp2 KeyFingerprint
+	fp2[KeyFPLen-1] = 1
+	if !fp1.IsZero() || fp2.IsZero() {
+		t.Error("Unexpected IsZero result for KeyFingerprint.")
+	}
+	if fp1.Compare(fp2) != -1 || fp2.Compare(fp1) != 1 || fp1.Compare(fp1) != 0 {
+		t.Error("Unexpected Compare result for KeyFingerprint.")
+	}
+
+	var mac1, mac2 Mac
+	mac2[0] = 1
+	if !mac1.IsZero() || mac2.IsZero() || mac1.Compare(mac2) != -1 {
+		t.Error("Unexpected result for Mac.")
+	}
+
+	var rid1, rid2 EphemeralRID
+	rid1[3] = 2
+	if rid1.IsZero() || !rid2.IsZero() || rid1.Compare(rid2) != 1 {
+		t.Error("Unexpected result for EphemeralRID.")
+	}
+
+	var sih1, sih2 SIH
+	sih1[0], sih2[0] = 5, 5
+	if sih1.IsZero() || sih1.Compare(sih2) != 0 {
+		t.Error("Unexpected result for SIH.")
+	}
+
+	// Marshal must return a copy
+	b := mac2.Marshal()
+	b[0] = 0xFF
+	if mac2[0] != 1 {
+		t.Error("Modifying the marshalled Mac modified the original.")
+	}
+}
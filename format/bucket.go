@@ -0,0 +1,53 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import (
+	"math/bits"
+
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+// bucketKeyLen is the number of bytes at the end of a fingerprint used to map
+// it to a bucket.
+const bucketKeyLen = 8
+
+// BucketOf maps the fingerprint to one of numBuckets buckets, such as the
+// storage shards of a gateway, so that every implementation agrees on the
+// mapping. Gateways and clients must use this function rather than their own
+// mapping.
+//
+// The last eight bytes of the fingerprint are read as a big-endian integer,
+// since the first bit of a key fingerprint is always zero to keep it in the
+// group; shorter fingerprints are left padded with zeros. The integer x is
+// mapped to bucket floor(x * numBuckets / 2^64), which is uniform for uniformly
+// distributed fingerprints and, unlike a modulus, keeps neighbouring
+// fingerprints in neighbouring buckets. Panics if numBuckets is not positive.
+func BucketOf(fp []byte, numBuckets int) int {
+	if numBuckets < 1 {
+		jww.FATAL.Panicf("Cannot map fingerprint to %d buckets; there must "+
+			"be at least one bucket.", numBuckets)
+	}
+
+	if len(fp) > bucketKeyLen {
+		fp = fp[len(fp)-bucketKeyLen:]
+	}
+	var x uint64
+	for _, b := range fp {
+		x = x<<8 | uint64(b)
+	}
+
+	bucket, _ := bits.Mul64(x, uint64(numBuckets))
+	return int(bucket)
+}
+
+// Bucket maps the fingerprint to one of numBuckets buckets. Refer to BucketOf
+// for more details.
+func (fp Fingerprint) Bucket(numBuckets int) int {
+	return BucketOf(fp[:], numBuckets)
+}
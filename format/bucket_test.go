@@ -0,0 +1,86 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Consistency test of BucketOf.
+func TestBucketOf_Consistency(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	expected := []int{3, 8, 14, 8, 9, 11, 0, 12, 1, 8}
+
+	for i, exp := range expected {
+		fp := make([]byte, KeyFPLen)
+		prng.Read(fp)
+		if bucket := BucketOf(fp, 16); bucket != exp {
+			t.Errorf("Unexpected bucket for fingerprint %d."+
+				"\nexpected: %d\nreceived: %d", i, exp, bucket)
+		}
+	}
+}
+
+// Tests that BucketOf uses only the last eight bytes of the fingerprint, read
+// as big-endian, and pads shorter fingerprints.
+func TestBucketOf_ByteOrder(t *testing.T) {
+	tests := []struct {
+		fp       []byte
+		expected int
+	}{
+		{nil, 0},
+		{[]byte{0x80}, 0},
+		{[]byte{0x80, 0, 0, 0, 0, 0, 0, 0}, 128},
+		{[]byte{0xFF, 0x7F, 0, 0, 0, 0, 0, 0, 0}, 127},
+		{[]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, 255},
+	}
+
+	for i, tt := range tests {
+		if bucket := BucketOf(tt.fp, 256); bucket != tt.expected {
+			t.Errorf("Unexpected bucket (%d).\nexpected: %d\nreceived: %d",
+				i, tt.expected, bucket)
+		}
+	}
+}
+
+// Tests that BucketOf maps random fingerprints uniformly across the buckets
+// and that Fingerprint.Bucket matches it.
+func TestBucketOf_Uniform(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	const numBuckets, perBucket = 10, 2000
+	counts := make([]int, numBuckets)
+
+	for i := 0; i < numBuckets*perBucket; i++ {
+		var fp Fingerprint
+		prng.Read(fp[:])
+		bucket := BucketOf(fp[:], numBuckets)
+		if bucket != fp.Bucket(numBuckets) {
+			t.Fatalf("Fingerprint.Bucket does not match BucketOf.")
+		}
+		counts[bucket]++
+	}
+
+	for bucket, count := range counts {
+		if count < perBucket*9/10 || count > perBucket*11/10 {
+			t.Errorf("Bucket %d has %d fingerprints; expected about %d.",
+				bucket, count, perBucket)
+		}
+	}
+}
+
+// Error path: Tests that BucketOf panics when there are no buckets.
+func TestBucketOf_NoBucketsPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Failed to panic for zero buckets.")
+		}
+	}()
+
+	BucketOf(make([]byte, KeyFPLen), 0)
+}
@@ -0,0 +1,75 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+// DataLenSize is the size, in bytes, of the length prefix stored at the start
+// of the contents by Message.SetData.
+const DataLenSize = 2
+
+/*
+                 Contents with length accounting
++----------------------------------------------------------------+
+|                            Contents                            |
+|                     Message.ContentsSize()                     |
++-------------+---------------------------------+----------------+
+| data length |              data               |  zero padding  |
+|   2 bytes   |          data length            |   remainder    |
++-------------+---------------------------------+----------------+
+*/
+
+// GetDataCapacity returns the maximum size of the data that can be stored via
+// Message.SetData.
+func (m Message) GetDataCapacity() int {
+	return m.ContentsSize() - DataLenSize
+}
+
+// GetDataLength returns the length of the data stored via Message.SetData as
+// recorded in the length prefix.
+func (m Message) GetDataLength() int {
+	return int(binary.BigEndian.Uint16(m.GetContents()[:DataLenSize]))
+}
+
+// SetData stores the data in the contents preceded by its length so that
+// Message.GetData returns exactly the data set, including any trailing zeros.
+// The remainder of the contents is zeroed. Panics if the data is larger than
+// the capacity.
+func (m Message) SetData(data []byte) {
+	if len(data) > m.GetDataCapacity() {
+		jww.ERROR.Panicf("Failed to set Message data: length must be equal "+
+			"to or less than %d, length of received data is %d.",
+			m.GetDataCapacity(), len(data))
+	}
+
+	c := make([]byte, m.ContentsSize())
+	binary.BigEndian.PutUint16(c[:DataLenSize], uint16(len(data)))
+	copy(c[DataLenSize:], data)
+	m.SetContents(c)
+}
+
+// GetData returns the data stored via Message.SetData. An error is returned if
+// the length prefix is larger than the capacity, which indicates the contents
+// were not set via Message.SetData.
+//
+//format:copy
+func (m Message) GetData() ([]byte, error) {
+	c := m.GetContents()
+	length := int(binary.BigEndian.Uint16(c[:DataLenSize]))
+	if length > m.GetDataCapacity() {
+		return nil, errors.Errorf("data length %d in prefix exceeds data "+
+			"capacity %d", length, m.GetDataCapacity())
+	}
+
+	return c[DataLenSize : DataLenSize+length], nil
+}
@@ -0,0 +1,75 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Tests that data set via Message.SetData, including trailing zeros, is
+// returned exactly by Message.GetData.
+func TestMessage_SetData_GetData(t *testing.T) {
+	msg := NewMessage(MinimumPrimeSize)
+	msg.SetContents(makeAndFillSlice(msg.ContentsSize(), 'a'))
+
+	tests := [][]byte{
+		{},
+		{1, 2, 3, 0, 0},
+		makeAndFillSlice(msg.GetDataCapacity(), 'b'),
+	}
+
+	for i, expected := range tests {
+		msg.SetData(expected)
+
+		if msg.GetDataLength() != len(expected) {
+			t.Errorf("Unexpected data length (%d).\nexpected: %d"+
+				"\nreceived: %d", i, len(expected), msg.GetDataLength())
+		}
+
+		data, err := msg.GetData()
+		if err != nil {
+			t.Errorf("Failed to get data (%d): %+v", i, err)
+		} else if !bytes.Equal(expected, data) {
+			t.Errorf("Unexpected data (%d).\nexpected: %v\nreceived: %v",
+				i, expected, data)
+		}
+	}
+}
+
+// Tests that Message.GetDataCapacity accounts for the length prefix.
+func TestMessage_GetDataCapacity(t *testing.T) {
+	msg := NewMessage(DefaultPrimeSize)
+	if msg.GetDataCapacity() != msg.ContentsSize()-DataLenSize {
+		t.Errorf("Unexpected capacity.\nexpected: %d\nreceived: %d",
+			msg.ContentsSize()-DataLenSize, msg.GetDataCapacity())
+	}
+}
+
+// Error path: Tests that Message.SetData panics when the data is too large.
+func TestMessage_SetData_TooLargePanic(t *testing.T) {
+	msg := NewMessage(MinimumPrimeSize)
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("SetData did not panic for data larger than capacity.")
+		}
+	}()
+
+	msg.SetData(make([]byte, msg.GetDataCapacity()+1))
+}
+
+// Error path: Tests that Message.GetData returns an error when the length
+// prefix is larger than the capacity.
+func TestMessage_GetData_InvalidLengthError(t *testing.T) {
+	msg := NewMessage(MinimumPrimeSize)
+	msg.SetContents([]byte{0xFF, 0xFF})
+
+	if _, err := msg.GetData(); err == nil {
+		t.Error("GetData did not return an error for an invalid length.")
+	}
+}
@@ -0,0 +1,83 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+
+	jww "github.com/spf13/jwalterweatherman"
+	"golang.org/x/crypto/blake2b"
+)
+
+// IdentityTagLen is the length of an IdentityTag in bytes.
+const IdentityTagLen = 4
+
+// IdentityTag is a short tag that distinguishes between multiple identities
+// that share a single ephemeral reception ID. It is carried in the leading
+// IdentityTagLen bytes of the SIH in the recipient ID of a Message, so tagging
+// a message takes no additional bytes. A tagged SIH is built with TagSIH and
+// both the sender and the receiver use it, so receivers still match the whole
+// SIH.
+type IdentityTag [IdentityTagLen]byte
+
+// NewIdentityTag generates the IdentityTag for the given identity. The tag is
+// the truncated blake2b hash of the identity so that tags of different
+// identities are uniformly distributed and unlikely to collide.
+func NewIdentityTag(identity []byte) IdentityTag {
+	h := blake2b.Sum256(identity)
+	var tag IdentityTag
+	copy(tag[:], h[:IdentityTagLen])
+	return tag
+}
+
+// Bytes returns the IdentityTag as a byte slice.
+func (tag IdentityTag) Bytes() []byte {
+	return tag[:]
+}
+
+// String returns the IdentityTag as a base 64 encoded string. This functions
+// satisfies the fmt.Stringer interface.
+func (tag IdentityTag) String() string {
+	return base64.StdEncoding.EncodeToString(tag.Bytes())
+}
+
+// TagSIH returns the SIH made of the IdentityTag followed by the leading bytes
+// of the SIH. Panics if the SIH is not of length SIHLen.
+func TagSIH(tag IdentityTag, sih []byte) []byte {
+	if len(sih) != SIHLen {
+		jww.FATAL.Panicf("Failed to tag Service Identification Hash: length "+
+			"must be %d, length of received data is %d.", SIHLen, len(sih))
+	}
+
+	tagged := make([]byte, SIHLen)
+	copy(tagged[copy(tagged, tag[:]):], sih)
+	return tagged
+}
+
+// SetIdentityTag sets the SIH of the message to the IdentityTag followed by the
+// leading bytes of the SIH, as returned by TagSIH. The whole SIH is set at once
+// so that a previously set SIH is never partially overwritten.
+func (m Message) SetIdentityTag(tag IdentityTag, sih []byte) {
+	m.SetSIH(TagSIH(tag, sih))
+}
+
+// GetIdentityTag returns the IdentityTag from the leading bytes of the SIH
+// stored in the message. The SIH is not modified.
+func (m Message) GetIdentityTag() IdentityTag {
+	var tag IdentityTag
+	copy(tag[:], m.sih)
+	return tag
+}
+
+// HasIdentityTag determines if the message is tagged for the given identity.
+// The comparison is done in constant time.
+func (m Message) HasIdentityTag(identity []byte) bool {
+	tag := NewIdentityTag(identity)
+	return subtle.ConstantTimeCompare(tag[:], m.sih[:IdentityTagLen]) == 1
+}
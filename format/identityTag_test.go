@@ -0,0 +1,87 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Consistency test of NewIdentityTag.
+func TestNewIdentityTag(t *testing.T) {
+	expected := []string{"DldRwA==", "4R2BSQ==", "h2g9qA=="}
+
+	for i, identity := range []string{"", "alice", "bob"} {
+		tag := NewIdentityTag([]byte(identity))
+		if tag.String() != expected[i] {
+			t.Errorf("Unexpected tag for %q.\nexpected: %s\nreceived: %s",
+				identity, expected[i], tag)
+		}
+	}
+}
+
+// Tests that an IdentityTag set via Message.SetIdentityTag is returned by
+// Message.GetIdentityTag, matched by Message.HasIdentityTag, and that the SIH
+// of the message matches the SIH computed by a receiver with TagSIH.
+func TestMessage_SetIdentityTag_GetIdentityTag(t *testing.T) {
+	msg := NewMessage(MinimumPrimeSize)
+	ephemeralRID := makeAndFillSlice(EphemeralRIDLen, 'e')
+	sih := makeAndFillSlice(SIHLen, 'f')
+	msg.SetEphemeralRID(ephemeralRID)
+
+	tag := NewIdentityTag([]byte("alice"))
+	msg.SetIdentityTag(tag, sih)
+
+	if msg.GetIdentityTag() != tag {
+		t.Errorf("Unexpected tag.\nexpected: %s\nreceived: %s",
+			tag, msg.GetIdentityTag())
+	}
+
+	if !msg.HasIdentityTag([]byte("alice")) {
+		t.Error("Message does not have tag for its identity.")
+	}
+	if msg.HasIdentityTag([]byte("bob")) {
+		t.Error("Message has tag for another identity.")
+	}
+
+	if !bytes.Equal(msg.GetSIH(), TagSIH(tag, sih)) {
+		t.Errorf("SIH does not match tagged SIH.\nexpected: %v\nreceived: %v",
+			TagSIH(tag, sih), msg.GetSIH())
+	}
+	if !bytes.Equal(msg.GetEphemeralRID(), ephemeralRID) {
+		t.Error("Setting the identity tag modified the ephemeral RID.")
+	}
+}
+
+// Tests that Message.GetIdentityTag reads the tag from the SIH without
+// modifying it.
+func TestMessage_GetIdentityTag_SIHUnchanged(t *testing.T) {
+	msg := NewMessage(MinimumPrimeSize)
+	sih := makeAndFillSlice(SIHLen, 'f')
+	msg.SetSIH(sih)
+
+	tag := msg.GetIdentityTag()
+	if !bytes.Equal(tag[:], sih[:IdentityTagLen]) {
+		t.Errorf("Unexpected tag.\nexpected: %v\nreceived: %v",
+			sih[:IdentityTagLen], tag[:])
+	}
+	if !bytes.Equal(msg.GetSIH(), sih) {
+		t.Error("Getting the identity tag modified the SIH.")
+	}
+}
+
+// Error path: Tests that TagSIH panics for an SIH of the wrong length.
+func TestTagSIH_InvalidLengthPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Failed to panic for SIH of invalid length.")
+		}
+	}()
+
+	TagSIH(IdentityTag{}, make([]byte, SIHLen-1))
+}
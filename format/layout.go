@@ -0,0 +1,146 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import (
+	"github.com/pkg/errors"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+// DefaultPrimeSize is the size, in bytes, of the 4096-bit prime used by the
+// network. Messages of this prime size have a 512-byte master buffer.
+const DefaultPrimeSize = 256
+
+// Region describes a contiguous range of bytes in the master buffer of a
+// Message.
+type Region struct {
+	Name   string
+	Offset int
+	Length int
+}
+
+// End returns the offset of the first byte after the Region.
+func (r Region) End() int {
+	return r.Offset + r.Length
+}
+
+// Slice returns the subslice of the master buffer covered by the Region. The
+// returned slice shares memory with data.
+func (r Region) Slice(data []byte) []byte {
+	return data[r.Offset:r.End()]
+}
+
+// Layout describes the position of every field in the master buffer of a
+// Message for a given prime size. External tools that need to parse raw
+// messages should use the Layout rather than hard-coding offsets.
+type Layout struct {
+	// TotalLen is the length of the master buffer (2*primeSize)
+	TotalLen int
+
+	// Regions in payload A
+	KeyFP     Region
+	Version   Region
+	Contents1 Region
+
+	// Regions in payload B
+	Mac          Region
+	Contents2    Region
+	EphemeralRID Region
+	SIH          Region
+}
+
+// NewLayout returns the Layout of a Message for the given prime size in bytes.
+// The returned Layout is not validated.
+func NewLayout(numPrimeBytes int) Layout {
+	l := Layout{TotalLen: 2 * numPrimeBytes}
+
+	l.KeyFP = Region{"keyFP", 0, KeyFPLen}
+	l.Version = Region{"version", l.KeyFP.End(), 1}
+	l.Contents1 = Region{"contents1", l.Version.End(),
+		numPrimeBytes - l.Version.End()}
+
+	l.Mac = Region{"mac", numPrimeBytes, MacLen}
+	l.Contents2 = Region{"contents2", l.Mac.End(),
+		l.TotalLen - RecipientIDLen - l.Mac.End()}
+	l.EphemeralRID = Region{"ephemeralRID", l.Contents2.End(), EphemeralRIDLen}
+	l.SIH = Region{"sih", l.EphemeralRID.End(), SIHLen}
+
+	return l
+}
+
+// Regions returns all the Regions of the Layout in the order they appear in
+// the master buffer.
+func (l Layout) Regions() []Region {
+	return []Region{l.KeyFP, l.Version, l.Contents1,
+		l.Mac, l.Contents2, l.EphemeralRID, l.SIH}
+}
+
+// PayloadA returns the Region covering payload A.
+func (l Layout) PayloadA() Region {
+	return Region{"payloadA", 0, l.TotalLen / 2}
+}
+
+// PayloadB returns the Region covering payload B.
+func (l Layout) PayloadB() Region {
+	return Region{"payloadB", l.TotalLen / 2, l.TotalLen / 2}
+}
+
+// RawContents returns the Region covering everything except the recipient ID.
+func (l Layout) RawContents() Region {
+	return Region{"rawContents", 0, l.EphemeralRID.Offset}
+}
+
+// Validate checks that the Regions of the Layout tile the master buffer exactly
+// with no gaps or overlaps and that no Region crosses the boundary between
+// payload A and payload B. Returns an error describing the first violation.
+func (l Layout) Validate() error {
+	if l.TotalLen%2 != 0 {
+		return errors.Errorf("total length %d is not even", l.TotalLen)
+	}
+
+	next := 0
+	for _, r := range l.Regions() {
+		if r.Length < 0 {
+			return errors.Errorf("region %s has negative length %d",
+				r.Name, r.Length)
+		} else if r.Offset < next {
+			return errors.Errorf("region %s at offset %d overlaps previous "+
+				"region ending at %d", r.Name, r.Offset, next)
+		} else if r.Offset > next {
+			return errors.Errorf("gap between offset %d and region %s at "+
+				"offset %d", next, r.Name, r.Offset)
+		} else if r.Offset < l.TotalLen/2 && r.End() > l.TotalLen/2 {
+			return errors.Errorf("region %s [%d, %d) crosses payload "+
+				"boundary at %d", r.Name, r.Offset, r.End(), l.TotalLen/2)
+		}
+		next = r.End()
+	}
+
+	if next != l.TotalLen {
+		return errors.Errorf("regions end at %d but total length is %d",
+			next, l.TotalLen)
+	}
+
+	return nil
+}
+
+// Layout returns the Layout of the Message.
+func (m Message) Layout() Layout {
+	return NewLayout(m.GetPrimeByteLen())
+}
+
+// init ensures the field constants produce a valid layout.
+func init() {
+	sizes := []int{MinimumPrimeSize, LegacyPrimeSize, DefaultPrimeSize}
+	for _, size := range sizes {
+		if err := NewLayout(size).Validate(); err != nil {
+			jww.FATAL.Panicf("Invalid message layout for prime size %d: %+v",
+				size, err)
+		}
+	}
+}
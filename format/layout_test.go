@@ -0,0 +1,102 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// Tests that NewLayout produces a valid Layout for a range of prime sizes.
+func TestNewLayout_Validate(t *testing.T) {
+	for size := MinimumPrimeSize; size <= 2*DefaultPrimeSize; size++ {
+		if err := NewLayout(size).Validate(); err != nil {
+			t.Errorf("Layout for prime size %d is invalid: %+v", size, err)
+		}
+	}
+}
+
+// Tests that the default Layout tiles a 512-byte master buffer.
+func TestNewLayout_Default(t *testing.T) {
+	l := NewLayout(DefaultPrimeSize)
+	if l.TotalLen != 512 {
+		t.Errorf("Unexpected total length.\nexpected: %d\nreceived: %d",
+			512, l.TotalLen)
+	}
+
+	if l.SIH.End() != l.TotalLen {
+		t.Errorf("SIH does not end at the end of the buffer: %d", l.SIH.End())
+	}
+}
+
+// Error path: Tests that Layout.Validate catches gaps, overlaps, regions
+// crossing the payload boundary, and an incorrect total length.
+func TestLayout_Validate_Error(t *testing.T) {
+	tests := []struct {
+		modify func(l *Layout)
+		err    string
+	}{
+		{func(l *Layout) { l.Version.Offset++ }, "gap"},
+		{func(l *Layout) { l.Contents1.Offset-- }, "overlaps"},
+		{func(l *Layout) { l.Contents1.Length-- }, "gap"},
+		{func(l *Layout) { l.SIH.Length++ }, "total length"},
+		{func(l *Layout) { l.Contents1.Length = -1 }, "negative"},
+		{func(l *Layout) {
+			l.Contents1.Length++
+			l.Mac.Offset++
+			l.Mac.Length--
+		}, "crosses"},
+	}
+
+	for i, tt := range tests {
+		l := NewLayout(DefaultPrimeSize)
+		tt.modify(&l)
+		err := l.Validate()
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Unexpected error (%d).\nexpected: %s\nreceived: %+v",
+				i, tt.err, err)
+		}
+	}
+}
+
+// Tests that the Regions of Message.Layout point to the same bytes as the
+// Message fields.
+func TestMessage_Layout(t *testing.T) {
+	msg := NewMessage(DefaultPrimeSize)
+	msg.SetKeyFP(NewFingerprint(makeAndFillSlice(KeyFPLen, 'c')))
+	msg.SetMac(makeAndFillSlice(MacLen, 'd'))
+	msg.SetEphemeralRID(makeAndFillSlice(EphemeralRIDLen, 'e'))
+	msg.SetSIH(makeAndFillSlice(SIHLen, 'f'))
+
+	l := msg.Layout()
+	data := msg.Marshal()
+	fields := []struct {
+		r        Region
+		expected []byte
+	}{
+		{l.KeyFP, msg.keyFP},
+		{l.Version, msg.version},
+		{l.Contents1, msg.contents1},
+		{l.Mac, msg.mac},
+		{l.Contents2, msg.contents2},
+		{l.EphemeralRID, msg.ephemeralRID},
+		{l.SIH, msg.sih},
+		{l.PayloadA(), msg.payloadA},
+		{l.PayloadB(), msg.payloadB},
+		{l.RawContents(), msg.rawContents},
+	}
+
+	for _, f := range fields {
+		if !bytes.Equal(f.r.Slice(data), f.expected) {
+			t.Errorf("Region %s does not match message field."+
+				"\nexpected: %v\nreceived: %v",
+				f.r.Name, f.expected, f.r.Slice(data))
+		}
+	}
+}
@@ -0,0 +1,102 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import (
+	"github.com/pkg/errors"
+)
+
+const (
+	// LegacyPrimeSize is the size, in bytes, of the 2048-bit prime used by the
+	// older network. Legacy messages have a 256-byte master buffer.
+	LegacyPrimeSize = 128
+
+	// LegacyTotalLen is the length of the master buffer of a legacy message.
+	LegacyTotalLen = 2 * LegacyPrimeSize
+
+	// LegacyVersion is the version byte that marks a message as using the
+	// legacy layout. It is at the top of the version range so that it does not
+	// collide with future versions of the current layout.
+	LegacyVersion uint8 = 0xFF
+)
+
+// NewLegacyLayout returns the Layout of a legacy 2048-bit message. It has the
+// same fields as the current layout, but the contents are shorter.
+func NewLegacyLayout() Layout {
+	return NewLayout(LegacyPrimeSize)
+}
+
+// UpgradeLegacyMessage converts a legacy 2048-bit message into a message of
+// DefaultPrimeSize with the current version. The key fingerprint, MAC,
+// ephemeral recipient ID, SIH, and contents are copied over and the extra
+// contents are left as zeros. This only changes the framing, so that archived
+// traffic and test vectors can be handled by current code; it does not
+// re-encrypt the contents. Returns an error if the message is not a legacy
+// message.
+func UpgradeLegacyMessage(legacy Message) (Message, error) {
+	if legacy.GetPrimeByteLen() != LegacyPrimeSize {
+		return Message{}, errors.Errorf("legacy message must have a prime "+
+			"size of %d bytes; received %d bytes",
+			LegacyPrimeSize, legacy.GetPrimeByteLen())
+	} else if legacy.GetVersion() != LegacyVersion {
+		return Message{}, errors.Errorf("legacy message must have version "+
+			"%d; received version %d", LegacyVersion, legacy.GetVersion())
+	}
+
+	m := NewMessage(DefaultPrimeSize)
+	copyMessageFields(m, legacy)
+	m.SetContents(legacy.GetContents())
+	m.SetVersion(CurrentVersion)
+
+	return m, nil
+}
+
+// DowngradeMessage converts a message into a legacy 2048-bit message with the
+// LegacyVersion. It is the inverse of UpgradeLegacyMessage. Returns an error if
+// the contents of the message do not fit in a legacy message, which is the case
+// if any byte after the legacy contents size is not zero.
+func DowngradeMessage(m Message) (Message, error) {
+	legacy := NewMessage(LegacyPrimeSize)
+
+	contents := m.GetContents()
+	if len(contents) > legacy.ContentsSize() {
+		for i, b := range contents[legacy.ContentsSize():] {
+			if b != 0 {
+				return Message{}, errors.Errorf("contents do not fit in a "+
+					"legacy message of %d bytes: byte %d is not zero",
+					legacy.ContentsSize(), legacy.ContentsSize()+i)
+			}
+		}
+		contents = contents[:legacy.ContentsSize()]
+	}
+
+	copyMessageFields(legacy, m)
+	legacy.SetContents(contents)
+	legacy.SetVersion(LegacyVersion)
+
+	return legacy, nil
+}
+
+// copyMessageFields copies the fixed-size fields that every layout shares from
+// src to dst.
+func copyMessageFields(dst, src Message) {
+	copy(dst.keyFP, src.keyFP)
+	copy(dst.mac, src.mac)
+	copy(dst.ephemeralRID, src.ephemeralRID)
+	copy(dst.sih, src.sih)
+}
+
+// parseMessageLegacy parses a message in the legacy 2048-bit layout.
+func parseMessageLegacy(data []byte) (Message, error) {
+	if len(data) != LegacyTotalLen {
+		return Message{}, errors.Errorf("legacy message data must be %d "+
+			"bytes; received %d bytes", LegacyTotalLen, len(data))
+	}
+
+	return Unmarshal(data)
+}
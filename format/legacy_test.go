@@ -0,0 +1,124 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// newRandomLegacyMessage returns a legacy message with random fields.
+func newRandomLegacyMessage(prng *rand.Rand) Message {
+	m := NewMessage(LegacyPrimeSize)
+	prng.Read(m.data)
+	m.SetGroupBits(false, false)
+	m.SetVersion(LegacyVersion)
+	return m
+}
+
+// Tests that the legacy layout is valid and has a master buffer of
+// LegacyTotalLen.
+func TestNewLegacyLayout(t *testing.T) {
+	l := NewLegacyLayout()
+	if l.TotalLen != LegacyTotalLen {
+		t.Errorf("Unexpected total length.\nexpected: %d\nreceived: %d",
+			LegacyTotalLen, l.TotalLen)
+	}
+	if err := l.Validate(); err != nil {
+		t.Errorf("Invalid legacy layout: %+v", err)
+	}
+}
+
+// Tests that a legacy message upgraded with UpgradeLegacyMessage keeps all its
+// fields and that DowngradeMessage converts it back to the original.
+func TestUpgradeLegacyMessage_DowngradeMessage(t *testing.T) {
+	legacy := newRandomLegacyMessage(rand.New(rand.NewSource(42)))
+
+	m, err := UpgradeLegacyMessage(legacy)
+	if err != nil {
+		t.Fatalf("Failed to upgrade message: %+v", err)
+	}
+
+	if m.GetPrimeByteLen() != DefaultPrimeSize {
+		t.Errorf("Unexpected prime size.\nexpected: %d\nreceived: %d",
+			DefaultPrimeSize, m.GetPrimeByteLen())
+	}
+	if m.GetVersion() != CurrentVersion {
+		t.Errorf("Unexpected version.\nexpected: %d\nreceived: %d",
+			CurrentVersion, m.GetVersion())
+	}
+	if m.GetKeyFP() != legacy.GetKeyFP() ||
+		!bytes.Equal(m.GetMac(), legacy.GetMac()) ||
+		!bytes.Equal(m.GetEphemeralRID(), legacy.GetEphemeralRID()) ||
+		!bytes.Equal(m.GetSIH(), legacy.GetSIH()) {
+		t.Error("Upgraded message fields do not match the legacy message.")
+	}
+	contents := m.GetContents()
+	if !bytes.Equal(contents[:legacy.ContentsSize()], legacy.GetContents()) {
+		t.Errorf("Unexpected contents.\nexpected: %v\nreceived: %v",
+			legacy.GetContents(), contents[:legacy.ContentsSize()])
+	}
+
+	downgraded, err := DowngradeMessage(m)
+	if err != nil {
+		t.Fatalf("Failed to downgrade message: %+v", err)
+	}
+	if !bytes.Equal(downgraded.Marshal(), legacy.Marshal()) {
+		t.Errorf("Downgraded message does not match original."+
+			"\nexpected: %v\nreceived: %v", legacy.Marshal(),
+			downgraded.Marshal())
+	}
+}
+
+// Tests that ParseMessage dispatches a legacy message by its version byte.
+func TestParseMessage_Legacy(t *testing.T) {
+	legacy := newRandomLegacyMessage(rand.New(rand.NewSource(42)))
+
+	parsed, err := ParseMessage(legacy.Marshal())
+	if err != nil {
+		t.Fatalf("Failed to parse legacy message: %+v", err)
+	}
+	if !bytes.Equal(parsed.Marshal(), legacy.Marshal()) {
+		t.Errorf("Parsed message does not match original."+
+			"\nexpected: %v\nreceived: %v", legacy.Marshal(), parsed.Marshal())
+	}
+
+	m := NewMessage(DefaultPrimeSize)
+	m.SetVersion(LegacyVersion)
+	if _, err = ParseMessage(m.Marshal()); err == nil {
+		t.Error("Expected error for legacy version with wrong length.")
+	}
+}
+
+// Error path: Tests that UpgradeLegacyMessage returns an error for messages of
+// the wrong size or version.
+func TestUpgradeLegacyMessage_Error(t *testing.T) {
+	if _, err := UpgradeLegacyMessage(NewMessage(DefaultPrimeSize)); err == nil {
+		t.Error("Expected error for message of the wrong size.")
+	}
+
+	legacy := NewMessage(LegacyPrimeSize)
+	legacy.SetVersion(CurrentVersion)
+	if _, err := UpgradeLegacyMessage(legacy); err == nil {
+		t.Error("Expected error for message of the wrong version.")
+	}
+}
+
+// Error path: Tests that DowngradeMessage returns an error when the contents do
+// not fit in a legacy message.
+func TestDowngradeMessage_ContentsTooLong(t *testing.T) {
+	m := NewMessage(DefaultPrimeSize)
+	contents := make([]byte, m.ContentsSize())
+	contents[NewMessage(LegacyPrimeSize).ContentsSize()] = 1
+	m.SetContents(contents)
+
+	if _, err := DowngradeMessage(m); err == nil {
+		t.Error("Expected error for contents that do not fit.")
+	}
+}
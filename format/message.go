@@ -60,6 +60,18 @@ PayloadA and PayloadB are within the group
 
 // Message structure stores all the data serially. Subsequent fields point to
 // subsections of the serialised data.
+//
+// Every method that returns a byte slice has a directive in its doc comment
+// stating whether the slice shares memory with the Message:
+//
+//   - //format:copy means the slice is newly allocated and may be modified by
+//     the caller without affecting the Message.
+//   - //format:alias means the slice points into the Message; modifying it
+//     modifies the Message, and later changes to the Message are visible in it.
+//
+// TestMessage_AliasingDirectives fails if an exported method returning a byte
+// slice is missing a directive. Note that assigning a Message copies only the
+// slice headers, so both values share the same data; use Message.Copy instead.
 type Message struct {
 	data []byte
 
@@ -88,23 +100,24 @@ func NewMessage(numPrimeBytes int) Message {
 	}
 
 	data := make([]byte, 2*numPrimeBytes)
+	l := NewLayout(numPrimeBytes)
 
 	return Message{
 		data: data,
 
-		payloadA: data[:numPrimeBytes],
-		payloadB: data[numPrimeBytes:],
+		payloadA: l.PayloadA().Slice(data),
+		payloadB: l.PayloadB().Slice(data),
 
-		keyFP:     data[:KeyFPLen],
-		version:   data[KeyFPLen : KeyFPLen+1],
-		contents1: data[1+KeyFPLen : numPrimeBytes],
+		keyFP:     l.KeyFP.Slice(data),
+		version:   l.Version.Slice(data),
+		contents1: l.Contents1.Slice(data),
 
-		mac:          data[numPrimeBytes : numPrimeBytes+MacLen],
-		contents2:    data[numPrimeBytes+MacLen : 2*numPrimeBytes-RecipientIDLen],
-		ephemeralRID: data[2*numPrimeBytes-RecipientIDLen : 2*numPrimeBytes-SIHLen],
-		sih:          data[2*numPrimeBytes-SIHLen:],
+		mac:          l.Mac.Slice(data),
+		contents2:    l.Contents2.Slice(data),
+		ephemeralRID: l.EphemeralRID.Slice(data),
+		sih:          l.SIH.Slice(data),
 
-		rawContents: data[:2*numPrimeBytes-RecipientIDLen],
+		rawContents: l.RawContents().Slice(data),
 	}
 }
 
@@ -112,6 +125,8 @@ func NewMessage(numPrimeBytes int) Message {
 // sending over the wire or other socket connection. Do not use this
 // if you ever want to compare a marshalled message with itself, because
 // both the Ephemeral ID and SIH are modified on each send attempt.
+//
+//format:copy
 func (m *Message) Marshal() []byte {
 	return copyByteSlice(m.data)
 }
@@ -120,6 +135,8 @@ func (m *Message) Marshal() []byte {
 // Ephemeral ID and the SIH both change every time a message is
 // sent. This function 0's those fields to guarantee that the same
 // message will be byte identical with itself when Marshalled.
+//
+//format:copy
 func (m *Message) MarshalImmutable() []byte {
 	newM := m.Copy()
 	newM.SetEphemeralRID(make([]byte, EphemeralRIDLen))
@@ -159,10 +176,20 @@ func (m Message) GetPrimeByteLen() int {
 }
 
 // GetPayloadA returns payload A, which is the first half of the message.
+//
+//format:copy
 func (m Message) GetPayloadA() []byte {
 	return copyByteSlice(m.payloadA)
 }
 
+// CopyPayloadA is an alias for GetPayloadA, which already returns a copy. It
+// exists so that call sites that modify the result can make the copy explicit.
+//
+//format:copy
+func (m Message) CopyPayloadA() []byte {
+	return m.GetPayloadA()
+}
+
 // SetPayloadA copies the passed byte slice into payload A. If the specified
 // byte slice is not exactly the same size as payload A, then it panics.
 func (m Message) SetPayloadA(payload []byte) {
@@ -175,10 +202,20 @@ func (m Message) SetPayloadA(payload []byte) {
 }
 
 // GetPayloadB returns payload B, which is the last half of the message.
+//
+//format:copy
 func (m Message) GetPayloadB() []byte {
 	return copyByteSlice(m.payloadB)
 }
 
+// CopyPayloadB is an alias for GetPayloadB, which already returns a copy. It
+// exists so that call sites that modify the result can make the copy explicit.
+//
+//format:copy
+func (m Message) CopyPayloadB() []byte {
+	return m.GetPayloadB()
+}
+
 // SetPayloadB copies the passed byte slice into payload B. If the specified
 // byte slice is not exactly the same size as payload B, then it panics.
 func (m Message) SetPayloadB(payload []byte) {
@@ -197,6 +234,8 @@ func (m Message) ContentsSize() int {
 
 // GetContents returns the exact contents of the message. This size of the
 // return is based on the size of the contents actually stored.
+//
+//format:copy
 func (m Message) GetContents() []byte {
 	c := make([]byte, len(m.contents1)+len(m.contents2))
 
@@ -206,6 +245,14 @@ func (m Message) GetContents() []byte {
 	return c
 }
 
+// CopyContents is an alias for GetContents, which already returns a copy. It
+// exists so that call sites that modify the result can make the copy explicit.
+//
+//format:copy
+func (m Message) CopyContents() []byte {
+	return m.GetContents()
+}
+
 // SetContents sets the contents of the message. This overwrites any storage
 // already in the message but will not clear bits beyond the size of the passed
 // contents. Panics if the passed contents is larger than the maximum contents
@@ -234,6 +281,8 @@ func (m Message) GetRawContentsSize() int {
 // over the group barrier and the setter of this is responsible for ensuring the
 // underlying payloads are within the group.
 // flips the first bit to 0 on return
+//
+//format:copy
 func (m Message) GetRawContents() []byte {
 	newRaw := copyByteSlice(m.rawContents)
 	clearFirstBit(newRaw)
@@ -277,6 +326,8 @@ func (m Message) SetKeyFP(fp Fingerprint) {
 
 // GetMac gets the MAC.
 // flips the first bit to 0 on return
+//
+//format:copy
 func (m Message) GetMac() []byte {
 	newMac := copyByteSlice(m.mac)
 	clearFirstBit(newMac)
@@ -300,6 +351,8 @@ func (m Message) SetMac(mac []byte) {
 }
 
 // GetEphemeralRID returns the ephemeral recipient ID.
+//
+//format:copy
 func (m Message) GetEphemeralRID() []byte {
 	return copyByteSlice(m.ephemeralRID)
 }
@@ -315,6 +368,8 @@ func (m Message) SetEphemeralRID(ephemeralRID []byte) {
 }
 
 // GetSIH return the Service Identification Hash.
+//
+//format:copy
 func (m Message) GetSIH() []byte {
 	return copyByteSlice(m.sih)
 }
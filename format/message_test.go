@@ -10,8 +10,13 @@ package format
 import (
 	"bytes"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
 	"math/rand"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -242,6 +247,40 @@ func TestMessage_GetPayloadA(t *testing.T) {
 	}
 }
 
+// Tests that Message.CopyPayloadA, Message.CopyPayloadB, and
+// Message.CopyContents return the same data as their Get counterparts and that
+// modifying the returned slices does not modify the message.
+func TestMessage_CopyPayloadA_CopyPayloadB_CopyContents(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	msg := NewMessage(MinimumPrimeSize)
+	prng.Read(msg.data)
+	expected := msg.Marshal()
+
+	tests := []struct {
+		name      string
+		get, copy func() []byte
+	}{
+		{"PayloadA", msg.GetPayloadA, msg.CopyPayloadA},
+		{"PayloadB", msg.GetPayloadB, msg.CopyPayloadB},
+		{"Contents", msg.GetContents, msg.CopyContents},
+	}
+
+	for _, tt := range tests {
+		c := tt.copy()
+		if !bytes.Equal(tt.get(), c) {
+			t.Errorf("Copy%s does not match Get%s.", tt.name, tt.name)
+		}
+
+		for i := range c {
+			c[i] ^= 0xFF
+		}
+		if !bytes.Equal(expected, msg.Marshal()) {
+			t.Errorf("Modifying the result of Copy%s modified the message.",
+				tt.name)
+		}
+	}
+}
+
 // Happy path.
 func TestMessage_SetPayloadA(t *testing.T) {
 	msg := NewMessage(MinimumPrimeSize)
@@ -887,3 +926,98 @@ func makeAndFillSlice(size int, r rune) []byte {
 	buff = bytes.Map(func(r2 rune) rune { return r }, buff)
 	return buff
 }
+
+// Tests that every exported method of Message that returns a byte slice has
+// either a //format:copy or //format:alias directive, and that modifying the
+// result of a //format:copy method does not modify the message.
+func TestMessage_AliasingDirectives(t *testing.T) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse package: %+v", err)
+	}
+
+	prng := rand.New(rand.NewSource(42))
+	msg := NewMessage(MinimumPrimeSize)
+	prng.Read(msg.data)
+	msg.SetData([]byte("data"))
+
+	var checked int
+	for _, f := range pkgs["format"].Files {
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || !isMessageMethod(fn) || !fn.Name.IsExported() ||
+				!returnsByteSlice(fn) {
+				continue
+			}
+
+			var directive string
+			if fn.Doc != nil {
+				for _, c := range fn.Doc.List {
+					if c.Text == "//format:copy" || c.Text == "//format:alias" {
+						directive = c.Text
+					}
+				}
+			}
+
+			switch directive {
+			case "":
+				t.Errorf("Message.%s returns a byte slice but has no "+
+					"//format:copy or //format:alias directive.", fn.Name)
+			case "//format:copy":
+				expected := msg.Marshal()
+				results := reflect.ValueOf(&msg).MethodByName(fn.Name.Name).
+					Call(nil)
+				b := results[0].Bytes()
+				for i := range b {
+					b[i] ^= 0xFF
+				}
+				if !bytes.Equal(expected, msg.Marshal()) {
+					t.Errorf("Message.%s is marked //format:copy but its "+
+						"result aliases the message.", fn.Name)
+				}
+			}
+			checked++
+		}
+	}
+
+	if checked == 0 {
+		t.Error("No methods returning byte slices found.")
+	}
+}
+
+// isMessageMethod determines if the function is a method on Message or
+// *Message.
+func isMessageMethod(fn *ast.FuncDecl) bool {
+	if fn.Recv == nil || len(fn.Recv.List) != 1 {
+		return false
+	}
+
+	typ := fn.Recv.List[0].Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	ident, ok := typ.(*ast.Ident)
+	return ok && ident.Name == "Message"
+}
+
+// returnsByteSlice determines if any result of the function is a []byte.
+func returnsByteSlice(fn *ast.FuncDecl) bool {
+	if fn.Type.Results == nil {
+		return false
+	}
+
+	for _, field := range fn.Type.Results.List {
+		arr, ok := field.Type.(*ast.ArrayType)
+		if !ok || arr.Len != nil {
+			continue
+		}
+		if ident, ok := arr.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return true
+		}
+	}
+
+	return false
+}
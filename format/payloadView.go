@@ -0,0 +1,89 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// PayloadView provides bounds-checked access to one payload of a Message. It
+// implements io.ReaderAt and io.WriterAt with offsets relative to the start of
+// the payload, so that callers, such as crypto layers, can read and write
+// sub-ranges of a payload without slicing the Message's master buffer
+// themselves and risking reads or writes that spill into the other payload.
+//
+// A PayloadView aliases the Message: writes are visible in the Message and
+// changes to the Message are visible through the view. Use io.NewSectionReader
+// to restrict reads to a smaller range.
+type PayloadView struct {
+	region Region
+	data   []byte
+}
+
+// PayloadAView returns a PayloadView of payload A, which is the first half of
+// the message.
+func (m Message) PayloadAView() PayloadView {
+	return PayloadView{m.Layout().PayloadA(), m.payloadA}
+}
+
+// PayloadBView returns a PayloadView of payload B, which is the last half of
+// the message.
+func (m Message) PayloadBView() PayloadView {
+	return PayloadView{m.Layout().PayloadB(), m.payloadB}
+}
+
+// Size returns the length of the payload in bytes.
+func (pv PayloadView) Size() int64 {
+	return int64(len(pv.data))
+}
+
+// Region returns the Region of the Message's master buffer that the payload
+// covers.
+func (pv PayloadView) Region() Region {
+	return pv.region
+}
+
+// ReadAt copies len(p) bytes of the payload, starting at offset off, into p.
+// If fewer than len(p) bytes remain in the payload, it copies the remaining
+// bytes and returns io.EOF. Returns an error if the offset is negative. This
+// function adheres to the io.ReaderAt interface.
+func (pv PayloadView) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.Errorf("cannot read %s at negative offset %d",
+			pv.region.Name, off)
+	} else if off >= pv.Size() {
+		return 0, io.EOF
+	}
+
+	n := copy(p, pv.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// WriteAt copies p into the payload starting at offset off. The write must fit
+// entirely within the payload; otherwise, nothing is written and an error is
+// returned. This function adheres to the io.WriterAt interface.
+func (pv PayloadView) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 || off > pv.Size() || int64(len(p)) > pv.Size()-off {
+		return 0, errors.Errorf("cannot write %d bytes to %s at offset %d: "+
+			"range [%d, %d) is outside of payload of %d bytes", len(p),
+			pv.region.Name, off, off, off+int64(len(p)), pv.Size())
+	}
+
+	return copy(pv.data[off:], p), nil
+}
+
+// Ensure PayloadView adheres to the io.ReaderAt and io.WriterAt interfaces.
+var (
+	_ io.ReaderAt = PayloadView{}
+	_ io.WriterAt = PayloadView{}
+)
@@ -0,0 +1,159 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+// Tests that Message.PayloadAView and Message.PayloadBView cover exactly the
+// bytes of their payloads.
+func TestMessage_PayloadAView_PayloadBView(t *testing.T) {
+	m := newTestWipeMessage(rand.New(rand.NewSource(42)))
+
+	for i, tt := range []struct {
+		view     PayloadView
+		expected []byte
+	}{
+		{m.PayloadAView(), m.GetPayloadA()},
+		{m.PayloadBView(), m.GetPayloadB()},
+	} {
+		if tt.view.Size() != int64(len(tt.expected)) {
+			t.Errorf("Unexpected size (%d).\nexpected: %d\nreceived: %d",
+				i, len(tt.expected), tt.view.Size())
+		}
+
+		received, err := io.ReadAll(io.NewSectionReader(
+			tt.view, 0, tt.view.Size()+1))
+		if err != nil {
+			t.Errorf("Failed to read view (%d): %+v", i, err)
+		}
+		if !bytes.Equal(tt.expected, received) {
+			t.Errorf("Unexpected payload (%d).\nexpected: %v\nreceived: %v",
+				i, tt.expected, received)
+		}
+
+		r := tt.view.Region()
+		if !bytes.Equal(tt.expected, r.Slice(m.data)) {
+			t.Errorf("Region %+v does not cover the payload (%d).", r, i)
+		}
+	}
+}
+
+// Tests that PayloadView.ReadAt reads sub-ranges of the payload and returns
+// io.EOF when the range runs past the end of the payload.
+func TestPayloadView_ReadAt(t *testing.T) {
+	m := newTestWipeMessage(rand.New(rand.NewSource(42)))
+	pv := m.PayloadBView()
+	payload := m.GetPayloadB()
+	size := int(pv.Size())
+
+	tests := []struct {
+		off, n, read int
+		err          error
+	}{
+		{0, size, size, nil},
+		{0, 10, 10, nil},
+		{MacLen, 5, 5, nil},
+		{size - 5, 5, 5, nil},
+		{size - 5, 10, 5, io.EOF},
+		{size, 1, 0, io.EOF},
+		{size + 100, 1, 0, io.EOF},
+	}
+
+	for i, tt := range tests {
+		p := make([]byte, tt.n)
+		n, err := pv.ReadAt(p, int64(tt.off))
+		if err != tt.err {
+			t.Errorf("Unexpected error (%d).\nexpected: %v\nreceived: %+v",
+				i, tt.err, err)
+		}
+		if n != tt.read {
+			t.Errorf("Unexpected number of bytes read (%d)."+
+				"\nexpected: %d\nreceived: %d", i, tt.read, n)
+		}
+		if n > 0 && !bytes.Equal(payload[tt.off:tt.off+n], p[:n]) {
+			t.Errorf("Unexpected bytes read (%d).\nexpected: %v"+
+				"\nreceived: %v", i, payload[tt.off:tt.off+n], p[:n])
+		}
+	}
+}
+
+// Tests that PayloadView.WriteAt writes into the Message without modifying
+// the other payload.
+func TestPayloadView_WriteAt(t *testing.T) {
+	m := NewMessage(MinimumPrimeSize)
+	pvA, pvB := m.PayloadAView(), m.PayloadBView()
+
+	ones := bytes.Repeat([]byte{0xFF}, int(pvA.Size()))
+	for i, tt := range []struct {
+		pv    PayloadView
+		get   func() []byte
+		other func() []byte
+	}{
+		{pvA, m.GetPayloadA, m.GetPayloadB},
+		{pvB, m.GetPayloadB, m.GetPayloadA},
+	} {
+		m.Wipe()
+		n, err := tt.pv.WriteAt(ones, 0)
+		if err != nil || n != len(ones) {
+			t.Errorf("Failed to write full payload (%d): %d, %+v", i, n, err)
+		}
+		if !bytes.Equal(ones, tt.get()) {
+			t.Errorf("Payload not written (%d): %v", i, tt.get())
+		}
+		if !isZero(tt.other()) {
+			t.Errorf("Write modified the other payload (%d): %v",
+				i, tt.other())
+		}
+	}
+
+	m.Wipe()
+	n, err := pvB.WriteAt([]byte{1, 2, 3}, pvB.Size()-3)
+	if err != nil || n != 3 {
+		t.Errorf("Failed to write end of payload: %d, %+v", n, err)
+	}
+	if !bytes.Equal([]byte{1, 2, 3}, m.GetSIH()[SIHLen-3:]) {
+		t.Errorf("Unexpected SIH after write: %v", m.GetSIH())
+	}
+}
+
+// Error path: Tests that PayloadView.WriteAt and PayloadView.ReadAt reject
+// ranges outside the payload and that WriteAt writes nothing when it fails.
+func TestPayloadView_OutOfBounds(t *testing.T) {
+	m := NewMessage(MinimumPrimeSize)
+	pvA := m.PayloadAView()
+	size := pvA.Size()
+
+	for i, tt := range []struct {
+		n   int
+		off int64
+	}{
+		{1, -1},
+		{2, size - 1},
+		{1, size},
+		{1, size + 1},
+		{int(size) + 1, 0},
+	} {
+		n, err := pvA.WriteAt(bytes.Repeat([]byte{0xFF}, tt.n), tt.off)
+		if err == nil || n != 0 {
+			t.Errorf("Expected error writing %d bytes at offset %d (%d): "+
+				"%d, %v", tt.n, tt.off, i, n, err)
+		}
+		if !isZero(m.data) {
+			t.Errorf("Failed write modified the Message (%d).", i)
+		}
+	}
+
+	if _, err := pvA.ReadAt(make([]byte, 1), -1); err == nil || err == io.EOF {
+		t.Errorf("Expected error reading at negative offset: %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// ErrTruncatedMessage is returned by ParsePayloads when the wire bytes are
+// shorter than a message of DefaultPrimeSize. Use errors.As to retrieve it and
+// distinguish truncation, which usually means a short read, from corruption,
+// which is reported as an *InvariantError.
+type ErrTruncatedMessage struct {
+	// Region is the part of the message that is truncated: "message" for a
+	// full frame, or "payloadA" or "payloadB" for a half.
+	Region string
+
+	// Got is the number of bytes received.
+	Got int
+
+	// Want is the number of bytes expected.
+	Want int
+}
+
+// Error returns the ErrTruncatedMessage as a string. This function adheres to
+// the error interface.
+func (e ErrTruncatedMessage) Error() string {
+	return e.Region + " is truncated: got " + strconv.Itoa(e.Got) +
+		" bytes, want " + strconv.Itoa(e.Want)
+}
+
+// NewMessageFromPayloads builds a Message from the two payloads received over
+// the wire. Unlike NewMessage followed by Message.SetPayloadA and
+// Message.SetPayloadB, it returns an error instead of panicking. Returns an
+// error if the payloads differ in length or are shorter than MinimumPrimeSize,
+// or a wrapped *InvariantError if the group byte of either payload leaves it
+// outside the group, as it does when the payload is all zeros. The payloads are
+// copied.
+//
+// The group bit itself may be set, since senders set it deliberately with
+// Message.SetGroupBits. Use Message.VerifyGroupMembership before sending, and
+// before setting the group bits, to also check that the group bits are zero.
+func NewMessageFromPayloads(payloadA, payloadB []byte) (*Message, error) {
+	if len(payloadA) != len(payloadB) {
+		return nil, errors.Errorf("payload A length %d does not match "+
+			"payload B length %d", len(payloadA), len(payloadB))
+	} else if len(payloadA) < MinimumPrimeSize {
+		return nil, errors.Errorf("payload length %d is smaller than the "+
+			"minimum %d", len(payloadA), MinimumPrimeSize)
+	}
+
+	m := NewMessage(len(payloadA))
+	copy(m.payloadA, payloadA)
+	copy(m.payloadB, payloadB)
+
+	if err := m.verifyGroupMembership(true); err != nil {
+		return nil, errors.Wrap(err, "invalid message payloads")
+	}
+
+	return &m, nil
+}
+
+// ParsePayloads builds a Message of DefaultPrimeSize from bytes received over
+// the wire. It accepts either a single full frame of 2*DefaultPrimeSize bytes
+// or the two payloads separately, each of DefaultPrimeSize bytes.
+//
+// Returns an ErrTruncatedMessage if the frame or either payload is too short,
+// a wrapped *InvariantError if the group byte check of NewMessageFromPayloads
+// fails, or another error if the input is too long or there are not one or two
+// inputs. Like NewMessageFromPayloads, the group bits may be set. The input is
+// copied.
+func ParsePayloads(data ...[]byte) (*Message, error) {
+	const frameLen = 2 * DefaultPrimeSize
+	var payloadA, payloadB []byte
+	switch len(data) {
+	case 1:
+		if err := checkWireLen("message", data[0], frameLen); err != nil {
+			return nil, err
+		}
+		payloadA = data[0][:DefaultPrimeSize]
+		payloadB = data[0][DefaultPrimeSize:]
+	case 2:
+		payloadA, payloadB = data[0], data[1]
+		err := checkWireLen("payloadA", payloadA, DefaultPrimeSize)
+		if err != nil {
+			return nil, err
+		}
+		err = checkWireLen("payloadB", payloadB, DefaultPrimeSize)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Errorf("expected a full message or two payloads; "+
+			"received %d inputs", len(data))
+	}
+
+	return NewMessageFromPayloads(payloadA, payloadB)
+}
+
+// checkWireLen returns an ErrTruncatedMessage if the data is shorter than want
+// or an error if it is longer.
+func checkWireLen(region string, data []byte, want int) error {
+	if len(data) < want {
+		return ErrTruncatedMessage{Region: region, Got: len(data), Want: want}
+	} else if len(data) > want {
+		return errors.Errorf("%s is too long: got %d bytes, want %d",
+			region, len(data), want)
+	}
+	return nil
+}
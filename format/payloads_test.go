@@ -0,0 +1,203 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+// Tests that NewMessageFromPayloads produces a Message with the given payloads
+// that does not share memory with them.
+func TestNewMessageFromPayloads(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	payloadA := make([]byte, DefaultPrimeSize)
+	payloadB := make([]byte, DefaultPrimeSize)
+	prng.Read(payloadA)
+	prng.Read(payloadB)
+	payloadA[0] &= 0x7F
+	payloadB[0] &= 0x7F
+
+	m, err := NewMessageFromPayloads(payloadA, payloadB)
+	if err != nil {
+		t.Fatalf("Failed to create message: %+v", err)
+	}
+
+	if !bytes.Equal(m.GetPayloadA(), payloadA) ||
+		!bytes.Equal(m.GetPayloadB(), payloadB) {
+		t.Error("Message payloads do not match.")
+	}
+
+	payloadA[1]++
+	if bytes.Equal(m.GetPayloadA(), payloadA) {
+		t.Error("Message shares memory with payload A.")
+	}
+}
+
+// Error path: Tests that NewMessageFromPayloads returns an error for invalid
+// payload lengths and an *InvariantError for payloads outside the group.
+func TestNewMessageFromPayloads_Error(t *testing.T) {
+	valid := make([]byte, DefaultPrimeSize)
+	valid[1] = 1
+
+	tests := []struct {
+		a, b      []byte
+		invariant bool
+	}{
+		{valid, valid[:DefaultPrimeSize-1], false},
+		{valid[:MinimumPrimeSize-1], valid[:MinimumPrimeSize-1], false},
+		{make([]byte, DefaultPrimeSize), valid, true},
+		{valid, make([]byte, DefaultPrimeSize), true},
+	}
+
+	for i, tt := range tests {
+		_, err := NewMessageFromPayloads(tt.a, tt.b)
+		var ie *InvariantError
+		if err == nil {
+			t.Errorf("No error for invalid payloads (%d).", i)
+		} else if errors.As(err, &ie) != tt.invariant {
+			t.Errorf("Unexpected error type (%d): %+v", i, err)
+		}
+	}
+}
+
+// Tests that a message whose group bits were set with Message.SetGroupBits
+// before sending is accepted by NewMessageFromPayloads and ParsePayloads with
+// its payloads intact.
+func TestNewMessageFromPayloads_GroupBits(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	m := NewMessage(DefaultPrimeSize)
+	payloadA := make([]byte, DefaultPrimeSize)
+	payloadB := make([]byte, DefaultPrimeSize)
+	prng.Read(payloadA)
+	prng.Read(payloadB)
+	m.SetPayloadA(payloadA)
+	m.SetPayloadB(payloadB)
+	m.SetGroupBits(true, true)
+	sent := m.Marshal()
+
+	received, err := NewMessageFromPayloads(m.GetPayloadA(), m.GetPayloadB())
+	if err != nil {
+		t.Fatalf("Failed to receive message with group bits set: %+v", err)
+	}
+	if !bytes.Equal(sent, received.Marshal()) {
+		t.Errorf("Received message does not match.\nexpected: %x"+
+			"\nreceived: %x", sent, received.Marshal())
+	}
+
+	parsed, err := ParsePayloads(sent)
+	if err != nil {
+		t.Fatalf("Failed to parse message with group bits set: %+v", err)
+	}
+	if !bytes.Equal(sent, parsed.Marshal()) {
+		t.Errorf("Parsed message does not match.\nexpected: %x"+
+			"\nreceived: %x", sent, parsed.Marshal())
+	}
+}
+
+// Tests that ParsePayloads produces the same Message from a full frame and
+// from its two halves.
+func TestParsePayloads(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	frame := make([]byte, 2*DefaultPrimeSize)
+	prng.Read(frame)
+	frame[0] &= 0x7F
+	frame[DefaultPrimeSize] &= 0x7F
+
+	m, err := ParsePayloads(frame)
+	if err != nil {
+		t.Fatalf("Failed to parse full frame: %+v", err)
+	}
+	if !bytes.Equal(m.Marshal(), frame) {
+		t.Error("Message parsed from full frame does not match.")
+	}
+
+	m, err = ParsePayloads(frame[:DefaultPrimeSize], frame[DefaultPrimeSize:])
+	if err != nil {
+		t.Fatalf("Failed to parse payloads: %+v", err)
+	}
+	if !bytes.Equal(m.Marshal(), frame) {
+		t.Error("Message parsed from payloads does not match.")
+	}
+
+	frame[1]++
+	if bytes.Equal(m.Marshal(), frame) {
+		t.Error("Message shares memory with the frame.")
+	}
+}
+
+// Error path: Tests that ParsePayloads returns an ErrTruncatedMessage with the
+// received and expected lengths for short input, a wrapped *InvariantError for
+// all-zero payloads, and other errors for long input and the wrong number of
+// inputs.
+func TestParsePayloads_Error(t *testing.T) {
+	frame := make([]byte, 2*DefaultPrimeSize)
+	frame[1], frame[DefaultPrimeSize+1] = 1, 1
+	a, b := frame[:DefaultPrimeSize], frame[DefaultPrimeSize:]
+
+	truncated := []struct {
+		data     [][]byte
+		expected ErrTruncatedMessage
+	}{
+		{[][]byte{frame[:300]}, ErrTruncatedMessage{"message", 300, 512}},
+		{[][]byte{nil}, ErrTruncatedMessage{"message", 0, 512}},
+		{[][]byte{a[:10], b}, ErrTruncatedMessage{"payloadA", 10, 256}},
+		{[][]byte{a, b[:255]}, ErrTruncatedMessage{"payloadB", 255, 256}},
+	}
+	for i, tt := range truncated {
+		_, err := ParsePayloads(tt.data...)
+		var truncErr ErrTruncatedMessage
+		if !errors.As(err, &truncErr) || truncErr != tt.expected {
+			t.Errorf("Unexpected error (%d).\nexpected: %v\nreceived: %+v",
+				i, tt.expected, err)
+		}
+	}
+
+	corrupt := []struct {
+		data   [][]byte
+		region string
+	}{
+		{[][]byte{make([]byte, 2*DefaultPrimeSize)}, "payloadA"},
+		{[][]byte{a, make([]byte, DefaultPrimeSize)}, "payloadB"},
+	}
+	for i, tt := range corrupt {
+		_, err := ParsePayloads(tt.data...)
+		var invErr *InvariantError
+		if !errors.As(err, &invErr) {
+			t.Errorf("Unexpected error for corrupt payload (%d): %+v", i, err)
+		} else if invErr.Region != tt.region {
+			t.Errorf("Unexpected region (%d).\nexpected: %s\nreceived: %s",
+				i, tt.region, invErr.Region)
+		}
+	}
+
+	for i, data := range [][][]byte{
+		{append(frame, 0)},
+		{a, append(b, 0)},
+		{},
+		{a, b, b},
+	} {
+		_, err := ParsePayloads(data...)
+		var truncErr ErrTruncatedMessage
+		if err == nil || errors.As(err, &truncErr) {
+			t.Errorf("Unexpected error (%d): %+v", i, err)
+		}
+	}
+}
+
+// Tests that ErrTruncatedMessage.Error returns the expected string.
+func TestErrTruncatedMessage_Error(t *testing.T) {
+	expected := "payloadA is truncated: got 10 bytes, want 256"
+	err := ErrTruncatedMessage{Region: "payloadA", Got: 10, Want: 256}
+	if err.Error() != expected {
+		t.Errorf("Unexpected error string.\nexpected: %s\nreceived: %s",
+			expected, err.Error())
+	}
+}
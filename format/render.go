@@ -0,0 +1,114 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import (
+	"encoding/base64"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// hexDigits are the lowercase hexadecimal digits.
+const hexDigits = "0123456789abcdef"
+
+// Base64 returns the serialised message, as returned by Message.Marshal, as a
+// standard base 64 string. It encodes the message directly, without copying
+// it first.
+func (m Message) Base64() string {
+	return base64.StdEncoding.EncodeToString(m.data)
+}
+
+// MessageFromBase64 decodes a message encoded with Message.Base64. The data is
+// decoded directly into the new message's buffer. Returns an error if the
+// string is not valid base 64 or does not decode to a message of at least
+// twice MinimumPrimeSize bytes.
+func MessageFromBase64(s string) (Message, error) {
+	length := len(s) / 4 * 3
+	if len(s)%4 != 0 {
+		return Message{}, errors.Errorf(
+			"base 64 message length %d is not a multiple of 4", len(s))
+	} else if strings.HasSuffix(s, "==") {
+		length -= 2
+	} else if strings.HasSuffix(s, "=") {
+		length--
+	}
+
+	if length%2 != 0 || length/2 < MinimumPrimeSize {
+		return Message{}, errors.Errorf("base 64 message decodes to %d "+
+			"bytes; expected an even length of at least %d",
+			length, 2*MinimumPrimeSize)
+	}
+
+	m := NewMessage(length / 2)
+	dec := base64.NewDecoder(base64.StdEncoding, strings.NewReader(s))
+	if _, err := io.ReadFull(dec, m.data); err != nil {
+		return Message{}, errors.Wrap(err, "failed to decode base 64 message")
+	} else if n, err := dec.Read(make([]byte, 1)); n != 0 || err != io.EOF {
+		return Message{}, errors.Errorf(
+			"failed to decode base 64 message: trailing data: %v", err)
+	}
+
+	return m, nil
+}
+
+// KeyFPHex returns the key fingerprint, as returned by Message.GetKeyFP, as a
+// lowercase hexadecimal string.
+func (m Message) KeyFPHex() string {
+	return hexString(m.keyFP, true)
+}
+
+// MacHex returns the MAC, as returned by Message.GetMac, as a lowercase
+// hexadecimal string.
+func (m Message) MacHex() string {
+	return hexString(m.mac, true)
+}
+
+// EphemeralRIDHex returns the ephemeral recipient ID as a lowercase
+// hexadecimal string.
+func (m Message) EphemeralRIDHex() string {
+	return hexString(m.ephemeralRID, false)
+}
+
+// SIHHex returns the Service Identification Hash as a lowercase hexadecimal
+// string.
+func (m Message) SIHHex() string {
+	return hexString(m.sih, false)
+}
+
+// ContentsHex returns the contents, as returned by Message.GetContents, as a
+// lowercase hexadecimal string.
+func (m Message) ContentsHex() string {
+	var sb strings.Builder
+	sb.Grow(2 * (len(m.contents1) + len(m.contents2)))
+	writeHex(&sb, m.contents1, false)
+	writeHex(&sb, m.contents2, false)
+	return sb.String()
+}
+
+// hexString returns the bytes as a lowercase hexadecimal string using a single
+// allocation. If clearFirst is true, the first bit of the first byte is
+// written as zero to match the getters of fields that hold the group bit.
+func hexString(b []byte, clearFirst bool) string {
+	var sb strings.Builder
+	sb.Grow(2 * len(b))
+	writeHex(&sb, b, clearFirst)
+	return sb.String()
+}
+
+// writeHex writes the bytes to the builder in hexadecimal. See hexString.
+func writeHex(sb *strings.Builder, b []byte, clearFirst bool) {
+	for i, c := range b {
+		if i == 0 && clearFirst {
+			c &= 0x7F
+		}
+		sb.WriteByte(hexDigits[c>>4])
+		sb.WriteByte(hexDigits[c&0x0F])
+	}
+}
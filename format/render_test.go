@@ -0,0 +1,112 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"math/rand"
+	"testing"
+)
+
+// newRenderTestMessage returns a message with random data, including set group
+// bits.
+func newRenderTestMessage(prng *rand.Rand) Message {
+	m := NewMessage(MinimumPrimeSize * 2)
+	prng.Read(m.data)
+	m.SetGroupBits(true, true)
+	return m
+}
+
+// Tests that a message encoded with Message.Base64 and decoded with
+// MessageFromBase64 matches the original and that the encoding matches the
+// standard encoding of Message.Marshal.
+func TestMessage_Base64_MessageFromBase64(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	for _, primeSize := range []int{MinimumPrimeSize, 255, DefaultPrimeSize} {
+		m := NewMessage(primeSize)
+		prng.Read(m.data)
+
+		encoded := m.Base64()
+		expected := base64.StdEncoding.EncodeToString(m.Marshal())
+		if expected != encoded {
+			t.Errorf("Unexpected base 64.\nexpected: %s\nreceived: %s",
+				expected, encoded)
+		}
+
+		decoded, err := MessageFromBase64(encoded)
+		if err != nil {
+			t.Fatalf("Failed to decode message of prime size %d: %+v",
+				primeSize, err)
+		}
+		if !bytes.Equal(m.Marshal(), decoded.Marshal()) {
+			t.Errorf("Decoded message of prime size %d does not match.",
+				primeSize)
+		}
+	}
+}
+
+// Error path: Tests that MessageFromBase64 returns an error for invalid base
+// 64 and for data that is not a valid message length.
+func TestMessageFromBase64_Error(t *testing.T) {
+	valid := newRenderTestMessage(rand.New(rand.NewSource(42))).Base64()
+	tests := map[string]string{
+		"empty":     "",
+		"length":    valid[:len(valid)-1],
+		"character": "!" + valid[1:],
+		"short":     base64.StdEncoding.EncodeToString(make([]byte, 10)),
+		"odd": base64.StdEncoding.EncodeToString(
+			make([]byte, 2*MinimumPrimeSize+1)),
+	}
+
+	for name, s := range tests {
+		if _, err := MessageFromBase64(s); err == nil {
+			t.Errorf("No error for %s.", name)
+		}
+	}
+}
+
+// Tests that the hex accessors match the hex encoding of the corresponding
+// getters.
+func TestMessage_HexAccessors(t *testing.T) {
+	m := newRenderTestMessage(rand.New(rand.NewSource(42)))
+	tests := map[string]struct {
+		field    []byte
+		received string
+	}{
+		"KeyFP":        {m.GetKeyFP().Bytes(), m.KeyFPHex()},
+		"Mac":          {m.GetMac(), m.MacHex()},
+		"EphemeralRID": {m.GetEphemeralRID(), m.EphemeralRIDHex()},
+		"SIH":          {m.GetSIH(), m.SIHHex()},
+		"Contents":     {m.GetContents(), m.ContentsHex()},
+	}
+
+	for name, tt := range tests {
+		if expected := hex.EncodeToString(tt.field); expected != tt.received {
+			t.Errorf("Unexpected %s hex.\nexpected: %s\nreceived: %s",
+				name, expected, tt.received)
+		}
+	}
+
+	if (Message{}).KeyFPHex() != "" {
+		t.Error("Hex of empty message is not empty.")
+	}
+}
+
+// Tests that the hex accessors allocate only the returned string.
+func TestMessage_HexAccessors_Allocs(t *testing.T) {
+	m := newRenderTestMessage(rand.New(rand.NewSource(42)))
+	for name, f := range map[string]func() string{
+		"KeyFP": m.KeyFPHex, "Contents": m.ContentsHex} {
+		if n := testing.AllocsPerRun(10, func() { f() }); n != 1 {
+			t.Errorf("Unexpected allocations for %s.\nexpected: %d"+
+				"\nreceived: %f", name, 1, n)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+// Package testutils contains helpers for tests that need format.Message
+// values. The messages are built with the format setters, so they always have
+// the correct layout, and pass format.Message.VerifyGroupMembership and
+// format.Message.VerifyPaddingZeroed.
+package testutils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	jww "github.com/spf13/jwalterweatherman"
+
+	"gitlab.com/elixxir/primitives/format"
+)
+
+// maxFuzzedPrimeSize is the largest prime size chosen by NewFuzzedMessage.
+const maxFuzzedPrimeSize = 2 * format.DefaultPrimeSize
+
+// NewRandomMessage returns a new format.Message of format.DefaultPrimeSize with
+// the key fingerprint, MAC, ephemeral recipient ID, SIH, and data set to values
+// read from rng. The length of the data is also random. Panics if reading
+// from rng fails.
+func NewRandomMessage(rng io.Reader) *format.Message {
+	return newRandomMessage(rng, format.DefaultPrimeSize)
+}
+
+// NewFuzzedMessage returns a new format.Message built from fuzzer input. The
+// first two bytes of data choose a prime size between format.MinimumPrimeSize
+// and twice format.DefaultPrimeSize and the remainder fill the fields as in
+// NewRandomMessage. Once data is exhausted, zeros are used, so any input,
+// including an empty one, produces a message.
+func NewFuzzedMessage(data []byte) *format.Message {
+	r := io.MultiReader(bytes.NewReader(data), zeroReader{})
+
+	b := make([]byte, 2)
+	readRandom(r, b)
+	primeSize := format.MinimumPrimeSize + int(binary.BigEndian.Uint16(b))%
+		(maxFuzzedPrimeSize-format.MinimumPrimeSize+1)
+
+	return newRandomMessage(r, primeSize)
+}
+
+// newRandomMessage returns a new format.Message of the given prime size with
+// its fields read from rng.
+func newRandomMessage(rng io.Reader, primeSize int) *format.Message {
+	msg := format.NewMessage(primeSize)
+
+	// The first bits of the key fingerprint and MAC are cleared to keep the
+	// payloads in the group and a bit is set if they are otherwise zero so
+	// that neither payload is entirely zero
+	var fp format.Fingerprint
+	readRandom(rng, fp[:])
+	groupSafe(fp[:])
+	msg.SetKeyFP(fp)
+
+	mac := make([]byte, format.MacLen)
+	readRandom(rng, mac)
+	groupSafe(mac)
+	msg.SetMac(mac)
+
+	ephemeralRID := make([]byte, format.EphemeralRIDLen)
+	readRandom(rng, ephemeralRID)
+	msg.SetEphemeralRID(ephemeralRID)
+
+	sih := make([]byte, format.SIHLen)
+	readRandom(rng, sih)
+	msg.SetSIH(sih)
+
+	b := make([]byte, 2)
+	readRandom(rng, b)
+	data := make([]byte, int(binary.BigEndian.Uint16(b))%
+		(msg.GetDataCapacity()+1))
+	readRandom(rng, data)
+	msg.SetData(data)
+
+	return &msg
+}
+
+// groupSafe clears the first bit of b and sets its last bit if it is zero.
+func groupSafe(b []byte) {
+	b[0] &= 0x7F
+	if bytes.Equal(b, make([]byte, len(b))) {
+		b[len(b)-1] = 1
+	}
+}
+
+// readRandom fills b from rng. Panics on error.
+func readRandom(rng io.Reader, b []byte) {
+	if _, err := io.ReadFull(rng, b); err != nil {
+		jww.FATAL.Panicf("Failed to read %d random bytes: %+v", len(b), err)
+	}
+}
+
+// zeroReader is an io.Reader that only returns zeros.
+type zeroReader struct{}
+
+// Read fills b with zeros. This function adheres to the io.Reader interface.
+func (zeroReader) Read(b []byte) (int, error) {
+	for i := range b {
+		b[i] = 0
+	}
+	return len(b), nil
+}
@@ -0,0 +1,75 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package testutils
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"gitlab.com/elixxir/primitives/format"
+)
+
+// Tests that NewRandomMessage returns structurally valid messages that survive
+// a marshal round trip and that are deterministic for a seeded source.
+func TestNewRandomMessage(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	for i := 0; i < 50; i++ {
+		msg := NewRandomMessage(prng)
+		checkMessage(t, msg, i)
+		if msg.GetPrimeByteLen() != format.DefaultPrimeSize {
+			t.Errorf("Unexpected prime size (%d).\nexpected: %d\nreceived: %d",
+				i, format.DefaultPrimeSize, msg.GetPrimeByteLen())
+		}
+	}
+
+	a := NewRandomMessage(rand.New(rand.NewSource(7)))
+	b := NewRandomMessage(rand.New(rand.NewSource(7)))
+	if !bytes.Equal(a.Marshal(), b.Marshal()) {
+		t.Error("Messages from the same seed differ.")
+	}
+}
+
+// Tests that NewFuzzedMessage returns structurally valid messages of varying
+// prime sizes for arbitrary input, including empty input.
+func TestNewFuzzedMessage(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	inputs := [][]byte{nil, {0xFF, 0xFF}, bytes.Repeat([]byte{0xFF}, 2000)}
+	for i := 0; i < 50; i++ {
+		data := make([]byte, prng.Intn(1500))
+		prng.Read(data)
+		inputs = append(inputs, data)
+	}
+
+	for i, data := range inputs {
+		msg := NewFuzzedMessage(data)
+		checkMessage(t, msg, i)
+		if msg.GetPrimeByteLen() < format.MinimumPrimeSize ||
+			msg.GetPrimeByteLen() > maxFuzzedPrimeSize {
+			t.Errorf("Prime size %d out of range (%d).",
+				msg.GetPrimeByteLen(), i)
+		}
+	}
+}
+
+// checkMessage fails the test if the message is not structurally valid.
+func checkMessage(t *testing.T, msg *format.Message, i int) {
+	if err := msg.VerifyGroupMembership(); err != nil {
+		t.Errorf("Message not in group (%d): %+v", i, err)
+	}
+	if err := msg.VerifyPaddingZeroed(); err != nil {
+		t.Errorf("Message padding not zeroed (%d): %+v", i, err)
+	}
+
+	unmarshalled, err := format.Unmarshal(msg.Marshal())
+	if err != nil {
+		t.Errorf("Failed to unmarshal message (%d): %+v", i, err)
+	} else if !bytes.Equal(unmarshalled.Marshal(), msg.Marshal()) {
+		t.Errorf("Unmarshalled message does not match (%d).", i)
+	}
+}
@@ -0,0 +1,128 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import (
+	"fmt"
+)
+
+// InvariantError describes a structural invariant of a Message that does not
+// hold. Offset is the position of the offending byte in the master buffer.
+type InvariantError struct {
+	Region string
+	Offset int
+	Value  byte
+	Reason string
+}
+
+// Error returns the InvariantError as a string. This function adheres to the
+// error interface.
+func (e *InvariantError) Error() string {
+	return fmt.Sprintf("message invariant violated in %s at byte %d "+
+		"(value 0x%02x): %s", e.Region, e.Offset, e.Value, e.Reason)
+}
+
+// VerifyGroupMembership checks the structural invariants that keep each
+// payload within the cyclic group: the group bit, which is the first bit of
+// each payload, must be zero and no payload may be entirely zero. Returns an
+// *InvariantError describing the first violation found.
+//
+// Messages whose group bits were deliberately set via Message.SetGroupBits
+// after checking against the prime fail this check, so it must be used before
+// sending, before the group bits are set, and never on received messages.
+func (m Message) VerifyGroupMembership() error {
+	return m.verifyGroupMembership(false)
+}
+
+// verifyGroupMembership checks the group byte and group membership of each
+// payload as described in Message.VerifyGroupMembership. If groupBitsSet is
+// true, the group bit may be set, as it is on received messages whose group
+// bits were set with Message.SetGroupBits, but the payload must still not be
+// entirely zero.
+func (m Message) verifyGroupMembership(groupBitsSet bool) error {
+	l := m.Layout()
+	payloads := []struct {
+		name   string
+		region Region
+	}{
+		{"payloadA", l.PayloadA()},
+		{"payloadB", l.PayloadB()},
+	}
+
+	for _, p := range payloads {
+		payload := p.region.Slice(m.data)
+		if !groupBitsSet && payload[0]>>7 != 0 {
+			return &InvariantError{
+				Region: p.name,
+				Offset: p.region.Offset,
+				Value:  payload[0],
+				Reason: "group bit must be zero",
+			}
+		}
+
+		if isZero(payload) {
+			return &InvariantError{
+				Region: p.name,
+				Offset: p.region.Offset,
+				Reason: "payload of all zeros is not a member of the group",
+			}
+		}
+	}
+
+	return nil
+}
+
+// VerifyPaddingZeroed checks that the contents were set via Message.SetData:
+// the length prefix must fit within the data capacity and every byte of
+// padding after the data must be zero. Returns an *InvariantError describing
+// the first violation found.
+func (m Message) VerifyPaddingZeroed() error {
+	l := m.Layout()
+	length := m.GetDataLength()
+	if length > m.GetDataCapacity() {
+		return &InvariantError{
+			Region: l.Contents1.Name,
+			Offset: l.Contents1.Offset,
+			Value:  m.contents1[0],
+			Reason: fmt.Sprintf("data length %d exceeds data capacity %d",
+				length, m.GetDataCapacity()),
+		}
+	}
+
+	for i := DataLenSize + length; i < m.ContentsSize(); i++ {
+		region, offset, b := l.Contents1, i, byte(0)
+		if i < len(m.contents1) {
+			b = m.contents1[i]
+		} else {
+			region, offset = l.Contents2, i-len(m.contents1)
+			b = m.contents2[offset]
+		}
+
+		if b != 0 {
+			return &InvariantError{
+				Region: region.Name,
+				Offset: region.Offset + offset,
+				Value:  b,
+				Reason: fmt.Sprintf("padding after %d bytes of data must "+
+					"be zero", length),
+			}
+		}
+	}
+
+	return nil
+}
+
+// isZero determines if every byte in the slice is zero.
+func isZero(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import (
+	"errors"
+	"testing"
+)
+
+// newVerifyTestMessage returns a message with data set and nonzero payloads
+// that satisfies all invariants.
+func newVerifyTestMessage() Message {
+	m := NewMessage(DefaultPrimeSize)
+	m.SetData([]byte("hello, world"))
+	mac := make([]byte, MacLen)
+	mac[1] = 1
+	m.SetMac(mac)
+	return m
+}
+
+// Tests that Message.VerifyGroupMembership returns nil for a valid message.
+func TestMessage_VerifyGroupMembership(t *testing.T) {
+	m := newVerifyTestMessage()
+	if err := m.VerifyGroupMembership(); err != nil {
+		t.Errorf("Unexpected error: %+v", err)
+	}
+}
+
+// Tests that Message.VerifyGroupMembership returns an InvariantError pointing
+// to the offending payload when a group bit is set or a payload is zero.
+func TestMessage_VerifyGroupMembership_Error(t *testing.T) {
+	m := newVerifyTestMessage()
+	m.SetGroupBits(false, true)
+
+	var ie *InvariantError
+	err := m.VerifyGroupMembership()
+	if !errors.As(err, &ie) {
+		t.Fatalf("Expected *InvariantError, received: %v", err)
+	}
+	if ie.Region != "payloadB" || ie.Offset != DefaultPrimeSize {
+		t.Errorf("Unexpected error location: %s at %d", ie.Region, ie.Offset)
+	}
+
+	m = NewMessage(DefaultPrimeSize)
+	m.SetData([]byte("data"))
+	err = m.VerifyGroupMembership()
+	if !errors.As(err, &ie) || ie.Region != "payloadB" {
+		t.Errorf("Expected error for zero payload B, received: %v", err)
+	}
+}
+
+// Tests that Message.VerifyPaddingZeroed returns nil for data set via
+// Message.SetData.
+func TestMessage_VerifyPaddingZeroed(t *testing.T) {
+	m := newVerifyTestMessage()
+	if err := m.VerifyPaddingZeroed(); err != nil {
+		t.Errorf("Unexpected error: %+v", err)
+	}
+
+	m.SetData(make([]byte, m.GetDataCapacity()))
+	if err := m.VerifyPaddingZeroed(); err != nil {
+		t.Errorf("Unexpected error for full data: %+v", err)
+	}
+}
+
+// Tests that Message.VerifyPaddingZeroed returns an InvariantError pointing to
+// the nonzero padding byte in both contents regions.
+func TestMessage_VerifyPaddingZeroed_Error(t *testing.T) {
+	l := NewLayout(DefaultPrimeSize)
+
+	// Nonzero padding in contents 1
+	m := newVerifyTestMessage()
+	m.data[l.Contents1.End()-1] = 0xFF
+
+	var ie *InvariantError
+	err := m.VerifyPaddingZeroed()
+	if !errors.As(err, &ie) {
+		t.Fatalf("Expected *InvariantError, received: %v", err)
+	}
+	if ie.Region != l.Contents1.Name || ie.Offset != l.Contents1.End()-1 ||
+		ie.Value != 0xFF {
+		t.Errorf("Unexpected error: %+v", ie)
+	}
+
+	// Nonzero padding in contents 2
+	m = newVerifyTestMessage()
+	m.data[l.Contents2.Offset+3] = 0x01
+	err = m.VerifyPaddingZeroed()
+	if !errors.As(err, &ie) || ie.Offset != l.Contents2.Offset+3 {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// Length prefix too large
+	m = newVerifyTestMessage()
+	m.data[l.Contents1.Offset] = 0xFF
+	if err = m.VerifyPaddingZeroed(); err == nil {
+		t.Error("Expected error for length prefix larger than capacity.")
+	}
+}
@@ -0,0 +1,60 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import (
+	"github.com/pkg/errors"
+)
+
+// CurrentVersion is the format version written into new messages.
+const CurrentVersion = messagePayloadVersion
+
+// messageParsers maps each supported format version to the function that
+// parses a message of that version. Future layouts are added here so that
+// receivers can support multiple versions during a migration.
+var messageParsers = map[uint8]func(data []byte) (Message, error){
+	messagePayloadVersion: parseMessageV0,
+	LegacyVersion:         parseMessageLegacy,
+}
+
+// GetVersion returns the format version of the message.
+func (m Message) GetVersion() uint8 {
+	return m.version[0]
+}
+
+// SetVersion sets the format version of the message.
+func (m Message) SetVersion(version uint8) {
+	m.version[0] = version
+}
+
+// ParseMessage parses a marshalled message, dispatching to the layout that
+// matches its version byte. Returns an error if the data is too short to be a
+// message, is not made of two equal payloads, or has an unsupported version.
+func ParseMessage(data []byte) (Message, error) {
+	if len(data) < 2*MinimumPrimeSize {
+		return Message{}, errors.Errorf("message data must be at least %d "+
+			"bytes; received %d bytes", 2*MinimumPrimeSize, len(data))
+	} else if len(data)%2 != 0 {
+		return Message{}, errors.Errorf(
+			"message data length %d must be even", len(data))
+	}
+
+	version := data[NewLayout(len(data)/2).Version.Offset]
+	parse, exists := messageParsers[version]
+	if !exists {
+		return Message{}, errors.Errorf(
+			"unsupported message format version %d", version)
+	}
+
+	return parse(data)
+}
+
+// parseMessageV0 parses a message in the original layout.
+func parseMessageV0(data []byte) (Message, error) {
+	return Unmarshal(data)
+}
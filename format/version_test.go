@@ -0,0 +1,70 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Tests that Message.SetVersion sets the version byte that is returned by
+// Message.GetVersion and Message.Version without modifying other fields.
+func TestMessage_SetVersion_GetVersion(t *testing.T) {
+	m := NewMessage(MinimumPrimeSize)
+	contents := bytes.Repeat([]byte{0xAB}, m.ContentsSize())
+	m.SetContents(contents)
+
+	m.SetVersion(7)
+
+	if m.GetVersion() != 7 {
+		t.Errorf("Unexpected version.\nexpected: %d\nreceived: %d",
+			7, m.GetVersion())
+	}
+	if m.Version() != m.GetVersion() {
+		t.Errorf("Version and GetVersion disagree: %d != %d",
+			m.Version(), m.GetVersion())
+	}
+	if !bytes.Equal(m.GetContents(), contents) {
+		t.Error("Setting the version modified the contents.")
+	}
+}
+
+// Tests that ParseMessage parses a message of the current version.
+func TestParseMessage(t *testing.T) {
+	m := NewMessage(DefaultPrimeSize)
+	m.SetContents(bytes.Repeat([]byte{1}, m.ContentsSize()))
+	m.SetVersion(CurrentVersion)
+
+	parsed, err := ParseMessage(m.Marshal())
+	if err != nil {
+		t.Fatalf("Failed to parse message: %+v", err)
+	}
+
+	if !bytes.Equal(m.Marshal(), parsed.Marshal()) {
+		t.Errorf("Parsed message does not match original."+
+			"\nexpected: %v\nreceived: %v", m.Marshal(), parsed.Marshal())
+	}
+}
+
+// Tests that ParseMessage returns an error for unsupported versions and
+// invalid lengths.
+func TestParseMessage_Error(t *testing.T) {
+	m := NewMessage(DefaultPrimeSize)
+	m.SetVersion(CurrentVersion + 1)
+	if _, err := ParseMessage(m.Marshal()); err == nil {
+		t.Error("Expected error for unsupported version.")
+	}
+
+	if _, err := ParseMessage(make([]byte, 2*MinimumPrimeSize-2)); err == nil {
+		t.Error("Expected error for data that is too short.")
+	}
+
+	if _, err := ParseMessage(make([]byte, 2*MinimumPrimeSize+1)); err == nil {
+		t.Error("Expected error for data of odd length.")
+	}
+}
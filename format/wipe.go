@@ -0,0 +1,50 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import "runtime"
+
+// Wipe zeroes the entire underlying buffer of the Message, including the
+// contents and associated data. Every copy of the Message that shares the
+// buffer is wiped; copies made with Message.Copy or Message.Marshal are not.
+func (m Message) Wipe() {
+	wipe(m.data)
+}
+
+// WipeContents zeroes the contents of the Message in the underlying buffer,
+// leaving the version, associated data, and group bits intact.
+func (m Message) WipeContents() {
+	wipe(m.contents1)
+	wipe(m.contents2)
+}
+
+// WipeAssociatedData zeroes the key fingerprint, MAC, ephemeral recipient ID,
+// and SIH of the Message in the underlying buffer.
+func (m Message) WipeAssociatedData() {
+	wipe(m.keyFP)
+	wipe(m.mac)
+	wipe(m.ephemeralRID)
+	wipe(m.sih)
+}
+
+// Wipe zeroes every field of the AssociatedData.
+func (ad *AssociatedData) Wipe() {
+	wipe(ad.KeyFP[:])
+	wipe(ad.Mac[:])
+	wipe(ad.EphemeralRID[:])
+	wipe(ad.SIH[:])
+}
+
+// wipe zeroes the byte slice. The explicit loop, followed by keeping the slice
+// alive, ensures the stores are not optimised away.
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+	runtime.KeepAlive(b)
+}
@@ -0,0 +1,85 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package format
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// newTestWipeMessage returns a Message with random contents and associated
+// data.
+func newTestWipeMessage(prng *rand.Rand) Message {
+	m := NewMessage(MinimumPrimeSize * 4)
+	contents := make([]byte, m.ContentsSize())
+	prng.Read(contents)
+	m.SetContents(contents)
+	m.SetAssociatedData(newTestAssociatedData(prng))
+	return m
+}
+
+// Tests that Message.Wipe zeroes the whole underlying buffer and leaves copies
+// intact.
+func TestMessage_Wipe(t *testing.T) {
+	m := newTestWipeMessage(rand.New(rand.NewSource(42)))
+	c := m.Copy()
+
+	m.Wipe()
+
+	if !isZero(m.data) {
+		t.Errorf("Message buffer not wiped: %v", m.data)
+	}
+	if isZero(c.GetContents()) {
+		t.Error("Wiping the Message wiped its copy.")
+	}
+}
+
+// Tests that Message.WipeContents zeroes only the contents, leaving the version
+// and associated data, and Message.WipeAssociatedData zeroes only the
+// associated data.
+func TestMessage_WipeContents_WipeAssociatedData(t *testing.T) {
+	m := newTestWipeMessage(rand.New(rand.NewSource(42)))
+	m.SetVersion(3)
+	ad := m.GetAssociatedData()
+
+	m.WipeContents()
+	if !isZero(m.GetContents()) {
+		t.Errorf("Contents not wiped: %v", m.GetContents())
+	}
+	if m.GetAssociatedData() != ad {
+		t.Error("Associated data changed when wiping contents.")
+	}
+	if m.GetVersion() != 3 {
+		t.Errorf("Version changed when wiping contents."+
+			"\nexpected: %d\nreceived: %d", 3, m.GetVersion())
+	}
+
+	contents := make([]byte, m.ContentsSize())
+	rand.New(rand.NewSource(42)).Read(contents)
+	m.SetContents(contents)
+	m.WipeAssociatedData()
+	if !m.GetAssociatedData().KeyFP.IsZero() ||
+		!m.GetAssociatedData().Mac.IsZero() ||
+		!m.GetAssociatedData().EphemeralRID.IsZero() ||
+		!m.GetAssociatedData().SIH.IsZero() {
+		t.Errorf("Associated data not wiped: %+v", m.GetAssociatedData())
+	}
+	if !bytes.Equal(m.GetContents(), contents) {
+		t.Error("Contents changed when wiping associated data.")
+	}
+}
+
+// Tests that AssociatedData.Wipe zeroes every field.
+func TestAssociatedData_Wipe(t *testing.T) {
+	ad := newTestAssociatedData(rand.New(rand.NewSource(42)))
+	ad.Wipe()
+	if ad != (AssociatedData{}) {
+		t.Errorf("Associated data not wiped: %+v", ad)
+	}
+}
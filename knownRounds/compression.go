@@ -0,0 +1,199 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+// Algorithm compresses and decompresses marshalled KnownRounds for
+// KnownRounds.MarshalCompressed. Each Algorithm is identified by a one-byte ID
+// written after the compression magic so that KnownRounds.Unmarshal can select
+// it.
+type Algorithm interface {
+	// ID returns the byte that identifies the algorithm.
+	ID() byte
+
+	// Compress returns the compressed data.
+	Compress(data []byte) ([]byte, error)
+
+	// Decompress returns the decompressed data, reading no more than limit
+	// bytes of output.
+	Decompress(data []byte, limit int64) ([]byte, error)
+}
+
+// IDs of the built-in algorithms.
+const (
+	GzipID  byte = 0x01
+	FlateID byte = 0x02
+)
+
+// compressedMagic prefixes the output of KnownRounds.MarshalCompressed. As the
+// start of the output of KnownRounds.Marshal, it would be a firstUnchecked of
+// math.MaxUint64 with a lastChecked below math.MaxUint64-1, which Unmarshal
+// rejects, so the two formats cannot be confused.
+var compressedMagic = []byte{
+	0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 'K', 'R'}
+
+// maxDecompressedLen is the largest decompressed KnownRounds accepted by
+// Unmarshal. It exceeds the marshalled size of the largest bit stream so that
+// only malicious data is rejected.
+const maxDecompressedLen = 18 + 16*maxBitStreamLen
+
+// Gzip compresses KnownRounds using gzip at the best compression level.
+type Gzip struct{}
+
+// ID returns GzipID. This function adheres to the Algorithm interface.
+func (Gzip) ID() byte { return GzipID }
+
+// Compress compresses the data using gzip. This function adheres to the
+// Algorithm interface.
+func (Gzip) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	return compress(&buf, w, data)
+}
+
+// Decompress decompresses gzip data. This function adheres to the Algorithm
+// interface.
+func (Gzip) Decompress(data []byte, limit int64) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return decompress(r, limit)
+}
+
+// Flate compresses KnownRounds using DEFLATE at the best compression level.
+// It has less overhead than Gzip.
+type Flate struct{}
+
+// ID returns FlateID. This function adheres to the Algorithm interface.
+func (Flate) ID() byte { return FlateID }
+
+// Compress compresses the data using DEFLATE. This function adheres to the
+// Algorithm interface.
+func (Flate) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	return compress(&buf, w, data)
+}
+
+// Decompress decompresses DEFLATE data. This function adheres to the Algorithm
+// interface.
+func (Flate) Decompress(data []byte, limit int64) ([]byte, error) {
+	return decompress(flate.NewReader(bytes.NewReader(data)), limit)
+}
+
+// compress writes the data to the compressing writer and returns the contents
+// of its underlying buffer once it is closed.
+func compress(buf *bytes.Buffer, w io.WriteCloser, data []byte) (
+	[]byte, error) {
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	} else if err = w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress reads the decompressing reader until EOF. Returns an error if
+// more than limit bytes are read.
+func decompress(r io.ReadCloser, limit int64) ([]byte, error) {
+	defer r.Close()
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	} else if int64(len(data)) > limit {
+		return nil, errors.Errorf("decompressed data exceeds %d bytes", limit)
+	}
+	return data, nil
+}
+
+var (
+	algorithms = map[byte]Algorithm{
+		GzipID:  Gzip{},
+		FlateID: Flate{},
+	}
+	algorithmsMux sync.RWMutex
+)
+
+// RegisterAlgorithm adds an Algorithm, such as a zstd implementation, so that
+// its output can be unmarshalled by KnownRounds.Unmarshal. Panics if the ID is
+// zero or is already registered.
+func RegisterAlgorithm(algo Algorithm) {
+	algorithmsMux.Lock()
+	defer algorithmsMux.Unlock()
+
+	if algo.ID() == 0 {
+		jww.FATAL.Panicf("Compression algorithm ID must not be zero.")
+	} else if _, exists := algorithms[algo.ID()]; exists {
+		jww.FATAL.Panicf("Compression algorithm ID %#x already registered.",
+			algo.ID())
+	}
+
+	algorithms[algo.ID()] = algo
+}
+
+// MarshalCompressed returns the output of KnownRounds.Marshal compressed with
+// the Algorithm. It is prefixed with a magic value and the algorithm's ID so
+// that KnownRounds.Unmarshal accepts both compressed and uncompressed data.
+// Large windows with few patterns, such as 500,000 rounds that are mostly
+// checked, compress to a small fraction of their marshalled size.
+func (kr *KnownRounds) MarshalCompressed(algo Algorithm) ([]byte, error) {
+	compressed, err := algo.Compress(kr.Marshal())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to compress KnownRounds with "+
+			"algorithm %#x", algo.ID())
+	}
+
+	data := make([]byte, 0, len(compressedMagic)+1+len(compressed))
+	data = append(data, compressedMagic...)
+	data = append(data, algo.ID())
+	return append(data, compressed...), nil
+}
+
+// decompressKnownRounds returns the decompressed data if it was produced by
+// KnownRounds.MarshalCompressed. Otherwise, it returns the data unchanged.
+func decompressKnownRounds(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, compressedMagic) {
+		return data, nil
+	} else if len(data) == len(compressedMagic) {
+		return nil, errors.New("compressed KnownRounds is missing the " +
+			"algorithm ID")
+	}
+
+	algoID := data[len(compressedMagic)]
+	algorithmsMux.RLock()
+	algo, exists := algorithms[algoID]
+	algorithmsMux.RUnlock()
+	if !exists {
+		return nil, errors.Errorf("unknown compression algorithm %#x", algoID)
+	}
+
+	decompressed, err := algo.Decompress(
+		data[len(compressedMagic)+1:], maxDecompressedLen)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decompress KnownRounds with "+
+			"algorithm %#x", algoID)
+	}
+	return decompressed, nil
+}
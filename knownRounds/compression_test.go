@@ -0,0 +1,96 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// Tests that a KnownRounds marshalled with KnownRounds.MarshalCompressed using
+// each built-in Algorithm is unmarshalled by KnownRounds.Unmarshal to match
+// the original, and that the compressed form of a large window is smaller.
+func TestKnownRounds_MarshalCompressed_Unmarshal(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	kr := NewKnownRound(500000)
+	kr.Forward(1000)
+	for i := 0; i < 2000; i++ {
+		kr.Check(1000 + id.Round(prng.Intn(499000)))
+	}
+	kr.Check(1000 + 499999)
+	marshalled := kr.Marshal()
+
+	for _, algo := range []Algorithm{Gzip{}, Flate{}} {
+		data, err := kr.MarshalCompressed(algo)
+		if err != nil {
+			t.Fatalf("Failed to compress with %T: %+v", algo, err)
+		}
+		if len(data) >= len(marshalled) {
+			t.Errorf("Compressed size with %T not smaller: %d >= %d",
+				algo, len(data), len(marshalled))
+		}
+
+		newKr := NewKnownRound(0)
+		if err = newKr.Unmarshal(data); err != nil {
+			t.Fatalf("Failed to unmarshal %T: %+v", algo, err)
+		}
+		if !bytes.Equal(marshalled, newKr.Marshal()) {
+			t.Errorf("Unmarshalled %T does not match original.", algo)
+		}
+	}
+}
+
+// Tests that RegisterAlgorithm panics for a zero or duplicate ID.
+func TestRegisterAlgorithm_Panic(t *testing.T) {
+	for _, algo := range []Algorithm{testAlgorithm{0}, Gzip{}} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("No panic for algorithm ID %#x.", algo.ID())
+				}
+			}()
+			RegisterAlgorithm(algo)
+		}()
+	}
+}
+
+// Error path: Tests that KnownRounds.Unmarshal returns an error for compressed
+// data with a missing or unknown algorithm ID, corrupt contents, or contents
+// that decompress to more than the limit.
+func TestKnownRounds_Unmarshal_CompressedError(t *testing.T) {
+	kr := NewKnownRound(128)
+	kr.Check(5)
+	data, _ := kr.MarshalCompressed(Flate{})
+
+	bomb, _ := Flate{}.Compress(make([]byte, maxDecompressedLen+1))
+	tests := map[string][]byte{
+		"missingID": compressedMagic,
+		"unknownID": append(append([]byte{}, compressedMagic...), 0xEE, 1),
+		"corrupt":   data[:len(data)-2],
+		"bomb": append(append([]byte{}, compressedMagic...),
+			append([]byte{FlateID}, bomb...)...),
+	}
+	for name, data := range tests {
+		if err := NewKnownRound(0).Unmarshal(data); err == nil {
+			t.Errorf("No error for %s data.", name)
+		}
+	}
+}
+
+// testAlgorithm is an Algorithm with a configurable ID that does not
+// compress.
+type testAlgorithm struct{ id byte }
+
+func (a testAlgorithm) ID() byte                           { return a.id }
+func (testAlgorithm) Compress(data []byte) ([]byte, error) { return data, nil }
+func (testAlgorithm) Decompress(data []byte, _ int64) ([]byte, error) {
+	return data, nil
+}
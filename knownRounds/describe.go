@@ -0,0 +1,105 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"strconv"
+	"strings"
+
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+// DescribeChange returns a short, single-line summary of the differences
+// between two states of a KnownRounds for logging: the number of rounds that
+// became checked or unchecked and how the window moved. For example:
+//
+//	3 rounds checked, window moved from [5, 100] to [8, 120]
+//
+// The window is given as [firstUnchecked, lastChecked]. Every round between
+// the earliest and latest round of either window is compared, so the cost is
+// proportional to the size of the windows.
+func DescribeChange(before, after *KnownRounds) string {
+	if before == nil || after == nil {
+		return "no KnownRounds to compare"
+	}
+
+	var checked, unchecked int
+	start := minRound(before.firstUnchecked, after.firstUnchecked)
+	end := maxRound(before.lastChecked, after.lastChecked)
+	for rid := start; rid <= end; rid++ {
+		wasChecked, isChecked := before.Checked(rid), after.Checked(rid)
+		if !wasChecked && isChecked {
+			checked++
+		} else if wasChecked && !isChecked {
+			unchecked++
+		}
+
+		// Prevent overflow on the last round
+		if rid == end {
+			break
+		}
+	}
+
+	var parts []string
+	if checked > 0 {
+		parts = append(parts, pluralRounds(checked)+" checked")
+	}
+	if unchecked > 0 {
+		parts = append(parts, pluralRounds(unchecked)+" unchecked")
+	}
+	if before.firstUnchecked != after.firstUnchecked ||
+		before.lastChecked != after.lastChecked {
+		parts = append(parts, "window moved from "+
+			describeWindow(before)+" to "+describeWindow(after))
+	}
+
+	if len(parts) == 0 {
+		return "no change to window " + describeWindow(after)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// describeWindow returns the window of the KnownRounds as
+// "[firstUnchecked, lastChecked]".
+func describeWindow(kr *KnownRounds) string {
+	return "[" + strconv.FormatUint(uint64(kr.firstUnchecked), 10) + ", " +
+		strconv.FormatUint(uint64(kr.lastChecked), 10) + "]"
+}
+
+// pluralRounds returns the number of rounds with the correct noun.
+func pluralRounds(n int) string {
+	if n == 1 {
+		return "1 round"
+	}
+	return strconv.Itoa(n) + " rounds"
+}
+
+// traceEnabled determines if TRACE messages are written to either the log or
+// stdout. It is used to skip the work of building expensive TRACE messages.
+func traceEnabled() bool {
+	return jww.GetLogThreshold() <= jww.LevelTrace ||
+		jww.GetStdoutThreshold() <= jww.LevelTrace
+}
+
+// traceChange logs the changes made to the KnownRounds since the before copy
+// was taken. The copy is nil when tracing is disabled.
+func traceChange(prefix string, before, after *KnownRounds) {
+	if before != nil {
+		jww.TRACE.Printf("%s: %s", prefix, DescribeChange(before, after))
+	}
+}
+
+// traceCopy returns a copy of the KnownRounds for traceChange if tracing is
+// enabled; otherwise, it returns nil.
+func traceCopy(kr *KnownRounds) *KnownRounds {
+	if !traceEnabled() {
+		return nil
+	}
+	return kr.deepCopy()
+}
@@ -0,0 +1,96 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	jww "github.com/spf13/jwalterweatherman"
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// Tests that DescribeChange summarizes the rounds checked and unchecked and
+// the movement of the window.
+func TestDescribeChange(t *testing.T) {
+	before := NewKnownRound(256)
+	before.Check(5)
+	before.Check(10)
+
+	tests := []struct {
+		change   func(kr *KnownRounds)
+		expected string
+	}{
+		{func(*KnownRounds) {}, "no change to window [0, 10]"},
+		{func(kr *KnownRounds) { kr.Check(7) }, "1 round checked"},
+		{func(kr *KnownRounds) { kr.Check(7); kr.Check(8) },
+			"2 rounds checked"},
+		{func(kr *KnownRounds) { kr.Check(20) },
+			"1 round checked, window moved from [0, 10] to [0, 20]"},
+		{func(kr *KnownRounds) { kr.Forward(8) },
+			"7 rounds checked, window moved from [0, 10] to [8, 10]"},
+	}
+
+	for i, tt := range tests {
+		after := before.deepCopy()
+		tt.change(after)
+		if s := DescribeChange(before, after); s != tt.expected {
+			t.Errorf("Unexpected description (%d).\nexpected: %q\nreceived: %q",
+				i, tt.expected, s)
+		}
+	}
+
+	if s := DescribeChange(nil, before); s != "no KnownRounds to compare" {
+		t.Errorf("Unexpected description for nil KnownRounds: %q", s)
+	}
+}
+
+// Tests that DescribeChange reports rounds that are checked in the before state
+// but not in the after state.
+func TestDescribeChange_Unchecked(t *testing.T) {
+	before := NewKnownRound(128)
+	before.Check(3)
+	before.Check(4)
+	after := NewKnownRound(128)
+	after.Check(4)
+
+	expected := "1 round unchecked"
+	if s := DescribeChange(before, after); s != expected {
+		t.Errorf("Unexpected description.\nexpected: %q\nreceived: %q",
+			expected, s)
+	}
+}
+
+// Tests that KnownRounds.RangeUncheckedMaskedRange logs a single short
+// description of its changes when TRACE logging is enabled.
+func TestKnownRounds_RangeUncheckedMaskedRange_Trace(t *testing.T) {
+	var buf bytes.Buffer
+	jww.SetLogOutput(&buf)
+	jww.SetLogThreshold(jww.LevelTrace)
+	defer func() {
+		jww.SetLogThreshold(jww.LevelWarn)
+		jww.SetLogOutput(nil)
+	}()
+
+	kr := NewKnownRound(128)
+	kr.Check(100)
+	mask := NewKnownRound(128)
+	mask.Forward(60)
+	kr.RangeUncheckedMaskedRange(mask,
+		func(id.Round) bool { return true }, 0, 100, 50)
+
+	log := buf.String()
+	if !strings.Contains(log, "RangeUncheckedMaskedRange: 50 rounds "+
+		"checked") {
+		t.Errorf("Log does not describe the change: %q", log)
+	}
+	if strings.Count(log, "\n") != 1 || len(log) > 200 {
+		t.Errorf("Log is not a single short line: %q", log)
+	}
+}
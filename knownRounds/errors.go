@@ -0,0 +1,57 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"strconv"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// ErrBufferTooSmall is returned, wrapped with details, when rounds do not fit
+// in the bit stream of a KnownRounds. Use errors.As to retrieve it, for
+// example, to retry with a KnownRounds of at least Need rounds.
+type ErrBufferTooSmall struct {
+	// Need is the number of rounds the bit stream must hold.
+	Need uint64
+
+	// Have is the number of rounds the bit stream holds.
+	Have uint64
+}
+
+// Error returns the ErrBufferTooSmall as a string. This function adheres to the
+// error interface.
+func (e ErrBufferTooSmall) Error() string {
+	return "bit stream of " + strconv.FormatUint(e.Have, 10) +
+		" rounds is too small; " + strconv.FormatUint(e.Need, 10) +
+		" rounds are needed"
+}
+
+// ErrOutOfScope is returned by KnownRounds.TryCheck when a round is too far
+// from the last checked round to fit in the bit stream. Use errors.As to
+// retrieve it.
+type ErrOutOfScope struct {
+	// Round is the round that could not be checked.
+	Round id.Round
+
+	// LastChecked is the last checked round of the KnownRounds.
+	LastChecked id.Round
+
+	// Window is the number of rounds the bit stream holds. Rounds must be
+	// less than Window rounds from LastChecked.
+	Window int
+}
+
+// Error returns the ErrOutOfScope as a string. This function adheres to the
+// error interface.
+func (e ErrOutOfScope) Error() string {
+	return "round " + strconv.FormatUint(uint64(e.Round), 10) +
+		" is outside the scope of " + strconv.Itoa(e.Window) +
+		" rounds from last checked round " +
+		strconv.FormatUint(uint64(e.LastChecked), 10)
+}
@@ -0,0 +1,137 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// Tests that KnownRounds.Unmarshal returns an error that can be unwrapped to
+// ErrBufferTooSmall with the required and available rounds, and that the
+// returned size is enough to unmarshal the data.
+func TestKnownRounds_Unmarshal_ErrBufferTooSmall(t *testing.T) {
+	testKR := NewKnownRound(256)
+	testKR.Check(10)
+	testKR.Check(150)
+	data := testKR.Marshal()
+
+	err := NewKnownRound(64).Unmarshal(data)
+	var tooSmall ErrBufferTooSmall
+	if !errors.As(err, &tooSmall) {
+		t.Fatalf("Error is not ErrBufferTooSmall: %+v", err)
+	}
+
+	// Need is the number of rounds from firstUnchecked (0) to lastChecked
+	// (150), not the size of the marshalled bit stream, since the data can be
+	// unmarshalled into any KnownRounds that holds those rounds
+	expected := ErrBufferTooSmall{Need: 151, Have: 64}
+	if tooSmall != expected {
+		t.Errorf("Unexpected ErrBufferTooSmall."+
+			"\nexpected: %+v\nreceived: %+v", expected, tooSmall)
+	}
+
+	// The error is enough information to resize and retry
+	err = NewKnownRound(int(tooSmall.Need)).Unmarshal(data)
+	if err != nil {
+		t.Errorf("Failed to unmarshal into resized KnownRounds: %+v", err)
+	}
+}
+
+// Tests that KnownRounds.Merge returns an error that can be unwrapped to
+// ErrBufferTooSmall when the merged window does not fit.
+func TestKnownRounds_Merge_ErrBufferTooSmall(t *testing.T) {
+	kr := NewKnownRound(128)
+	kr.Check(5)
+	other := NewKnownRound(256)
+	other.Check(200)
+
+	err := kr.Merge(other, MergeOr)
+	var tooSmall ErrBufferTooSmall
+	if !errors.As(err, &tooSmall) {
+		t.Fatalf("Error is not ErrBufferTooSmall: %+v", err)
+	}
+
+	expected := ErrBufferTooSmall{Need: 201, Have: 128}
+	if tooSmall != expected {
+		t.Errorf("Unexpected ErrBufferTooSmall."+
+			"\nexpected: %+v\nreceived: %+v", expected, tooSmall)
+	}
+}
+
+// Tests that KnownRounds.TryCheck checks rounds within scope.
+func TestKnownRounds_TryCheck(t *testing.T) {
+	kr := NewKnownRound(128)
+	for _, rid := range []uint64{5, 100, 127} {
+		if err := kr.TryCheck(id.Round(rid)); err != nil {
+			t.Errorf("Failed to check round %d: %+v", rid, err)
+		}
+		if !kr.Checked(id.Round(rid)) {
+			t.Errorf("Round %d not checked.", rid)
+		}
+	}
+}
+
+// Error path: Tests that KnownRounds.TryCheck returns ErrOutOfScope and does
+// not modify the KnownRounds for a round outside the scope.
+func TestKnownRounds_TryCheck_ErrOutOfScope(t *testing.T) {
+	kr := NewKnownRound(128)
+	kr.Check(5)
+	expected := kr.Marshal()
+
+	err := kr.TryCheck(300)
+	var outOfScope ErrOutOfScope
+	if !errors.As(err, &outOfScope) {
+		t.Fatalf("Error is not ErrOutOfScope: %+v", err)
+	}
+
+	expectedErr := ErrOutOfScope{Round: 300, LastChecked: 5, Window: 128}
+	if outOfScope != expectedErr {
+		t.Errorf("Unexpected ErrOutOfScope."+
+			"\nexpected: %+v\nreceived: %+v", expectedErr, outOfScope)
+	}
+
+	if string(kr.Marshal()) != string(expected) {
+		t.Error("KnownRounds modified by failed TryCheck.")
+	}
+}
+
+// Tests that KnownRounds.TryCheck never errors with the AutoForward policy.
+func TestKnownRounds_TryCheck_AutoForward(t *testing.T) {
+	kr := NewKnownRoundWithPolicy(128, AutoForward)
+	if err := kr.TryCheck(300); err != nil {
+		t.Errorf("TryCheck returned an error: %+v", err)
+	}
+	if !kr.Checked(300) {
+		t.Error("Round 300 not checked.")
+	}
+}
+
+// Tests that the error messages contain the fields of each error.
+func TestErrors_Error(t *testing.T) {
+	tests := []struct {
+		err      error
+		contains []string
+	}{
+		{ErrBufferTooSmall{Need: 150, Have: 64}, []string{"150", "64"}},
+		{ErrOutOfScope{Round: 300, LastChecked: 5, Window: 128},
+			[]string{"300", "5", "128"}},
+	}
+
+	for i, tt := range tests {
+		for _, s := range tt.contains {
+			if !strings.Contains(tt.err.Error(), s) {
+				t.Errorf("Error %q does not contain %q (%d).",
+					tt.err.Error(), s, i)
+			}
+		}
+	}
+}
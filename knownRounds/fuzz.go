@@ -0,0 +1,110 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"github.com/pkg/errors"
+	jww "github.com/spf13/jwalterweatherman"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// maxFuzzOps is the maximum number of operations FuzzUnmarshal applies after
+// unmarshalling.
+const maxFuzzOps = 16
+
+// FuzzUnmarshal is a fuzzing entry point compatible with go-fuzz and oss-fuzz.
+// The first byte of data selects the number of operations, the following two
+// bytes per operation describe each operation, and the rest of the data is
+// passed to KnownRounds.Unmarshal. If unmarshalling succeeds, the operations
+// are applied and the invariants of KnownRounds are checked after each one.
+// Panics if an invariant does not hold. Returns 1 if the data unmarshalled
+// successfully and 0 otherwise.
+func FuzzUnmarshal(data []byte) int {
+	if len(data) < 1 {
+		return 0
+	}
+
+	numOps := int(data[0]) % (maxFuzzOps + 1)
+	if len(data) < 1+2*numOps {
+		return 0
+	}
+	ops, payload := data[1:1+2*numOps], data[1+2*numOps:]
+
+	kr := NewKnownRound(0)
+	if err := kr.Unmarshal(payload); err != nil {
+		return 0
+	}
+
+	if err := kr.checkInvariants(); err != nil {
+		jww.FATAL.Panicf("Invariant violated after Unmarshal: %+v", err)
+	}
+
+	for i := 0; i < len(ops); i += 2 {
+		// Pick a round around the window that does not wrap
+		offset := id.Round(ops[i+1]) * id.Round(kr.Len()) / 128
+		rid := kr.firstUnchecked + offset
+		if rid < kr.firstUnchecked {
+			continue
+		}
+
+		switch ops[i] % 3 {
+		case 0:
+			kr.ForceCheck(rid)
+		case 1:
+			kr.Forward(rid)
+		case 2:
+			kr.ExpireBefore(rid)
+		}
+
+		if err := kr.checkInvariants(); err != nil {
+			jww.FATAL.Panicf("Invariant violated after operation %d (%d on "+
+				"round %d): %+v", i/2, ops[i]%3, rid, err)
+		}
+	}
+
+	return 1
+}
+
+// checkInvariants returns an error if the KnownRounds is in an inconsistent
+// state or does not survive a Marshal and Unmarshal round trip.
+func (kr *KnownRounds) checkInvariants() error {
+	if kr.fuPos < 0 || kr.fuPos >= kr.Len() {
+		return errors.Errorf("fuPos %d outside of buffer of length %d",
+			kr.fuPos, kr.Len())
+	} else if kr.firstUnchecked > kr.lastChecked+1 {
+		return errors.Errorf("firstUnchecked %d more than one round after "+
+			"lastChecked %d", kr.firstUnchecked, kr.lastChecked)
+	} else if kr.firstUnchecked > 0 && !kr.Checked(kr.firstUnchecked-1) {
+		return errors.Errorf("round %d before firstUnchecked is unchecked",
+			kr.firstUnchecked-1)
+	} else if kr.firstUnchecked <= kr.lastChecked &&
+		kr.Checked(kr.firstUnchecked) {
+		return errors.Errorf("firstUnchecked %d is checked",
+			kr.firstUnchecked)
+	}
+
+	newKr := NewKnownRound(0)
+	if err := newKr.Unmarshal(kr.Marshal()); err != nil {
+		return errors.Wrap(err, "failed to unmarshal marshalled KnownRounds")
+	} else if newKr.firstUnchecked != kr.firstUnchecked ||
+		newKr.lastChecked != kr.lastChecked {
+		return errors.Errorf("round trip changed window from [%d, %d] to "+
+			"[%d, %d]", kr.firstUnchecked, kr.lastChecked,
+			newKr.firstUnchecked, newKr.lastChecked)
+	}
+
+	for rid := kr.firstUnchecked; rid <= kr.lastChecked; rid++ {
+		if kr.Checked(rid) != newKr.Checked(rid) {
+			return errors.Errorf("round trip changed checked state of "+
+				"round %d", rid)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"math/rand"
+	"testing"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// FuzzKnownRounds_Unmarshal is a native fuzz target wrapping FuzzUnmarshal. The
+// corpus is seeded with marshalled KnownRounds in a variety of states.
+func FuzzKnownRounds_Unmarshal(f *testing.F) {
+	prng := rand.New(rand.NewSource(42))
+	for i := 0; i < 8; i++ {
+		kr := NewKnownRound(64 * (i + 1))
+		kr.Forward(id.Round(prng.Intn(1000)))
+		for j := 0; j < prng.Intn(100); j++ {
+			kr.ForceCheck(kr.firstUnchecked + id.Round(prng.Intn(kr.Len())))
+		}
+
+		ops := make([]byte, 1+2*maxFuzzOps)
+		prng.Read(ops)
+		f.Add(append(ops, kr.Marshal()...))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		FuzzUnmarshal(data)
+	})
+}
+
+// Tests that FuzzUnmarshal accepts valid data and rejects malformed data.
+func TestFuzzUnmarshal(t *testing.T) {
+	kr := NewKnownRound(128)
+	kr.Check(5)
+	kr.Check(70)
+
+	if FuzzUnmarshal(append([]byte{0}, kr.Marshal()...)) != 1 {
+		t.Error("Valid data was rejected.")
+	}
+
+	if FuzzUnmarshal([]byte{2, 0, 1, 2, 3, 4}) != 0 {
+		t.Error("Malformed data was accepted.")
+	}
+}
+
+// Tests that Unmarshal rejects crafted data with an inconsistent window and
+// repairs a first unchecked round that is marked as checked.
+func TestKnownRounds_Unmarshal_Crafted(t *testing.T) {
+	// firstUnchecked more than one round after lastChecked
+	kr := NewKnownRound(64)
+	kr.Check(3)
+	data := kr.Marshal()
+	data[0] = 10
+	if err := NewKnownRound(0).Unmarshal(data); err == nil {
+		t.Error("Expected error for firstUnchecked after lastChecked.")
+	}
+
+	// Window larger than the bit stream
+	data = kr.Marshal()
+	data[9] = 1
+	if err := NewKnownRound(0).Unmarshal(data); err == nil {
+		t.Error("Expected error for window larger than the bit stream.")
+	}
+
+	// First unchecked marked as checked
+	kr = NewFromParts([]uint64{0xE000000000000000}, 0, 5, 0)
+	newKr := NewKnownRound(0)
+	if err := newKr.Unmarshal(kr.Marshal()); err != nil {
+		t.Fatalf("Failed to unmarshal: %+v", err)
+	}
+	if newKr.firstUnchecked != 3 {
+		t.Errorf("firstUnchecked not repaired.\nexpected: %d\nreceived: %d",
+			3, newKr.firstUnchecked)
+	}
+	if err := newKr.checkInvariants(); err != nil {
+		t.Errorf("Invariants do not hold after repair: %+v", err)
+	}
+}
+
+// Tests that a lastChecked falling at the start of a block survives a Marshal
+// and Unmarshal round trip.
+func TestKnownRounds_Marshal_LastCheckedBlockStart(t *testing.T) {
+	kr := NewKnownRound(128)
+	kr.Forward(1564)
+	kr.ForceCheck(1600)
+
+	newKr := NewKnownRound(0)
+	if err := newKr.Unmarshal(kr.Marshal()); err != nil {
+		t.Fatalf("Failed to unmarshal: %+v", err)
+	}
+	if !newKr.Checked(1600) {
+		t.Errorf("Round %d not checked after round trip.", 1600)
+	}
+}
+
+// Tests that unmarshal rejects truncated runs and runs that would exceed the
+// maximum bit stream length.
+func Test_unmarshal_BadRuns(t *testing.T) {
+	runs := [][]byte{
+		{2, 1, 0},
+		{2, 2, 0, 0, 0},
+		{2, 4, 0, 0, 0, 0, 0, 0},
+		{2, 4, 0, 0, 0, 0, 0xFF, 0xFF, 0xFF, 0xFF},
+		{2, 8, 0, 0, 0, 0, 0, 0, 0, 0, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+			0xFF, 0xFF},
+	}
+
+	for i, data := range runs {
+		if _, err := unmarshal(data); err == nil {
+			t.Errorf("Did not error for bad run (%d): %v", i, data)
+		}
+	}
+}
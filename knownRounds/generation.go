@@ -0,0 +1,74 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+// generationMagic prefixes the output of KnownRounds.MarshalWithGeneration.
+// Like compressedMagic, it starts with a firstUnchecked of math.MaxUint64,
+// which Unmarshal rejects, so it cannot be confused with the output of
+// KnownRounds.Marshal or KnownRounds.MarshalCompressed.
+var generationMagic = []byte{
+	0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 'K', 'G'}
+
+// generationLen is the length of the generation that follows generationMagic.
+const generationLen = 8
+
+// Generation returns the number of times the KnownRounds has been modified.
+// It starts at zero and increases by at least one on every change to which
+// rounds are checked or to the window, whether by Check, Forward, Merge, or
+// any method built on them. Unmarshalling data from MarshalWithGeneration
+// restores the marshalled generation; unmarshalling any other data counts as a
+// change.
+//
+// Consumers that receive KnownRounds from the same source, such as a gateway,
+// can compare generations to discard stale snapshots and order updates without
+// relying on wall clocks. Generations of KnownRounds from different sources
+// are unrelated.
+func (kr *KnownRounds) Generation() uint64 {
+	return kr.generation
+}
+
+// MarshalWithGeneration returns the output of KnownRounds.Marshal prefixed
+// with a magic value and the Generation so that KnownRounds.Unmarshal restores
+// it. Marshal itself never includes the generation because its output must
+// only depend on which rounds are checked.
+//
+//	+-----------------+------------+-----------------+
+//	| generationMagic | generation | Marshal output  |
+//	|    10 bytes     |  8 bytes   |    variable     |
+//	+-----------------+------------+-----------------+
+func (kr *KnownRounds) MarshalWithGeneration() []byte {
+	marshalled := kr.Marshal()
+	data := make([]byte, 0,
+		len(generationMagic)+generationLen+len(marshalled))
+	data = append(data, generationMagic...)
+	data = binary.LittleEndian.AppendUint64(data, kr.generation)
+	return append(data, marshalled...)
+}
+
+// splitGeneration returns the generation and the remaining data if the data
+// was produced by KnownRounds.MarshalWithGeneration. Otherwise, it returns the
+// data unchanged and false.
+func splitGeneration(data []byte) (uint64, []byte, bool, error) {
+	if !bytes.HasPrefix(data, generationMagic) {
+		return 0, data, false, nil
+	} else if len(data) < len(generationMagic)+generationLen {
+		return 0, nil, false, errors.Errorf("generation of %d bytes is "+
+			"shorter than %d bytes", len(data)-len(generationMagic),
+			generationLen)
+	}
+
+	data = data[len(generationMagic):]
+	return binary.LittleEndian.Uint64(data), data[generationLen:], true, nil
+}
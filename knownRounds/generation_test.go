@@ -0,0 +1,175 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"bytes"
+	"testing"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// Tests that KnownRounds.Generation increases on every modification and does
+// not change when a method leaves the KnownRounds as it was.
+func TestKnownRounds_Generation(t *testing.T) {
+	kr := NewKnownRound(128)
+	if kr.Generation() != 0 {
+		t.Errorf("New KnownRounds has generation %d.", kr.Generation())
+	}
+
+	tests := []struct {
+		name    string
+		modify  func()
+		changed bool
+	}{
+		{"Check", func() { kr.Check(5) }, true},
+		{"Check checked", func() { kr.Check(5) }, false},
+		{"Check before firstUnchecked", func() { kr.Check(0) }, true},
+		{"Check before firstUnchecked again", func() { kr.Check(0) }, false},
+		{"ForceCheck", func() { kr.ForceCheck(20) }, true},
+		{"Forward", func() { kr.Forward(10) }, true},
+		{"Forward behind", func() { kr.Forward(3) }, false},
+		{"ExpireBefore", func() { kr.ExpireBefore(15) }, true},
+		{"ExpireBefore behind", func() { kr.ExpireBefore(15) }, false},
+		{"Checked", func() { kr.Checked(20) }, false},
+		{"Marshal", func() { kr.Marshal() }, false},
+		{"Snapshot", func() { kr.Snapshot() }, false},
+		{"Merge", func() {
+			other := NewKnownRound(128)
+			other.Check(30)
+			if err := kr.Merge(other, MergeOr); err != nil {
+				t.Fatalf("Failed to merge: %+v", err)
+			}
+		}, true},
+		{"FromRoaringBytes", func() {
+			if err := kr.FromRoaringBytes(kr.ToRoaringBytes()); err != nil {
+				t.Fatalf("Failed to load roaring bytes: %+v", err)
+			}
+		}, true},
+		{"Unmarshal", func() {
+			if err := kr.Unmarshal(kr.Marshal()); err != nil {
+				t.Fatalf("Failed to unmarshal: %+v", err)
+			}
+		}, true},
+	}
+
+	for _, tt := range tests {
+		before := kr.Generation()
+		tt.modify()
+		if changed := kr.Generation() > before; changed != tt.changed {
+			t.Errorf("Unexpected generation change after %s."+
+				"\nexpected changed: %t\nbefore: %d\nafter: %d",
+				tt.name, tt.changed, before, kr.Generation())
+		}
+	}
+}
+
+// Tests that copies of a KnownRounds keep its generation and that modifying a
+// copy does not change the original's generation.
+func TestKnownRounds_Generation_Copies(t *testing.T) {
+	kr := NewKnownRound(128)
+	for rid := id.Round(0); rid < 50; rid += 2 {
+		kr.Check(rid)
+	}
+	generation := kr.Generation()
+
+	snapshot := kr.Snapshot()
+	for name, c := range map[string]*KnownRounds{
+		"Snapshot": snapshot, "deepCopy": kr.deepCopy(),
+		"Truncate": kr.Truncate(10),
+	} {
+		if c.Generation() != generation {
+			t.Errorf("%s did not keep the generation."+
+				"\nexpected: %d\nreceived: %d",
+				name, generation, c.Generation())
+		}
+	}
+
+	snapshot.Check(51)
+	if kr.Generation() != generation {
+		t.Errorf("Modifying snapshot changed the original's generation."+
+			"\nexpected: %d\nreceived: %d", generation, kr.Generation())
+	}
+}
+
+// Tests that a KnownRounds marshalled with KnownRounds.MarshalWithGeneration
+// and unmarshalled matches the original, including its generation, and that
+// the generation stamp only prefixes the output of KnownRounds.Marshal.
+func TestKnownRounds_MarshalWithGeneration(t *testing.T) {
+	kr := NewKnownRound(256)
+	kr.Forward(100)
+	for _, rid := range []id.Round{101, 103, 150, 200} {
+		kr.Check(rid)
+	}
+
+	data := kr.MarshalWithGeneration()
+	if !bytes.HasSuffix(data, kr.Marshal()) {
+		t.Errorf("Output does not end with the output of Marshal.")
+	}
+
+	newKR := NewKnownRound(256)
+	newKR.Check(5)
+	if err := newKR.Unmarshal(data); err != nil {
+		t.Fatalf("Failed to unmarshal: %+v", err)
+	}
+	if !bytes.Equal(kr.Marshal(), newKR.Marshal()) {
+		t.Errorf("Unmarshalled KnownRounds does not match original."+
+			"\nexpected: %+v\nreceived: %+v", kr, newKR)
+	}
+	if kr.Generation() != newKR.Generation() {
+		t.Errorf("Generation not restored.\nexpected: %d\nreceived: %d",
+			kr.Generation(), newKR.Generation())
+	}
+}
+
+// Tests that consumers can order two KnownRounds from the same source by
+// generation regardless of the order they were received in.
+func TestKnownRounds_MarshalWithGeneration_Ordering(t *testing.T) {
+	source := NewKnownRound(128)
+	source.Check(1)
+	older := source.MarshalWithGeneration()
+	source.Check(2)
+	newer := source.MarshalWithGeneration()
+
+	consumer := NewKnownRound(128)
+	for _, data := range [][]byte{newer, older} {
+		received := NewKnownRound(128)
+		if err := received.Unmarshal(data); err != nil {
+			t.Fatalf("Failed to unmarshal: %+v", err)
+		}
+		if received.Generation() > consumer.Generation() {
+			consumer = received
+		}
+	}
+
+	if !consumer.Checked(2) || consumer.Generation() != source.Generation() {
+		t.Errorf("Consumer kept stale KnownRounds with generation %d; "+
+			"expected generation %d.", consumer.Generation(),
+			source.Generation())
+	}
+}
+
+// Error path: Tests that KnownRounds.Unmarshal rejects a generation stamp that
+// is cut short and does not modify the generation.
+func TestKnownRounds_Unmarshal_ShortGeneration(t *testing.T) {
+	kr := NewKnownRound(128)
+	kr.Check(1)
+	data := kr.MarshalWithGeneration()
+
+	newKR := NewKnownRound(128)
+	for _, n := range []int{len(generationMagic),
+		len(generationMagic) + generationLen - 1} {
+		if err := newKR.Unmarshal(data[:n]); err == nil {
+			t.Errorf("No error for data of length %d.", n)
+		}
+		if newKR.Generation() != 0 {
+			t.Errorf("Failed Unmarshal changed generation to %d.",
+				newKR.Generation())
+		}
+	}
+}
@@ -0,0 +1,56 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// KnownRoundsReader is the read-only subset of KnownRounds that dependent
+// packages use to query round tracking. Accepting it instead of *KnownRounds
+// allows round tracking to be mocked in tests.
+type KnownRoundsReader interface {
+	// Checked determines if the round has been checked.
+	Checked(rid id.Round) bool
+
+	// GetFirstUnchecked returns the oldest round that has not been checked.
+	GetFirstUnchecked() id.Round
+
+	// GetLastChecked returns the newest round that has been checked.
+	GetLastChecked() id.Round
+
+	// RangeUnchecked runs roundCheck over the checked rounds from
+	// oldestUnknown to the last checked round. See KnownRounds.RangeUnchecked.
+	RangeUnchecked(oldestUnknown id.Round, threshold uint,
+		roundCheck func(id id.Round) bool, maxPickups int) (
+		earliestRound id.Round, has, unknown []id.Round)
+
+	// Marshal returns the serialised form of the rounds.
+	Marshal() []byte
+}
+
+// KnownRoundsWriter is the subset of KnownRounds that modifies which rounds are
+// checked.
+type KnownRoundsWriter interface {
+	// Check denotes a round has been checked.
+	Check(rid id.Round)
+
+	// Forward sets all rounds before the given round as checked.
+	Forward(rid id.Round)
+}
+
+// KnownRoundsReadWriter combines KnownRoundsReader and KnownRoundsWriter. Its
+// methods are a superset of the RoundTracker methods used for equivalence
+// testing, except for Len, which mocks are not expected to track.
+type KnownRoundsReadWriter interface {
+	KnownRoundsReader
+	KnownRoundsWriter
+}
+
+// Ensure KnownRounds implements the interfaces.
+var _ KnownRoundsReadWriter = (*KnownRounds)(nil)
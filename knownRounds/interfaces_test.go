@@ -0,0 +1,51 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"testing"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// mockReadWriter is a map-backed KnownRoundsReadWriter used to test that the
+// interfaces can be mocked without a bit stream.
+type mockReadWriter struct {
+	*ReferenceKnownRounds
+}
+
+func (m mockReadWriter) RangeUnchecked(id.Round, uint, func(id id.Round) bool,
+	int) (id.Round, []id.Round, []id.Round) {
+	return m.GetLastChecked() + 1, nil, nil
+}
+
+func (m mockReadWriter) Marshal() []byte { return nil }
+
+// Tests that both KnownRounds and a mock can be used through the
+// KnownRoundsReadWriter interface.
+func TestKnownRoundsReadWriter(t *testing.T) {
+	trackers := []KnownRoundsReadWriter{
+		NewKnownRound(64),
+		mockReadWriter{NewReferenceKnownRounds(64)},
+	}
+
+	for i, rw := range trackers {
+		var w KnownRoundsWriter = rw
+		w.Check(5)
+		w.Forward(3)
+
+		var r KnownRoundsReader = rw
+		if !r.Checked(2) || !r.Checked(5) || r.Checked(4) {
+			t.Errorf("Unexpected checked state (%d).", i)
+		}
+		if r.GetFirstUnchecked() != 3 || r.GetLastChecked() != 5 {
+			t.Errorf("Unexpected window (%d): [%d, %d]",
+				i, r.GetFirstUnchecked(), r.GetLastChecked())
+		}
+	}
+}
@@ -13,6 +13,8 @@ import (
 	"bytes"
 	"encoding/binary"
 	"math"
+	"math/bits"
+	"sync"
 
 	"github.com/pkg/errors"
 	jww "github.com/spf13/jwalterweatherman"
@@ -31,8 +33,43 @@ type KnownRounds struct {
 	firstUnchecked id.Round   // ID of the first round that us unchecked
 	lastChecked    id.Round   // ID of the last round that is checked
 	fuPos          int        // The bit position of firstUnchecked in bitStream
+
+	// Optional callback invoked when a round transitions to checked
+	onCheck func(rid id.Round)
+
+	policy        OverflowPolicy // Behaviour of Check when out of scope
+	autoDiscarded uint64         // Number of unchecked rounds auto-forwarded
+	generation    uint64         // Number of modifications; see Generation
+
+	// True when bitStream is shared with a Snapshot and must be copied before
+	// it is modified
+	shared bool
+
+	// True when bitStream was provided by the caller and must never be
+	// reallocated; see NewKnownRoundFromBuffer
+	fixedBuffer bool
+
+	// Lock held by CheckIfUnchecked. It is a pointer so that KnownRounds can
+	// still be copied by value; it is created by the constructors,
+	// Unmarshal, and FromRoaringBytes.
+	checkMux *sync.Mutex
 }
 
+// OverflowPolicy describes how Check behaves when a round is outside the
+// current scope of the bit stream.
+type OverflowPolicy uint8
+
+const (
+	// PanicOnOverflow causes Check to panic when a round is outside the scope.
+	// This is the default policy.
+	PanicOnOverflow OverflowPolicy = iota
+
+	// AutoForward causes Check to forward the window just far enough to hold
+	// the round. Any unchecked rounds that fall out of the window are
+	// discarded and counted; see KnownRounds.AutoDiscarded.
+	AutoForward
+)
+
 // DiskKnownRounds structure is used to as an intermediary to marshal and
 // unmarshal KnownRounds.
 type DiskKnownRounds struct {
@@ -48,6 +85,40 @@ func NewKnownRound(roundCapacity int) *KnownRounds {
 		firstUnchecked: 0,
 		lastChecked:    0,
 		fuPos:          0,
+		checkMux:       &sync.Mutex{},
+	}
+}
+
+// NewKnownRoundWithPolicy creates a new empty KnownRounds, like NewKnownRound,
+// that handles rounds outside the scope of the bit stream according to the
+// given OverflowPolicy.
+func NewKnownRoundWithPolicy(
+	roundCapacity int, policy OverflowPolicy) *KnownRounds {
+	kr := NewKnownRound(roundCapacity)
+	kr.policy = policy
+	return kr
+}
+
+// NewKnownRoundFromBuffer creates a new empty KnownRounds, like NewKnownRound,
+// that uses the given buffer as its bit stream. The buffer holds len(buff)*64
+// rounds and is cleared. It is never reallocated or replaced, so that callers,
+// such as WASM clients, can place it in a pre-allocated arena and avoid garbage
+// collection pressure. Methods that return new data, such as Marshal and
+// Snapshot, still allocate their results. The caller must not modify the buffer
+// while it is in use. Panics if the buffer is empty.
+func NewKnownRoundFromBuffer(buff []uint64) *KnownRounds {
+	if len(buff) == 0 {
+		jww.FATAL.Panicf("Cannot create KnownRounds from an empty buffer.")
+	}
+
+	for i := range buff {
+		buff[i] = 0
+	}
+
+	return &KnownRounds{
+		bitStream:   buff,
+		fixedBuffer: true,
+		checkMux:    &sync.Mutex{},
 	}
 }
 
@@ -60,23 +131,50 @@ func NewFromParts(
 		firstUnchecked: firstUnchecked,
 		lastChecked:    lastChecked,
 		fuPos:          fuPos,
+		checkMux:       &sync.Mutex{},
 	}
 }
 
 // Marshal returns the JSON encoding of DiskKnownRounds, which contains the
 // compressed information from KnownRounds. The bit stream is compressed such
-// that the firstUnchecked occurs in the first block of the bit stream.
+// that the firstUnchecked occurs in the first block of the bit stream at bit
+// firstUnchecked%64, regardless of where it is stored in the buffer, so that it
+// can be unmarshalled into a KnownRounds of any capacity that fits the rounds.
+//
+// The output is deterministic: it depends only on which rounds are checked and
+// not on the capacity of the buffer, where the window wraps in it, or the
+// history of operations. Downstream signatures are computed over the output,
+// so it must not change; see the golden fixtures in testdata. For the same
+// reason, the Generation is not included; use MarshalWithGeneration instead.
 func (kr *KnownRounds) Marshal() []byte {
-	// Calculate length of compressed bit stream.
-	startPos := kr.getBitStreamPos(kr.firstUnchecked)
-	endPos := kr.getBitStreamPos(kr.lastChecked)
+	// Calculate the positions of the window in the compressed bit stream,
+	// where firstUnchecked is always at bit firstUnchecked%64 of the first
+	// block, even if it is at a different bit in the buffer
+	startPos := int(kr.firstUnchecked % 64)
+	endPos := startPos
+	if kr.lastChecked >= kr.firstUnchecked {
+		// The end position passed to delta is exclusive and must not wrap so
+		// that a lastChecked at the start of a block is still included
+		endPos += int(kr.lastChecked-kr.firstUnchecked) + 1
+	}
 	length := kr.bitStream.delta(startPos, endPos)
 
-	// Copy only the blocks between firstUnchecked and lastChecked to the stream
-	startBlock, _ := kr.bitStream.convertLoc(startPos)
+	// Copy only the blocks between firstUnchecked and lastChecked to the
+	// stream, shifting them if the buffer is not aligned with the stream
+	buffStart := kr.getBitStreamPos(kr.firstUnchecked) - startPos + kr.Len()
 	bitStream := make(uint64Buff, length)
-	for i := 0; i < length; i++ {
-		bitStream[i] = kr.bitStream[(i+startBlock)%len(kr.bitStream)]
+	for i := range bitStream {
+		bitStream[i] = kr.bitStream.bits(buffStart + i*64)
+	}
+
+	// Set the bits outside the window to their implied values, checked before
+	// firstUnchecked and unchecked after lastChecked, so that the output only
+	// depends on which rounds are checked and not on stale data in the buffer
+	bitStream[0] |= ^(ones >> startPos)
+	if endPos == startPos {
+		bitStream[0] &= ^(ones >> startPos)
+	} else {
+		bitStream[length-1] &= ^(ones >> ((endPos-1)%64 + 1))
 	}
 
 	// Create new buffer
@@ -98,48 +196,148 @@ func (kr *KnownRounds) Marshal() []byte {
 	return buf.Bytes()
 }
 
-// Unmarshal parses the JSON-encoded data and stores it in the KnownRounds. An
-// error is returned if the bit stream data is larger than the KnownRounds bit
-// stream.
+// Unmarshal parses the JSON-encoded data and stores it in the KnownRounds. The
+// data may be unmarshalled into a KnownRounds of any capacity that holds the
+// rounds from firstUnchecked to lastChecked, which is independent of the
+// capacity it was marshalled from; otherwise, an error wrapping
+// ErrBufferTooSmall is returned. Data compressed with
+// KnownRounds.MarshalCompressed is detected and decompressed automatically.
+// Data produced by KnownRounds.MarshalWithGeneration restores the Generation;
+// any other data increments it.
 func (kr *KnownRounds) Unmarshal(data []byte) error {
-	buf := bytes.NewBuffer(data)
+	w, err := unmarshalWire(data)
+	if err != nil {
+		return errors.WithMessage(err, "KnownRounds Unmarshal")
+	}
 
-	if buf.Len() < 16 {
-		return errors.Errorf("KnownRounds Unmarshal: "+
-			"size of data %d < %d expected", buf.Len(), 16)
+	if kr.checkMux == nil {
+		kr.checkMux = &sync.Mutex{}
 	}
 
-	// Get firstUnchecked and lastChecked and calculate fuPos
-	kr.firstUnchecked = id.Round(binary.LittleEndian.Uint64(buf.Next(8)))
-	kr.lastChecked = id.Round(binary.LittleEndian.Uint64(buf.Next(8)))
+	kr.firstUnchecked, kr.lastChecked = w.firstUnchecked, w.lastChecked
 	kr.fuPos = int(kr.firstUnchecked % 64)
+	if len(w.bitStream) == 0 && len(kr.bitStream) == 0 {
+		return errors.New("KnownRounds Unmarshal: bit stream is empty")
+	}
 
-	// Unmarshal the bitStream from the rest of the bytes
-	bitStream, err := unmarshal(buf.Bytes())
-	if err != nil {
-		return errors.Errorf("Failed to unmarshal bitstream: %+v", err)
+	// The rounds from firstUnchecked to lastChecked must fit in the buffer,
+	// which is the received bit stream if there is no buffer
+	capacity := len(kr.bitStream) * 64
+	if capacity == 0 {
+		capacity = len(w.bitStream) * 64
+	}
+	if kr.lastChecked >= kr.firstUnchecked &&
+		uint64(kr.lastChecked-kr.firstUnchecked) >= uint64(capacity) {
+		need := uint64(kr.lastChecked-kr.firstUnchecked) + 1
+		return errors.WithMessagef(
+			ErrBufferTooSmall{Need: need, Have: uint64(capacity)},
+			"KnownRounds Unmarshal: %d rounds between firstUnchecked %d and "+
+				"lastChecked %d do not fit in bit stream of %d rounds",
+			need, kr.firstUnchecked, kr.lastChecked, capacity)
 	}
 
 	// Handle the copying in of the bit stream
 	if len(kr.bitStream) == 0 {
 		// If there is no bitstream, like in the wire representations, then make
 		// the size equal to what is coming in
-		kr.bitStream = bitStream
-	} else if len(kr.bitStream) >= len(bitStream) {
+		kr.bitStream = w.bitStream
+		kr.shared = false
+	} else if len(kr.bitStream) >= len(w.bitStream) {
 		// If a size already exists and the data fits within it, then copy it
 		// into the beginning of the buffer
-		copy(kr.bitStream, bitStream)
+		kr.ownBitStream()
+		copy(kr.bitStream, w.bitStream)
 	} else {
-		// If the passed in data is larger than the internal buffer, then return
-		// an error
-		return errors.Errorf("KnownRounds bitStream size of %d is too small "+
-			"for passed in bit stream of size %d.",
-			len(kr.bitStream), len(bitStream))
+		// If the passed in data spans more blocks than the internal buffer but
+		// the rounds fit, then wrap it around the buffer
+		kr.ownBitStream()
+		kr.bitStream.wrap(w.bitStream, kr.fuPos,
+			int(kr.lastChecked-kr.firstUnchecked)+1)
+	}
+
+	// A crafted or corrupted bit stream may mark firstUnchecked as checked;
+	// advance it to the actual first unchecked round to stay consistent
+	if kr.firstUnchecked <= kr.lastChecked && kr.Checked(kr.firstUnchecked) {
+		kr.migrateFirstUnchecked(kr.firstUnchecked)
+	}
+
+	if w.stamped {
+		kr.generation = w.generation
+	} else {
+		kr.generation++
 	}
 
 	return nil
 }
 
+// wireKnownRounds is the content of data produced by KnownRounds.Marshal.
+type wireKnownRounds struct {
+	// The blocks of the bit stream holding the rounds from firstUnchecked,
+	// which is at bit firstUnchecked%64 of the first block, to lastChecked
+	bitStream                   uint64Buff
+	firstUnchecked, lastChecked id.Round
+
+	// The generation and true if the data was produced by
+	// KnownRounds.MarshalWithGeneration
+	generation uint64
+	stamped    bool
+}
+
+// unmarshalWire parses data produced by KnownRounds.Marshal,
+// KnownRounds.MarshalWithGeneration, or KnownRounds.MarshalCompressed.
+func unmarshalWire(data []byte) (wireKnownRounds, error) {
+	var w wireKnownRounds
+	var err error
+	w.generation, data, w.stamped, err = splitGeneration(data)
+	if err != nil {
+		return wireKnownRounds{}, err
+	}
+
+	data, err = decompressKnownRounds(data)
+	if err != nil {
+		return wireKnownRounds{}, err
+	}
+	buf := bytes.NewBuffer(data)
+
+	if buf.Len() < 16 {
+		return wireKnownRounds{}, errors.Errorf(
+			"size of data %d < %d expected", buf.Len(), 16)
+	}
+
+	// Get firstUnchecked and lastChecked
+	w.firstUnchecked = id.Round(binary.LittleEndian.Uint64(buf.Next(8)))
+	w.lastChecked = id.Round(binary.LittleEndian.Uint64(buf.Next(8)))
+
+	if w.firstUnchecked > w.lastChecked+1 {
+		return wireKnownRounds{}, errors.Errorf("firstUnchecked %d is more "+
+			"than one round after lastChecked %d",
+			w.firstUnchecked, w.lastChecked)
+	}
+
+	// Unmarshal the bitStream from the rest of the bytes
+	w.bitStream, err = unmarshal(buf.Bytes())
+	if err != nil {
+		return wireKnownRounds{}, errors.Errorf(
+			"failed to unmarshal bitstream: %+v", err)
+	}
+
+	return w, nil
+}
+
+// GobEncode encodes the KnownRounds using the same format as
+// MarshalWithGeneration so that the Generation is preserved. This function
+// adheres to the gob.GobEncoder interface.
+func (kr *KnownRounds) GobEncode() ([]byte, error) {
+	return kr.MarshalWithGeneration(), nil
+}
+
+// GobDecode decodes data produced by GobEncode, Marshal, or
+// MarshalWithGeneration into the KnownRounds. This function adheres to the
+// gob.GobDecoder interface.
+func (kr *KnownRounds) GobDecode(data []byte) error {
+	return kr.Unmarshal(data)
+}
+
 // KrChanges map contains a list of changes between two KnownRounds bit streams.
 // The key is the index of the changed word and the value contains the change.
 type KrChanges map[int]uint64
@@ -191,17 +389,120 @@ func (kr *KnownRounds) Checked(rid id.Round) bool {
 	return kr.bitStream.get(pos)
 }
 
+// SetOnCheck registers a callback that is called each time a round transitions
+// from unchecked to checked via Check or ForceCheck. Rounds that are implicitly
+// marked as checked by Forward do not trigger the callback. Passing nil removes
+// any registered callback.
+func (kr *KnownRounds) SetOnCheck(onCheck func(rid id.Round)) {
+	kr.onCheck = onCheck
+}
+
 // Check denotes a round has been checked. If the passed in round occurred after
 // the last checked round, then every round between them is set as unchecked and
 // the passed in round becomes the last checked round. Will panic if the buffer
-// is not large enough to hold the current data and the new data
+// is not large enough to hold the current data and the new data, unless the
+// KnownRounds was created with the AutoForward policy, in which case the window
+// is forwarded to fit the new round. Use TryCheck to get an error instead.
 func (kr *KnownRounds) Check(rid id.Round) {
+	if err := kr.TryCheck(rid); err != nil {
+		jww.FATAL.Panicf("Cannot check a round outside the current scope. "+
+			"Scope is KnownRounds size more rounds than last checked. A call "+
+			"to Forward can be used to fix the scope: %+v", err)
+	}
+}
+
+// TryCheck checks the round like Check but returns ErrOutOfScope instead of
+// panicking if the round does not fit in the buffer. The KnownRounds is not
+// modified when an error is returned. With the AutoForward policy, it never
+// returns an error.
+func (kr *KnownRounds) TryCheck(rid id.Round) error {
 	if abs(int(kr.lastChecked-rid))/(len(kr.bitStream)*64) > 0 {
-		jww.FATAL.Panicf("Cannot check a round outside the current scope. " +
-			"Scope is KnownRounds size more rounds than last checked. A call " +
-			"to Forward can be used to fix the scope.")
+		if kr.policy != AutoForward {
+			return ErrOutOfScope{
+				Round:       rid,
+				LastChecked: kr.lastChecked,
+				Window:      kr.Len(),
+			}
+		}
+		kr.autoForward(rid)
 	}
+
 	kr.check(rid)
+	return nil
+}
+
+// CheckIfUnchecked checks the round, like Check, only if it is not already
+// checked. Returns true if this call marked the round as checked and false if
+// it was already checked. The test and the check are done under a lock owned by
+// the KnownRounds, so when concurrent workers pick up rounds with
+// CheckIfUnchecked, exactly one of them gets true for each round. The lock only
+// serialises calls to CheckIfUnchecked; all other methods must still be
+// synchronised by the caller. The OnCheck callback is called under the lock and
+// must not call CheckIfUnchecked.
+func (kr *KnownRounds) CheckIfUnchecked(rid id.Round) bool {
+	kr.checkMux.Lock()
+	defer kr.checkMux.Unlock()
+
+	if kr.Checked(rid) {
+		return false
+	}
+
+	kr.Check(rid)
+	return true
+}
+
+// AutoDiscarded returns the number of unchecked rounds that were discarded
+// when Check automatically forwarded the window under the AutoForward policy.
+func (kr *KnownRounds) AutoDiscarded() uint64 {
+	return kr.autoDiscarded
+}
+
+// autoForward forwards the window so that the given round is the last round
+// that fits in the bit stream and records the number of unchecked rounds that
+// were discarded.
+func (kr *KnownRounds) autoForward(rid id.Round) {
+	if rid < id.Round(kr.Len()) {
+		return
+	}
+
+	newFirst := rid + 1 - id.Round(kr.Len())
+	if newFirst <= kr.firstUnchecked {
+		return
+	}
+
+	kr.autoDiscarded += kr.countUnchecked(kr.firstUnchecked, newFirst)
+	kr.Forward(newFirst)
+}
+
+// countUnchecked returns the number of unchecked rounds from start up to, but
+// not including, end. All rounds after lastChecked are counted as unchecked.
+// The bit stream is read a word at a time so that large ranges, such as when
+// forwarding thousands of rounds, are counted quickly.
+func (kr *KnownRounds) countUnchecked(start, end id.Round) uint64 {
+	if start < kr.firstUnchecked {
+		start = kr.firstUnchecked
+	}
+
+	var count uint64
+	for rid := start; rid < end; {
+		if rid > kr.lastChecked {
+			return count + uint64(end-rid)
+		}
+
+		// Count the unchecked rounds in the next word, excluding any rounds
+		// after the end of the range or after lastChecked
+		n := uint64(64)
+		if uint64(end-rid) < n {
+			n = uint64(end - rid)
+		}
+		if uint64(kr.lastChecked-rid) < n {
+			n = uint64(kr.lastChecked-rid) + 1
+		}
+		unchecked := ^kr.bitStream.bits(kr.getBitStreamPos(rid))
+		count += uint64(bits.OnesCount64(unchecked >> (64 - n)))
+		rid += id.Round(n)
+	}
+	return count
 }
 
 func (kr *KnownRounds) ForceCheck(rid id.Round) {
@@ -224,7 +525,9 @@ func (kr *KnownRounds) check(rid id.Round) {
 	if rid < kr.firstUnchecked {
 		return
 	}
+	wasChecked := kr.Checked(rid)
 	pos := kr.getBitStreamPos(rid)
+	kr.ownBitStream()
 
 	// Set round as checked
 	kr.bitStream.set(pos)
@@ -257,6 +560,13 @@ func (kr *KnownRounds) check(rid id.Round) {
 
 	// Set round as checked
 	kr.bitStream.set(pos)
+
+	if !wasChecked {
+		kr.generation++
+		if kr.onCheck != nil {
+			kr.onCheck(rid)
+		}
+	}
 }
 
 // abs returns the absolute value of the passed in integer.
@@ -267,10 +577,24 @@ func abs(n int) int {
 	return n
 }
 
-// migrateFirstUnchecked moves firstUnchecked to the next unchecked round or
-// sets it to lastUnchecked if all rounds are checked.
+// migrateFirstUnchecked moves firstUnchecked to the first unchecked round at
+// or after rid or to the round after lastChecked if all rounds are checked.
+// The bit stream is scanned a word at a time so that long runs of checked
+// rounds, such as when forwarding thousands of rounds, are skipped quickly.
 func (kr *KnownRounds) migrateFirstUnchecked(rid id.Round) {
-	for ; kr.bitStream.get(kr.getBitStreamPos(rid)) && rid <= kr.lastChecked; rid++ {
+	for rid <= kr.lastChecked {
+		// Number of checked rounds at the start of the next word
+		word := kr.bitStream.bits(kr.getBitStreamPos(rid))
+		checked := bits.LeadingZeros64(^word)
+		if uint64(checked) > uint64(kr.lastChecked-rid) {
+			rid = kr.lastChecked + 1
+			break
+		}
+
+		rid += id.Round(checked)
+		if checked < 64 {
+			break
+		}
 	}
 	kr.fuPos = kr.getBitStreamPos(rid)
 	kr.firstUnchecked = rid
@@ -278,22 +602,53 @@ func (kr *KnownRounds) migrateFirstUnchecked(rid id.Round) {
 
 // Forward sets all rounds before the given round ID as checked.
 func (kr *KnownRounds) Forward(rid id.Round) {
+	if rid > kr.firstUnchecked {
+		kr.generation++
+	}
+
 	if rid > kr.lastChecked {
 		kr.firstUnchecked = rid
 		kr.lastChecked = rid
 		kr.fuPos = int(rid % 64)
+		// Clear any stale data left at the new position so that the new first
+		// unchecked round is not reported as checked
+		kr.ownBitStream()
+		kr.bitStream.clear(kr.fuPos)
 	} else if rid > kr.firstUnchecked {
 		kr.migrateFirstUnchecked(rid)
 	}
 }
 
+// ExpireBefore drops all rounds before the given round from the tracked window
+// so that they are treated as checked. It is intended for retention policies
+// where old rounds are no longer of interest, whether they were checked or not.
+// Returns the number of rounds that expired without having been checked.
+func (kr *KnownRounds) ExpireBefore(rid id.Round) uint64 {
+	if rid <= kr.firstUnchecked {
+		return 0
+	}
+
+	expired := kr.countUnchecked(kr.firstUnchecked, rid)
+	kr.Forward(rid)
+	return expired
+}
+
 // RangeUnchecked runs the passed function over all rounds starting with oldest
 // unknown and ending with
 func (kr *KnownRounds) RangeUnchecked(oldestUnknown id.Round, threshold uint,
 	roundCheck func(id id.Round) bool, maxPickups int) (
 	earliestRound id.Round, has, unknown []id.Round) {
+	return rangeUnchecked(kr.Checked, kr.lastChecked, oldestUnknown, threshold,
+		roundCheck, maxPickups)
+}
+
+// rangeUnchecked implements KnownRounds.RangeUnchecked for any round tracker
+// given its Checked function and last checked round.
+func rangeUnchecked(checked func(rid id.Round) bool, lastChecked,
+	oldestUnknown id.Round, threshold uint, roundCheck func(id id.Round) bool,
+	maxPickups int) (earliestRound id.Round, has, unknown []id.Round) {
 
-	newestRound := kr.lastChecked
+	newestRound := lastChecked
 
 	// Calculate how far back we should go back to check rounds
 	// If the newest round is smaller than the threshold, then our oldest round
@@ -303,25 +658,25 @@ func (kr *KnownRounds) RangeUnchecked(oldestUnknown id.Round, threshold uint,
 		oldestPossibleEarliestRound = newestRound - id.Round(threshold)
 	}
 
-	earliestRound = kr.lastChecked + 1
+	earliestRound = lastChecked + 1
 	has = make([]id.Round, 0, maxPickups)
 
 	// If the oldest unknown round is outside the range we are attempting to
 	// check, then skip checking
-	if oldestUnknown > kr.lastChecked {
+	if oldestUnknown > lastChecked {
 		jww.TRACE.Printf(
-			"RangeUnchecked: oldestUnknown (%d) > kr.lastChecked (%d)",
-			oldestUnknown, kr.lastChecked)
+			"RangeUnchecked: oldestUnknown (%d) > lastChecked (%d)",
+			oldestUnknown, lastChecked)
 		return oldestUnknown, nil, nil
 	}
 
 	// Loop through all rounds from the oldest unknown to the last checked round
 	// and check them, if possible
-	for i := oldestUnknown; i <= kr.lastChecked; i++ {
+	for i := oldestUnknown; i <= lastChecked; i++ {
 
 		// If the source does not know about the round, set that round as
 		// unknown and don't check it
-		if !kr.Checked(i) {
+		if !checked(i) {
 			if i < oldestPossibleEarliestRound {
 				unknown = append(unknown, i)
 			} else if i < earliestRound {
@@ -362,6 +717,8 @@ func (kr *KnownRounds) RangeUncheckedMasked(mask *KnownRounds,
 // RangeUncheckedMaskedRange masks the bit stream with the provided mask.
 func (kr *KnownRounds) RangeUncheckedMaskedRange(mask *KnownRounds,
 	roundCheck RoundCheckFunc, start, end id.Round, maxChecked int) {
+	before := traceCopy(kr)
+	defer traceChange("RangeUncheckedMaskedRange", before, kr)
 
 	numChecked := 0
 
@@ -393,6 +750,31 @@ func (kr *KnownRounds) RangeUncheckedMaskedRange(mask *KnownRounds,
 	}
 }
 
+// ApplyBloom marks every unchecked round from start up to, but not including,
+// end as checked if the bloom filter indicates the round holds nothing for the
+// client. The test function must return true if the round may be in the
+// filter. Rounds before firstUnchecked are skipped. Returns the number of
+// rounds that were checked.
+//
+// Rounds are checked with Check, so the range must be within the scope of the
+// KnownRounds unless it was created with the AutoForward policy.
+func (kr *KnownRounds) ApplyBloom(filter []byte, start, end id.Round,
+	test func(filter []byte, rid id.Round) bool) int {
+	if start < kr.firstUnchecked {
+		start = kr.firstUnchecked
+	}
+
+	var numChecked int
+	for rid := start; rid < end; rid++ {
+		if !kr.Checked(rid) && !test(filter, rid) {
+			kr.Check(rid)
+			numChecked++
+		}
+	}
+
+	return numChecked
+}
+
 // subSample returns a sub sample of the KnownRounds buffer from the start to
 // end round and its length.
 func (kr *KnownRounds) subSample(start, end id.Round) (uint64Buff, int) {
@@ -429,6 +811,8 @@ func (kr *KnownRounds) Truncate(start id.Round) *KnownRounds {
 		firstUnchecked: kr.firstUnchecked,
 		lastChecked:    kr.lastChecked,
 		fuPos:          kr.fuPos,
+		generation:     kr.generation,
+		checkMux:       &sync.Mutex{},
 	}
 
 	newKr.migrateFirstUnchecked(start)
@@ -436,6 +820,199 @@ func (kr *KnownRounds) Truncate(start id.Round) *KnownRounds {
 	return newKr
 }
 
+// LastChecked returns up to n of the most recently checked rounds, newest
+// first. The bit stream is scanned a word at a time from the last checked round
+// backwards; once the buffer is exhausted, the rounds before the first
+// unchecked round, which are all implicitly checked, are returned down to round
+// zero.
+func (kr *KnownRounds) LastChecked(n int) []id.Round {
+	if n <= 0 {
+		return nil
+	}
+
+	rounds := make([]id.Round, 0, n)
+
+	// Number of rounds in the buffer from rid back to firstUnchecked
+	var remaining int
+	if kr.lastChecked >= kr.firstUnchecked {
+		remaining = int(kr.lastChecked-kr.firstUnchecked) + 1
+	}
+
+	rid := kr.lastChecked
+	for remaining > 0 && len(rounds) < n {
+		pos := kr.getBitStreamPos(rid)
+		offset := pos % 64
+
+		// Shift the word so that bit i is the round i before rid and mask out
+		// bits beyond the start of the word or the buffer
+		span := offset + 1
+		if span > remaining {
+			span = remaining
+		}
+		word := kr.bitStream[pos/64] >> (63 - offset)
+		if span < 64 {
+			word &= (1 << span) - 1
+		}
+
+		if word == 0 {
+			rid -= id.Round(span)
+			remaining -= span
+			continue
+		}
+
+		skip := bits.TrailingZeros64(word)
+		rounds = append(rounds, rid-id.Round(skip))
+		rid -= id.Round(skip + 1)
+		remaining -= skip + 1
+	}
+
+	for rid = kr.firstUnchecked; rid > 0 && len(rounds) < n; rid-- {
+		rounds = append(rounds, rid-1)
+	}
+
+	return rounds
+}
+
+// NextUnchecked returns the first unchecked round strictly after the given
+// round. The bit stream is scanned a word at a time. All rounds after the last
+// checked round are unchecked, so if every round after the given round up to
+// and including the last checked round is checked, the round after the later
+// of the two is returned. Returns false only if there is no round after the
+// given round, i.e., it is the largest possible round.
+func (kr *KnownRounds) NextUnchecked(after id.Round) (id.Round, bool) {
+	rid := after + 1
+	if rid <= after {
+		return 0, false
+	} else if rid > kr.lastChecked {
+		return rid, true
+	} else if rid < kr.firstUnchecked {
+		rid = kr.firstUnchecked
+	}
+
+	remaining := int(kr.lastChecked-rid) + 1
+	for remaining > 0 {
+		pos := kr.getBitStreamPos(rid)
+		offset := pos % 64
+
+		// Invert the word so that unchecked rounds are ones, shift it so that
+		// rid is the most significant bit, and mask out bits beyond the end of
+		// the word or the buffer
+		span := 64 - offset
+		if span > remaining {
+			span = remaining
+		}
+		word := ^kr.bitStream[pos/64] << offset
+		if span < 64 {
+			word &= ^(ones >> span)
+		}
+
+		if word != 0 {
+			return rid + id.Round(bits.LeadingZeros64(word)), true
+		}
+
+		rid += id.Round(span)
+		remaining -= span
+	}
+
+	// The round after lastChecked is unchecked, unless it overflows
+	if kr.lastChecked+1 == 0 {
+		return 0, false
+	}
+	return kr.lastChecked + 1, true
+}
+
+// LargestUncheckedGap returns the first and last round of the longest run of
+// consecutive unchecked rounds between firstUnchecked and lastChecked, which
+// is useful for diagnosing stalled pickups. If there are multiple runs of the
+// same length, the earliest is returned. If every round in the window is
+// checked, then end is before start.
+func (kr *KnownRounds) LargestUncheckedGap() (start, end id.Round) {
+	if kr.lastChecked < kr.firstUnchecked {
+		return kr.firstUnchecked, kr.firstUnchecked - 1
+	}
+
+	var bestLen, runLen uint64
+	var runStart id.Round
+	pos := kr.getBitStreamPos(kr.firstUnchecked)
+	for i := uint64(0); i <= uint64(kr.lastChecked-kr.firstUnchecked); i++ {
+		rid := kr.firstUnchecked + id.Round(i)
+		if kr.bitStream.get(pos) {
+			runLen = 0
+		} else {
+			if runLen == 0 {
+				runStart = rid
+			}
+			runLen++
+			if runLen > bestLen {
+				bestLen, start, end = runLen, runStart, rid
+			}
+		}
+		pos = (pos + 1) % kr.Len()
+	}
+
+	if bestLen == 0 {
+		return kr.firstUnchecked, kr.firstUnchecked - 1
+	}
+	return start, end
+}
+
+// Progress returns the fraction of rounds, from the first round (round 1) up to
+// and including networkLastRound, that are checked. It is intended for sync
+// progress indicators. Rounds after lastChecked are unchecked, so a KnownRounds
+// with nothing checked has a progress of 0. A network head of 0 or one that is
+// behind firstUnchecked has nothing left to check and has a progress of 1.
+func (kr *KnownRounds) Progress(networkLastRound id.Round) float64 {
+	start := kr.firstUnchecked
+	if start < 1 {
+		start = 1
+	}
+	if networkLastRound < start {
+		return 1
+	}
+
+	unchecked := kr.countUnchecked(start, networkLastRound+1)
+	return 1 - float64(unchecked)/float64(networkLastRound)
+}
+
+// Snapshot returns a copy of the KnownRounds that shares the bit stream with
+// the original until either of them is next modified, at which point the
+// modified one copies the bit stream first. This makes taking a snapshot cheap
+// so that it can, for example, be marshalled outside the lock protecting the
+// original. Like every other method, Snapshot must be synchronised with
+// changes to the original. The OnCheck callback is not copied.
+//
+// If the KnownRounds uses a caller-provided buffer, the snapshot gets its own
+// copy of the bit stream instead so that the buffer is never reallocated.
+func (kr *KnownRounds) Snapshot() *KnownRounds {
+	bitStream := kr.bitStream
+	if kr.fixedBuffer {
+		bitStream = bitStream.deepCopy()
+	} else {
+		kr.shared = true
+	}
+
+	return &KnownRounds{
+		bitStream:      bitStream,
+		firstUnchecked: kr.firstUnchecked,
+		lastChecked:    kr.lastChecked,
+		fuPos:          kr.fuPos,
+		policy:         kr.policy,
+		autoDiscarded:  kr.autoDiscarded,
+		generation:     kr.generation,
+		shared:         !kr.fixedBuffer,
+		checkMux:       &sync.Mutex{},
+	}
+}
+
+// ownBitStream copies the bit stream if it is shared with a snapshot so that it
+// can be safely modified.
+func (kr *KnownRounds) ownBitStream() {
+	if kr.shared {
+		kr.bitStream = kr.bitStream.deepCopy()
+		kr.shared = false
+	}
+}
+
 // Get the position of the bit in the bit stream for the given round ID.
 func (kr *KnownRounds) getBitStreamPos(rid id.Round) int {
 	var delta int
@@ -9,11 +9,17 @@ package knownRounds
 
 import (
 	"bytes"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
+	"os"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 
 	"gitlab.com/xx_network/primitives/id"
@@ -26,6 +32,7 @@ func TestNewKnownRound(t *testing.T) {
 		firstUnchecked: 0,
 		lastChecked:    0,
 		fuPos:          0,
+		checkMux:       &sync.Mutex{},
 	}
 
 	testKR := NewKnownRound(310)
@@ -44,6 +51,7 @@ func TestNewFromParts(t *testing.T) {
 		firstUnchecked: 75,
 		lastChecked:    150,
 		fuPos:          75,
+		checkMux:       &sync.Mutex{},
 	}
 
 	received := NewFromParts(expected.bitStream, expected.firstUnchecked,
@@ -58,10 +66,12 @@ func TestNewFromParts(t *testing.T) {
 // Tests happy path of KnownRounds.Marshal.
 func TestKnownRounds_Marshal_Unmarshal(t *testing.T) {
 	testKR := &KnownRounds{
-		bitStream:      uint64Buff{0, math.MaxUint64, 0, math.MaxUint64, 0},
+		bitStream: uint64Buff{math.MaxUint64 - math.MaxUint64>>55,
+			math.MaxUint64, 0, math.MaxUint64, 0},
 		firstUnchecked: 55,
 		lastChecked:    270,
 		fuPos:          55,
+		checkMux:       &sync.Mutex{},
 	}
 
 	data := testKR.Marshal()
@@ -72,6 +82,8 @@ func TestKnownRounds_Marshal_Unmarshal(t *testing.T) {
 		t.Errorf("Unmarshal produced an error: %+v", err)
 	}
 
+	// Unmarshalling data without a generation counts as a modification
+	testKR.generation = 1
 	if !reflect.DeepEqual(testKR, newKR) {
 		t.Errorf("Original KnownRounds does not match Unmarshalled."+
 			"\nexpected: %+v\nreceived: %+v", testKR, newKR)
@@ -102,10 +114,12 @@ func TestKnownRounds_Marshal(t *testing.T) {
 // Tests happy path of KnownRounds.Unmarshal.
 func TestKnownRounds_Unmarshal(t *testing.T) {
 	testKR := &KnownRounds{
-		bitStream:      uint64Buff{0, math.MaxUint64, 0, 0, 0},
+		bitStream: uint64Buff{math.MaxUint64 - math.MaxUint64>>11,
+			math.MaxUint64 - math.MaxUint64>>23, 0, 0, 0},
 		firstUnchecked: 75,
 		lastChecked:    150,
 		fuPos:          11,
+		checkMux:       &sync.Mutex{},
 	}
 
 	data := testKR.Marshal()
@@ -117,6 +131,8 @@ func TestKnownRounds_Unmarshal(t *testing.T) {
 			"\nexpected: %+v\nreceived: %+v", nil, err)
 	}
 
+	// Unmarshalling data without a generation counts as a modification
+	testKR.generation = 1
 	if !reflect.DeepEqual(newKR, testKR) {
 		t.Errorf("Unmarshal produced an incorrect KnownRounds from the data."+
 			"\nexpected: %v\nreceived: %v", testKR, newKR)
@@ -143,6 +159,124 @@ func TestKnownRounds_Unmarshal_SizeError(t *testing.T) {
 	}
 }
 
+// Tests that a KnownRounds marshalled from any capacity and position of
+// firstUnchecked in the buffer, including positions not aligned with
+// firstUnchecked%64, unmarshalls into every capacity that fits its rounds with
+// every round in the same state. Also tests that the marshalled data does not
+// depend on the sender's capacity or alignment and that capacities too small
+// for the rounds return ErrBufferTooSmall.
+func TestKnownRounds_Marshal_Unmarshal_CrossCapacity(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	senderCapacities := []int{64, 128, 200, 1024}
+	receiverCapacities := []int{0, 64, 128, 192, 256, 2048}
+	windows := []int{1, 2, 10, 63, 64, 65, 127, 128, 129, 640}
+	firstUncheckedList := []id.Round{0, 5, 63, 64, 1000003}
+
+	for _, window := range windows {
+		for _, fu := range firstUncheckedList {
+			// firstUnchecked is unchecked and lastChecked, if it is after it,
+			// is checked
+			checked := make([]bool, window)
+			for i := 1; i < window; i++ {
+				checked[i] = i == window-1 || prng.Intn(2) == 0
+			}
+			lc := fu + id.Round(window-1)
+
+			var expected []byte
+			for _, senderCap := range senderCapacities {
+				if window > senderCap {
+					continue
+				}
+
+				buffLen := (senderCap + 63) / 64 * 64
+				for _, fuPos := range []int{int(fu % 64), 0, 1, 31, 63,
+					buffLen - 1, prng.Intn(buffLen)} {
+					sender := newCrossCapacityKR(
+						senderCap, fu, lc, fuPos, checked)
+					data := sender.Marshal()
+					if expected == nil {
+						expected = data
+					} else if !bytes.Equal(expected, data) {
+						t.Errorf("Marshal depends on capacity or alignment "+
+							"(window=%d fu=%d cap=%d fuPos=%d).",
+							window, fu, senderCap, fuPos)
+					}
+
+					for _, receiverCap := range receiverCapacities {
+						checkCrossCapacity(sender, data, receiverCap, t)
+					}
+				}
+			}
+		}
+	}
+}
+
+// newCrossCapacityKR creates a KnownRounds of the given capacity with
+// firstUnchecked stored at fuPos in the buffer and the rounds from
+// firstUnchecked to lastChecked checked as described by the checked list.
+func newCrossCapacityKR(capacity int, fu, lc id.Round, fuPos int,
+	checked []bool) *KnownRounds {
+	kr := NewKnownRound(capacity)
+	kr.firstUnchecked, kr.lastChecked, kr.fuPos = fu, lc, fuPos
+
+	// Fill the buffer with stale data that must not be marshalled
+	for i := range kr.bitStream {
+		kr.bitStream[i] = 0xA5A5A5A5A5A5A5A5
+	}
+	for i, c := range checked {
+		if c {
+			kr.bitStream.set(kr.getBitStreamPos(fu + id.Round(i)))
+		} else {
+			kr.bitStream.clear(kr.getBitStreamPos(fu + id.Round(i)))
+		}
+	}
+
+	return kr
+}
+
+// checkCrossCapacity unmarshalls the data into a KnownRounds of the receiver
+// capacity, or one with no buffer if the capacity is zero, and checks that it
+// matches the sender or returns ErrBufferTooSmall if the rounds do not fit.
+func checkCrossCapacity(
+	sender *KnownRounds, data []byte, receiverCap int, t *testing.T) {
+	receiver := &KnownRounds{}
+	if receiverCap > 0 {
+		receiver = NewKnownRound(receiverCap)
+	}
+	window := int(sender.lastChecked-sender.firstUnchecked) + 1
+	desc := fmt.Sprintf("window=%d fu=%d cap=%d fuPos=%d receiver=%d",
+		window, sender.firstUnchecked, sender.Len(), sender.fuPos, receiverCap)
+
+	err := receiver.Unmarshal(data)
+	if receiverCap > 0 && window > receiver.Len() {
+		var tooSmall ErrBufferTooSmall
+		if !errors.As(err, &tooSmall) {
+			t.Errorf("Expected ErrBufferTooSmall (%s): %+v", desc, err)
+		}
+		return
+	} else if err != nil {
+		t.Errorf("Failed to unmarshal (%s): %+v", desc, err)
+		return
+	}
+
+	start := sender.firstUnchecked
+	if start > 3 {
+		start -= 3
+	}
+	for rid := start; rid <= sender.lastChecked+3; rid++ {
+		if sender.Checked(rid) != receiver.Checked(rid) {
+			t.Errorf("Round %d state not preserved (%s).\nexpected: %t"+
+				"\nreceived: %t", rid, desc, sender.Checked(rid),
+				receiver.Checked(rid))
+			return
+		}
+	}
+
+	if !bytes.Equal(data, receiver.Marshal()) {
+		t.Errorf("Remarshalled data does not match (%s).", desc)
+	}
+}
+
 // Tests that KnownRounds.Unmarshal errors when given invalid JSON data.
 func TestKnownRounds_Unmarshal_JsonError(t *testing.T) {
 	newKR := NewKnownRound(1)
@@ -152,6 +286,38 @@ func TestKnownRounds_Unmarshal_JsonError(t *testing.T) {
 	}
 }
 
+// Tests that a KnownRounds encoded via gob and decoded matches the original
+// when embedded in another structure.
+func TestKnownRounds_GobEncode_GobDecode(t *testing.T) {
+	type wrapper struct {
+		Name string
+		KR   *KnownRounds
+	}
+	expected := wrapper{"test", &KnownRounds{
+		bitStream: uint64Buff{math.MaxUint64 - math.MaxUint64>>55,
+			math.MaxUint64, 0, math.MaxUint64, 0},
+		firstUnchecked: 55,
+		lastChecked:    270,
+		fuPos:          55,
+		checkMux:       &sync.Mutex{},
+	}}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(expected); err != nil {
+		t.Fatalf("Failed to gob encode: %+v", err)
+	}
+
+	var received wrapper
+	if err := gob.NewDecoder(&buf).Decode(&received); err != nil {
+		t.Fatalf("Failed to gob decode: %+v", err)
+	}
+
+	if !reflect.DeepEqual(expected, received) {
+		t.Errorf("Decoded KnownRounds does not match original."+
+			"\nexpected: %+v\nreceived: %+v", expected.KR, received.KR)
+	}
+}
+
 // Happy path.
 func TestKnownRounds_OutputBuffChanges(t *testing.T) {
 	// Generate test round IDs and expected buffers
@@ -161,23 +327,28 @@ func TestKnownRounds_OutputBuffChanges(t *testing.T) {
 		old     []uint64
 		changes KrChanges
 	}{{
-		current: KnownRounds{uint64Buff{}, 75, 320, 75},
+		current: KnownRounds{bitStream: uint64Buff{},
+			firstUnchecked: 75, lastChecked: 320, fuPos: 75},
 		old:     []uint64{},
 		changes: KrChanges{},
 	}, {
-		current: KnownRounds{uint64Buff{0, max, 0, max, 0}, 75, 320, 75},
+		current: KnownRounds{bitStream: uint64Buff{0, max, 0, max, 0},
+			firstUnchecked: 75, lastChecked: 320, fuPos: 75},
 		old:     []uint64{0, max, 0, max, 0},
 		changes: KrChanges{},
 	}, {
-		current: KnownRounds{uint64Buff{0, max, 0, max, 0}, 75, 320, 75},
+		current: KnownRounds{bitStream: uint64Buff{0, max, 0, max, 0},
+			firstUnchecked: 75, lastChecked: 320, fuPos: 75},
 		old:     []uint64{0, max, 0, max, 0},
 		changes: KrChanges{},
 	}, {
-		current: KnownRounds{uint64Buff{1, max, 0, max, 0}, 75, 320, 75},
+		current: KnownRounds{bitStream: uint64Buff{1, max, 0, max, 0},
+			firstUnchecked: 75, lastChecked: 320, fuPos: 75},
 		old:     []uint64{0, max, 0, max, 0},
 		changes: KrChanges{0: 1},
 	}, {
-		current: KnownRounds{uint64Buff{0, max, 0, max, 0}, 75, 320, 75},
+		current: KnownRounds{bitStream: uint64Buff{0, max, 0, max, 0},
+			firstUnchecked: 75, lastChecked: 320, fuPos: 75},
 		old:     []uint64{max, 0, max, 0, max},
 		changes: KrChanges{0: 0, 1: max, 2: 0, 3: max, 4: 0},
 	}}
@@ -221,11 +392,13 @@ func TestKnownRounds_OutputBuffChanges_IncorrectLengthError(t *testing.T) {
 		current KnownRounds
 		old     []uint64
 	}{{
-		current: KnownRounds{uint64Buff{0, max, 0, max, 0}, 75, 320, 75},
-		old:     []uint64{0, max, 0},
+		current: KnownRounds{bitStream: uint64Buff{0, max, 0, max, 0},
+			firstUnchecked: 75, lastChecked: 320, fuPos: 75},
+		old: []uint64{0, max, 0},
 	}, {
-		current: KnownRounds{uint64Buff{0, max, 0}, 75, 320, 75},
-		old:     []uint64{0, max, 0, max, 0},
+		current: KnownRounds{bitStream: uint64Buff{0, max, 0},
+			firstUnchecked: 75, lastChecked: 320, fuPos: 75},
+		old: []uint64{0, max, 0, max, 0},
 	}}
 
 	expectedErr := "not the same as length of the current buffer"
@@ -370,6 +543,67 @@ func TestKnownRounds_Check_NewKR(t *testing.T) {
 	}
 }
 
+// Tests that a KnownRounds created with the AutoForward policy forwards the
+// window instead of panicking and counts the discarded unchecked rounds.
+func TestKnownRounds_Check_AutoForward(t *testing.T) {
+	kr := NewKnownRoundWithPolicy(128, AutoForward)
+	kr.Check(0)
+	kr.Check(2)
+	kr.Check(100)
+
+	// Rounds 1, 3-99, and 101-172 are unchecked and fall out of the window
+	kr.Check(300)
+
+	if kr.AutoDiscarded() != 1+97+72 {
+		t.Errorf("Unexpected number of discarded rounds."+
+			"\nexpected: %d\nreceived: %d", 1+97+72, kr.AutoDiscarded())
+	}
+
+	if kr.firstUnchecked != 173 || !kr.Checked(300) || kr.Checked(299) {
+		t.Errorf("Unexpected window after auto forward: "+
+			"firstUnchecked=%d, lastChecked=%d",
+			kr.firstUnchecked, kr.lastChecked)
+	}
+}
+
+// Error path: Tests that the default policy still panics.
+func TestKnownRounds_Check_PanicOnOverflow(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Check did not panic for round outside of scope.")
+		}
+	}()
+
+	NewKnownRoundWithPolicy(128, PanicOnOverflow).Check(300)
+}
+
+// Tests that the callback registered with KnownRounds.SetOnCheck is called
+// only for rounds that transition from unchecked to checked.
+func TestKnownRounds_SetOnCheck(t *testing.T) {
+	kr := NewKnownRound(310)
+	var called []id.Round
+	kr.SetOnCheck(func(rid id.Round) { called = append(called, rid) })
+
+	for _, rid := range []id.Round{5, 7, 5, 0, 3, 7, 200} {
+		kr.Check(rid)
+	}
+	kr.ForceCheck(1000)
+	kr.ForceCheck(1000)
+
+	expected := []id.Round{5, 7, 0, 3, 200, 1000}
+	if !reflect.DeepEqual(expected, called) {
+		t.Errorf("Unexpected rounds passed to callback."+
+			"\nexpected: %v\nreceived: %v", expected, called)
+	}
+
+	// Remove the callback and ensure it is no longer called
+	kr.SetOnCheck(nil)
+	kr.Check(1001)
+	if len(called) != len(expected) {
+		t.Errorf("Callback called after being removed: %v", called)
+	}
+}
+
 // Happy path of KnownRounds.Checked.
 func TestKnownRounds_Checked(t *testing.T) {
 	// Generate test positions and expected value
@@ -610,6 +844,7 @@ func TestKnownRounds_RangeUncheckedMasked(t *testing.T) {
 		firstUnchecked: 15,
 		lastChecked:    191,
 		fuPos:          0,
+		generation:     2,
 	}
 	kr := KnownRounds{
 		bitStream:      uint64Buff{0, math.MaxUint64, 0, math.MaxUint64, 0},
@@ -636,6 +871,34 @@ func TestKnownRounds_RangeUncheckedMasked(t *testing.T) {
 	fmt.Printf("kr.bitStream: %+v\n", kr.bitStream)
 }
 
+// Tests that KnownRounds.ApplyBloom checks only the unchecked rounds in range
+// that are not in the filter.
+func TestKnownRounds_ApplyBloom(t *testing.T) {
+	kr := NewKnownRound(256)
+	kr.Forward(10)
+	kr.Check(12)
+
+	// The filter contains every round divisible by 5
+	filter := []byte{5}
+	test := func(filter []byte, rid id.Round) bool {
+		return rid%id.Round(filter[0]) == 0
+	}
+
+	numChecked := kr.ApplyBloom(filter, 0, 30, test)
+	if numChecked != 15 {
+		t.Errorf("Unexpected number of checked rounds."+
+			"\nexpected: %d\nreceived: %d", 15, numChecked)
+	}
+
+	for rid := id.Round(10); rid < 40; rid++ {
+		expected := rid == 12 || (rid < 30 && rid%5 != 0)
+		if kr.Checked(rid) != expected {
+			t.Errorf("Unexpected checked state for round %d."+
+				"\nexpected: %t\nreceived: %t", rid, expected, kr.Checked(rid))
+		}
+	}
+}
+
 // Happy path of getBitStreamPos.
 func TestKnownRounds_getBitStreamPos(t *testing.T) {
 	// Generate test round IDs and their expected positions
@@ -832,12 +1095,17 @@ func TestKnownRounds_Truncate(t *testing.T) {
 			"\nexpected: %d\nreceived: %d", 127, newKR.firstUnchecked)
 	}
 
+	// Every round skipped by the truncation is already checked and the first
+	// unchecked round stays in the same 64-round block, so the marshalled bit
+	// stream, which only depends on which rounds are checked, does not change;
+	// only firstUnchecked in the 16-byte header does. See
+	// TestKnownRounds_Truncate_Shrink.
 	krBytes := kr.Marshal()
 	newKrBytes := newKR.Marshal()
 
-	if len(newKrBytes) >= len(krBytes) {
-		t.Errorf("Marshalled truncated KR larger than original."+
-			"\nexpected: %d\nrecived: %d", len(krBytes), len(newKrBytes))
+	if !bytes.Equal(newKrBytes[16:], krBytes[16:]) {
+		t.Errorf("Marshalled truncated KR does not match original."+
+			"\nexpected: %v\nreceived: %v", krBytes, newKrBytes)
 	}
 }
 
@@ -861,9 +1129,53 @@ func TestKnownRounds_Truncate_Wrap_Around(t *testing.T) {
 	krBytes := kr.Marshal()
 	newKrBytes := newKR.Marshal()
 
-	if len(newKrBytes) >= len(krBytes) {
-		t.Errorf("Marshalled truncated KR larger than original."+
-			"\nexpected: %d\nrecived: %d", len(krBytes), len(newKrBytes))
+	if !bytes.Equal(newKrBytes[16:], krBytes[16:]) {
+		t.Errorf("Marshalled truncated KR does not match original."+
+			"\nexpected: %v\nreceived: %v", krBytes, newKrBytes)
+	}
+}
+
+// Tests that KnownRounds.Truncate shrinks the marshalled data when it discards
+// unchecked rounds, even when firstUnchecked is not stored at bit
+// firstUnchecked%64 of the buffer.
+func TestKnownRounds_Truncate_Shrink(t *testing.T) {
+	for _, fuPos := range []int{1, 64, 255} {
+		prng := rand.New(rand.NewSource(42))
+		kr := &KnownRounds{
+			bitStream:      make(uint64Buff, 6),
+			firstUnchecked: 64,
+			lastChecked:    300,
+			fuPos:          fuPos,
+		}
+		for rid := id.Round(65); rid <= 300; rid++ {
+			if rid == 300 || (rid != 255 && prng.Intn(2) == 0) {
+				kr.bitStream.set(kr.getBitStreamPos(rid))
+			}
+		}
+
+		newKR := kr.Truncate(255)
+		if newKR.firstUnchecked != 255 {
+			t.Errorf("First unchecked not migrated correctly (fuPos %d)."+
+				"\nexpected: %d\nreceived: %d", fuPos, 255,
+				newKR.firstUnchecked)
+		}
+
+		krBytes, newKrBytes := kr.Marshal(), newKR.Marshal()
+		if len(newKrBytes) >= len(krBytes) {
+			t.Errorf("Marshalled truncated KR not smaller than original "+
+				"(fuPos %d).\noriginal: %d\ntruncated: %d",
+				fuPos, len(krBytes), len(newKrBytes))
+		}
+
+		for rid := id.Round(0); rid <= 310; rid++ {
+			// Rounds before the new firstUnchecked are implicitly checked
+			expected := rid < 255 || kr.Checked(rid)
+			if newKR.Checked(rid) != expected {
+				t.Errorf("Unexpected state of round %d (fuPos %d)."+
+					"\nexpected: %t\nreceived: %t",
+					rid, fuPos, expected, newKR.Checked(rid))
+			}
+		}
 	}
 }
 
@@ -881,6 +1193,7 @@ func TestKnownRounds_Database_Simulation(t *testing.T) {
 		firstUnchecked: 5,
 		lastChecked:    id.Round(n * 64),
 		fuPos:          5,
+		checkMux:       &sync.Mutex{},
 	}
 
 	saved := kr
@@ -909,6 +1222,9 @@ func TestKnownRounds_Database_Simulation(t *testing.T) {
 		newKR := NewFromParts(saved.bitStream,
 			saved.firstUnchecked, saved.lastChecked, saved.fuPos)
 
+		// The generation is not part of the saved changes
+		newKR.generation = kr.generation
+
 		// Compare the original KnownRounds to the reconstructed KnownRounds
 		if !reflect.DeepEqual(kr, newKR) {
 			t.Errorf("Reconstructed KnownRounds does not match original."+
@@ -944,3 +1260,639 @@ func TestKnownRounds_Len(t *testing.T) {
 		t.Errorf("Failed to unmarshal: %+v", err)
 	}
 }
+
+// Tests that KnownRounds.LastChecked returns the same rounds as a naive scan
+// of KnownRounds.Checked from the last checked round backwards.
+func TestKnownRounds_LastChecked(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+
+	for i := 0; i < 50; i++ {
+		kr := NewKnownRound(256)
+		start := id.Round(prng.Intn(500))
+		kr.Forward(start)
+		for j := 0; j < prng.Intn(200); j++ {
+			kr.Check(start + id.Round(prng.Intn(250)))
+		}
+
+		n := prng.Intn(300) + 1
+		var expected []id.Round
+		for rid := int(kr.lastChecked); rid >= 0 && len(expected) < n; rid-- {
+			if kr.Checked(id.Round(rid)) {
+				expected = append(expected, id.Round(rid))
+			}
+		}
+
+		received := kr.LastChecked(n)
+		if !reflect.DeepEqual(expected, received) {
+			t.Errorf("Unexpected last checked rounds (%d) for n=%d."+
+				"\nexpected: %v\nreceived: %v", i, n, expected, received)
+		}
+	}
+}
+
+// Tests that KnownRounds.LastChecked returns nil when n is not positive.
+func TestKnownRounds_LastChecked_NonPositive(t *testing.T) {
+	kr := NewKnownRound(64)
+	kr.Check(5)
+
+	if rounds := kr.LastChecked(0); rounds != nil {
+		t.Errorf("Expected nil for n=0, received: %v", rounds)
+	}
+}
+
+// Tests that KnownRounds.NextUnchecked returns the same round as a naive scan
+// of KnownRounds.Checked.
+func TestKnownRounds_NextUnchecked(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+
+	for i := 0; i < 50; i++ {
+		kr := NewKnownRound(256)
+		start := id.Round(prng.Intn(500))
+		kr.Forward(start)
+		for j := 0; j < prng.Intn(300); j++ {
+			kr.Check(start + id.Round(prng.Intn(250)))
+		}
+
+		for after := id.Round(0); after < start+260; after++ {
+			// All rounds after lastChecked are unchecked, so the scan always
+			// finds a round
+			expected, expectedFound := id.Round(0), false
+			for rid := after + 1; !expectedFound; rid++ {
+				if !kr.Checked(rid) {
+					expected, expectedFound = rid, true
+				}
+			}
+
+			received, found := kr.NextUnchecked(after)
+			if expected != received || expectedFound != found {
+				t.Errorf("Unexpected next unchecked after %d (%d)."+
+					"\nexpected: %d, %t\nreceived: %d, %t",
+					after, i, expected, expectedFound, received, found)
+			}
+		}
+	}
+}
+
+// Tests that KnownRounds.NextUnchecked returns the round after the last checked
+// round when every round in the window is checked and only returns false when
+// there is no next round.
+func TestKnownRounds_NextUnchecked_AllChecked(t *testing.T) {
+	kr := NewKnownRound(128)
+	kr.Forward(50)
+	kr.Check(60)
+	for rid := id.Round(50); rid < 60; rid++ {
+		kr.Check(rid)
+	}
+
+	for _, tt := range []struct{ after, expected id.Round }{
+		{0, 61}, {49, 61}, {60, 61}, {61, 62}, {1000, 1001},
+	} {
+		received, found := kr.NextUnchecked(tt.after)
+		if !found || received != tt.expected {
+			t.Errorf("Unexpected next unchecked after %d."+
+				"\nexpected: %d, true\nreceived: %d, %t",
+				tt.after, tt.expected, received, found)
+		}
+	}
+
+	if rid, found := kr.NextUnchecked(math.MaxUint64); found {
+		t.Errorf("Found round %d after the largest round.", rid)
+	}
+}
+
+// Tests that KnownRounds.ExpireBefore marks all rounds before the given round
+// as checked and returns the number that were unchecked.
+func TestKnownRounds_ExpireBefore(t *testing.T) {
+	kr := NewKnownRound(128)
+	for _, rid := range []id.Round{0, 1, 3, 6, 9, 20} {
+		kr.Check(rid)
+	}
+
+	// Rounds 2, 4, 5, 7, and 8 are unchecked before round 9
+	if expired := kr.ExpireBefore(9); expired != 5 {
+		t.Errorf("Unexpected expired count.\nexpected: %d\nreceived: %d",
+			5, expired)
+	}
+
+	for rid := id.Round(0); rid < 10; rid++ {
+		if !kr.Checked(rid) {
+			t.Errorf("Round %d not checked after expiry.", rid)
+		}
+	}
+	if kr.Checked(10) || !kr.Checked(20) {
+		t.Errorf("Rounds after expiry changed: 10=%t, 20=%t",
+			kr.Checked(10), kr.Checked(20))
+	}
+
+	// Rounds 10 to 19 and 21 to 29 are unchecked before round 30
+	if expired := kr.ExpireBefore(30); expired != 19 {
+		t.Errorf("Unexpected expired count.\nexpected: %d\nreceived: %d",
+			19, expired)
+	}
+
+	if expired := kr.ExpireBefore(5); expired != 0 {
+		t.Errorf("Expected no expired rounds, received: %d", expired)
+	}
+}
+
+// Tests that KnownRounds.LargestUncheckedGap returns the earliest longest run
+// of unchecked rounds in the window, including across the end of the buffer.
+func TestKnownRounds_LargestUncheckedGap(t *testing.T) {
+	tests := []struct {
+		checked    []id.Round
+		start, end id.Round
+	}{
+		{nil, 0, 0},
+		{[]id.Round{1, 2, 3}, 0, 0},
+		{[]id.Round{0, 1, 5, 6, 11}, 7, 10},
+		{[]id.Round{0, 1, 5, 6, 10, 11}, 2, 4},
+		{[]id.Round{0, 4, 8, 9}, 1, 3},
+		{[]id.Round{0, 100, 101, 199, 300}, 200, 299},
+		{[]id.Round{0, 1, 2}, 3, 2},
+	}
+
+	for i, tt := range tests {
+		kr := NewKnownRound(512)
+		for _, rid := range tt.checked {
+			kr.Check(rid)
+		}
+		if i == len(tests)-1 {
+			// Leave every round in the window checked
+			kr.lastChecked = kr.firstUnchecked - 1
+		}
+
+		start, end := kr.LargestUncheckedGap()
+		if start != tt.start || end != tt.end {
+			t.Errorf("Unexpected gap (%d).\nexpected: [%d, %d]"+
+				"\nreceived: [%d, %d]", i, tt.start, tt.end, start, end)
+		}
+	}
+
+	// The window wraps around the end of the buffer
+	kr := NewKnownRound(128)
+	kr.Forward(100)
+	for _, rid := range []id.Round{100, 110, 200, 220} {
+		kr.Check(rid)
+	}
+	if start, end := kr.LargestUncheckedGap(); start != 111 || end != 199 {
+		t.Errorf("Unexpected gap for wrapped window.\nexpected: [%d, %d]"+
+			"\nreceived: [%d, %d]", 111, 199, start, end)
+	}
+}
+
+// Tests that KnownRounds.Progress returns the fraction of rounds up to the
+// network head that are checked, including the edge cases.
+func TestKnownRounds_Progress(t *testing.T) {
+	kr := NewKnownRound(128)
+	if p := kr.Progress(100); p != 0 {
+		t.Errorf("Unexpected progress for empty KnownRounds."+
+			"\nexpected: %f\nreceived: %f", 0.0, p)
+	}
+
+	// Rounds 1 to 49 and 60 checked
+	kr.Forward(50)
+	kr.Check(60)
+	tests := []struct {
+		head     id.Round
+		expected float64
+	}{
+		{0, 1},
+		{10, 1},
+		{50, 49.0 / 50},
+		{60, 50.0 / 60},
+		{100, 50.0 / 100},
+	}
+
+	for _, tt := range tests {
+		if p := kr.Progress(tt.head); p != tt.expected {
+			t.Errorf("Unexpected progress for network head %d."+
+				"\nexpected: %f\nreceived: %f", tt.head, tt.expected, p)
+		}
+	}
+}
+
+// Tests that KnownRounds.Snapshot shares the bit stream until the original is
+// modified and that modifying either one does not affect the other.
+func TestKnownRounds_Snapshot(t *testing.T) {
+	kr := NewKnownRound(256)
+	for _, rid := range []id.Round{0, 1, 5, 9} {
+		kr.Check(rid)
+	}
+	expected := kr.Marshal()
+
+	snap := kr.Snapshot()
+	if &snap.bitStream[0] != &kr.bitStream[0] {
+		t.Error("Snapshot does not share the bit stream.")
+	}
+
+	kr.Check(7)
+	kr.Forward(100)
+	if !bytes.Equal(snap.Marshal(), expected) {
+		t.Errorf("Snapshot changed when the original was modified."+
+			"\nexpected: %v\nreceived: %v", expected, snap.Marshal())
+	}
+
+	snap2 := kr.Snapshot()
+	snap2.Check(150)
+	if kr.Checked(150) {
+		t.Error("Original changed when the snapshot was modified.")
+	}
+}
+
+// Tests that a snapshot can be marshalled while the original is concurrently
+// modified. Run with the race detector to catch shared writes.
+func TestKnownRounds_Snapshot_Concurrent(t *testing.T) {
+	kr := NewKnownRound(16 * 1024)
+	var mux sync.Mutex
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			mux.Lock()
+			snap := kr.Snapshot()
+			mux.Unlock()
+			if err := NewKnownRound(0).Unmarshal(snap.Marshal()); err != nil {
+				t.Errorf("Failed to unmarshal snapshot %d: %+v", i, err)
+			}
+		}
+	}()
+
+	for rid := id.Round(0); rid < 5000; rid += 2 {
+		mux.Lock()
+		kr.Check(rid)
+		mux.Unlock()
+	}
+	<-done
+}
+
+// Tests that KnownRounds.CheckIfUnchecked only returns true for the call that
+// checks the round when called concurrently by multiple workers.
+func TestKnownRounds_CheckIfUnchecked(t *testing.T) {
+	kr := NewKnownRound(1024)
+	kr.Forward(10)
+	const numWorkers, numRounds = 8, 500
+	var wg sync.WaitGroup
+	wins := make([][]id.Round, numWorkers)
+
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for rid := id.Round(0); rid < numRounds; rid++ {
+				if kr.CheckIfUnchecked(rid) {
+					wins[i] = append(wins[i], rid)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	picked := make(map[id.Round]int)
+	for _, rounds := range wins {
+		for _, rid := range rounds {
+			picked[rid]++
+		}
+	}
+
+	for rid := id.Round(0); rid < numRounds; rid++ {
+		expected := 1
+		if rid < 10 {
+			// Forwarded rounds are already checked
+			expected = 0
+		}
+		if picked[rid] != expected {
+			t.Errorf("Round %d picked up %d times; expected %d.",
+				rid, picked[rid], expected)
+		}
+		if !kr.Checked(rid) {
+			t.Errorf("Round %d not checked.", rid)
+		}
+	}
+}
+
+// Tests that every way of creating a KnownRounds gives it its own
+// CheckIfUnchecked lock, so that unrelated KnownRounds do not contend.
+func TestKnownRounds_CheckIfUnchecked_Lock(t *testing.T) {
+	kr := NewKnownRound(128)
+	kr.Check(5)
+
+	unmarshalled := &KnownRounds{}
+	if err := unmarshalled.Unmarshal(kr.Marshal()); err != nil {
+		t.Fatalf("Failed to unmarshal: %+v", err)
+	}
+	roaring := &KnownRounds{}
+	if err := roaring.FromRoaringBytes(kr.ToRoaringBytes()); err != nil {
+		t.Fatalf("Failed to load roaring bitmap: %+v", err)
+	}
+
+	krs := []*KnownRounds{kr, NewKnownRoundWithPolicy(128, AutoForward),
+		NewKnownRoundFromBuffer(make([]uint64, 2)),
+		NewFromParts(make([]uint64, 2), 0, 0, 0), kr.Truncate(3),
+		kr.Snapshot(), kr.deepCopy(), unmarshalled, roaring}
+	locks := make(map[*sync.Mutex]int)
+	for i, k := range krs {
+		if k.checkMux == nil {
+			t.Errorf("KnownRounds %d has no lock.", i)
+		} else if j, exists := locks[k.checkMux]; exists {
+			t.Errorf("KnownRounds %d shares a lock with %d.", i, j)
+		}
+		locks[k.checkMux] = i
+		k.CheckIfUnchecked(6)
+	}
+}
+
+// Tests that a KnownRounds created by NewKnownRoundFromBuffer uses the given
+// buffer, clears it, and never replaces it when checking, forwarding,
+// snapshotting, or unmarshalling. Also tests that Check does not allocate.
+func TestNewKnownRoundFromBuffer(t *testing.T) {
+	buff := make([]uint64, 4)
+	for i := range buff {
+		buff[i] = math.MaxUint64
+	}
+
+	kr := NewKnownRoundFromBuffer(buff)
+	if kr.Len() != len(buff)*64 {
+		t.Errorf("Unexpected length.\nexpected: %d\nreceived: %d",
+			len(buff)*64, kr.Len())
+	}
+	for i, word := range buff {
+		if word != 0 {
+			t.Errorf("Word %d of buffer not cleared: %064b", i, word)
+		}
+	}
+
+	expected := NewKnownRound(len(buff) * 64)
+	snap := kr.Snapshot()
+	for _, rid := range []id.Round{0, 1, 5, 9, 200, 300} {
+		kr.Check(rid)
+		expected.Check(rid)
+	}
+	kr.Forward(150)
+	expected.Forward(150)
+	if err := kr.Unmarshal(expected.Marshal()); err != nil {
+		t.Errorf("Failed to unmarshal: %+v", err)
+	}
+
+	if &kr.bitStream[0] != &buff[0] || len(kr.bitStream) != len(buff) {
+		t.Error("KnownRounds no longer uses the given buffer.")
+	} else if &snap.bitStream[0] == &buff[0] {
+		t.Error("Snapshot shares the given buffer.")
+	} else if snap.Checked(0) {
+		t.Error("Snapshot changed when the original was modified.")
+	}
+	if !bytes.Equal(kr.Marshal(), expected.Marshal()) {
+		t.Errorf("Unexpected marshalled KnownRounds.\nexpected: %v"+
+			"\nreceived: %v", expected.Marshal(), kr.Marshal())
+	}
+
+	rid := id.Round(301)
+	allocs := testing.AllocsPerRun(100, func() {
+		kr.Check(rid)
+		rid++
+	})
+	if allocs != 0 {
+		t.Errorf("Check allocated %f times.", allocs)
+	}
+}
+
+// Error path: Tests that NewKnownRoundFromBuffer panics for an empty buffer.
+func TestNewKnownRoundFromBuffer_EmptyBufferPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Failed to panic for an empty buffer.")
+		}
+	}()
+
+	NewKnownRoundFromBuffer(nil)
+}
+
+// goldenMarshalFile contains the expected output of KnownRounds.Marshal for
+// each KnownRounds built by goldenMarshalBuilders. Downstream signatures are
+// computed over the marshalled bytes, so the output must never change.
+const goldenMarshalFile = "testdata/marshalGolden.json"
+
+// goldenMarshal is a single fixture in goldenMarshalFile.
+type goldenMarshal struct {
+	Name    string `json:"name"`
+	Marshal string `json:"marshal"` // Hex encoded
+}
+
+// goldenMarshalBuilders builds the KnownRounds for each golden fixture.
+var goldenMarshalBuilders = map[string]func() *KnownRounds{
+	"empty": func() *KnownRounds { return NewKnownRound(64) },
+	"sequential": func() *KnownRounds {
+		kr := NewKnownRound(128)
+		for rid := id.Round(0); rid < 100; rid++ {
+			kr.Check(rid)
+		}
+		return kr
+	},
+	"sparse": func() *KnownRounds {
+		kr := NewKnownRound(256)
+		for _, rid := range []id.Round{3, 7, 64, 65, 200} {
+			kr.Check(rid)
+		}
+		return kr
+	},
+	"forwarded": func() *KnownRounds {
+		kr := NewKnownRound(128)
+		kr.Forward(1000)
+		for rid := id.Round(1000); rid < 1010; rid += 3 {
+			kr.Check(rid)
+		}
+		kr.Check(1100)
+		return kr
+	},
+	"wrapped": func() *KnownRounds {
+		kr := NewKnownRound(128)
+		kr.Forward(100)
+		kr.Check(150)
+		kr.Check(220)
+		return kr
+	},
+	"random": func() *KnownRounds {
+		prng := rand.New(rand.NewSource(42))
+		kr := NewKnownRound(16384)
+		for i := 0; i < 5000; i++ {
+			kr.Check(id.Round(prng.Intn(10000)))
+		}
+		return kr
+	},
+}
+
+// Tests that KnownRounds.Marshal matches the golden fixtures and that
+// unmarshalling and re-marshalling each fixture reproduces it.
+func TestKnownRounds_Marshal_Golden(t *testing.T) {
+	data, err := os.ReadFile(goldenMarshalFile)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %+v", goldenMarshalFile, err)
+	}
+	var fixtures []goldenMarshal
+	if err = json.Unmarshal(data, &fixtures); err != nil {
+		t.Fatalf("Failed to parse %s: %+v", goldenMarshalFile, err)
+	}
+	if len(fixtures) != len(goldenMarshalBuilders) {
+		t.Errorf("Expected %d fixtures, found %d.",
+			len(goldenMarshalBuilders), len(fixtures))
+	}
+
+	for _, f := range fixtures {
+		expected, err := hex.DecodeString(f.Marshal)
+		if err != nil {
+			t.Fatalf("Failed to decode fixture %q: %+v", f.Name, err)
+		}
+
+		build, exists := goldenMarshalBuilders[f.Name]
+		if !exists {
+			t.Errorf("No builder for fixture %q.", f.Name)
+			continue
+		}
+		if received := build().Marshal(); !bytes.Equal(expected, received) {
+			t.Errorf("Marshal output for %q does not match fixture."+
+				"\nexpected: %x\nreceived: %x", f.Name, expected, received)
+		}
+
+		kr := NewKnownRound(0)
+		if err = kr.Unmarshal(expected); err != nil {
+			t.Errorf("Failed to unmarshal fixture %q: %+v", f.Name, err)
+		} else if received := kr.Marshal(); !bytes.Equal(expected, received) {
+			t.Errorf("Re-marshalled fixture %q does not match."+
+				"\nexpected: %x\nreceived: %x", f.Name, expected, received)
+		}
+	}
+}
+
+// Tests that KnownRounds.Marshal only depends on which rounds are checked and
+// not on the capacity of the buffer or on stale data left in it by earlier
+// operations.
+func TestKnownRounds_Marshal_Deterministic(t *testing.T) {
+	build := func(capacity int, stale bool) *KnownRounds {
+		kr := NewKnownRound(capacity)
+		if stale {
+			for rid := id.Round(0); rid < id.Round(capacity) && rid < 500; rid += 2 {
+				kr.ForceCheck(rid)
+			}
+		}
+		kr.Forward(500)
+		for _, rid := range []id.Round{501, 503, 560, 570} {
+			kr.Check(rid)
+		}
+		return kr
+	}
+
+	expected := build(128, false).Marshal()
+	for _, capacity := range []int{128, 256, 1024} {
+		for _, stale := range []bool{false, true} {
+			received := build(capacity, stale).Marshal()
+			if !bytes.Equal(expected, received) {
+				t.Errorf("Marshal output differs for capacity %d and stale "+
+					"data %t.\nexpected: %x\nreceived: %x",
+					capacity, stale, expected, received)
+			}
+		}
+	}
+}
+
+// newJumpBenchKR returns a KnownRounds of 16384 rounds where every round up to
+// 12,000 is checked except the first, as after a client reconnects and
+// catches up on the rounds it missed.
+func newJumpBenchKR(policy OverflowPolicy) *KnownRounds {
+	kr := NewKnownRoundWithPolicy(16384, policy)
+	for rid := id.Round(1); rid <= 12000; rid++ {
+		kr.Check(rid)
+	}
+	return kr
+}
+
+// resetBenchKR restores kr to the state of src without allocating.
+func resetBenchKR(kr, src *KnownRounds) {
+	bitStream := kr.bitStream
+	copy(bitStream, src.bitStream)
+	*kr = *src
+	kr.bitStream = bitStream
+}
+
+// Benchmarks KnownRounds.Forward jumping 10,000 checked rounds.
+func BenchmarkKnownRounds_Forward_Jump10k(b *testing.B) {
+	src := newJumpBenchKR(PanicOnOverflow)
+	kr := src.deepCopy()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		resetBenchKR(kr, src)
+		kr.Forward(10000)
+	}
+}
+
+// Benchmarks KnownRounds.ExpireBefore expiring 10,000 rounds.
+func BenchmarkKnownRounds_ExpireBefore_Jump10k(b *testing.B) {
+	src := newJumpBenchKR(PanicOnOverflow)
+	kr := src.deepCopy()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		resetBenchKR(kr, src)
+		kr.ExpireBefore(10000)
+	}
+}
+
+// Benchmarks KnownRounds.Check with the AutoForward policy checking a round
+// that forwards the window by over 10,000 rounds.
+func BenchmarkKnownRounds_Check_AutoForward_Jump10k(b *testing.B) {
+	src := newJumpBenchKR(AutoForward)
+	kr := src.deepCopy()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		resetBenchKR(kr, src)
+		kr.Check(30000)
+	}
+}
+
+// Consistency test of KnownRounds.migrateFirstUnchecked and
+// KnownRounds.countUnchecked against bit-by-bit implementations for random
+// KnownRounds, including ones whose window wraps around the buffer.
+func TestKnownRounds_WordAligned_Consistency(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	for i := 0; i < 500; i++ {
+		kr := NewKnownRound(64 * (1 + prng.Intn(4)))
+		kr.Forward(id.Round(prng.Intn(1000)))
+		density := prng.Float64()
+		for j := 0; j < kr.Len(); j++ {
+			rid := kr.firstUnchecked + id.Round(prng.Intn(kr.Len()))
+			if prng.Float64() < density {
+				kr.Check(rid)
+			}
+		}
+
+		start := kr.firstUnchecked + id.Round(prng.Intn(kr.Len()+10))
+		end := start + id.Round(prng.Intn(2*kr.Len()))
+		expected := uint64(0)
+		for rid := start; rid < end; rid++ {
+			if !kr.Checked(rid) {
+				expected++
+			}
+		}
+		if count := kr.countUnchecked(start, end); count != expected {
+			t.Errorf("Unexpected count of unchecked rounds from %d to %d "+
+				"(%d).\nexpected: %d\nreceived: %d",
+				start, end, i, expected, count)
+		}
+
+		// Rounds can only be migrated to within the window
+		if start > kr.lastChecked+1 {
+			start = kr.lastChecked + 1
+		}
+		expectedFu := start
+		for ; kr.Checked(expectedFu) && expectedFu <= kr.lastChecked; expectedFu++ {
+		}
+		kr.migrateFirstUnchecked(start)
+		if kr.firstUnchecked != expectedFu ||
+			kr.fuPos != kr.getBitStreamPos(expectedFu) {
+			t.Errorf("Unexpected firstUnchecked after migrating from %d "+
+				"(%d).\nexpected: %d\nreceived: %d",
+				start, i, expectedFu, kr.firstUnchecked)
+		}
+		if err := kr.checkInvariants(); err != nil {
+			t.Errorf("Invariants violated (%d): %+v", i, err)
+		}
+	}
+}
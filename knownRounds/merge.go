@@ -0,0 +1,123 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"github.com/pkg/errors"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// MergePolicy describes how KnownRounds.Merge combines two KnownRounds.
+type MergePolicy uint8
+
+const (
+	// MergeOr marks a round as checked if it is checked in either
+	// KnownRounds. Use it to combine the progress of several sources, such as
+	// gateways, that each checked a subset of the rounds.
+	MergeOr MergePolicy = iota
+
+	// MergeAnd marks a round as checked only if it is checked in both
+	// KnownRounds. Use it to find the rounds that every source has checked.
+	MergeAnd
+)
+
+// String returns the string representation of the MergePolicy. This functions
+// adheres to the fmt.Stringer interface.
+func (mp MergePolicy) String() string {
+	switch mp {
+	case MergeOr:
+		return "OR"
+	case MergeAnd:
+		return "AND"
+	default:
+		return "INVALID MERGE POLICY"
+	}
+}
+
+// Merge combines the other KnownRounds into this one according to the
+// MergePolicy. The other KnownRounds is not modified.
+//
+// Because every round before firstUnchecked is checked and every round after
+// lastChecked is unchecked, the window of the result follows from the policy:
+//
+//   - MergeOr: the result's firstUnchecked is the later of the two and its
+//     lastChecked is the later of the two.
+//   - MergeAnd: the result's firstUnchecked is the earlier of the two and its
+//     lastChecked is the earlier of the two.
+//
+// The firstUnchecked is then advanced past any rounds at the start of the
+// window that are checked in the result. Returns an error, without modifying
+// the KnownRounds, if the policy is invalid or if the resulting window does not
+// fit in its buffer, in which case it wraps ErrBufferTooSmall. The OnCheck
+// callback is not called for rounds that become checked.
+func (kr *KnownRounds) Merge(other *KnownRounds, policy MergePolicy) error {
+	var fu, lc id.Round
+	var checked func(rid id.Round) bool
+	switch policy {
+	case MergeOr:
+		fu = maxRound(kr.firstUnchecked, other.firstUnchecked)
+		lc = maxRound(kr.lastChecked, other.lastChecked)
+		checked = func(rid id.Round) bool {
+			return kr.Checked(rid) || other.Checked(rid)
+		}
+	case MergeAnd:
+		fu = minRound(kr.firstUnchecked, other.firstUnchecked)
+		lc = minRound(kr.lastChecked, other.lastChecked)
+		checked = func(rid id.Round) bool {
+			return kr.Checked(rid) && other.Checked(rid)
+		}
+	default:
+		return errors.Errorf("invalid merge policy %d", policy)
+	}
+
+	if lc >= fu && uint64(lc-fu) >= uint64(kr.Len()) {
+		need := uint64(lc-fu) + 1
+		return errors.WithMessagef(
+			ErrBufferTooSmall{Need: need, Have: uint64(kr.Len())},
+			"merged window of %d rounds from %d to %d does not fit in bit "+
+				"stream of %d rounds", need, fu, lc, kr.Len())
+	}
+
+	// Build the result in a new buffer, starting at the first bit, since both
+	// KnownRounds are read while it is built
+	bitStream := make(uint64Buff, len(kr.bitStream))
+	for rid := fu; rid <= lc && rid >= fu; rid++ {
+		if checked(rid) {
+			bitStream.set(int(rid - fu))
+		}
+	}
+
+	if kr.fixedBuffer {
+		copy(kr.bitStream, bitStream)
+	} else {
+		kr.bitStream = bitStream
+		kr.shared = false
+	}
+	kr.firstUnchecked, kr.lastChecked, kr.fuPos = fu, lc, 0
+	kr.migrateFirstUnchecked(fu)
+	kr.generation++
+
+	return nil
+}
+
+// maxRound returns the larger of the two rounds.
+func maxRound(a, b id.Round) id.Round {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// minRound returns the smaller of the two rounds.
+func minRound(a, b id.Round) id.Round {
+	if a < b {
+		return a
+	}
+	return b
+}
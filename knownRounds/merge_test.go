@@ -0,0 +1,134 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"math/rand"
+	"testing"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// Tests that KnownRounds.Merge marks a round checked if it is checked in
+// either KnownRounds for MergeOr and in both for MergeAnd, for randomly
+// checked rounds with differing windows.
+func TestKnownRounds_Merge(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	newRandom := func(start id.Round) *KnownRounds {
+		kr := NewKnownRound(1024)
+		kr.Forward(start)
+		for i := 0; i < 300; i++ {
+			kr.Check(start + id.Round(prng.Intn(400)))
+		}
+		return kr
+	}
+
+	for i := 0; i < 20; i++ {
+		for _, policy := range []MergePolicy{MergeOr, MergeAnd} {
+			a := newRandom(id.Round(1000 + prng.Intn(200)))
+			b := newRandom(id.Round(1000 + prng.Intn(200)))
+			merged := a.deepCopy()
+			if err := merged.Merge(b, policy); err != nil {
+				t.Fatalf("Failed to merge with %s (%d): %+v", policy, i, err)
+			}
+
+			for rid := id.Round(900); rid < 1700; rid++ {
+				expected := a.Checked(rid) || b.Checked(rid)
+				if policy == MergeAnd {
+					expected = a.Checked(rid) && b.Checked(rid)
+				}
+				if merged.Checked(rid) != expected {
+					t.Errorf("Round %d merged incorrectly with %s (%d)."+
+						"\nexpected: %t\nreceived: %t",
+						rid, policy, i, expected, merged.Checked(rid))
+				}
+			}
+
+			if !merged.Checked(merged.firstUnchecked-1) ||
+				merged.firstUnchecked <= merged.lastChecked &&
+					merged.Checked(merged.firstUnchecked) {
+				t.Errorf("firstUnchecked %d is not the first unchecked "+
+					"round with %s (%d).", merged.firstUnchecked, policy, i)
+			}
+		}
+	}
+}
+
+// Tests that KnownRounds.Merge reconciles the windows of the two KnownRounds
+// as documented.
+func TestKnownRounds_Merge_Window(t *testing.T) {
+	newKr := func(checked ...id.Round) *KnownRounds {
+		kr := NewKnownRound(256)
+		kr.Forward(10)
+		for _, rid := range checked {
+			kr.Check(rid)
+		}
+		return kr
+	}
+
+	tests := []struct {
+		policy MergePolicy
+		fu, lc id.Round
+	}{
+		{MergeOr, 11, 50},
+		{MergeAnd, 10, 20},
+	}
+	for _, tt := range tests {
+		kr := newKr(12, 20)
+		if err := kr.Merge(newKr(10, 15, 50), tt.policy); err != nil {
+			t.Fatalf("Failed to merge with %s: %+v", tt.policy, err)
+		}
+
+		if kr.firstUnchecked != tt.fu || kr.lastChecked != tt.lc {
+			t.Errorf("Unexpected window with %s.\nexpected: [%d, %d]"+
+				"\nreceived: [%d, %d]", tt.policy, tt.fu, tt.lc,
+				kr.firstUnchecked, kr.lastChecked)
+		}
+	}
+}
+
+// Tests that KnownRounds.Merge keeps using the buffer of a KnownRounds created
+// with NewKnownRoundFromBuffer.
+func TestKnownRounds_Merge_FixedBuffer(t *testing.T) {
+	buff := make([]uint64, 4)
+	kr := NewKnownRoundFromBuffer(buff)
+	kr.Check(3)
+	other := NewKnownRound(256)
+	other.Check(5)
+
+	if err := kr.Merge(other, MergeOr); err != nil {
+		t.Fatalf("Failed to merge: %+v", err)
+	}
+	if &kr.bitStream[0] != &buff[0] {
+		t.Error("Merge replaced the fixed buffer.")
+	}
+	if !kr.Checked(3) || !kr.Checked(5) || kr.Checked(4) {
+		t.Errorf("Rounds not merged correctly: %v", kr.bitStream)
+	}
+}
+
+// Error path: Tests that KnownRounds.Merge returns an error and does not modify
+// the KnownRounds when the merged window does not fit or the policy is
+// invalid.
+func TestKnownRounds_Merge_Error(t *testing.T) {
+	kr := NewKnownRound(128)
+	kr.Check(5)
+	other := NewKnownRound(256)
+	other.Check(200)
+	expected := kr.Marshal()
+
+	if err := kr.Merge(other, MergeOr); err == nil {
+		t.Error("No error for merged window that does not fit.")
+	}
+	if err := kr.Merge(other, MergePolicy(2)); err == nil {
+		t.Error("No error for invalid policy.")
+	}
+	if string(kr.Marshal()) != string(expected) {
+		t.Error("KnownRounds modified by failed Merge.")
+	}
+}
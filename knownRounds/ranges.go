@@ -0,0 +1,77 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// RoundRange is an inclusive range of round IDs.
+type RoundRange struct {
+	Start, End id.Round
+}
+
+// String returns the range in the form "start-end", or just "start" if the
+// range contains a single round. This functions adheres to the fmt.Stringer
+// interface.
+func (rr RoundRange) String() string {
+	if rr.Start == rr.End {
+		return strconv.FormatUint(uint64(rr.Start), 10)
+	}
+	return strconv.FormatUint(uint64(rr.Start), 10) + "-" +
+		strconv.FormatUint(uint64(rr.End), 10)
+}
+
+// CheckedRanges returns the maximal contiguous ranges of checked rounds in
+// ascending order. Because every round before firstUnchecked is checked, the
+// first range starts at round zero if firstUnchecked is above zero. This allows
+// peers to exchange compact summaries, such as "rounds 100-1500 and 1502-1600",
+// which can be loaded with FromRanges.
+func (kr *KnownRounds) CheckedRanges() []RoundRange {
+	intervals := kr.checkedIntervals()
+	ranges := make([]RoundRange, len(intervals))
+	for i, in := range intervals {
+		ranges[i] = RoundRange{id.Round(in.start), id.Round(in.end)}
+	}
+	return ranges
+}
+
+// FromRanges creates a new KnownRounds with the rounds in the given ranges
+// checked. It is the reciprocal of KnownRounds.CheckedRanges. The ranges must
+// be in ascending order and must not overlap, though adjacent ranges are
+// allowed. The first unchecked round is the first round not in a range and the
+// last checked round is the end of the last range.
+//
+// The bit stream holds the given number of rounds, which must be enough to
+// hold the rounds from the first unchecked round to the last checked round. If
+// the capacity is zero, a bit stream just large enough is allocated. Returns an
+// error if the ranges are invalid or do not fit.
+func FromRanges(ranges []RoundRange, roundCapacity int) (*KnownRounds, error) {
+	intervals := make([]roundInterval, 0, len(ranges))
+	for i, rr := range ranges {
+		if rr.Start > rr.End {
+			return nil, errors.Errorf("FromRanges: range %d (%d-%d) starts "+
+				"after it ends", i, rr.Start, rr.End)
+		} else if i > 0 && rr.Start <= ranges[i-1].End {
+			return nil, errors.Errorf("FromRanges: range %d (%s) is out of "+
+				"order or overlaps range %d (%s)", i, rr, i-1, ranges[i-1])
+		}
+		addInterval(&intervals, uint64(rr.Start), uint64(rr.End))
+	}
+
+	kr := NewKnownRound(roundCapacity)
+	if err := kr.setCheckedIntervals(intervals); err != nil {
+		return nil, errors.WithMessage(err, "FromRanges")
+	}
+
+	return kr, nil
+}
@@ -0,0 +1,135 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// Tests that KnownRounds.CheckedRanges returns the maximal checked ranges,
+// including the rounds before firstUnchecked.
+func TestKnownRounds_CheckedRanges(t *testing.T) {
+	kr := NewKnownRound(2048)
+	kr.Forward(100)
+	for rid := id.Round(100); rid <= 1600; rid++ {
+		if rid != 1501 {
+			kr.Check(rid)
+		}
+	}
+	kr.Check(1700)
+
+	expected := []RoundRange{{0, 1500}, {1502, 1600}, {1700, 1700}}
+	if ranges := kr.CheckedRanges(); !reflect.DeepEqual(expected, ranges) {
+		t.Errorf("Unexpected ranges.\nexpected: %v\nreceived: %v",
+			expected, ranges)
+	}
+
+	if ranges := NewKnownRound(64).CheckedRanges(); len(ranges) != 0 {
+		t.Errorf("Expected no ranges for new KnownRounds: %v", ranges)
+	}
+}
+
+// Tests that a KnownRounds created with FromRanges from the output of
+// KnownRounds.CheckedRanges has the same checked rounds and ranges as the
+// original.
+func TestKnownRounds_CheckedRanges_FromRanges(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	kr := NewKnownRound(16384)
+	kr.Forward(70000)
+	for i := 0; i < 5000; i++ {
+		kr.Check(70000 + id.Round(prng.Intn(10000)))
+	}
+	ranges := kr.CheckedRanges()
+
+	for _, capacity := range []int{0, 16384} {
+		newKr, err := FromRanges(ranges, capacity)
+		if err != nil {
+			t.Fatalf("Failed to create KnownRounds with capacity %d: %+v",
+				capacity, err)
+		}
+
+		if !bytes.Equal(kr.Marshal(), newKr.Marshal()) {
+			t.Errorf("KnownRounds with capacity %d does not match original."+
+				"\nexpected: %s\nreceived: %s",
+				capacity, kr.Marshal(), newKr.Marshal())
+		}
+		if newRanges := newKr.CheckedRanges(); !reflect.DeepEqual(
+			ranges, newRanges) {
+			t.Errorf("Unexpected ranges with capacity %d."+
+				"\nexpected: %v\nreceived: %v", capacity, ranges, newRanges)
+		}
+	}
+}
+
+// Tests that FromRanges merges adjacent ranges and accepts an empty list.
+func TestFromRanges(t *testing.T) {
+	kr, err := FromRanges(
+		[]RoundRange{{0, 9}, {10, 20}, {30, 30}, {31, 40}}, 0)
+	if err != nil {
+		t.Fatalf("Failed to create KnownRounds: %+v", err)
+	}
+
+	expected := []RoundRange{{0, 20}, {30, 40}}
+	if ranges := kr.CheckedRanges(); !reflect.DeepEqual(expected, ranges) {
+		t.Errorf("Unexpected ranges.\nexpected: %v\nreceived: %v",
+			expected, ranges)
+	}
+	if kr.firstUnchecked != 21 || kr.lastChecked != 40 {
+		t.Errorf("Unexpected window %d-%d.", kr.firstUnchecked, kr.lastChecked)
+	}
+
+	kr, err = FromRanges(nil, 64)
+	if err != nil {
+		t.Fatalf("Failed to create empty KnownRounds: %+v", err)
+	}
+	if ranges := kr.CheckedRanges(); len(ranges) != 0 {
+		t.Errorf("Expected no ranges: %v", ranges)
+	}
+}
+
+// Error path: Tests that FromRanges rejects inverted, unordered, and
+// overlapping ranges and ranges that do not fit in the capacity.
+func TestFromRanges_Error(t *testing.T) {
+	for i, ranges := range [][]RoundRange{
+		{{10, 5}},
+		{{10, 20}, {0, 5}},
+		{{10, 20}, {20, 30}},
+		{{10, 20}, {15, 30}},
+	} {
+		if _, err := FromRanges(ranges, 0); err == nil {
+			t.Errorf("No error for invalid ranges %v (%d).", ranges, i)
+		}
+	}
+
+	_, err := FromRanges([]RoundRange{{0, 10}, {100, 200}}, 64)
+	if !errors.As(err, &ErrBufferTooSmall{}) {
+		t.Errorf("Unexpected error for ranges that do not fit: %+v", err)
+	}
+}
+
+// Tests that RoundRange.String returns the expected string.
+func TestRoundRange_String(t *testing.T) {
+	tests := map[RoundRange]string{
+		{100, 1500}:  "100-1500",
+		{1502, 1502}: "1502",
+	}
+
+	for rr, expected := range tests {
+		if s := rr.String(); s != expected {
+			t.Errorf("Unexpected string.\nexpected: %s\nreceived: %s",
+				expected, s)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"math/rand"
+
+	"github.com/pkg/errors"
+	jww "github.com/spf13/jwalterweatherman"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// RoundTracker is the set of operations shared by KnownRounds and
+// ReferenceKnownRounds that RunEquivalenceCheck exercises.
+type RoundTracker interface {
+	Checked(rid id.Round) bool
+	Check(rid id.Round)
+	Forward(rid id.Round)
+	GetFirstUnchecked() id.Round
+	GetLastChecked() id.Round
+	Len() int
+}
+
+// ReferenceKnownRounds is a slow but obviously correct implementation of the
+// KnownRounds semantics backed by a map. It is intended to be used as a model
+// in property-based tests and should not be used in production code.
+type ReferenceKnownRounds struct {
+	checked        map[id.Round]bool
+	firstUnchecked id.Round
+	lastChecked    id.Round
+	capacity       int
+}
+
+// NewReferenceKnownRounds creates a new ReferenceKnownRounds that enforces the
+// same scope as a KnownRounds with the given round capacity.
+func NewReferenceKnownRounds(roundCapacity int) *ReferenceKnownRounds {
+	return &ReferenceKnownRounds{
+		checked:  make(map[id.Round]bool),
+		capacity: (roundCapacity + 63) / 64 * 64,
+	}
+}
+
+// Checked determines if the round has been checked.
+func (r *ReferenceKnownRounds) Checked(rid id.Round) bool {
+	if rid < r.firstUnchecked {
+		return true
+	} else if rid > r.lastChecked {
+		return false
+	}
+	return r.checked[rid]
+}
+
+// Check denotes a round has been checked. Panics if the round is outside the
+// scope that a KnownRounds of the same capacity can hold.
+func (r *ReferenceKnownRounds) Check(rid id.Round) {
+	if abs(int(r.lastChecked-rid))/r.capacity > 0 {
+		jww.FATAL.Panicf("Cannot check round %d outside the current scope "+
+			"of the reference model.", rid)
+	}
+	if rid < r.firstUnchecked {
+		return
+	}
+
+	r.checked[rid] = true
+	if rid > r.lastChecked {
+		r.lastChecked = rid
+	}
+
+	if rid == r.firstUnchecked {
+		if rid == r.lastChecked {
+			r.firstUnchecked = rid + 1
+			r.lastChecked = rid + 1
+			delete(r.checked, rid+1)
+		} else {
+			r.migrateFirstUnchecked(rid)
+		}
+	}
+
+	r.prune()
+}
+
+// Forward sets all rounds before the given round ID as checked.
+func (r *ReferenceKnownRounds) Forward(rid id.Round) {
+	if rid > r.lastChecked {
+		r.firstUnchecked = rid
+		r.lastChecked = rid
+		r.checked = make(map[id.Round]bool)
+	} else if rid > r.firstUnchecked {
+		r.migrateFirstUnchecked(rid)
+	}
+	r.prune()
+}
+
+// GetFirstUnchecked returns the oldest round that has not been checked.
+func (r *ReferenceKnownRounds) GetFirstUnchecked() id.Round {
+	return r.firstUnchecked
+}
+
+// GetLastChecked returns the newest round that has been checked.
+func (r *ReferenceKnownRounds) GetLastChecked() id.Round {
+	return r.lastChecked
+}
+
+// Len returns the max number of round IDs the model can hold.
+func (r *ReferenceKnownRounds) Len() int {
+	return r.capacity
+}
+
+// migrateFirstUnchecked moves firstUnchecked to the first unchecked round at
+// or after rid.
+func (r *ReferenceKnownRounds) migrateFirstUnchecked(rid id.Round) {
+	for ; r.checked[rid] && rid <= r.lastChecked; rid++ {
+	}
+	r.firstUnchecked = rid
+}
+
+// prune removes entries for rounds before firstUnchecked, which are implicitly
+// checked.
+func (r *ReferenceKnownRounds) prune() {
+	for rid := range r.checked {
+		if rid < r.firstUnchecked {
+			delete(r.checked, rid)
+		}
+	}
+}
+
+// RunEquivalenceCheck applies numOps random in-scope operations, drawn from
+// rng, to both subject and a ReferenceKnownRounds of the same capacity. After
+// each operation, the first unchecked round and the checked state of every
+// round around the window are compared. Returns an error describing the first
+// divergence found. The subject must be newly created.
+func RunEquivalenceCheck(subject RoundTracker, rng *rand.Rand, numOps int) error {
+	model := NewReferenceKnownRounds(subject.Len())
+	if subject.Len() != model.Len() {
+		return errors.Errorf("subject capacity %d is not a multiple of 64",
+			subject.Len())
+	}
+
+	for i := 0; i < numOps; i++ {
+		fu, lc := model.GetFirstUnchecked(), model.GetLastChecked()
+
+		var op string
+		switch n := rng.Intn(100); {
+		case n < 5:
+			rid := fu + id.Round(rng.Intn(int(lc-fu)+model.Len()/2+1))
+			op = "Forward"
+			subject.Forward(rid)
+			model.Forward(rid)
+		default:
+			// Pick a round that neither lapses the first unchecked round nor
+			// falls out of scope of the last checked round
+			hi := fu + id.Round(model.Len()-1)
+			if lc+id.Round(model.Len()-1) < hi {
+				hi = lc + id.Round(model.Len()-1)
+			}
+			rid := fu + id.Round(rng.Int63n(int64(hi-fu)))
+			op = "Check"
+			subject.Check(rid)
+			model.Check(rid)
+		}
+
+		if err := compareTrackers(subject, model); err != nil {
+			return errors.Wrapf(err, "divergence after operation %d (%s)",
+				i, op)
+		}
+	}
+
+	return nil
+}
+
+// compareTrackers returns an error if the subject and model disagree on the
+// first unchecked round or the checked state of any round in their windows.
+func compareTrackers(subject, model RoundTracker) error {
+	if subject.GetFirstUnchecked() != model.GetFirstUnchecked() {
+		return errors.Errorf("first unchecked round mismatch: subject %d, "+
+			"model %d", subject.GetFirstUnchecked(), model.GetFirstUnchecked())
+	}
+
+	start := model.GetFirstUnchecked()
+	if start > 0 {
+		start--
+	}
+	end := model.GetLastChecked() + 2
+	if subject.GetLastChecked()+2 > end {
+		end = subject.GetLastChecked() + 2
+	}
+
+	for rid := start; rid < end; rid++ {
+		if subject.Checked(rid) != model.Checked(rid) {
+			return errors.Errorf("checked state mismatch for round %d: "+
+				"subject %t, model %t", rid, subject.Checked(rid),
+				model.Checked(rid))
+		}
+	}
+
+	return nil
+}
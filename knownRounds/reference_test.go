@@ -0,0 +1,88 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"math/rand"
+	"testing"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// Tests that KnownRounds behaves the same as the reference model for a range
+// of capacities and random operation sequences.
+func TestRunEquivalenceCheck(t *testing.T) {
+	for _, capacity := range []int{64, 128, 320, 1024} {
+		for seed := int64(0); seed < 5; seed++ {
+			rng := rand.New(rand.NewSource(seed))
+			err := RunEquivalenceCheck(NewKnownRound(capacity), rng, 2000)
+			if err != nil {
+				t.Errorf("Equivalence check failed for capacity %d and "+
+					"seed %d: %+v", capacity, seed, err)
+			}
+		}
+	}
+}
+
+// Tests that RunEquivalenceCheck reports a divergence when the subject does not
+// follow KnownRounds semantics.
+func TestRunEquivalenceCheck_Divergence(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	err := RunEquivalenceCheck(&brokenTracker{NewKnownRound(64)}, rng, 100)
+	if err == nil {
+		t.Error("Failed to detect divergence from the reference model.")
+	}
+}
+
+// Tests that ReferenceKnownRounds.Check advances the first unchecked round
+// past contiguous checked rounds.
+func TestReferenceKnownRounds_Check(t *testing.T) {
+	r := NewReferenceKnownRounds(64)
+	r.Check(2)
+	r.Check(1)
+	if r.GetFirstUnchecked() != 0 {
+		t.Errorf("Unexpected first unchecked.\nexpected: %d\nreceived: %d",
+			0, r.GetFirstUnchecked())
+	}
+
+	r.Check(0)
+	if r.GetFirstUnchecked() != 3 {
+		t.Errorf("Unexpected first unchecked.\nexpected: %d\nreceived: %d",
+			3, r.GetFirstUnchecked())
+	}
+
+	for rid, expected := range []bool{true, true, true, false, false} {
+		if r.Checked(id.Round(rid)) != expected {
+			t.Errorf("Checked(%d) returned %t, expected %t",
+				rid, !expected, expected)
+		}
+	}
+}
+
+// Tests that ReferenceKnownRounds.Check panics for rounds out of scope.
+func TestReferenceKnownRounds_Check_OutOfScopePanic(t *testing.T) {
+	r := NewReferenceKnownRounds(64)
+	defer func() {
+		if recover() == nil {
+			t.Error("Failed to panic for round outside of scope.")
+		}
+	}()
+
+	r.Check(64)
+}
+
+// brokenTracker wraps a KnownRounds but ignores every third check.
+type brokenTracker struct {
+	*KnownRounds
+}
+
+func (b *brokenTracker) Check(rid id.Round) {
+	if rid%3 != 0 {
+		b.KnownRounds.Check(rid)
+	}
+}
@@ -0,0 +1,475 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// Constants of the roaring bitmap portable format. See
+// https://github.com/RoaringBitmap/RoaringFormatSpec.
+const (
+	// Cookie of a bitmap with run containers; the upper 16 bits hold the
+	// number of containers minus one
+	roaringSerialCookie = 12347
+
+	// Cookie of a bitmap without run containers
+	roaringSerialCookieNoRun = 12346
+
+	// Bitmaps with run containers have an offset header only if they have at
+	// least this many containers
+	roaringNoOffsetThreshold = 4
+
+	// Containers with more values than this are bitset containers
+	roaringMaxArrayCard = 4096
+
+	// Size of a bitset container in bytes
+	roaringBitsetLen = 8192
+)
+
+// roundInterval is an inclusive range of round IDs.
+type roundInterval struct {
+	start, end uint64
+}
+
+// roaringContainer is a container of a 32-bit roaring bitmap holding the
+// values whose upper 16 bits are the key. Its values are stored as sorted
+// inclusive ranges of the lower 16 bits.
+type roaringContainer struct {
+	key  uint16
+	runs []roundInterval
+}
+
+// ToRoaringBytes returns the set of checked rounds in the 64-bit roaring bitmap
+// portable format, which is implemented in many languages, so that other tools
+// can use the KnownRounds without implementing its bit stream layout. The set
+// includes every round before firstUnchecked; these are stored compactly as
+// run containers.
+//
+// The 64-bit format is the number of 32-bit bitmaps as a little-endian uint64
+// followed, for each bitmap, by the upper 32 bits of its values as a
+// little-endian uint32 and the bitmap in the 32-bit portable format.
+func (kr *KnownRounds) ToRoaringBytes() []byte {
+	// Group the containers of each 32-bit bitmap
+	var highKeys []uint32
+	var bitmaps [][]roaringContainer
+	for _, in := range kr.checkedIntervals() {
+		for v := in.start; ; {
+			// Split the interval at each container boundary
+			end := v | math.MaxUint16
+			if end > in.end {
+				end = in.end
+			}
+
+			high, key := uint32(v>>32), uint16(v>>16)
+			if len(highKeys) == 0 || highKeys[len(highKeys)-1] != high {
+				highKeys = append(highKeys, high)
+				bitmaps = append(bitmaps, nil)
+			}
+			cs := &bitmaps[len(bitmaps)-1]
+			if len(*cs) == 0 || (*cs)[len(*cs)-1].key != key {
+				*cs = append(*cs, roaringContainer{key: key})
+			}
+			c := &(*cs)[len(*cs)-1]
+			c.runs = append(c.runs,
+				roundInterval{v & math.MaxUint16, end & math.MaxUint16})
+
+			if end == in.end {
+				break
+			}
+			v = end + 1
+		}
+	}
+
+	data := binary.LittleEndian.AppendUint64(nil, uint64(len(bitmaps)))
+	for i, containers := range bitmaps {
+		data = binary.LittleEndian.AppendUint32(data, highKeys[i])
+		data = append(data, marshalRoaring32(containers)...)
+	}
+
+	return data
+}
+
+// FromRoaringBytes sets the KnownRounds to the set of checked rounds in the
+// 64-bit roaring bitmap produced by ToRoaringBytes. The first unchecked round
+// is the first round not in the set and the last checked round is the largest
+// round in the set. If the KnownRounds has no bit stream, one just large enough
+// to hold the rounds between them is allocated; otherwise, they must fit in the
+// existing bit stream. Returns an error if the data is malformed or does not
+// fit.
+func (kr *KnownRounds) FromRoaringBytes(data []byte) error {
+	intervals, err := unmarshalRoaring64(data)
+	if err != nil {
+		return errors.WithMessage(err, "KnownRounds FromRoaringBytes")
+	}
+
+	if err = kr.setCheckedIntervals(intervals); err != nil {
+		return errors.WithMessage(err, "KnownRounds FromRoaringBytes")
+	}
+
+	if kr.checkMux == nil {
+		kr.checkMux = &sync.Mutex{}
+	}
+
+	return nil
+}
+
+// setCheckedIntervals sets the KnownRounds to the given sorted, non-adjacent
+// ranges of checked rounds. The first unchecked round is the first round not in
+// a range and the last checked round is the end of the last range. If the
+// KnownRounds has no bit stream, one just large enough to hold the rounds
+// between them is allocated; otherwise, they must fit in the existing bit
+// stream.
+func (kr *KnownRounds) setCheckedIntervals(intervals []roundInterval) error {
+	// Rounds before the first gap are before firstUnchecked
+	var fu, lc id.Round
+	if len(intervals) > 0 {
+		if intervals[0].start == 0 {
+			if intervals[0].end == math.MaxUint64 {
+				return errors.New("every round is checked")
+			}
+			fu = id.Round(intervals[0].end + 1)
+			intervals = intervals[1:]
+		}
+		lc = fu
+		if len(intervals) > 0 {
+			lc = id.Round(intervals[len(intervals)-1].end)
+		} else if fu > 0 {
+			lc = fu - 1
+		}
+	}
+
+	// The rounds from firstUnchecked to lastChecked must fit in the buffer
+	var window uint64
+	if lc >= fu {
+		window = uint64(lc-fu) + 1
+	}
+	if len(kr.bitStream) == 0 {
+		if window > maxBitStreamLen*64 {
+			return errors.Errorf("%d rounds between firstUnchecked %d and "+
+				"lastChecked %d exceed the maximum bit stream of %d words",
+				window, fu, lc, maxBitStreamLen)
+		}
+		words := int((window + 63) / 64)
+		if words == 0 {
+			words = 1
+		}
+		kr.bitStream = make(uint64Buff, words)
+		kr.shared = false
+	} else if window > uint64(kr.Len()) {
+		return errors.WithMessagef(
+			ErrBufferTooSmall{Need: window, Have: uint64(kr.Len())},
+			"%d rounds between firstUnchecked %d and lastChecked %d do not "+
+				"fit in bit stream of %d rounds",
+			window, fu, lc, kr.Len())
+	}
+
+	kr.ownBitStream()
+	kr.bitStream.clearAll()
+	kr.firstUnchecked = fu
+	kr.lastChecked = lc
+	kr.fuPos = int(fu % 64)
+	for _, in := range intervals {
+		for rid := in.start; ; rid++ {
+			kr.bitStream.set(kr.getBitStreamPos(id.Round(rid)))
+			if rid == in.end {
+				break
+			}
+		}
+	}
+	kr.generation++
+
+	return nil
+}
+
+// checkedIntervals returns the sorted ranges of checked rounds, starting with
+// the rounds before firstUnchecked.
+func (kr *KnownRounds) checkedIntervals() []roundInterval {
+	var intervals []roundInterval
+	if kr.firstUnchecked > 0 {
+		intervals = append(intervals,
+			roundInterval{0, uint64(kr.firstUnchecked) - 1})
+	}
+
+	if kr.lastChecked < kr.firstUnchecked {
+		return intervals
+	}
+
+	for i := uint64(0); i <= uint64(kr.lastChecked-kr.firstUnchecked); i++ {
+		rid := uint64(kr.firstUnchecked) + i
+		if !kr.Checked(id.Round(rid)) {
+			continue
+		}
+
+		if n := len(intervals); n > 0 && intervals[n-1].end+1 == rid {
+			intervals[n-1].end = rid
+		} else {
+			intervals = append(intervals, roundInterval{rid, rid})
+		}
+	}
+
+	return intervals
+}
+
+// marshalRoaring32 serialises the containers as a 32-bit roaring bitmap in the
+// portable format. Each container is stored as whichever of a run container and
+// an array or bitset container is smaller.
+func marshalRoaring32(containers []roaringContainer) []byte {
+	isRun := make([]bool, len(containers))
+	sizes := make([]int, len(containers))
+	var hasRun bool
+	for i, c := range containers {
+		sizes[i] = roaringBitsetLen
+		if card := c.cardinality(); card <= roaringMaxArrayCard {
+			sizes[i] = 2 * card
+		}
+		if runSize := 2 + 4*len(c.runs); runSize < sizes[i] {
+			isRun[i], sizes[i], hasRun = true, runSize, true
+		}
+	}
+
+	// Cookie and, if there are run containers, the run flags
+	var data []byte
+	n := len(containers)
+	if hasRun {
+		data = binary.LittleEndian.AppendUint32(
+			nil, roaringSerialCookie|uint32(n-1)<<16)
+		flags := make([]byte, (n+7)/8)
+		for i := range containers {
+			if isRun[i] {
+				flags[i/8] |= 1 << (i % 8)
+			}
+		}
+		data = append(data, flags...)
+	} else {
+		data = binary.LittleEndian.AppendUint32(nil, roaringSerialCookieNoRun)
+		data = binary.LittleEndian.AppendUint32(data, uint32(n))
+	}
+
+	// Descriptive header of each key and cardinality minus one
+	for _, c := range containers {
+		data = binary.LittleEndian.AppendUint16(data, c.key)
+		data = binary.LittleEndian.AppendUint16(data, uint16(c.cardinality()-1))
+	}
+
+	// Offset header of the position of each container
+	if !hasRun || n >= roaringNoOffsetThreshold {
+		offset := len(data) + 4*n
+		for i := range containers {
+			data = binary.LittleEndian.AppendUint32(data, uint32(offset))
+			offset += sizes[i]
+		}
+	}
+
+	for i, c := range containers {
+		switch {
+		case isRun[i]:
+			data = binary.LittleEndian.AppendUint16(data, uint16(len(c.runs)))
+			for _, r := range c.runs {
+				data = binary.LittleEndian.AppendUint16(data, uint16(r.start))
+				data = binary.LittleEndian.AppendUint16(
+					data, uint16(r.end-r.start))
+			}
+		case c.cardinality() > roaringMaxArrayCard:
+			bitset := make([]byte, roaringBitsetLen)
+			for _, r := range c.runs {
+				for v := r.start; v <= r.end; v++ {
+					bitset[v/8] |= 1 << (v % 8)
+				}
+			}
+			data = append(data, bitset...)
+		default:
+			for _, r := range c.runs {
+				for v := r.start; v <= r.end; v++ {
+					data = binary.LittleEndian.AppendUint16(data, uint16(v))
+				}
+			}
+		}
+	}
+
+	return data
+}
+
+// cardinality returns the number of values in the container.
+func (c roaringContainer) cardinality() int {
+	var card int
+	for _, r := range c.runs {
+		card += int(r.end-r.start) + 1
+	}
+	return card
+}
+
+// unmarshalRoaring64 parses a 64-bit roaring bitmap in the portable format and
+// returns its values as sorted, non-adjacent ranges.
+func unmarshalRoaring64(data []byte) ([]roundInterval, error) {
+	if len(data) < 8 {
+		return nil, errors.Errorf("roaring bitmap of %d bytes is too short "+
+			"for the number of bitmaps", len(data))
+	}
+	numBitmaps := binary.LittleEndian.Uint64(data)
+	data = data[8:]
+
+	var intervals []roundInterval
+	for i := uint64(0); i < numBitmaps; i++ {
+		if len(data) < 4 {
+			return nil, errors.Errorf(
+				"roaring bitmap %d of %d is truncated", i, numBitmaps)
+		}
+		high := uint64(binary.LittleEndian.Uint32(data)) << 32
+		if len(intervals) > 0 && high <= intervals[len(intervals)-1].end {
+			return nil, errors.Errorf("roaring bitmap %d of %d is out of "+
+				"order", i, numBitmaps)
+		}
+
+		n, err := unmarshalRoaring32(data[4:], high, &intervals)
+		if err != nil {
+			return nil, errors.WithMessagef(err,
+				"failed to parse roaring bitmap %d of %d", i, numBitmaps)
+		}
+		data = data[4+n:]
+	}
+
+	if len(data) != 0 {
+		return nil, errors.Errorf(
+			"roaring bitmap has %d trailing bytes", len(data))
+	}
+
+	return intervals, nil
+}
+
+// unmarshalRoaring32 parses a 32-bit roaring bitmap in the portable format at
+// the start of the data, adding each value, offset by high, to the intervals.
+// Returns the number of bytes read.
+func unmarshalRoaring32(
+	data []byte, high uint64, intervals *[]roundInterval) (int, error) {
+	r := roaringReader{data: data}
+
+	// Cookie and, if there are run containers, the run flags
+	var n int
+	var runFlags []byte
+	cookie := r.uint32()
+	if cookie&math.MaxUint16 == roaringSerialCookie {
+		n = int(cookie>>16) + 1
+		runFlags = r.next((n + 7) / 8)
+	} else if cookie == roaringSerialCookieNoRun {
+		n = int(r.uint32())
+	} else if r.err == nil {
+		return 0, errors.Errorf("invalid cookie %d", cookie)
+	}
+
+	// Each container has at least four bytes of header
+	if r.err != nil || n > len(r.data)/4 {
+		return 0, errors.New("truncated header")
+	}
+
+	keys, cards := make([]uint16, n), make([]int, n)
+	for i := range keys {
+		keys[i], cards[i] = r.uint16(), int(r.uint16())+1
+		if i > 0 && keys[i] <= keys[i-1] {
+			return 0, errors.Errorf("container %d is out of order", i)
+		}
+	}
+	if runFlags == nil || n >= roaringNoOffsetThreshold {
+		r.next(4 * n)
+	}
+
+	for i, key := range keys {
+		base := high | uint64(key)<<16
+		if runFlags != nil && runFlags[i/8]&(1<<(i%8)) != 0 {
+			numRuns := int(r.uint16())
+			last := -1
+			for j := 0; j < numRuns && r.err == nil; j++ {
+				start, length := int(r.uint16()), int(r.uint16())
+				if start <= last || start+length > math.MaxUint16 {
+					return 0, errors.Errorf(
+						"invalid run %d of container %d", j, i)
+				}
+				addInterval(intervals, base+uint64(start),
+					base+uint64(start+length))
+				last = start + length
+			}
+		} else if cards[i] <= roaringMaxArrayCard {
+			last := -1
+			for j := 0; j < cards[i] && r.err == nil; j++ {
+				v := int(r.uint16())
+				if v <= last {
+					return 0, errors.Errorf("invalid value %d of container "+
+						"%d", j, i)
+				}
+				addInterval(intervals, base+uint64(v), base+uint64(v))
+				last = v
+			}
+		} else {
+			bitset := r.next(roaringBitsetLen)
+			for v := 0; v < len(bitset)*8; v++ {
+				if bitset[v/8]&(1<<(v%8)) != 0 {
+					addInterval(intervals, base+uint64(v), base+uint64(v))
+				}
+			}
+		}
+
+		if r.err != nil {
+			return 0, errors.Errorf("container %d is truncated", i)
+		}
+	}
+
+	return len(data) - len(r.data), nil
+}
+
+// addInterval appends the range to the sorted intervals, merging it with the
+// last interval if they are adjacent.
+func addInterval(intervals *[]roundInterval, start, end uint64) {
+	if n := len(*intervals); n > 0 && (*intervals)[n-1].end+1 == start {
+		(*intervals)[n-1].end = end
+	} else {
+		*intervals = append(*intervals, roundInterval{start, end})
+	}
+}
+
+// roaringReader reads little-endian values from the data, recording an error
+// instead of panicking if the data is too short.
+type roaringReader struct {
+	data []byte
+	err  error
+}
+
+// next returns the next n bytes or nil if there are not enough.
+func (r *roaringReader) next(n int) []byte {
+	if r.err != nil || n > len(r.data) {
+		r.err = errors.New("unexpected end of data")
+		return nil
+	}
+
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+// uint16 returns the next little-endian uint16 or zero if there is not enough
+// data.
+func (r *roaringReader) uint16() uint16 {
+	if b := r.next(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+// uint32 returns the next little-endian uint32 or zero if there is not enough
+// data.
+func (r *roaringReader) uint32() uint32 {
+	if b := r.next(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
@@ -0,0 +1,127 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/rand"
+	"testing"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// Consistency test of KnownRounds.ToRoaringBytes against the portable format
+// of the bitmap {1, 2, 3}, which is a single array container.
+func TestKnownRounds_ToRoaringBytes_Consistency(t *testing.T) {
+	kr := NewKnownRound(64)
+	for _, rid := range []id.Round{1, 2, 3} {
+		kr.Check(rid)
+	}
+
+	expected, _ := hex.DecodeString("0100000000000000" + "00000000" +
+		"3a300000" + "01000000" + "00000200" + "10000000" + "010002000300")
+	data := kr.ToRoaringBytes()
+	if !bytes.Equal(expected, data) {
+		t.Errorf("Unexpected roaring bitmap.\nexpected: %x\nreceived: %x",
+			expected, data)
+	}
+}
+
+// Tests that a KnownRounds exported with KnownRounds.ToRoaringBytes and
+// imported with KnownRounds.FromRoaringBytes has the same checked rounds for
+// run, array, and bitset containers and for rounds past 2^32.
+func TestKnownRounds_ToRoaringBytes_FromRoaringBytes(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	tests := []struct {
+		name  string
+		build func(kr *KnownRounds)
+	}{
+		{"empty", func(kr *KnownRounds) {}},
+		{"allChecked", func(kr *KnownRounds) {
+			for rid := id.Round(0); rid < 500; rid++ {
+				kr.Check(rid)
+			}
+		}},
+		{"random", func(kr *KnownRounds) {
+			kr.Forward(70000)
+			for i := 0; i < 5000; i++ {
+				kr.Check(70000 + id.Round(prng.Intn(10000)))
+			}
+		}},
+		{"alternating", func(kr *KnownRounds) {
+			kr.Forward(100)
+			for rid := id.Round(100); rid < 12000; rid += 2 {
+				kr.Check(rid)
+			}
+		}},
+		{"past2^32", func(kr *KnownRounds) {
+			kr.Forward(1<<32 - 100)
+			for rid := id.Round(1<<32 - 50); rid < 1<<32+50; rid += 3 {
+				kr.Check(rid)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		kr := NewKnownRound(16384)
+		tt.build(kr)
+		data := kr.ToRoaringBytes()
+
+		for _, newKr := range []*KnownRounds{
+			NewKnownRound(0), NewKnownRound(16384)} {
+			if err := newKr.FromRoaringBytes(data); err != nil {
+				t.Fatalf("Failed to import %s: %+v", tt.name, err)
+			}
+
+			start := id.Round(0)
+			if kr.firstUnchecked > 100 {
+				start = kr.firstUnchecked - 100
+			}
+			for rid := start; rid < kr.lastChecked+100; rid++ {
+				if kr.Checked(rid) != newKr.Checked(rid) {
+					t.Errorf("Round %d of %s not imported correctly."+
+						"\nexpected: %t\nreceived: %t",
+						rid, tt.name, kr.Checked(rid), newKr.Checked(rid))
+				}
+			}
+
+			if !bytes.Equal(data, newKr.ToRoaringBytes()) {
+				t.Errorf("Re-exported %s does not match.", tt.name)
+			}
+		}
+	}
+}
+
+// Error path: Tests that KnownRounds.FromRoaringBytes returns an error for
+// malformed bitmaps and for rounds that do not fit in the bit stream.
+func TestKnownRounds_FromRoaringBytes_Error(t *testing.T) {
+	kr := NewKnownRound(16384)
+	kr.Forward(100)
+	for rid := id.Round(100); rid < 10000; rid += 2 {
+		kr.Check(rid)
+	}
+	data := kr.ToRoaringBytes()
+
+	tests := map[string][]byte{
+		"empty":     nil,
+		"truncated": data[:len(data)-1],
+		"trailing":  append(append([]byte{}, data...), 0),
+		"cookie": append(append([]byte{}, data[:12]...),
+			append([]byte{0xFF}, data[13:]...)...),
+	}
+	for name, data := range tests {
+		if err := NewKnownRound(0).FromRoaringBytes(data); err == nil {
+			t.Errorf("No error for %s bitmap.", name)
+		}
+	}
+
+	if err := NewKnownRound(64).FromRoaringBytes(data); err == nil {
+		t.Error("No error for rounds that do not fit in the bit stream.")
+	}
+}
@@ -0,0 +1,158 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"sync"
+
+	jww "github.com/spf13/jwalterweatherman"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// ShardedKnownRounds partitions the round space across multiple independent
+// KnownRounds, each with its own lock, so that concurrent writers checking
+// different rounds do not contend on a single lock. Round rid is stored in
+// shard rid % numShards at local position rid / numShards.
+type ShardedKnownRounds struct {
+	shards []*krShard
+}
+
+// krShard is a single KnownRounds partition guarded by its own lock.
+type krShard struct {
+	kr *KnownRounds
+	sync.Mutex
+}
+
+// NewShardedKnownRounds creates a new ShardedKnownRounds with the given number
+// of shards that can hold at least roundCapacity rounds in total. Panics if
+// numShards is less than one.
+func NewShardedKnownRounds(numShards, roundCapacity int) *ShardedKnownRounds {
+	if numShards < 1 {
+		jww.FATAL.Panicf("Cannot create ShardedKnownRounds with %d shards; "+
+			"there must be at least one shard.", numShards)
+	}
+
+	shardCapacity := (roundCapacity + numShards - 1) / numShards
+	skr := &ShardedKnownRounds{shards: make([]*krShard, numShards)}
+	for i := range skr.shards {
+		skr.shards[i] = &krShard{kr: NewKnownRound(shardCapacity)}
+	}
+
+	return skr
+}
+
+// NumShards returns the number of shards.
+func (skr *ShardedKnownRounds) NumShards() int {
+	return len(skr.shards)
+}
+
+// Checked determines if the round has been checked.
+func (skr *ShardedKnownRounds) Checked(rid id.Round) bool {
+	s, local := skr.locate(rid)
+	s.Lock()
+	defer s.Unlock()
+	return s.kr.Checked(local)
+}
+
+// Check denotes a round has been checked. Panics if the round is outside the
+// scope of its shard. Refer to KnownRounds.Check for more details.
+func (skr *ShardedKnownRounds) Check(rid id.Round) {
+	s, local := skr.locate(rid)
+	s.Lock()
+	defer s.Unlock()
+	s.kr.Check(local)
+}
+
+// ForceCheck denotes a round has been checked, forwarding the round's shard if
+// the round is outside its scope. Refer to KnownRounds.ForceCheck for more
+// details.
+func (skr *ShardedKnownRounds) ForceCheck(rid id.Round) {
+	s, local := skr.locate(rid)
+	s.Lock()
+	defer s.Unlock()
+	s.kr.ForceCheck(local)
+}
+
+// Forward sets all rounds before the given round ID as checked in every shard.
+func (skr *ShardedKnownRounds) Forward(rid id.Round) {
+	for i, s := range skr.shards {
+		// First local round in the shard that is not before rid
+		var local id.Round
+		if rid > id.Round(i) {
+			local = (rid - id.Round(i) + id.Round(len(skr.shards)) - 1) /
+				id.Round(len(skr.shards))
+		}
+
+		s.Lock()
+		s.kr.Forward(local)
+		s.Unlock()
+	}
+}
+
+// Marshal merges all the shards into a single KnownRounds and returns its
+// marshalled form. The output can be unmarshalled by KnownRounds.Unmarshal. All
+// shards are locked for the duration of the merge so that the result is a
+// consistent snapshot.
+func (skr *ShardedKnownRounds) Marshal() []byte {
+	for _, s := range skr.shards {
+		s.Lock()
+	}
+	defer func() {
+		for _, s := range skr.shards {
+			s.Unlock()
+		}
+	}()
+
+	return skr.merge().Marshal()
+}
+
+// merge combines all shards into a single KnownRounds large enough to hold the
+// range between the earliest firstUnchecked and the latest lastChecked of all
+// the shards. The shards must be locked by the caller.
+func (skr *ShardedKnownRounds) merge() *KnownRounds {
+	n := id.Round(len(skr.shards))
+	firstUnchecked := skr.shards[0].kr.firstUnchecked * n
+	var lastChecked id.Round
+	for i, s := range skr.shards {
+		if fu := s.kr.firstUnchecked*n + id.Round(i); fu < firstUnchecked {
+			firstUnchecked = fu
+		}
+		if lc := s.kr.lastChecked*n + id.Round(i); lc > lastChecked {
+			lastChecked = lc
+		}
+	}
+	if lastChecked < firstUnchecked {
+		lastChecked = firstUnchecked
+	}
+
+	fuPos := int(firstUnchecked % 64)
+	numBits := fuPos + int(lastChecked-firstUnchecked) + 1
+	merged := &KnownRounds{
+		bitStream:      make(uint64Buff, (numBits+63)/64),
+		firstUnchecked: firstUnchecked,
+		lastChecked:    lastChecked,
+		fuPos:          fuPos,
+		checkMux:       &sync.Mutex{},
+	}
+
+	for rid := firstUnchecked; rid <= lastChecked; rid++ {
+		if skr.shards[rid%n].kr.Checked(rid / n) {
+			merged.bitStream.set(merged.getBitStreamPos(rid))
+		}
+	}
+
+	return merged
+}
+
+// locate returns the shard that holds the round and the round's local ID in
+// that shard.
+func (skr *ShardedKnownRounds) locate(rid id.Round) (*krShard, id.Round) {
+	n := id.Round(len(skr.shards))
+	return skr.shards[rid%n], rid / n
+}
@@ -0,0 +1,103 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// Tests that ShardedKnownRounds reports the same checked rounds as a single
+// KnownRounds after the same sequence of checks.
+func TestShardedKnownRounds_Check_Checked(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	skr := NewShardedKnownRounds(4, 1024)
+	kr := NewKnownRound(1024)
+
+	for i := 0; i < 300; i++ {
+		rid := id.Round(prng.Intn(800))
+		skr.Check(rid)
+		kr.Check(rid)
+	}
+
+	for rid := id.Round(0); rid < 1000; rid++ {
+		if skr.Checked(rid) != kr.Checked(rid) {
+			t.Errorf("Checked mismatch for round %d.\nexpected: %t"+
+				"\nreceived: %t", rid, kr.Checked(rid), skr.Checked(rid))
+		}
+	}
+}
+
+// Tests that ShardedKnownRounds.Forward marks all rounds before the given
+// round as checked in every shard.
+func TestShardedKnownRounds_Forward(t *testing.T) {
+	skr := NewShardedKnownRounds(3, 300)
+	skr.Check(150)
+	skr.Forward(100)
+
+	for rid := id.Round(0); rid < 100; rid++ {
+		if !skr.Checked(rid) {
+			t.Errorf("Round %d before forwarded round is not checked.", rid)
+		}
+	}
+	for rid := id.Round(100); rid < 150; rid++ {
+		if skr.Checked(rid) {
+			t.Errorf("Round %d after forwarded round is checked.", rid)
+		}
+	}
+	if !skr.Checked(150) {
+		t.Errorf("Round %d is not checked.", 150)
+	}
+}
+
+// Tests that the merged output of ShardedKnownRounds.Marshal can be
+// unmarshalled into a KnownRounds that matches the sharded rounds.
+func TestShardedKnownRounds_Marshal(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	skr := NewShardedKnownRounds(5, 2000)
+	skr.Forward(300)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			prng := rand.New(rand.NewSource(seed))
+			for j := 0; j < 200; j++ {
+				skr.Check(id.Round(300 + prng.Intn(1500)))
+			}
+		}(prng.Int63())
+	}
+	wg.Wait()
+
+	kr := &KnownRounds{}
+	if err := kr.Unmarshal(skr.Marshal()); err != nil {
+		t.Fatalf("Failed to unmarshal merged KnownRounds: %+v", err)
+	}
+
+	for rid := id.Round(0); rid < 2000; rid++ {
+		if skr.Checked(rid) != kr.Checked(rid) {
+			t.Errorf("Checked mismatch for round %d.\nexpected: %t"+
+				"\nreceived: %t", rid, skr.Checked(rid), kr.Checked(rid))
+		}
+	}
+}
+
+// Error path: Tests that NewShardedKnownRounds panics for zero shards.
+func TestNewShardedKnownRounds_NoShardsPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("NewShardedKnownRounds did not panic for zero shards.")
+		}
+	}()
+
+	NewShardedKnownRounds(0, 100)
+}
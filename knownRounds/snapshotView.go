@@ -0,0 +1,99 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// SnapshotView wraps a KnownRounds so that readers never block on writers.
+// Writers apply changes to a private working copy under a lock and then
+// atomically publish a read-only KnownRounds.Snapshot of it. The snapshot
+// shares the bit stream with the working copy, which copies it before it is
+// next modified, so publishing does not copy. Readers load the latest published
+// snapshot without any locking, so they may briefly observe state from before
+// the most recent write.
+type SnapshotView struct {
+	working  *KnownRounds
+	snapshot atomic.Pointer[KnownRounds]
+	mux      sync.Mutex
+}
+
+// NewSnapshotView creates a new SnapshotView that takes ownership of the given
+// KnownRounds. The KnownRounds must not be used directly after this call.
+func NewSnapshotView(kr *KnownRounds) *SnapshotView {
+	sv := &SnapshotView{working: kr}
+	sv.snapshot.Store(kr.Snapshot())
+	return sv
+}
+
+// Checked determines if the round has been checked in the latest published
+// snapshot. It does not lock.
+func (sv *SnapshotView) Checked(rid id.Round) bool {
+	return sv.snapshot.Load().Checked(rid)
+}
+
+// GetFirstUnchecked returns the first unchecked round of the latest published
+// snapshot. It does not lock.
+func (sv *SnapshotView) GetFirstUnchecked() id.Round {
+	return sv.snapshot.Load().firstUnchecked
+}
+
+// GetLastChecked returns the last checked round of the latest published
+// snapshot. It does not lock.
+func (sv *SnapshotView) GetLastChecked() id.Round {
+	return sv.snapshot.Load().lastChecked
+}
+
+// Load returns the latest published snapshot. The returned KnownRounds is
+// shared with other readers and must not be modified.
+func (sv *SnapshotView) Load() *KnownRounds {
+	return sv.snapshot.Load()
+}
+
+// Update applies the changes made by the given function to the working copy
+// and publishes a new snapshot once it returns. The bit stream is copied at
+// most once per Update, on the first change after the previous snapshot was
+// published, so batching several changes into a single Update avoids copying
+// it for each change.
+func (sv *SnapshotView) Update(update func(kr *KnownRounds)) {
+	sv.mux.Lock()
+	defer sv.mux.Unlock()
+
+	update(sv.working)
+	sv.snapshot.Store(sv.working.Snapshot())
+}
+
+// Check marks the round as checked and publishes a new snapshot.
+func (sv *SnapshotView) Check(rid id.Round) {
+	sv.Update(func(kr *KnownRounds) { kr.Check(rid) })
+}
+
+// Forward sets all rounds before the given round as checked and publishes a
+// new snapshot.
+func (sv *SnapshotView) Forward(rid id.Round) {
+	sv.Update(func(kr *KnownRounds) { kr.Forward(rid) })
+}
+
+// deepCopy returns a copy of the KnownRounds that shares no memory with the
+// original. The OnCheck callback is not copied.
+func (kr *KnownRounds) deepCopy() *KnownRounds {
+	return &KnownRounds{
+		bitStream:      kr.bitStream.deepCopy(),
+		firstUnchecked: kr.firstUnchecked,
+		lastChecked:    kr.lastChecked,
+		fuPos:          kr.fuPos,
+		policy:         kr.policy,
+		autoDiscarded:  kr.autoDiscarded,
+		generation:     kr.generation,
+		checkMux:       &sync.Mutex{},
+	}
+}
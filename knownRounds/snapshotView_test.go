@@ -0,0 +1,112 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"sync"
+	"testing"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// Tests that changes made via SnapshotView.Check, SnapshotView.Forward, and
+// SnapshotView.Update are visible to readers once published.
+func TestSnapshotView_Update(t *testing.T) {
+	sv := NewSnapshotView(NewKnownRound(128))
+
+	sv.Check(5)
+	if !sv.Checked(5) || sv.Checked(4) {
+		t.Errorf("Unexpected checked state after Check: 4=%t, 5=%t",
+			sv.Checked(4), sv.Checked(5))
+	}
+
+	sv.Update(func(kr *KnownRounds) {
+		for rid := id.Round(0); rid < 5; rid++ {
+			kr.Check(rid)
+		}
+	})
+	if sv.GetFirstUnchecked() != 6 || sv.GetLastChecked() != 5 {
+		t.Errorf("Unexpected window after Update: firstUnchecked=%d, "+
+			"lastChecked=%d", sv.GetFirstUnchecked(), sv.GetLastChecked())
+	}
+
+	sv.Forward(100)
+	if !sv.Checked(99) || sv.Checked(100) {
+		t.Errorf("Unexpected checked state after Forward: 99=%t, 100=%t",
+			sv.Checked(99), sv.Checked(100))
+	}
+}
+
+// Tests that a loaded snapshot is not affected by later writes.
+func TestSnapshotView_Load_Immutable(t *testing.T) {
+	sv := NewSnapshotView(NewKnownRound(64))
+	snapshot := sv.Load()
+
+	sv.Check(10)
+
+	if snapshot.Checked(10) {
+		t.Error("Previously loaded snapshot was modified by a later write.")
+	}
+	if !sv.Load().Checked(10) {
+		t.Error("New snapshot does not contain the latest write.")
+	}
+}
+
+// Tests that SnapshotView.Update publishes a snapshot that shares the bit
+// stream of the working copy and that the working copy only copies it when it
+// is next modified.
+func TestSnapshotView_Update_CopyOnWrite(t *testing.T) {
+	sv := NewSnapshotView(NewKnownRound(1024))
+	sv.Check(5)
+	snapshot := sv.Load()
+
+	if &snapshot.bitStream[0] != &sv.working.bitStream[0] {
+		t.Error("Published snapshot does not share the bit stream.")
+	}
+
+	// An update that does not modify the bit stream does not copy it
+	sv.Update(func(*KnownRounds) {})
+	if &sv.Load().bitStream[0] != &snapshot.bitStream[0] {
+		t.Error("Bit stream copied by an update that did not modify it.")
+	}
+
+	sv.Check(10)
+	if &sv.working.bitStream[0] == &snapshot.bitStream[0] {
+		t.Error("Working copy modified the bit stream of a snapshot.")
+	}
+	if snapshot.Checked(10) || !sv.Checked(10) {
+		t.Errorf("Unexpected checked state of round 10: snapshot=%t, "+
+			"latest=%t", snapshot.Checked(10), sv.Checked(10))
+	}
+}
+
+// Tests that concurrent readers and a writer do not race. Run with -race.
+func TestSnapshotView_Concurrent(t *testing.T) {
+	sv := NewSnapshotView(NewKnownRound(256))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rid := id.Round(0); rid < 500; rid++ {
+				sv.Checked(rid)
+			}
+		}()
+	}
+
+	for rid := id.Round(0); rid < 200; rid++ {
+		sv.Check(rid)
+	}
+	wg.Wait()
+
+	if sv.GetFirstUnchecked() != 200 {
+		t.Errorf("Unexpected first unchecked.\nexpected: %d\nreceived: %d",
+			200, sv.GetFirstUnchecked())
+	}
+}
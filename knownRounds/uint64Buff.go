@@ -19,6 +19,11 @@ import (
 
 const (
 	ones = math.MaxUint64
+
+	// maxBitStreamLen is the largest bit stream, in 64-bit words, accepted
+	// when unmarshalling. It protects against crafted runs causing huge
+	// allocations.
+	maxBitStreamLen = 1 << 20
 )
 
 type uint64Buff []uint64
@@ -132,6 +137,35 @@ func (u64b uint64Buff) extend(numBlocks int) uint64Buff {
 	return ext
 }
 
+// bits returns the 64 bits starting at the given position in the buffer,
+// wrapping around to the start of the buffer if needed.
+func (u64b uint64Buff) bits(pos int) uint64 {
+	bin, offset := u64b.convertLoc(pos)
+	if offset == 0 {
+		return u64b[bin]
+	}
+	return u64b[bin]<<offset | u64b[u64b.getBin(bin+1)]>>(64-offset)
+}
+
+// wrap clears the buffer and copies the length bits starting at position start
+// of the source, which may span more blocks than the buffer, into the same
+// positions of the buffer, wrapping around to the start of the buffer. The
+// length must not be larger than the buffer.
+func (u64b uint64Buff) wrap(src uint64Buff, start, length int) {
+	u64b.clearAll()
+	end := start + length
+	for i := start / 64; i <= (end-1)/64 && i < len(src); i++ {
+		block := src[i]
+		if i == start/64 {
+			block &= ones >> (start % 64)
+		}
+		if i == (end-1)/64 {
+			block &= ^(ones >> ((end-1)%64 + 1))
+		}
+		u64b[u64b.getBin(i)] |= block
+	}
+}
+
 // convertLoc returns the block index and the position of the bit in that block
 // for the given position in the buffer.
 func (u64b uint64Buff) convertLoc(pos int) (int, int) {
@@ -317,7 +351,7 @@ func unmarshal1ByteVer2(b []byte) (uint64Buff, error) {
 		if num == 0 || num == 0xFF {
 			run, err := buf.ReadByte()
 			if err != nil {
-				jww.FATAL.Panicf("Failed to read next byte: %+v", err)
+				return nil, errors.Errorf("failed to get run: %+v", err)
 			}
 			runBuf := make([]uint8, run)
 			for i := range runBuf {
@@ -357,6 +391,18 @@ func unmarshal1ByteVer2(b []byte) (uint64Buff, error) {
 	return u64b, nil
 }
 
+// checkRunLen returns an error if appending a run of the given length to
+// decoded data of length have would exceed maxBitStreamLen. Both lengths are in
+// words of the encoding, with perWord of them making up a 64-bit word.
+func checkRunLen(have int, run, perWord uint64) error {
+	limit := maxBitStreamLen * perWord
+	if uint64(have) > limit || run > limit-uint64(have) {
+		return errors.Errorf("run of %d exceeds maximum bit stream length "+
+			"of %d words", run, maxBitStreamLen)
+	}
+	return nil
+}
+
 func write2Bytes(i uint16) []byte {
 	b := make([]byte, u16bLen)
 	binary.BigEndian.PutUint16(b, i)
@@ -420,7 +466,15 @@ func unmarshal2BytesVer2(b []byte) (uint64Buff, error) {
 	for ; len(bb) == u16bLen; bb = buf.Next(u16bLen) {
 		num := binary.BigEndian.Uint16(bb)
 		if num == 0 || num == math.MaxUint16 {
-			run := binary.BigEndian.Uint16(buf.Next(u16bLen))
+			bb = buf.Next(u16bLen)
+			if len(bb) != u16bLen {
+				return nil, errors.New("failed to get run")
+			}
+			run := binary.BigEndian.Uint16(bb)
+			err := checkRunLen(len(u16b), uint64(run), 4)
+			if err != nil {
+				return nil, err
+			}
 			runBuf := make([]uint16, run)
 			for i := range runBuf {
 				runBuf[i] = num
@@ -514,7 +568,15 @@ func unmarshal4BytesVer2(b []byte) (uint64Buff, error) {
 	for ; len(bb) == u32bLen; bb = buf.Next(u32bLen) {
 		num := binary.BigEndian.Uint32(bb)
 		if num == 0 || num == math.MaxUint32 {
-			run := binary.BigEndian.Uint32(buf.Next(u32bLen))
+			bb = buf.Next(u32bLen)
+			if len(bb) != u32bLen {
+				return nil, errors.New("failed to get run")
+			}
+			run := binary.BigEndian.Uint32(bb)
+			err := checkRunLen(len(u32b), uint64(run), 2)
+			if err != nil {
+				return nil, err
+			}
 			runBuf := make([]uint32, run)
 			for i := range runBuf {
 				runBuf[i] = num
@@ -602,6 +664,9 @@ func unmarshal8BytesVer2(b []byte) (uint64Buff, error) {
 				return nil, errors.New("failed to get run")
 			}
 			run := binary.LittleEndian.Uint64(bb)
+			if err := checkRunLen(len(buff), run, 1); err != nil {
+				return nil, err
+			}
 			runBuf := make(uint64Buff, run)
 			for i := range runBuf {
 				runBuf[i] = num
@@ -0,0 +1,98 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"github.com/pkg/errors"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// KnownRoundsView is a read-only KnownRounds for clients that only consume
+// rounds received from a gateway. It is unmarshalled directly from the output
+// of KnownRounds.Marshal, without creating a KnownRounds, and keeps only the
+// blocks of the bit stream sent on the wire, so it has no buffer to size and no
+// methods to modify it.
+//
+// The zero value is empty and reports every round as unchecked.
+type KnownRoundsView struct {
+	bitStream      uint64Buff
+	firstUnchecked id.Round
+	lastChecked    id.Round
+	fuPos          int
+}
+
+// Unmarshal parses data produced by KnownRounds.Marshal into the
+// KnownRoundsView, replacing its contents. The view is unchanged if an error is
+// returned.
+func (v *KnownRoundsView) Unmarshal(data []byte) error {
+	w, err := unmarshalWire(data)
+	if err != nil {
+		return errors.WithMessage(err, "KnownRoundsView Unmarshal")
+	} else if len(w.bitStream) == 0 {
+		return errors.New("KnownRoundsView Unmarshal: bit stream is empty")
+	} else if w.lastChecked >= w.firstUnchecked &&
+		uint64(w.lastChecked-w.firstUnchecked) >= uint64(len(w.bitStream))*64 {
+		need := uint64(w.lastChecked-w.firstUnchecked) + 1
+		have := uint64(len(w.bitStream)) * 64
+		return errors.WithMessagef(ErrBufferTooSmall{Need: need, Have: have},
+			"KnownRoundsView Unmarshal: %d rounds between firstUnchecked %d "+
+				"and lastChecked %d do not fit in bit stream of %d rounds",
+			need, w.firstUnchecked, w.lastChecked, have)
+	}
+
+	*v = KnownRoundsView{
+		bitStream:      w.bitStream,
+		firstUnchecked: w.firstUnchecked,
+		lastChecked:    w.lastChecked,
+		fuPos:          int(w.firstUnchecked % 64),
+	}
+
+	// A crafted or corrupted bit stream may mark firstUnchecked as checked;
+	// advance it to the actual first unchecked round, as KnownRounds does
+	for v.firstUnchecked <= v.lastChecked && v.bitStream.get(v.fuPos) {
+		v.firstUnchecked++
+		v.fuPos = (v.fuPos + 1) % (len(v.bitStream) * 64)
+		if v.firstUnchecked == 0 {
+			break
+		}
+	}
+
+	return nil
+}
+
+// Checked determines if the round has been checked.
+func (v *KnownRoundsView) Checked(rid id.Round) bool {
+	if len(v.bitStream) == 0 || rid > v.lastChecked {
+		return false
+	} else if rid < v.firstUnchecked {
+		return true
+	}
+
+	pos := (v.fuPos + int(rid-v.firstUnchecked)) % (len(v.bitStream) * 64)
+	return v.bitStream.get(pos)
+}
+
+// GetFirstUnchecked returns the oldest round that has not been checked.
+func (v *KnownRoundsView) GetFirstUnchecked() id.Round {
+	return v.firstUnchecked
+}
+
+// GetLastChecked returns the newest round that has been checked.
+func (v *KnownRoundsView) GetLastChecked() id.Round {
+	return v.lastChecked
+}
+
+// RangeUnchecked runs roundCheck over the checked rounds from oldestUnknown to
+// the last checked round. See KnownRounds.RangeUnchecked.
+func (v *KnownRoundsView) RangeUnchecked(oldestUnknown id.Round,
+	threshold uint, roundCheck func(id id.Round) bool, maxPickups int) (
+	earliestRound id.Round, has, unknown []id.Round) {
+	return rangeUnchecked(v.Checked, v.lastChecked, oldestUnknown, threshold,
+		roundCheck, maxPickups)
+}
@@ -0,0 +1,120 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package knownRounds
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// Tests that a KnownRoundsView unmarshalled from a marshalled KnownRounds
+// answers Checked and RangeUnchecked the same as the original.
+func TestKnownRoundsView_Unmarshal(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	kr := NewKnownRound(1024)
+	kr.Forward(300)
+	for i := 0; i < 400; i++ {
+		kr.Check(300 + id.Round(prng.Intn(900)))
+	}
+
+	var v KnownRoundsView
+	if err := v.Unmarshal(kr.Marshal()); err != nil {
+		t.Fatalf("Failed to unmarshal: %+v", err)
+	}
+
+	if v.GetFirstUnchecked() != kr.GetFirstUnchecked() ||
+		v.GetLastChecked() != kr.GetLastChecked() {
+		t.Errorf("Unexpected window.\nexpected: [%d, %d]\nreceived: [%d, %d]",
+			kr.GetFirstUnchecked(), kr.GetLastChecked(),
+			v.GetFirstUnchecked(), v.GetLastChecked())
+	}
+
+	for rid := id.Round(0); rid < kr.GetLastChecked()+100; rid++ {
+		if v.Checked(rid) != kr.Checked(rid) {
+			t.Errorf("Round %d not unmarshalled correctly."+
+				"\nexpected: %t\nreceived: %t", rid, kr.Checked(rid),
+				v.Checked(rid))
+		}
+	}
+
+	roundCheck := func(rid id.Round) bool { return rid%3 == 0 }
+	earliest, has, unknown := kr.RangeUnchecked(250, 500, roundCheck, 50)
+	vEarliest, vHas, vUnknown := v.RangeUnchecked(250, 500, roundCheck, 50)
+	if earliest != vEarliest || !reflect.DeepEqual(has, vHas) ||
+		!reflect.DeepEqual(unknown, vUnknown) {
+		t.Errorf("RangeUnchecked does not match.\nexpected: %d %v %v"+
+			"\nreceived: %d %v %v",
+			earliest, has, unknown, vEarliest, vHas, vUnknown)
+	}
+}
+
+// Tests that KnownRoundsView.Unmarshal decodes compressed and
+// generation-stamped data the same as KnownRounds.Unmarshal.
+func TestKnownRoundsView_Unmarshal_Formats(t *testing.T) {
+	kr := NewKnownRound(256)
+	kr.Forward(70)
+	for _, rid := range []id.Round{71, 75, 130, 200} {
+		kr.Check(rid)
+	}
+	compressed, err := kr.MarshalCompressed(Gzip{})
+	if err != nil {
+		t.Fatalf("Failed to compress: %+v", err)
+	}
+
+	for i, data := range [][]byte{compressed, kr.MarshalWithGeneration()} {
+		var v KnownRoundsView
+		if err = v.Unmarshal(data); err != nil {
+			t.Fatalf("Failed to unmarshal data %d: %+v", i, err)
+		}
+		if v.GetFirstUnchecked() != kr.GetFirstUnchecked() ||
+			v.GetLastChecked() != kr.GetLastChecked() {
+			t.Errorf("Unexpected window for data %d."+
+				"\nexpected: [%d, %d]\nreceived: [%d, %d]", i,
+				kr.GetFirstUnchecked(), kr.GetLastChecked(),
+				v.GetFirstUnchecked(), v.GetLastChecked())
+		}
+		for rid := id.Round(0); rid < 300; rid++ {
+			if v.Checked(rid) != kr.Checked(rid) {
+				t.Errorf("Round %d of data %d not unmarshalled correctly.",
+					rid, i)
+			}
+		}
+	}
+}
+
+// Tests that the zero value of KnownRoundsView reports every round as
+// unchecked.
+func TestKnownRoundsView_Checked_Zero(t *testing.T) {
+	var v KnownRoundsView
+	for _, rid := range []id.Round{0, 1, 1000} {
+		if v.Checked(rid) {
+			t.Errorf("Round %d checked in empty view.", rid)
+		}
+	}
+}
+
+// Error path: Tests that KnownRoundsView.Unmarshal returns an error for
+// invalid data and leaves the view unchanged.
+func TestKnownRoundsView_Unmarshal_Error(t *testing.T) {
+	kr := NewKnownRound(128)
+	kr.Check(5)
+	var v KnownRoundsView
+	if err := v.Unmarshal(kr.Marshal()); err != nil {
+		t.Fatalf("Failed to unmarshal: %+v", err)
+	}
+
+	if err := v.Unmarshal([]byte{1, 2, 3}); err == nil {
+		t.Error("No error for invalid data.")
+	}
+	if !v.Checked(5) || v.GetLastChecked() != 5 {
+		t.Error("View modified by failed Unmarshal.")
+	}
+}
@@ -0,0 +1,151 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ackFixedLen is the length of the fixed size fields of a marshalled Ack: the
+// round ID, count, receipt timestamp, and bot ID length.
+const ackFixedLen = 8 + 4 + 8 + 2
+
+// Ack is the acknowledgement sent by a notification bot instance to a gateway
+// when it accepts the batch of notifications for a round.
+type Ack struct {
+	RoundID  uint64    `json:"roundID"`
+	Count    uint32    `json:"count"`
+	Received time.Time `json:"received"`
+	BotID    string    `json:"botID"`
+}
+
+// Marshal serialises the Ack into a byte slice with the following structure,
+// with all integers big-endian and the receipt timestamp in Unix nanoseconds:
+//
+//	+---------+---------+----------+------------+----------+
+//	| RoundID |  Count  | Received | BotID len  |  BotID   |
+//	| 8 bytes | 4 bytes | 8 bytes  |  2 bytes   | variable |
+//	+---------+---------+----------+------------+----------+
+//
+// Returns an error if the bot ID is longer than 65535 bytes.
+func (a Ack) Marshal() ([]byte, error) {
+	if len(a.BotID) > math.MaxUint16 {
+		return nil, errors.Errorf("bot ID length %d exceeds maximum %d",
+			len(a.BotID), math.MaxUint16)
+	}
+
+	b := make([]byte, ackFixedLen, ackFixedLen+len(a.BotID))
+	binary.BigEndian.PutUint64(b[:8], a.RoundID)
+	binary.BigEndian.PutUint32(b[8:12], a.Count)
+	binary.BigEndian.PutUint64(b[12:20], uint64(a.Received.UnixNano()))
+	binary.BigEndian.PutUint16(b[20:22], uint16(len(a.BotID)))
+
+	return append(b, a.BotID...), nil
+}
+
+// UnmarshalAck deserializes the byte slice into an Ack. The receipt timestamp
+// is returned in the local time zone.
+func UnmarshalAck(data []byte) (Ack, error) {
+	if len(data) < ackFixedLen {
+		return Ack{}, errors.Errorf("ack data must be at least %d bytes; "+
+			"received %d bytes", ackFixedLen, len(data))
+	}
+
+	botIDLen := int(binary.BigEndian.Uint16(data[20:22]))
+	if len(data) != ackFixedLen+botIDLen {
+		return Ack{}, errors.Errorf("ack data with bot ID of %d bytes must "+
+			"be %d bytes; received %d bytes",
+			botIDLen, ackFixedLen+botIDLen, len(data))
+	}
+
+	return Ack{
+		RoundID:  binary.BigEndian.Uint64(data[:8]),
+		Count:    binary.BigEndian.Uint32(data[8:12]),
+		Received: time.Unix(0, int64(binary.BigEndian.Uint64(data[12:20]))),
+		BotID:    string(data[ackFixedLen:]),
+	}, nil
+}
+
+// AckBatch is a list of Ack sent together.
+type AckBatch []Ack
+
+// Marshal serialises the AckBatch into a byte slice containing the number of
+// acks as a 4-byte big-endian integer followed by each marshalled Ack prefixed
+// with its 2-byte big-endian length.
+func (ab AckBatch) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(len(ab)))
+	buf.Write(b)
+
+	for i, a := range ab {
+		ack, err := a.Marshal()
+		if err != nil {
+			return nil, errors.WithMessagef(err,
+				"failed to marshal ack %d of %d", i, len(ab))
+		} else if len(ack) > math.MaxUint16 {
+			return nil, errors.Errorf("marshalled ack %d of %d has length "+
+				"%d greater than maximum %d",
+				i, len(ab), len(ack), math.MaxUint16)
+		}
+
+		binary.BigEndian.PutUint16(b, uint16(len(ack)))
+		buf.Write(b[:2])
+		buf.Write(ack)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalAckBatch deserializes the byte slice into an AckBatch.
+func UnmarshalAckBatch(data []byte) (AckBatch, error) {
+	buf := bytes.NewBuffer(data)
+	b := buf.Next(4)
+	if len(b) != 4 {
+		return nil, errors.Errorf("ack batch data must be at least %d bytes; "+
+			"received %d bytes", 4, len(data))
+	}
+
+	num := binary.BigEndian.Uint32(b)
+	if uint64(num)*(2+ackFixedLen) > uint64(buf.Len()) {
+		return nil, errors.Errorf("ack batch data of %d bytes is too short "+
+			"for %d acks", len(data), num)
+	}
+
+	ab := make(AckBatch, num)
+	for i := range ab {
+		b = buf.Next(2)
+		if len(b) != 2 {
+			return nil, errors.Errorf("ack %d of %d is truncated", i, num)
+		}
+
+		ackLen := int(binary.BigEndian.Uint16(b))
+		ackData := buf.Next(ackLen)
+		if len(ackData) != ackLen {
+			return nil, errors.Errorf("ack %d of %d is truncated", i, num)
+		}
+
+		var err error
+		if ab[i], err = UnmarshalAck(ackData); err != nil {
+			return nil, errors.WithMessagef(err,
+				"failed to unmarshal ack %d of %d", i, num)
+		}
+	}
+
+	if buf.Len() != 0 {
+		return nil, errors.Errorf("extraneous data of length %d found at "+
+			"end of ack batch", buf.Len())
+	}
+
+	return ab, nil
+}
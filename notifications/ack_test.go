@@ -0,0 +1,76 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Tests that an AckBatch marshalled with AckBatch.Marshal and unmarshalled
+// with UnmarshalAckBatch matches the original.
+func TestAckBatch_Marshal_UnmarshalAckBatch(t *testing.T) {
+	ab := AckBatch{
+		{42, 7, time.Unix(1700000000, 123), "bot-1"},
+		{43, 0, time.Unix(1700000001, 0), ""},
+		{44, 100, time.Unix(1700000002, 999), "bot-2"},
+	}
+
+	data, err := ab.Marshal()
+	if err != nil {
+		t.Fatalf("Failed to marshal: %+v", err)
+	}
+
+	received, err := UnmarshalAckBatch(data)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal: %+v", err)
+	}
+
+	if !reflect.DeepEqual(ab, received) {
+		t.Errorf("Unmarshalled batch does not match original."+
+			"\nexpected: %+v\nreceived: %+v", ab, received)
+	}
+}
+
+// Tests that an empty AckBatch survives a round trip.
+func TestAckBatch_Marshal_Empty(t *testing.T) {
+	data, err := AckBatch{}.Marshal()
+	if err != nil {
+		t.Fatalf("Failed to marshal: %+v", err)
+	}
+
+	received, err := UnmarshalAckBatch(data)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal: %+v", err)
+	} else if len(received) != 0 {
+		t.Errorf("Expected empty batch, received: %+v", received)
+	}
+}
+
+// Error path: Tests that Ack.Marshal returns an error for a bot ID that is too
+// long and that the unmarshal functions reject truncated data.
+func TestAck_Error(t *testing.T) {
+	if _, err := (Ack{BotID: strings.Repeat("a", 1<<16)}).Marshal(); err == nil {
+		t.Error("Expected error for long bot ID.")
+	}
+
+	ack, _ := Ack{1, 2, time.Unix(3, 0), "bot"}.Marshal()
+	if _, err := UnmarshalAck(ack[:len(ack)-1]); err == nil {
+		t.Error("Expected error for truncated ack.")
+	}
+
+	data, _ := AckBatch{{1, 2, time.Unix(3, 0), "bot"}}.Marshal()
+	for _, d := range [][]byte{data[:3], data[:len(data)-1],
+		append(data, 0)} {
+		if _, err := UnmarshalAckBatch(d); err == nil {
+			t.Errorf("Expected error for invalid batch data %v.", d)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+// BatcherParams are the limits that trigger a Batcher to flush.
+type BatcherParams struct {
+	// MaxSize is the maximum size, in bytes, of a flushed CSV payload. The
+	// batch is flushed before adding an entry that would exceed it.
+	MaxSize int
+
+	// MaxCount is the maximum number of entries in a batch. If zero, the
+	// number of entries is not limited.
+	MaxCount int
+
+	// MaxAge is the longest time the first entry of a batch waits before the
+	// batch is flushed. If zero, batches are not flushed based on age.
+	MaxAge time.Duration
+}
+
+// FlushFunc receives the notifications CSV, as produced by
+// BuildNotificationCSV, and the entries it contains each time a Batcher
+// flushes.
+type FlushFunc func(payload []byte, batch []*Data)
+
+// Batcher accumulates [Data] entries and flushes them as a notifications CSV
+// once the batch reaches BatcherParams.MaxSize or BatcherParams.MaxCount, or
+// its oldest entry reaches BatcherParams.MaxAge. Batches are passed to the
+// FlushFunc in the order they were filled. It is safe for concurrent use.
+type Batcher struct {
+	params  BatcherParams
+	onFlush FlushFunc
+
+	pending []*Data
+	size    int         // Size of the CSV of pending entries
+	timer   *time.Timer // Fires when the oldest pending entry reaches MaxAge
+	gen     uint64      // Incremented on each flush to invalidate old timers
+	stopped bool
+	mux     sync.Mutex
+
+	// flushMux is held while calling onFlush so that batches are delivered in
+	// order without holding mux
+	flushMux sync.Mutex
+}
+
+// NewBatcher creates a new Batcher that passes each flushed batch to onFlush.
+// The FlushFunc is called from the goroutine that triggers the flush, which may
+// be a timer goroutine, and must not call methods on the Batcher. Panics if
+// BatcherParams.MaxSize is less than one or onFlush is nil.
+func NewBatcher(params BatcherParams, onFlush FlushFunc) *Batcher {
+	if params.MaxSize < 1 {
+		jww.FATAL.Panicf("Cannot create Batcher with maximum size %d; "+
+			"size must be at least 1.", params.MaxSize)
+	} else if onFlush == nil {
+		jww.FATAL.Panicf("Cannot create Batcher with nil FlushFunc.")
+	}
+
+	return &Batcher{params: params, onFlush: onFlush}
+}
+
+// Add adds the entry to the current batch, flushing the batch first if the
+// entry would not fit and afterwards if the batch is full. Returns an error if
+// the entry alone exceeds BatcherParams.MaxSize or the Batcher is stopped.
+func (b *Batcher) Add(nd *Data) error {
+	csv, _ := BuildNotificationCSV([]*Data{nd}, math.MaxInt)
+	if len(csv) > b.params.MaxSize {
+		return errors.Errorf("entry of %d bytes exceeds maximum batch size "+
+			"of %d bytes", len(csv), b.params.MaxSize)
+	}
+
+	// Another Add may fill the batch while the lock is released to flush, so
+	// check again each time it is reacquired
+	b.mux.Lock()
+	for b.size+len(csv) > b.params.MaxSize {
+		b.flush()
+		b.mux.Lock()
+	}
+
+	if b.stopped {
+		b.mux.Unlock()
+		return errors.New("cannot add entry to stopped Batcher")
+	}
+
+	b.pending = append(b.pending, nd)
+	b.size += len(csv)
+	if len(b.pending) == 1 && b.params.MaxAge > 0 {
+		gen := b.gen
+		b.timer = time.AfterFunc(b.params.MaxAge, func() { b.flushAged(gen) })
+	}
+
+	if b.size == b.params.MaxSize ||
+		(b.params.MaxCount > 0 && len(b.pending) >= b.params.MaxCount) {
+		b.flush()
+	} else {
+		b.mux.Unlock()
+	}
+
+	return nil
+}
+
+// Flush immediately flushes the current batch, if it has any entries.
+func (b *Batcher) Flush() {
+	b.mux.Lock()
+	b.flush()
+}
+
+// Stop flushes the current batch and stops the Batcher. Entries can no longer
+// be added once it is stopped.
+func (b *Batcher) Stop() {
+	b.mux.Lock()
+	b.stopped = true
+	b.flush()
+}
+
+// Len returns the number of entries in the current batch.
+func (b *Batcher) Len() int {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return len(b.pending)
+}
+
+// flushAged flushes the batch when its timer fires, unless the batch it was
+// started for has already been flushed.
+func (b *Batcher) flushAged(gen uint64) {
+	b.mux.Lock()
+	if gen != b.gen {
+		b.mux.Unlock()
+		return
+	}
+	b.flush()
+}
+
+// flush takes the pending entries and passes them to the FlushFunc. It must be
+// called with mux locked and unlocks it before calling the FlushFunc.
+func (b *Batcher) flush() {
+	if len(b.pending) == 0 {
+		b.mux.Unlock()
+		return
+	}
+
+	batch := b.pending
+	b.pending, b.size = nil, 0
+	b.gen++
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+
+	b.flushMux.Lock()
+	b.mux.Unlock()
+	defer b.flushMux.Unlock()
+
+	csv, _ := BuildNotificationCSV(batch, b.params.MaxSize)
+	b.onFlush(csv, batch)
+}
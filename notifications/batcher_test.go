@@ -0,0 +1,229 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// batchRecorder records the batches flushed by a Batcher.
+type batchRecorder struct {
+	payloads [][]byte
+	batches  [][]*Data
+	mux      sync.Mutex
+}
+
+func (br *batchRecorder) onFlush(payload []byte, batch []*Data) {
+	br.mux.Lock()
+	defer br.mux.Unlock()
+	br.payloads = append(br.payloads, payload)
+	br.batches = append(br.batches, batch)
+}
+
+func (br *batchRecorder) len() int {
+	br.mux.Lock()
+	defer br.mux.Unlock()
+	return len(br.batches)
+}
+
+// Tests that a Batcher flushes once MaxCount entries are added and that every
+// payload is the CSV of its batch.
+func TestBatcher_MaxCount(t *testing.T) {
+	var br batchRecorder
+	b := NewBatcher(BatcherParams{MaxSize: 4096, MaxCount: 3}, br.onFlush)
+	ndList := GenerateTestData(7, rand.New(rand.NewSource(42)))
+
+	for _, nd := range ndList {
+		if err := b.Add(nd); err != nil {
+			t.Fatalf("Failed to add entry: %+v", err)
+		}
+	}
+	if b.Len() != 1 {
+		t.Errorf("Unexpected pending entries.\nexpected: %d\nreceived: %d",
+			1, b.Len())
+	}
+	b.Stop()
+
+	expected := [][]*Data{ndList[:3], ndList[3:6], ndList[6:]}
+	if !reflect.DeepEqual(expected, br.batches) {
+		t.Errorf("Unexpected batches.\nexpected: %v\nreceived: %v",
+			expected, br.batches)
+	}
+	for i, batch := range br.batches {
+		csv, _ := BuildNotificationCSV(batch, math.MaxInt)
+		if !bytes.Equal(csv, br.payloads[i]) {
+			t.Errorf("Unexpected payload %d.\nexpected: %s\nreceived: %s",
+				i, csv, br.payloads[i])
+		}
+	}
+}
+
+// Tests that a Batcher flushes before an entry that would exceed MaxSize and
+// never produces a payload larger than MaxSize.
+func TestBatcher_MaxSize(t *testing.T) {
+	// Every line of the test data has the same length
+	ndList := GenerateTestData(20, rand.New(rand.NewSource(42)))
+	line, _ := BuildNotificationCSV(ndList[:1], math.MaxInt)
+	lineLen := len(line)
+
+	var br batchRecorder
+	b := NewBatcher(BatcherParams{MaxSize: 3*lineLen + 1}, br.onFlush)
+	for _, nd := range ndList {
+		if err := b.Add(nd); err != nil {
+			t.Fatalf("Failed to add entry: %+v", err)
+		}
+	}
+	b.Flush()
+
+	var total int
+	for i, batch := range br.batches {
+		if len(br.payloads[i]) > 3*lineLen+1 {
+			t.Errorf("Payload %d of %d bytes exceeds maximum size.",
+				i, len(br.payloads[i]))
+		}
+		if len(batch) != 3 && i != len(br.batches)-1 {
+			t.Errorf("Unexpected batch %d size.\nexpected: %d\nreceived: %d",
+				i, 3, len(batch))
+		}
+		total += len(batch)
+	}
+	if total != len(ndList) {
+		t.Errorf("Unexpected number of flushed entries."+
+			"\nexpected: %d\nreceived: %d", len(ndList), total)
+	}
+}
+
+// Tests that concurrent calls to Batcher.Add never produce a payload larger
+// than MaxSize and that every added entry is in exactly one payload, which
+// contains exactly the entries of its batch.
+func TestBatcher_Add_Concurrent(t *testing.T) {
+	const goroutines, perGoroutine = 8, 200
+	ndList := GenerateTestData(
+		goroutines*perGoroutine, rand.New(rand.NewSource(42)))
+	line, _ := BuildNotificationCSV(ndList[:1], math.MaxInt)
+	maxSize := 3*len(line) + 1
+
+	// A slow FlushFunc gives other goroutines time to fill the batch while
+	// the lock is released to flush
+	var br batchRecorder
+	b := NewBatcher(BatcherParams{MaxSize: maxSize},
+		func(payload []byte, batch []*Data) {
+			time.Sleep(50 * time.Microsecond)
+			br.onFlush(payload, batch)
+		})
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(entries []*Data) {
+			defer wg.Done()
+			for _, nd := range entries {
+				if err := b.Add(nd); err != nil {
+					t.Errorf("Failed to add entry: %+v", err)
+				}
+			}
+		}(ndList[i*perGoroutine : (i+1)*perGoroutine])
+	}
+	wg.Wait()
+	b.Stop()
+
+	seen := make(map[*Data]bool, len(ndList))
+	for i, batch := range br.batches {
+		if len(br.payloads[i]) > maxSize {
+			t.Errorf("Payload %d of %d bytes exceeds maximum size %d.",
+				i, len(br.payloads[i]), maxSize)
+		}
+		csv, _ := BuildNotificationCSV(batch, math.MaxInt)
+		if !bytes.Equal(csv, br.payloads[i]) {
+			t.Errorf("Payload %d does not contain all entries of its batch.",
+				i)
+		}
+		for _, nd := range batch {
+			if seen[nd] {
+				t.Errorf("Entry flushed more than once: %s", nd)
+			}
+			seen[nd] = true
+		}
+	}
+	if len(seen) != len(ndList) {
+		t.Errorf("Unexpected number of flushed entries."+
+			"\nexpected: %d\nreceived: %d", len(ndList), len(seen))
+	}
+}
+
+// Tests that a Batcher flushes a batch once its first entry reaches MaxAge.
+func TestBatcher_MaxAge(t *testing.T) {
+	var br batchRecorder
+	b := NewBatcher(BatcherParams{MaxSize: 4096, MaxAge: 20 * time.Millisecond},
+		br.onFlush)
+	ndList := GenerateTestData(2, rand.New(rand.NewSource(42)))
+
+	for _, nd := range ndList {
+		if err := b.Add(nd); err != nil {
+			t.Fatalf("Failed to add entry: %+v", err)
+		}
+	}
+
+	for start := time.Now(); br.len() == 0; time.Sleep(time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("Timed out waiting for batch to be flushed.")
+		}
+	}
+
+	if !reflect.DeepEqual([][]*Data{ndList}, br.batches) {
+		t.Errorf("Unexpected batches.\nexpected: %v\nreceived: %v",
+			[][]*Data{ndList}, br.batches)
+	}
+}
+
+// Error path: Tests that Batcher.Add returns an error for an entry larger than
+// MaxSize and after the Batcher is stopped.
+func TestBatcher_Add_Error(t *testing.T) {
+	var br batchRecorder
+	b := NewBatcher(BatcherParams{MaxSize: 10}, br.onFlush)
+	nd := GenerateTestData(1, rand.New(rand.NewSource(42)))[0]
+	if err := b.Add(nd); err == nil {
+		t.Error("No error for entry larger than MaxSize.")
+	}
+
+	b = NewBatcher(BatcherParams{MaxSize: 4096}, br.onFlush)
+	b.Stop()
+	if err := b.Add(nd); err == nil {
+		t.Error("No error for stopped Batcher.")
+	}
+	if br.len() != 0 {
+		t.Errorf("Unexpected batches: %v", br.batches)
+	}
+}
+
+// Tests that NewBatcher panics for an invalid MaxSize or nil FlushFunc.
+func TestNewBatcher_Panic(t *testing.T) {
+	tests := []struct {
+		params  BatcherParams
+		onFlush FlushFunc
+	}{
+		{BatcherParams{MaxSize: 0}, func([]byte, []*Data) {}},
+		{BatcherParams{MaxSize: 10}, nil},
+	}
+
+	for i, tt := range tests {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("No panic for invalid parameters (%d).", i)
+				}
+			}()
+			NewBatcher(tt.params, tt.onFlush)
+		}()
+	}
+}
@@ -0,0 +1,115 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"encoding/binary"
+	"math"
+
+	jww "github.com/spf13/jwalterweatherman"
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	// The length of the bloom filter header, which holds the number of hash
+	// functions.
+	bloomHeaderLen = 1
+
+	// The maximum number of hash functions used by the bloom filter.
+	maxBloomHashes = math.MaxUint8
+)
+
+// BuildIdentityBloom builds a bloom filter of the identity fingerprints in the
+// [Data] list with the given false positive rate. It is small enough to send in
+// a single push so that a client with multiple identities can determine, using
+// CheckIdentityBloom, which of its identities might have messages before
+// fetching the full list.
+//
+// The filter is a one byte header holding the number of hash functions followed
+// by the bit array. Duplicate identities and nil entries are ignored. The false
+// positive rate must be between 0 and 1, exclusive.
+func BuildIdentityBloom(ndList []*Data, falsePositiveRate float64) []byte {
+	if !(falsePositiveRate > 0 && falsePositiveRate < 1) {
+		jww.FATAL.Panicf("Bloom filter false positive rate %f must be "+
+			"between 0 and 1, exclusive", falsePositiveRate)
+	}
+
+	identities := make(map[string]struct{}, len(ndList))
+	for _, nd := range ndList {
+		if nd != nil {
+			identities[string(nd.IdentityFP)] = struct{}{}
+		}
+	}
+
+	// Size the filter for the number of distinct identities using the optimal
+	// number of bits m = -n*ln(p)/ln(2)^2 and hash functions k = m/n*ln(2)
+	n := float64(len(identities))
+	if n == 0 {
+		return []byte{1}
+	}
+	numBits := math.Ceil(
+		-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	numBytes := int(math.Ceil(numBits / 8))
+	numHashes := int(math.Round(float64(numBytes*8) / n * math.Ln2))
+	if numHashes < 1 {
+		numHashes = 1
+	} else if numHashes > maxBloomHashes {
+		numHashes = maxBloomHashes
+	}
+
+	bloom := make([]byte, bloomHeaderLen+numBytes)
+	bloom[0] = byte(numHashes)
+	bits := bloom[bloomHeaderLen:]
+	for identityFP := range identities {
+		indexes := bloomIndexes([]byte(identityFP), numHashes, len(bits)*8)
+		for _, i := range indexes {
+			bits[i/8] |= 1 << (i % 8)
+		}
+	}
+
+	return bloom
+}
+
+// CheckIdentityBloom determines if the identity fingerprint might be in the
+// bloom filter built by BuildIdentityBloom. Returns false only if the identity
+// is definitely not in the filter. A malformed filter returns true so that the
+// client falls back to fetching the full list.
+func CheckIdentityBloom(bloom, identityFP []byte) bool {
+	if len(bloom) < bloomHeaderLen || bloom[0] == 0 {
+		return true
+	}
+
+	bits := bloom[bloomHeaderLen:]
+	if len(bits) == 0 {
+		return false
+	}
+
+	for _, i := range bloomIndexes(identityFP, int(bloom[0]), len(bits)*8) {
+		if bits[i/8]&(1<<(i%8)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// bloomIndexes returns the bit index of each of the hash functions for the
+// identity fingerprint in a filter of numBits bits. The indexes are derived
+// from a single blake2b hash using double hashing.
+func bloomIndexes(identityFP []byte, numHashes, numBits int) []uint64 {
+	h := blake2b.Sum256(identityFP)
+	h1 := binary.BigEndian.Uint64(h[:8])
+	h2 := binary.BigEndian.Uint64(h[8:16])
+
+	indexes := make([]uint64, numHashes)
+	for i := range indexes {
+		indexes[i] = (h1 + uint64(i)*h2) % uint64(numBits)
+	}
+
+	return indexes
+}
@@ -0,0 +1,91 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Tests that every identity in the Data list is found in the bloom filter
+// built by BuildIdentityBloom and that the false positive rate of identities
+// not in the list is close to the requested rate.
+func TestBuildIdentityBloom_CheckIdentityBloom(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	ndList := GenerateTestData(500, prng)
+	const falsePositiveRate = 0.01
+
+	bloom := BuildIdentityBloom(ndList, falsePositiveRate)
+	for i, nd := range ndList {
+		if !CheckIdentityBloom(bloom, nd.IdentityFP) {
+			t.Errorf("Identity %d not found in bloom filter.", i)
+		}
+	}
+
+	const numChecks = 10000
+	var falsePositives int
+	identityFP := make([]byte, IdentityFPLen)
+	for i := 0; i < numChecks; i++ {
+		prng.Read(identityFP)
+		if CheckIdentityBloom(bloom, identityFP) {
+			falsePositives++
+		}
+	}
+
+	if rate := float64(falsePositives) / numChecks; rate > 3*falsePositiveRate {
+		t.Errorf("False positive rate too high.\nexpected: %f\nreceived: %f",
+			falsePositiveRate, rate)
+	}
+}
+
+// Tests that BuildIdentityBloom ignores duplicate identities and nil entries,
+// producing the same filter as the list without them.
+func TestBuildIdentityBloom_Duplicates(t *testing.T) {
+	ndList := GenerateTestData(20, rand.New(rand.NewSource(42)))
+	withDuplicates := append(append([]*Data{nil}, ndList...), ndList...)
+
+	expected := BuildIdentityBloom(ndList, 0.05)
+	bloom := BuildIdentityBloom(withDuplicates, 0.05)
+	if string(expected) != string(bloom) {
+		t.Errorf("Unexpected bloom filter.\nexpected: %v\nreceived: %v",
+			expected, bloom)
+	}
+}
+
+// Tests that a bloom filter of an empty list contains no identities and that a
+// malformed filter matches every identity.
+func TestCheckIdentityBloom_EmptyAndMalformed(t *testing.T) {
+	identityFP := make([]byte, IdentityFPLen)
+
+	bloom := BuildIdentityBloom(nil, 0.01)
+	if CheckIdentityBloom(bloom, identityFP) {
+		t.Errorf("Identity found in empty bloom filter %v.", bloom)
+	}
+
+	for _, malformed := range [][]byte{nil, {0, 0xFF}} {
+		if !CheckIdentityBloom(malformed, identityFP) {
+			t.Errorf("Identity not found in malformed bloom filter %v.",
+				malformed)
+		}
+	}
+}
+
+// Error path: Tests that BuildIdentityBloom panics for a false positive rate
+// outside of (0, 1).
+func TestBuildIdentityBloom_InvalidRatePanic(t *testing.T) {
+	for _, rate := range []float64{0, 1, -0.5, 2} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("Failed to panic for rate %f.", rate)
+				}
+			}()
+			BuildIdentityBloom(nil, rate)
+		}()
+	}
+}
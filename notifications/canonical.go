@@ -0,0 +1,57 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"bytes"
+	"sort"
+)
+
+// CanonicalizeBatch returns a copy of the [Data] list in canonical order so
+// that payloads built from the same notifications are byte-for-byte identical,
+// regardless of the order in which they were received. Entries are sorted by
+// RoundID, then MessageHash, then IdentityFP. Duplicate entries, with the same
+// round, identity fingerprint, and message hash, are removed, keeping the one
+// that appears first in the list. Nil entries are dropped. The original list
+// is not modified.
+func CanonicalizeBatch(ndList []*Data) []*Data {
+	canonical := make([]*Data, 0, len(ndList))
+	seen := make(map[string]struct{}, len(ndList))
+	for _, nd := range ndList {
+		if nd == nil {
+			continue
+		}
+
+		key := dedupeKey(nd)
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		seen[key] = struct{}{}
+		canonical = append(canonical, nd)
+	}
+
+	sort.Slice(canonical, func(i, j int) bool {
+		a, b := canonical[i], canonical[j]
+		if a.RoundID != b.RoundID {
+			return a.RoundID < b.RoundID
+		} else if c := bytes.Compare(a.MessageHash, b.MessageHash); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(a.IdentityFP, b.IdentityFP) < 0
+	})
+
+	return canonical
+}
+
+// BuildCanonicalNotificationCSV canonicalizes the [Data] list with
+// CanonicalizeBatch and then builds the CSV with BuildNotificationCSV. The
+// returned excluded entries are in canonical order.
+func BuildCanonicalNotificationCSV(ndList []*Data, maxSize int) (
+	[]byte, []*Data) {
+	return BuildNotificationCSV(CanonicalizeBatch(ndList), maxSize)
+}
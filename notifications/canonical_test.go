@@ -0,0 +1,75 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// Tests that CanonicalizeBatch sorts by round, message hash, and identity
+// fingerprint, removes duplicates and nil entries, and does not modify the
+// original list.
+func TestCanonicalizeBatch(t *testing.T) {
+	newData := func(round uint64, fp, hash string) *Data {
+		return &Data{RoundID: round, IdentityFP: []byte(fp),
+			MessageHash: []byte(hash)}
+	}
+	a, b, c, d := newData(2, "x", "b"), newData(1, "y", "z"),
+		newData(2, "y", "a"), newData(2, "w", "b")
+	dup := newData(2, "x", "b")
+	dup.Priority = Silent
+
+	ndList := []*Data{a, b, nil, c, dup, d}
+	original := append([]*Data{}, ndList...)
+
+	expected := []*Data{b, c, d, a}
+	canonical := CanonicalizeBatch(ndList)
+	if !reflect.DeepEqual(expected, canonical) || canonical[3] != a {
+		t.Errorf("Unexpected canonical batch.\nexpected: %v\nreceived: %v",
+			expected, canonical)
+	}
+	if !reflect.DeepEqual(original, ndList) {
+		t.Error("Original list was modified.")
+	}
+}
+
+// Tests that BuildCanonicalNotificationCSV produces the same CSV for every
+// permutation of a batch with duplicates.
+func TestBuildCanonicalNotificationCSV(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	ndList := GenerateTestData(20, prng)
+	ndList = append(ndList, ndList[3], ndList[7])
+
+	expected, _ := BuildCanonicalNotificationCSV(ndList, 4096)
+	for i := 0; i < 10; i++ {
+		prng.Shuffle(len(ndList), func(i, j int) {
+			ndList[i], ndList[j] = ndList[j], ndList[i]
+		})
+
+		csv, rest := BuildCanonicalNotificationCSV(ndList, 4096)
+		if !bytes.Equal(expected, csv) {
+			t.Errorf("CSV differs for permutation %d.\nexpected: %s"+
+				"\nreceived: %s", i, expected, csv)
+		}
+		if len(rest) != 0 {
+			t.Errorf("Unexpected excluded entries (%d): %v", i, rest)
+		}
+	}
+
+	decoded, err := DecodeNotificationsCSV(string(expected))
+	if err != nil {
+		t.Fatalf("Failed to decode CSV: %+v", err)
+	}
+	if len(decoded) != 20 {
+		t.Errorf("Duplicates not removed.\nexpected: %d\nreceived: %d",
+			20, len(decoded))
+	}
+}
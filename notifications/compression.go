@@ -0,0 +1,193 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+// Compressor compresses and decompresses notification payloads. Each
+// Compressor is identified by a one-byte marker that prefixes the compressed
+// payload so that the decoder can select the correct algorithm.
+//
+// Markers must be below 0x20 so that they cannot be confused with the first
+// character of an uncompressed CSV, which is always printable base 64.
+type Compressor interface {
+	// Marker returns the byte that identifies the compression format.
+	Marker() byte
+
+	// Compress returns the compressed data.
+	Compress(data []byte) ([]byte, error)
+
+	// Decompress returns the decompressed data, reading no more than limit
+	// bytes of output.
+	Decompress(data []byte, limit int64) ([]byte, error)
+}
+
+// maxDecompressedLen is the largest decompressed payload accepted by
+// DecompressPayload. Provider payloads are limited to a few kilobytes, so
+// only malicious data decompresses to more than this.
+const maxDecompressedLen = 1 << 20
+
+// GzipMarker is the format marker of GzipCompressor.
+const GzipMarker byte = 0x01
+
+// GzipCompressor compresses payloads using gzip at the best compression level.
+type GzipCompressor struct{}
+
+// Marker returns GzipMarker. This function adheres to the Compressor interface.
+func (GzipCompressor) Marker() byte { return GzipMarker }
+
+// Compress compresses the data using gzip. This function adheres to the
+// Compressor interface.
+func (GzipCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(data); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress decompresses gzip data. Returns an error if it decompresses to
+// more than limit bytes. This function adheres to the Compressor interface.
+func (GzipCompressor) Decompress(data []byte, limit int64) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	decompressed, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	} else if int64(len(decompressed)) > limit {
+		return nil, errors.Errorf(
+			"decompressed data exceeds %d bytes", limit)
+	}
+	return decompressed, nil
+}
+
+var (
+	compressors = map[byte]Compressor{
+		GzipMarker: GzipCompressor{},
+	}
+	compressorsMux sync.RWMutex
+)
+
+// RegisterCompressor adds a Compressor, such as a zstd implementation, so that
+// its payloads can be decompressed by DecompressPayload. Panics if the marker
+// is not below 0x20 or is already registered.
+func RegisterCompressor(c Compressor) {
+	compressorsMux.Lock()
+	defer compressorsMux.Unlock()
+
+	if c.Marker() == 0 || c.Marker() >= 0x20 {
+		jww.FATAL.Panicf("Compressor marker %#x must be between 0x01 and "+
+			"0x1F.", c.Marker())
+	} else if _, exists := compressors[c.Marker()]; exists {
+		jww.FATAL.Panicf("Compressor marker %#x already registered.",
+			c.Marker())
+	}
+
+	compressors[c.Marker()] = c
+}
+
+// CompressPayload compresses the payload and prefixes it with the
+// Compressor's marker. The compressed data is base 64 encoded after the marker
+// so that the payload is valid UTF-8 and survives being carried as a JSON
+// string in provider payloads, which replace invalid UTF-8.
+func CompressPayload(payload []byte, c Compressor) ([]byte, error) {
+	compressed, err := c.Compress(payload)
+	if err != nil {
+		return nil, errors.Wrapf(err,
+			"failed to compress payload with marker %#x", c.Marker())
+	}
+
+	data := make([]byte, 1+base64.StdEncoding.EncodedLen(len(compressed)))
+	data[0] = c.Marker()
+	base64.StdEncoding.Encode(data[1:], compressed)
+	return data, nil
+}
+
+// DecompressPayload decompresses a payload produced by CompressPayload. If the
+// payload does not start with a compression marker, it is returned unchanged.
+// Returns an error if the payload decompresses to more than 1 MiB.
+func DecompressPayload(data []byte) ([]byte, error) {
+	if len(data) == 0 || data[0] >= 0x20 {
+		return data, nil
+	}
+
+	compressorsMux.RLock()
+	c, exists := compressors[data[0]]
+	compressorsMux.RUnlock()
+	if !exists {
+		return nil, errors.Errorf("unknown compression marker %#x", data[0])
+	}
+
+	compressed := make([]byte, base64.StdEncoding.DecodedLen(len(data)-1))
+	n, err := base64.StdEncoding.Decode(compressed, data[1:])
+	if err != nil {
+		return nil, errors.Wrapf(err,
+			"failed to decode payload with marker %#x", data[0])
+	}
+
+	decompressed, err := c.Decompress(compressed[:n], maxDecompressedLen)
+	if err != nil {
+		return nil, errors.Wrapf(err,
+			"failed to decompress payload with marker %#x", data[0])
+	}
+	return decompressed, nil
+}
+
+// BuildCompressedNotificationCSV converts the [Data] list into a compressed
+// CSV, as produced by BuildNotificationCSV, that includes as many entries as
+// possible while the compressed payload, including its marker, stays within
+// maxSize. Returns the payload and the excluded [Data] entries.
+func BuildCompressedNotificationCSV(ndList []*Data, maxSize int,
+	c Compressor) ([]byte, []*Data, error) {
+	build := func(n int) ([]byte, error) {
+		csv, _ := BuildNotificationCSV(ndList[:n], int(^uint(0)>>1))
+		return CompressPayload(csv, c)
+	}
+
+	// Find the largest number of entries whose compressed size fits
+	var buildErr error
+	n := sort.Search(len(ndList), func(i int) bool {
+		payload, err := build(i + 1)
+		if err != nil {
+			buildErr = err
+			return true
+		}
+		return len(payload) > maxSize
+	})
+	if buildErr != nil {
+		return nil, nil, buildErr
+	}
+
+	payload, err := build(n)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return payload, ndList[n:], nil
+}
@@ -0,0 +1,158 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// Tests that a payload compressed via CompressPayload and decompressed via
+// DecompressPayload matches the original.
+func TestCompressPayload_DecompressPayload(t *testing.T) {
+	expected := bytes.Repeat([]byte("notification data,"), 100)
+
+	compressed, err := CompressPayload(expected, GzipCompressor{})
+	if err != nil {
+		t.Fatalf("Failed to compress: %+v", err)
+	}
+	if compressed[0] != GzipMarker {
+		t.Errorf("Unexpected marker.\nexpected: %#x\nreceived: %#x",
+			GzipMarker, compressed[0])
+	}
+	if len(compressed) >= len(expected) {
+		t.Errorf("Compressed size %d not smaller than original %d.",
+			len(compressed), len(expected))
+	}
+
+	decompressed, err := DecompressPayload(compressed)
+	if err != nil {
+		t.Fatalf("Failed to decompress: %+v", err)
+	}
+	if !bytes.Equal(expected, decompressed) {
+		t.Errorf("Decompressed payload does not match original.")
+	}
+
+	// Uncompressed payloads pass through unchanged
+	if plain, _ := DecompressPayload(expected); !bytes.Equal(expected, plain) {
+		t.Errorf("Uncompressed payload was modified.")
+	}
+}
+
+// Tests that a compressed payload survives being carried as a JSON string, as
+// it is in provider payloads.
+func TestCompressPayload_JSONString(t *testing.T) {
+	expected := bytes.Repeat([]byte("notification data,"), 100)
+	compressed, err := CompressPayload(expected, GzipCompressor{})
+	if err != nil {
+		t.Fatalf("Failed to compress: %+v", err)
+	}
+
+	data, err := json.Marshal(string(compressed))
+	if err != nil {
+		t.Fatalf("Failed to JSON marshal: %+v", err)
+	}
+	var received string
+	if err = json.Unmarshal(data, &received); err != nil {
+		t.Fatalf("Failed to JSON unmarshal: %+v", err)
+	}
+
+	decompressed, err := DecompressPayload([]byte(received))
+	if err != nil {
+		t.Fatalf("Failed to decompress: %+v", err)
+	}
+	if !bytes.Equal(expected, decompressed) {
+		t.Errorf("Decompressed payload does not match original.")
+	}
+}
+
+// Error path: Tests that DecompressPayload rejects payloads that decompress to
+// more than maxDecompressedLen bytes.
+func TestDecompressPayload_TooLargeError(t *testing.T) {
+	compressed, err := CompressPayload(
+		make([]byte, maxDecompressedLen+1), GzipCompressor{})
+	if err != nil {
+		t.Fatalf("Failed to compress: %+v", err)
+	}
+
+	if _, err = DecompressPayload(compressed); err == nil {
+		t.Error("No error for payload exceeding the decompressed limit.")
+	}
+
+	compressed, _ = CompressPayload(
+		make([]byte, maxDecompressedLen), GzipCompressor{})
+	if _, err = DecompressPayload(compressed); err != nil {
+		t.Errorf("Failed to decompress payload at the limit: %+v", err)
+	}
+}
+
+// Error path: Tests that DecompressPayload rejects unknown markers and corrupt
+// data.
+func TestDecompressPayload_Error(t *testing.T) {
+	if _, err := DecompressPayload([]byte{0x1F, 1, 2, 3}); err == nil {
+		t.Error("No error for unknown marker.")
+	}
+	if _, err := DecompressPayload([]byte{GzipMarker, 1, 2, 3}); err == nil {
+		t.Error("No error for invalid base 64.")
+	}
+	if _, err := DecompressPayload([]byte("\x01AQID")); err == nil {
+		t.Error("No error for corrupt gzip data.")
+	}
+}
+
+// Tests that BuildCompressedNotificationCSV fits more entries than
+// BuildNotificationCSV for the same size and that DecodeNotificationsCSV
+// transparently decodes the compressed payload.
+func TestBuildCompressedNotificationCSV(t *testing.T) {
+	// Use repeated identity fingerprints, as seen in heavy rounds
+	dataList := newTestDataList(300, 42)
+	for i := range dataList {
+		dataList[i].IdentityFP = dataList[i%3].IdentityFP
+	}
+
+	const maxSize = 4096
+	payload, rest, err :=
+		BuildCompressedNotificationCSV(dataList, maxSize, GzipCompressor{})
+	if err != nil {
+		t.Fatalf("Failed to build compressed CSV: %+v", err)
+	}
+	if len(payload) > maxSize {
+		t.Errorf("Payload size %d larger than maximum %d.",
+			len(payload), maxSize)
+	}
+
+	_, uncompressedRest := BuildNotificationCSV(dataList, maxSize)
+	if len(rest) >= len(uncompressedRest) {
+		t.Errorf("Compression did not increase the number of included "+
+			"entries: %d excluded compressed vs %d uncompressed.",
+			len(rest), len(uncompressedRest))
+	}
+
+	decoded, err := DecodeNotificationsCSV(string(payload))
+	if err != nil {
+		t.Fatalf("Failed to decode compressed CSV: %+v", err)
+	}
+	if !reflect.DeepEqual(dataList[:len(decoded)], decoded) ||
+		len(decoded)+len(rest) != len(dataList) {
+		t.Errorf("Decoded entries do not match the included entries.")
+	}
+}
+
+// Error path: Tests that RegisterCompressor panics for a marker that is
+// already registered.
+func TestRegisterCompressor_DuplicateMarkerPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("RegisterCompressor did not panic for a used marker.")
+		}
+	}()
+
+	RegisterCompressor(GzipCompressor{})
+}
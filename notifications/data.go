@@ -18,11 +18,34 @@ import (
 	jww "github.com/spf13/jwalterweatherman"
 )
 
+// Data describes a single notification.
+//
+// Every field has explicit json, cbor, and msgpack struct tags with the same
+// key so that alternate serializers used by storage layers produce
+// interoperable bytes. The canonical order of the fields, which serializers
+// should use when writing them, is returned by Data.FieldOrder.
 type Data struct {
-	EphemeralID int64
-	RoundID     uint64
-	IdentityFP  []byte
-	MessageHash []byte
+	EphemeralID int64  `json:"EphemeralID" cbor:"EphemeralID" msgpack:"EphemeralID"`
+	RoundID     uint64 `json:"RoundID" cbor:"RoundID" msgpack:"RoundID"`
+	IdentityFP  []byte `json:"IdentityFP" cbor:"IdentityFP" msgpack:"IdentityFP"`
+	MessageHash []byte `json:"MessageHash" cbor:"MessageHash" msgpack:"MessageHash"`
+
+	// Priority is omitted from the JSON encoding when it is Immediate so that
+	// the encodings of existing data are unchanged. It is not carried by the
+	// CSV of BuildNotificationCSV.
+	Priority Priority `json:"Priority,omitempty" cbor:"Priority,omitempty" msgpack:"Priority,omitempty"`
+}
+
+// dataFieldOrder is the canonical order of the serialized keys of Data.
+var dataFieldOrder = []string{
+	"EphemeralID", "RoundID", "IdentityFP", "MessageHash", "Priority"}
+
+// FieldOrder returns the serialized keys of the fields of Data in their
+// canonical order. Serializers that do not preserve the order of struct fields
+// should write the keys in this order. The returned slice is a copy and may be
+// modified.
+func (Data) FieldOrder() []string {
+	return append([]string{}, dataFieldOrder...)
 }
 
 func (d *Data) String() string {
@@ -41,7 +64,12 @@ func (d *Data) String() string {
 //
 // The CSV contains each [Data] entry on its own row with column one the
 // [Data.MessageHash] and column two having the [Data.IdentityFP], but base 64
-// encoded
+// encoded. The [Data.Priority] is not included so that every row has two
+// columns, as expected by clients that predate versioning; use
+// BuildNamedNotificationCSV or the binary encoding to carry it.
+//
+// Entries are written in the order given. Use BuildCanonicalNotificationCSV to
+// produce the same CSV for any order of the same entries.
 func BuildNotificationCSV(ndList []*Data, maxSize int) ([]byte, []*Data) {
 	var buf bytes.Buffer
 	var numWritten int
@@ -74,9 +102,16 @@ func BuildNotificationCSV(ndList []*Data, maxSize int) ([]byte, []*Data) {
 	return buf.Bytes(), ndList[numWritten:]
 }
 
-// DecodeNotificationsCSV decodes the Data list CSV into a slice of Data.
+// DecodeNotificationsCSV decodes the Data list CSV into a slice of Data. CSVs
+// compressed with CompressPayload are transparently decompressed.
 func DecodeNotificationsCSV(data string) ([]*Data, error) {
-	r := csv.NewReader(strings.NewReader(data))
+	decompressed, err := DecompressPayload([]byte(data))
+	if err != nil {
+		return nil, errors.WithMessage(err,
+			"Failed to decompress notifications CSV.")
+	}
+
+	r := csv.NewReader(bytes.NewReader(decompressed))
 	records, err := r.ReadAll()
 	if err != nil {
 		return nil, errors.Wrapf(err, "Failed to read notifications CSV records.")
@@ -173,3 +173,32 @@ func TestDecodeNotificationsCSV_NoEofError(t *testing.T) {
 			"\nexpected: %s\nreceived: %+v", expectedErr, err)
 	}
 }
+
+// Tests that Data.FieldOrder lists every field of Data in declaration order
+// and that the json, cbor, and msgpack keys of each field match it.
+func TestData_FieldOrder(t *testing.T) {
+	order := Data{}.FieldOrder()
+	dataType := reflect.TypeOf(Data{})
+	if dataType.NumField() != len(order) {
+		t.Fatalf("Unexpected number of fields.\nexpected: %d\nreceived: %d",
+			dataType.NumField(), len(order))
+	}
+
+	for i, key := range order {
+		field := dataType.Field(i)
+		for _, tag := range []string{"json", "cbor", "msgpack"} {
+			name := strings.Split(field.Tag.Get(tag), ",")[0]
+			if name != key {
+				t.Errorf("Unexpected %s key for field %s (%d)."+
+					"\nexpected: %q\nreceived: %q",
+					tag, field.Name, i, key, name)
+			}
+		}
+	}
+
+	// Modifying the returned slice does not modify the canonical order
+	order[0] = "modified"
+	if (Data{}).FieldOrder()[0] != "EphemeralID" {
+		t.Errorf("FieldOrder returned the canonical slice.")
+	}
+}
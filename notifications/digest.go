@@ -0,0 +1,236 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DigestPrefixLen is the number of leading bytes of Data.IdentityFP that
+	// identify an identity in a Digest.
+	DigestPrefixLen = 8
+
+	// digestMarker is the first byte of an encoded Digest. It has the
+	// versionMarker bit set, so it never starts a CSV or compressed payload,
+	// and is far above any Version.
+	digestMarker byte = versionMarker | 0x40
+
+	// digestHeaderLen is the length of the marker and the omitted counts.
+	digestHeaderLen = 1 + 4 + 4
+
+	// digestEntryLen is the length of an encoded DigestEntry, excluding its
+	// prefix.
+	digestEntryLen = 1 + 4 + 8
+)
+
+// Digest summarizes a [Data] list by identity instead of listing every
+// notification. It is sent in place of the list when the list does not fit in
+// a payload; see BuildPayloadOrDigest.
+type Digest struct {
+	// Entries contains one entry per identity, ordered by DigestEntry.Less.
+	Entries []DigestEntry
+
+	// OmittedIdentities and OmittedMessages are the number of identities and
+	// their messages that were dropped from the Digest when it was encoded
+	// because it exceeded the maximum size.
+	OmittedIdentities uint32
+	OmittedMessages   uint32
+}
+
+// DigestEntry summarizes the notifications of a single identity.
+type DigestEntry struct {
+	// IdentityPrefix is the first DigestPrefixLen bytes of the identity's
+	// Data.IdentityFP, or all of it if it is shorter.
+	IdentityPrefix []byte
+
+	// Count is the number of notifications for the identity.
+	Count uint32
+
+	// NewestRound is the largest Data.RoundID of the identity's notifications.
+	NewestRound uint64
+}
+
+// Less determines if the entry is ordered before the other in a Digest.
+// Entries with newer rounds come first, so truncation drops the identities
+// with the oldest activity. Ties are broken by the identity prefix so that
+// the order is deterministic.
+func (de DigestEntry) Less(other DigestEntry) bool {
+	if de.NewestRound != other.NewestRound {
+		return de.NewestRound > other.NewestRound
+	}
+	return bytes.Compare(de.IdentityPrefix, other.IdentityPrefix) < 0
+}
+
+// NewDigest summarizes the [Data] list, grouping entries by the prefix of
+// their IdentityFP. Nil entries are skipped.
+func NewDigest(ndList []*Data) Digest {
+	index := make(map[string]int)
+	var d Digest
+	for _, nd := range ndList {
+		if nd == nil {
+			continue
+		}
+
+		prefix := nd.IdentityFP
+		if len(prefix) > DigestPrefixLen {
+			prefix = prefix[:DigestPrefixLen]
+		}
+
+		i, exists := index[string(prefix)]
+		if !exists {
+			i = len(d.Entries)
+			index[string(prefix)] = i
+			d.Entries = append(d.Entries,
+				DigestEntry{IdentityPrefix: append([]byte{}, prefix...)})
+		}
+
+		e := &d.Entries[i]
+		if e.Count < math.MaxUint32 {
+			e.Count++
+		}
+		if nd.RoundID > e.NewestRound {
+			e.NewestRound = nd.RoundID
+		}
+	}
+
+	sort.Slice(d.Entries, func(i, j int) bool {
+		return d.Entries[i].Less(d.Entries[j])
+	})
+
+	return d
+}
+
+// Encode serialises the Digest into a payload no larger than maxSize. All
+// clients interpret a truncated Digest the same way because truncation
+// follows these rules:
+//
+//  1. Entries are written in the order of DigestEntry.Less, newest first.
+//  2. Entries are written until the first one that does not fit. It and every
+//     entry after it are omitted, even if a later, shorter entry would fit.
+//  3. The numbers of omitted identities and messages, added to any already in
+//     the Digest, are written in the header so that clients can report them.
+//
+// The payload has the following structure, with all integers big-endian:
+//
+//	+--------+-------------------+-----------------+---------+-----+
+//	| marker | OmittedIdentities | OmittedMessages | entry 1 | ... |
+//	| 1 byte |      4 bytes      |     4 bytes     |         |     |
+//	+--------+-------------------+-----------------+---------+-----+
+//
+// Each entry is a 1-byte prefix length, the prefix, the 4-byte count, and the
+// 8-byte newest round. Returns an error if the header does not fit.
+func (d Digest) Encode(maxSize int) ([]byte, error) {
+	if maxSize < digestHeaderLen {
+		return nil, errors.Errorf("digest header of %d bytes does not fit "+
+			"in the maximum payload size of %d bytes",
+			digestHeaderLen, maxSize)
+	}
+
+	omittedIdentities, omittedMessages :=
+		d.OmittedIdentities, d.OmittedMessages
+	body := make([]byte, 0, maxSize-digestHeaderLen)
+	for i, e := range d.Entries {
+		if len(e.IdentityPrefix) > DigestPrefixLen {
+			return nil, errors.Errorf("entry %d prefix length %d exceeds "+
+				"maximum %d", i, len(e.IdentityPrefix), DigestPrefixLen)
+		}
+
+		if digestHeaderLen+len(body)+digestEntryLen+len(e.IdentityPrefix) >
+			maxSize {
+			for _, omitted := range d.Entries[i:] {
+				omittedIdentities = addSaturating(omittedIdentities, 1)
+				omittedMessages =
+					addSaturating(omittedMessages, omitted.Count)
+			}
+			break
+		}
+
+		body = append(body, byte(len(e.IdentityPrefix)))
+		body = append(body, e.IdentityPrefix...)
+		body = binary.BigEndian.AppendUint32(body, e.Count)
+		body = binary.BigEndian.AppendUint64(body, e.NewestRound)
+	}
+
+	payload := make([]byte, 1, digestHeaderLen+len(body))
+	payload[0] = digestMarker
+	payload = binary.BigEndian.AppendUint32(payload, omittedIdentities)
+	payload = binary.BigEndian.AppendUint32(payload, omittedMessages)
+	return append(payload, body...), nil
+}
+
+// DecodeDigest deserialises a payload produced by Digest.Encode.
+func DecodeDigest(payload []byte) (Digest, error) {
+	if !IsDigest(payload) {
+		return Digest{}, errors.New("payload is not a digest")
+	} else if len(payload) < digestHeaderLen {
+		return Digest{}, errors.Errorf("digest header is truncated: "+
+			"%d < %d bytes", len(payload), digestHeaderLen)
+	}
+
+	d := Digest{
+		OmittedIdentities: binary.BigEndian.Uint32(payload[1:5]),
+		OmittedMessages:   binary.BigEndian.Uint32(payload[5:9]),
+	}
+
+	buf := bytes.NewBuffer(payload[digestHeaderLen:])
+	for buf.Len() > 0 {
+		prefixLen, _ := buf.ReadByte()
+		if prefixLen > DigestPrefixLen {
+			return Digest{}, errors.Errorf("entry %d prefix length %d "+
+				"exceeds maximum %d", len(d.Entries), prefixLen,
+				DigestPrefixLen)
+		} else if buf.Len() < int(prefixLen)+digestEntryLen-1 {
+			return Digest{}, errors.Errorf("entry %d is truncated",
+				len(d.Entries))
+		}
+
+		d.Entries = append(d.Entries, DigestEntry{
+			IdentityPrefix: append([]byte{}, buf.Next(int(prefixLen))...),
+			Count:          binary.BigEndian.Uint32(buf.Next(4)),
+			NewestRound:    binary.BigEndian.Uint64(buf.Next(8)),
+		})
+	}
+
+	return d, nil
+}
+
+// IsDigest determines if the payload is an encoded Digest rather than a
+// payload that can be decoded with DecodeAny.
+func IsDigest(payload []byte) bool {
+	return len(payload) > 0 && payload[0] == digestMarker
+}
+
+// BuildPayloadOrDigest encodes the [Data] list with BuildVersionedPayload if
+// every entry fits in maxSize. Otherwise, it encodes a Digest of the list and
+// returns true. Clients use IsDigest to tell the two apart.
+func BuildPayloadOrDigest(ndList []*Data, maxSize int, v Version) (
+	[]byte, bool, error) {
+	payload, rest, err := BuildVersionedPayload(ndList, maxSize, v)
+	if err != nil {
+		return nil, false, err
+	} else if len(rest) == 0 {
+		return payload, false, nil
+	}
+
+	payload, err = NewDigest(ndList).Encode(maxSize)
+	return payload, true, err
+}
+
+// addSaturating returns a+b, capped at math.MaxUint32.
+func addSaturating(a, b uint32) uint32 {
+	if a > math.MaxUint32-b {
+		return math.MaxUint32
+	}
+	return a + b
+}
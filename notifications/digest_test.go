@@ -0,0 +1,169 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// Tests that NewDigest groups entries by identity prefix, counts them, tracks
+// the newest round, and orders the entries newest first.
+func TestNewDigest(t *testing.T) {
+	fpA := bytes.Repeat([]byte{'a'}, IdentityFPLen)
+	fpA2 := append(bytes.Repeat([]byte{'a'}, DigestPrefixLen),
+		bytes.Repeat([]byte{'x'}, IdentityFPLen-DigestPrefixLen)...)
+	fpB := bytes.Repeat([]byte{'b'}, IdentityFPLen)
+	fpC := []byte{'c'}
+
+	ndList := []*Data{
+		{RoundID: 5, IdentityFP: fpA},
+		{RoundID: 9, IdentityFP: fpB},
+		nil,
+		{RoundID: 7, IdentityFP: fpA2},
+		{RoundID: 9, IdentityFP: fpC},
+		{RoundID: 2, IdentityFP: fpB},
+	}
+
+	expected := Digest{Entries: []DigestEntry{
+		{fpB[:DigestPrefixLen], 2, 9},
+		{fpC, 1, 9},
+		{fpA[:DigestPrefixLen], 2, 7},
+	}}
+	if d := NewDigest(ndList); !reflect.DeepEqual(expected, d) {
+		t.Errorf("Unexpected digest.\nexpected: %+v\nreceived: %+v",
+			expected, d)
+	}
+}
+
+// Tests that a Digest encoded with Digest.Encode and decoded with DecodeDigest
+// matches the original when it fits.
+func TestDigest_Encode_DecodeDigest(t *testing.T) {
+	d := NewDigest(GenerateTestData(20, rand.New(rand.NewSource(42))))
+	d.OmittedIdentities, d.OmittedMessages = 3, 7
+
+	payload, err := d.Encode(4096)
+	if err != nil {
+		t.Fatalf("Failed to encode: %+v", err)
+	}
+	if !IsDigest(payload) {
+		t.Error("Encoded payload is not a digest.")
+	}
+
+	decoded, err := DecodeDigest(payload)
+	if err != nil {
+		t.Fatalf("Failed to decode: %+v", err)
+	}
+	if !reflect.DeepEqual(d, decoded) {
+		t.Errorf("Decoded digest does not match original."+
+			"\nexpected: %+v\nreceived: %+v", d, decoded)
+	}
+}
+
+// Tests that Digest.Encode stops at the first entry that does not fit, even if
+// a later shorter entry would fit, and counts every omitted identity and
+// message in the header.
+func TestDigest_Encode_Truncated(t *testing.T) {
+	d := Digest{
+		Entries: []DigestEntry{
+			{[]byte("12345678"), 4, 30},
+			{[]byte("abcdefgh"), 2, 20},
+			{[]byte("z"), 5, 10},
+		},
+		OmittedIdentities: 1,
+		OmittedMessages:   1,
+	}
+
+	// Room for the first entry and the short last entry, but not the second
+	maxSize := digestHeaderLen + 2*digestEntryLen + 8 + 1
+	payload, err := d.Encode(maxSize)
+	if err != nil {
+		t.Fatalf("Failed to encode: %+v", err)
+	}
+
+	decoded, err := DecodeDigest(payload)
+	if err != nil {
+		t.Fatalf("Failed to decode: %+v", err)
+	}
+	expected := Digest{
+		Entries:           d.Entries[:1],
+		OmittedIdentities: 3,
+		OmittedMessages:   8,
+	}
+	if !reflect.DeepEqual(expected, decoded) {
+		t.Errorf("Unexpected truncated digest.\nexpected: %+v\nreceived: %+v",
+			expected, decoded)
+	}
+}
+
+// Tests that BuildPayloadOrDigest returns a versioned payload when every entry
+// fits and a Digest otherwise.
+func TestBuildPayloadOrDigest(t *testing.T) {
+	ndList := GenerateTestData(50, rand.New(rand.NewSource(42)))
+
+	payload, isDigest, err := BuildPayloadOrDigest(ndList, 8192, VersionBinary)
+	if err != nil || isDigest {
+		t.Fatalf("Expected full payload: %t %+v", isDigest, err)
+	}
+	if decoded, _, err := DecodeAny(payload); err != nil ||
+		len(decoded) != len(ndList) {
+		t.Errorf("Failed to decode full payload: %d %+v", len(decoded), err)
+	}
+
+	payload, isDigest, err = BuildPayloadOrDigest(ndList, 512, VersionBinary)
+	if err != nil || !isDigest || !IsDigest(payload) {
+		t.Fatalf("Expected digest: %t %+v", isDigest, err)
+	}
+	if len(payload) > 512 {
+		t.Errorf("Digest of %d bytes exceeds maximum size.", len(payload))
+	}
+
+	d, err := DecodeDigest(payload)
+	if err != nil {
+		t.Fatalf("Failed to decode digest: %+v", err)
+	}
+	var total uint32
+	for _, e := range d.Entries {
+		total += e.Count
+	}
+	if total+d.OmittedMessages != uint32(len(ndList)) {
+		t.Errorf("Digest does not account for every message."+
+			"\nexpected: %d\nreceived: %d", len(ndList),
+			total+d.OmittedMessages)
+	}
+}
+
+// Error path: Tests that Digest.Encode and DecodeDigest return errors for
+// invalid input.
+func TestDigest_Encode_DecodeDigest_Error(t *testing.T) {
+	if _, err := (Digest{}).Encode(digestHeaderLen - 1); err == nil {
+		t.Error("No error when the header does not fit.")
+	}
+	long := Digest{Entries: []DigestEntry{{IdentityPrefix: make([]byte, 9)}}}
+	if _, err := long.Encode(4096); err == nil {
+		t.Error("No error for prefix that is too long.")
+	}
+
+	payload, _ := Digest{Entries: []DigestEntry{{[]byte("ab"), 1, 2}}}.
+		Encode(4096)
+	tests := map[string][]byte{
+		"empty":           nil,
+		"notDigest":       {versionMarker | byte(VersionBinary)},
+		"truncatedHeader": payload[:digestHeaderLen-1],
+		"truncatedEntry":  payload[:len(payload)-1],
+		"longPrefix": append(append([]byte{}, payload[:digestHeaderLen]...),
+			DigestPrefixLen+1),
+	}
+	for name, data := range tests {
+		if _, err := DecodeDigest(data); err == nil {
+			t.Errorf("No error for %s payload.", name)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"crypto/rand"
+	"io"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	// EncryptionKeyLen is the length, in bytes, of the symmetric key used by
+	// EncryptPayload and DecryptPayload.
+	EncryptionKeyLen = chacha20poly1305.KeySize
+
+	// encryptedMarker is the first byte of an encrypted payload. It has the
+	// versionMarker bit set, so it never starts a CSV or compressed payload,
+	// and does not collide with a Version or the digestMarker.
+	encryptedMarker byte = versionMarker | 0x20
+
+	// encryptionNonceLen is the length of the random nonce that follows the
+	// marker.
+	encryptionNonceLen = chacha20poly1305.NonceSizeX
+
+	// EncryptionOverhead is the number of bytes EncryptPayload adds to the
+	// payload: the marker, the nonce, and the authentication tag.
+	EncryptionOverhead = 1 + encryptionNonceLen + chacha20poly1305.Overhead
+)
+
+// ErrDecryptionFailed is returned by DecryptPayload when the payload was not
+// encrypted with the key or has been modified.
+var ErrDecryptionFailed = errors.New("failed to authenticate encrypted payload")
+
+// EncryptPayload encrypts and authenticates the notification payload with the
+// symmetric key so that push providers, which relay the payload to the client,
+// cannot read the identity fingerprints or message hashes it contains. The key
+// must be EncryptionKeyLen bytes.
+//
+// The payload is sealed with XChaCha20-Poly1305 using a random nonce, which is
+// large enough that nonces can be chosen at random for every payload without
+// risk of reuse under the same key. The marker byte is authenticated as
+// additional data. The output is EncryptionOverhead bytes longer than the
+// payload, which must be accounted for when choosing the payload's max size.
+// The output is binary, so use WrapPayload to place it in a provider payload,
+// which base 64 encodes it and adds a third to its size.
+//
+//	+--------+-------+------------+-----+
+//	| marker | nonce | ciphertext | tag |
+//	| 1 byte |  24   |  variable  | 16  |
+//	+--------+-------+------------+-----+
+func EncryptPayload(payload, symmetricKey []byte) ([]byte, error) {
+	return encryptPayload(payload, symmetricKey, rand.Reader)
+}
+
+// encryptPayload encrypts the payload using a nonce read from the given
+// reader.
+func encryptPayload(payload, symmetricKey []byte, csprng io.Reader) (
+	[]byte, error) {
+	aead, err := chacha20poly1305.NewX(symmetricKey)
+	if err != nil {
+		return nil, errors.Errorf("invalid key length %d; expected %d",
+			len(symmetricKey), EncryptionKeyLen)
+	}
+
+	data := make([]byte, 1+encryptionNonceLen,
+		EncryptionOverhead+len(payload))
+	data[0] = encryptedMarker
+	nonce := data[1:]
+	if _, err = io.ReadFull(csprng, nonce); err != nil {
+		return nil, errors.Wrap(err, "failed to generate nonce")
+	}
+
+	return aead.Seal(data, nonce, payload, data[:1]), nil
+}
+
+// DecryptPayload authenticates and decrypts a payload produced by
+// EncryptPayload with the same symmetric key. Returns ErrDecryptionFailed if
+// the key is wrong or the payload has been modified.
+func DecryptPayload(data, symmetricKey []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(symmetricKey)
+	if err != nil {
+		return nil, errors.Errorf("invalid key length %d; expected %d",
+			len(symmetricKey), EncryptionKeyLen)
+	}
+
+	if len(data) < EncryptionOverhead {
+		return nil, errors.Errorf("encrypted payload of %d bytes is shorter "+
+			"than the minimum of %d bytes", len(data), EncryptionOverhead)
+	} else if !IsEncrypted(data) {
+		return nil, errors.Errorf("payload is not encrypted: unexpected "+
+			"marker %#x", data[0])
+	}
+
+	nonce := data[1 : 1+encryptionNonceLen]
+	payload, err := aead.Open(
+		nil, nonce, data[1+encryptionNonceLen:], data[:1])
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+
+	return payload, nil
+}
+
+// IsEncrypted determines if the payload was produced by EncryptPayload.
+func IsEncrypted(data []byte) bool {
+	return len(data) > 0 && data[0] == encryptedMarker
+}
@@ -0,0 +1,213 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// Tests that a payload encrypted with EncryptPayload and decrypted with
+// DecryptPayload matches the original and that the ciphertext does not
+// contain the payload.
+func TestEncryptPayload_DecryptPayload(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	key := make([]byte, EncryptionKeyLen)
+	prng.Read(key)
+
+	for i, size := range []int{0, 1, 64, MaxAPNSPayload} {
+		payload := make([]byte, size)
+		prng.Read(payload)
+
+		data, err := EncryptPayload(payload, key)
+		if err != nil {
+			t.Fatalf("Failed to encrypt payload (%d): %+v", i, err)
+		}
+
+		if len(data) != size+EncryptionOverhead {
+			t.Errorf("Unexpected encrypted length (%d)."+
+				"\nexpected: %d\nreceived: %d",
+				i, size+EncryptionOverhead, len(data))
+		}
+		if !IsEncrypted(data) {
+			t.Errorf("Encrypted payload not detected (%d).", i)
+		}
+		// Short payloads may appear in the ciphertext by chance
+		if size >= 16 && bytes.Contains(data, payload) {
+			t.Errorf("Encrypted payload contains the plaintext (%d).", i)
+		}
+
+		decrypted, err := DecryptPayload(data, key)
+		if err != nil {
+			t.Fatalf("Failed to decrypt payload (%d): %+v", i, err)
+		}
+		if !bytes.Equal(payload, decrypted) {
+			t.Errorf("Unexpected decrypted payload (%d)."+
+				"\nexpected: %v\nreceived: %v", i, payload, decrypted)
+		}
+	}
+}
+
+// Tests that EncryptPayload uses a new nonce for every payload, so the same
+// payload encrypted twice produces different ciphertexts.
+func TestEncryptPayload_UniqueNonce(t *testing.T) {
+	key := make([]byte, EncryptionKeyLen)
+	payload := []byte("identity fingerprint and message hash")
+
+	data1, err := EncryptPayload(payload, key)
+	if err != nil {
+		t.Fatalf("Failed to encrypt payload: %+v", err)
+	}
+	data2, err := EncryptPayload(payload, key)
+	if err != nil {
+		t.Fatalf("Failed to encrypt payload: %+v", err)
+	}
+
+	nonceEnd := 1 + encryptionNonceLen
+	if bytes.Equal(data1[1:nonceEnd], data2[1:nonceEnd]) {
+		t.Errorf("Nonce reused: %v", data1[1:nonceEnd])
+	}
+	if bytes.Equal(data1, data2) {
+		t.Errorf("Same payload encrypted to the same ciphertext.")
+	}
+}
+
+// Tests that encrypted payloads are not mistaken for any other payload type.
+func TestIsEncrypted_OtherPayloads(t *testing.T) {
+	csv, _ := BuildNotificationCSV(nil, 64)
+	digest, _ := Digest{}.Encode(64)
+	for i, payload := range [][]byte{
+		nil, csv, []byte("dGVzdA==,dGVzdA==\n"), {GzipMarker},
+		{versionMarker | byte(VersionCSV)}, {versionMarker | byte(LatestVersion)},
+		digest,
+	} {
+		if IsEncrypted(payload) {
+			t.Errorf("Payload %v detected as encrypted (%d).", payload, i)
+		}
+	}
+}
+
+// Error path: Tests that EncryptPayload and DecryptPayload reject keys of the
+// wrong length.
+func TestEncryptPayload_DecryptPayload_InvalidKey(t *testing.T) {
+	for _, n := range []int{0, EncryptionKeyLen - 1, EncryptionKeyLen + 1} {
+		key := make([]byte, n)
+		if _, err := EncryptPayload([]byte("payload"), key); err == nil {
+			t.Errorf("No error encrypting with key of length %d.", n)
+		}
+		data := make([]byte, EncryptionOverhead)
+		data[0] = encryptedMarker
+		if _, err := DecryptPayload(data, key); err == nil {
+			t.Errorf("No error decrypting with key of length %d.", n)
+		}
+	}
+}
+
+// Error path: Tests that DecryptPayload returns ErrDecryptionFailed when the
+// key is wrong or any byte of the payload is modified.
+func TestDecryptPayload_AuthenticationFailure(t *testing.T) {
+	key := make([]byte, EncryptionKeyLen)
+	data, err := EncryptPayload([]byte("payload"), key)
+	if err != nil {
+		t.Fatalf("Failed to encrypt payload: %+v", err)
+	}
+
+	wrongKey := make([]byte, EncryptionKeyLen)
+	wrongKey[0] = 1
+	_, err = DecryptPayload(data, wrongKey)
+	if !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("Unexpected error for wrong key: %+v", err)
+	}
+
+	// The marker is checked before authentication, so start after it
+	for i := 1; i < len(data); i++ {
+		tampered := append([]byte{}, data...)
+		tampered[i] ^= 0x01
+		_, err = DecryptPayload(tampered, key)
+		if !errors.Is(err, ErrDecryptionFailed) {
+			t.Errorf("Unexpected error for modified byte %d: %+v", i, err)
+		}
+	}
+}
+
+// Error path: Tests that DecryptPayload rejects payloads that are too short or
+// were not encrypted.
+func TestDecryptPayload_InvalidPayload(t *testing.T) {
+	key := make([]byte, EncryptionKeyLen)
+	data, err := EncryptPayload([]byte("payload"), key)
+	if err != nil {
+		t.Fatalf("Failed to encrypt payload: %+v", err)
+	}
+
+	notEncrypted := append([]byte{}, data...)
+	notEncrypted[0] = versionMarker | byte(VersionBinary)
+	for i, payload := range [][]byte{
+		nil, data[:EncryptionOverhead-1], notEncrypted,
+	} {
+		if _, err = DecryptPayload(payload, key); err == nil {
+			t.Errorf("No error decrypting invalid payload (%d).", i)
+		} else if errors.Is(err, ErrDecryptionFailed) {
+			t.Errorf("Invalid payload reported as failed authentication "+
+				"(%d): %+v", i, err)
+		}
+	}
+}
+
+// Tests that an encrypted payload placed in a provider payload with
+// WrapPayload and extracted with ParseProviderPayload decrypts to the original
+// and that the provider payload does not contain the plaintext.
+func TestEncryptPayload_WrapPayload(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	key := make([]byte, EncryptionKeyLen)
+	prng.Read(key)
+
+	ndList := GenerateTestData(10, prng)
+	csv, _ := BuildNotificationCSV(ndList, 2048)
+	data, err := EncryptPayload(csv, key)
+	if err != nil {
+		t.Fatalf("Failed to encrypt payload: %+v", err)
+	}
+
+	for _, provider := range []Provider{APNS, FCM} {
+		wrapped, err := WrapPayload(data, provider)
+		if err != nil {
+			t.Fatalf("Failed to wrap %s payload: %+v", provider, err)
+		}
+		if bytes.Contains(wrapped, csv[:32]) {
+			t.Errorf("%s payload contains the plaintext.", provider)
+		}
+
+		received, _, err := ParseProviderPayload(wrapped, provider)
+		if err != nil {
+			t.Fatalf("Failed to parse %s payload: %+v", provider, err)
+		}
+
+		decrypted, err := DecryptPayload([]byte(received), key)
+		if err != nil {
+			t.Fatalf("Failed to decrypt %s payload: %+v", provider, err)
+		}
+
+		decoded, err := DecodeNotificationsCSV(string(decrypted))
+		if err != nil {
+			t.Fatalf("Failed to decode %s payload: %+v", provider, err)
+		} else if len(decoded) != len(ndList) {
+			t.Fatalf("Unexpected number of entries from %s."+
+				"\nexpected: %d\nreceived: %d",
+				provider, len(ndList), len(decoded))
+		}
+		for i, nd := range decoded {
+			if !bytes.Equal(nd.MessageHash, ndList[i].MessageHash) ||
+				!bytes.Equal(nd.IdentityFP, ndList[i].IdentityFP) {
+				t.Errorf("Entry %d from %s does not match.", i, provider)
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+// MaxAddressSize is the largest ephemeral ID address space size, in bits.
+const MaxAddressSize = 64
+
+// EncodeEphemeralForPush converts the ephemeral ID into its canonical form for
+// inclusion in a notification payload. Only the lowest size bits of the ID are
+// kept, which removes any sign extension applied to the int64, and the result
+// is encoded big-endian into the minimum number of bytes that can hold size
+// bits.
+func EncodeEphemeralForPush(ephID int64, size uint) ([]byte, error) {
+	if err := checkAddressSize(size); err != nil {
+		return nil, err
+	}
+
+	var buff [8]byte
+	binary.BigEndian.PutUint64(buff[:], uint64(ephID)&addressMask(size))
+
+	return buff[8-ephemeralEncodedLen(size):], nil
+}
+
+// DecodeEphemeralFromPush decodes an ephemeral ID encoded with
+// EncodeEphemeralForPush using the same address space size. The returned ID
+// matches the int64 form of an ephemeral ID truncated to size bits, so it is
+// only negative when size is 64 and the top bit is set. Returns an error if the
+// data is the wrong length or has bits set outside the address space.
+func DecodeEphemeralFromPush(data []byte, size uint) (int64, error) {
+	if err := checkAddressSize(size); err != nil {
+		return 0, err
+	}
+
+	if len(data) != ephemeralEncodedLen(size) {
+		return 0, errors.Errorf("encoded ephemeral ID for address size %d "+
+			"must be %d bytes; received %d bytes",
+			size, ephemeralEncodedLen(size), len(data))
+	}
+
+	var buff [8]byte
+	copy(buff[8-len(data):], data)
+	value := binary.BigEndian.Uint64(buff[:])
+
+	if value&^addressMask(size) != 0 {
+		return 0, errors.Errorf("encoded ephemeral ID %d has bits set "+
+			"outside of the %d-bit address space", value, size)
+	}
+
+	return int64(value), nil
+}
+
+// checkAddressSize returns an error if the address space size is not between 1
+// and MaxAddressSize bits.
+func checkAddressSize(size uint) error {
+	if size < 1 || size > MaxAddressSize {
+		return errors.Errorf("address size %d must be between 1 and %d bits",
+			size, MaxAddressSize)
+	}
+	return nil
+}
+
+// addressMask returns a mask of the lowest size bits.
+func addressMask(size uint) uint64 {
+	if size >= MaxAddressSize {
+		return ^uint64(0)
+	}
+	return (1 << size) - 1
+}
+
+// ephemeralEncodedLen returns the number of bytes needed to hold size bits.
+func ephemeralEncodedLen(size uint) int {
+	return int(size+7) / 8
+}
@@ -0,0 +1,77 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Tests that EncodeEphemeralForPush truncates to the address size and strips
+// sign extension and that DecodeEphemeralFromPush recovers the canonical ID.
+func TestEncodeEphemeralForPush_DecodeEphemeralFromPush(t *testing.T) {
+	tests := []struct {
+		ephID    int64
+		size     uint
+		encoded  []byte
+		expected int64
+	}{
+		{5, 16, []byte{0, 5}, 5},
+		{-1, 16, []byte{0xFF, 0xFF}, 0xFFFF},
+		{-2, 12, []byte{0x0F, 0xFE}, 0xFFE},
+		{0x1234, 8, []byte{0x34}, 0x34},
+		{-1, 1, []byte{1}, 1},
+		{-1, 64, bytes.Repeat([]byte{0xFF}, 8), -1},
+		{0x7FFFFFFFFFFFFFFF, 63,
+			[]byte{0x7F, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF},
+			0x7FFFFFFFFFFFFFFF},
+	}
+
+	for i, tt := range tests {
+		encoded, err := EncodeEphemeralForPush(tt.ephID, tt.size)
+		if err != nil {
+			t.Errorf("Failed to encode ephemeral ID (%d): %+v", i, err)
+			continue
+		}
+		if !bytes.Equal(tt.encoded, encoded) {
+			t.Errorf("Unexpected encoding (%d).\nexpected: %v\nreceived: %v",
+				i, tt.encoded, encoded)
+		}
+
+		decoded, err := DecodeEphemeralFromPush(encoded, tt.size)
+		if err != nil {
+			t.Errorf("Failed to decode ephemeral ID (%d): %+v", i, err)
+		} else if decoded != tt.expected {
+			t.Errorf("Unexpected decoded ID (%d).\nexpected: %d\nreceived: %d",
+				i, tt.expected, decoded)
+		}
+	}
+}
+
+// Tests that EncodeEphemeralForPush returns an error for invalid sizes.
+func TestEncodeEphemeralForPush_InvalidSizeError(t *testing.T) {
+	for _, size := range []uint{0, 65} {
+		if _, err := EncodeEphemeralForPush(1, size); err == nil {
+			t.Errorf("Expected error for address size %d.", size)
+		}
+	}
+}
+
+// Tests that DecodeEphemeralFromPush returns an error for data of the wrong
+// length or with bits outside the address space.
+func TestDecodeEphemeralFromPush_Error(t *testing.T) {
+	if _, err := DecodeEphemeralFromPush([]byte{1, 2, 3}, 16); err == nil {
+		t.Error("Expected error for data of the wrong length.")
+	}
+	if _, err := DecodeEphemeralFromPush([]byte{0x10, 0}, 12); err == nil {
+		t.Error("Expected error for bits outside the address space.")
+	}
+	if _, err := DecodeEphemeralFromPush(nil, 0); err == nil {
+		t.Error("Expected error for invalid address size.")
+	}
+}
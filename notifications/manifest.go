@@ -0,0 +1,100 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	// ManifestDigestLen is the length of RoundManifest.Digest.
+	ManifestDigestLen = blake2b.Size256
+
+	// RoundManifestLen is the length of a marshalled RoundManifest.
+	RoundManifestLen = 8 + 4 + ManifestDigestLen
+)
+
+// RoundManifest summarises the notifications the bot received for a single
+// round. The bot sends it to gateways to acknowledge a processed round, and
+// gateways compare it against what they sent to detect missing callbacks.
+type RoundManifest struct {
+	RoundID uint64                  `json:"roundID"`
+	Count   uint32                  `json:"count"`
+	Digest  [ManifestDigestLen]byte `json:"digest"`
+}
+
+// NewRoundManifest creates a RoundManifest for the given round from the
+// notifications in ndList. Only entries for the given round are included. The
+// digest depends on the order of the entries.
+func NewRoundManifest(roundID uint64, ndList []*Data) RoundManifest {
+	// Hash creation only fails for invalid key lengths
+	h, _ := blake2b.New256(nil)
+	rm := RoundManifest{RoundID: roundID}
+
+	var buff [8]byte
+	for _, nd := range ndList {
+		if nd == nil || nd.RoundID != roundID {
+			continue
+		}
+		rm.Count++
+
+		binary.BigEndian.PutUint64(buff[:], uint64(nd.EphemeralID))
+		h.Write(buff[:])
+		for _, field := range [][]byte{nd.IdentityFP, nd.MessageHash} {
+			binary.BigEndian.PutUint64(buff[:], uint64(len(field)))
+			h.Write(buff[:])
+			h.Write(field)
+		}
+	}
+
+	copy(rm.Digest[:], h.Sum(nil))
+	return rm
+}
+
+// Matches determines if the RoundManifest describes the same notifications as
+// those in ndList for the manifest's round.
+func (rm RoundManifest) Matches(ndList []*Data) bool {
+	return rm == NewRoundManifest(rm.RoundID, ndList)
+}
+
+// Marshal serialises the RoundManifest into a byte slice of length
+// RoundManifestLen: the round ID and count, both big-endian, followed by the
+// digest.
+func (rm RoundManifest) Marshal() []byte {
+	var buff bytes.Buffer
+	buff.Grow(RoundManifestLen)
+
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, rm.RoundID)
+	buff.Write(b)
+	binary.BigEndian.PutUint32(b, rm.Count)
+	buff.Write(b[:4])
+	buff.Write(rm.Digest[:])
+
+	return buff.Bytes()
+}
+
+// UnmarshalRoundManifest deserializes the byte slice into a RoundManifest.
+func UnmarshalRoundManifest(data []byte) (RoundManifest, error) {
+	if len(data) != RoundManifestLen {
+		return RoundManifest{}, errors.Errorf("round manifest data must be "+
+			"%d bytes; received %d bytes", RoundManifestLen, len(data))
+	}
+
+	rm := RoundManifest{
+		RoundID: binary.BigEndian.Uint64(data[:8]),
+		Count:   binary.BigEndian.Uint32(data[8:12]),
+	}
+	copy(rm.Digest[:], data[12:])
+
+	return rm, nil
+}
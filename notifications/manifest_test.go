@@ -0,0 +1,86 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+)
+
+// Tests that NewRoundManifest only counts entries for the given round and
+// that RoundManifest.Matches detects missing or modified entries.
+func TestNewRoundManifest_Matches(t *testing.T) {
+	ndList := GenerateTestData(10, rand.New(rand.NewSource(42)))
+	for _, nd := range ndList[:6] {
+		nd.RoundID = 5
+	}
+
+	rm := NewRoundManifest(5, ndList)
+	if rm.Count != 6 {
+		t.Errorf("Unexpected count.\nexpected: %d\nreceived: %d", 6, rm.Count)
+	}
+
+	if !rm.Matches(ndList) {
+		t.Error("Manifest does not match the list it was created from.")
+	}
+	if !rm.Matches(ndList[:6]) {
+		t.Error("Manifest does not match when other rounds are removed.")
+	}
+	if rm.Matches(ndList[1:]) {
+		t.Error("Manifest matches list with a missing entry.")
+	}
+
+	ndList[2].MessageHash[0]++
+	if rm.Matches(ndList) {
+		t.Error("Manifest matches list with a modified entry.")
+	}
+}
+
+// Tests that a RoundManifest marshalled with RoundManifest.Marshal and
+// unmarshalled with UnmarshalRoundManifest matches the original.
+func TestRoundManifest_Marshal_UnmarshalRoundManifest(t *testing.T) {
+	ndList := GenerateTestData(3, rand.New(rand.NewSource(7)))
+	rm := NewRoundManifest(ndList[1].RoundID, ndList)
+
+	data := rm.Marshal()
+	if len(data) != RoundManifestLen {
+		t.Errorf("Unexpected length.\nexpected: %d\nreceived: %d",
+			RoundManifestLen, len(data))
+	}
+
+	newRm, err := UnmarshalRoundManifest(data)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal: %+v", err)
+	} else if rm != newRm {
+		t.Errorf("Unexpected manifest.\nexpected: %+v\nreceived: %+v",
+			rm, newRm)
+	}
+
+	if _, err = UnmarshalRoundManifest(data[1:]); err == nil {
+		t.Error("Expected error for data of the wrong length.")
+	}
+}
+
+// Tests that a RoundManifest can be JSON marshalled and unmarshalled.
+func TestRoundManifest_JSON(t *testing.T) {
+	rm := NewRoundManifest(1, []*Data{{RoundID: 1, MessageHash: []byte{1}}})
+
+	data, err := json.Marshal(rm)
+	if err != nil {
+		t.Fatalf("Failed to JSON marshal: %+v", err)
+	}
+
+	var newRm RoundManifest
+	if err = json.Unmarshal(data, &newRm); err != nil {
+		t.Fatalf("Failed to JSON unmarshal: %+v", err)
+	} else if rm != newRm {
+		t.Errorf("Unexpected manifest.\nexpected: %+v\nreceived: %+v",
+			rm, newRm)
+	}
+}
@@ -0,0 +1,56 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"sort"
+	"strconv"
+)
+
+// MergeNotificationBatches merges notification batches received from multiple
+// gateways into a single list ordered by round. Entries that appear in more
+// than one batch, with the same round, identity fingerprint, and message hash,
+// are only included once. Within a round, entries keep the order in which they
+// first appear, with earlier batches taking precedence. Nil entries are
+// dropped.
+func MergeNotificationBatches(batches ...[]*Data) []*Data {
+	var total int
+	for _, batch := range batches {
+		total += len(batch)
+	}
+
+	merged := make([]*Data, 0, total)
+	seen := make(map[string]struct{}, total)
+	for _, batch := range batches {
+		for _, nd := range batch {
+			if nd == nil {
+				continue
+			}
+
+			key := dedupeKey(nd)
+			if _, exists := seen[key]; exists {
+				continue
+			}
+			seen[key] = struct{}{}
+			merged = append(merged, nd)
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].RoundID < merged[j].RoundID
+	})
+
+	return merged
+}
+
+// dedupeKey returns a key that uniquely identifies the notification.
+func dedupeKey(nd *Data) string {
+	return strconv.FormatUint(nd.RoundID, 10) + ":" +
+		strconv.Itoa(len(nd.IdentityFP)) + ":" +
+		string(nd.IdentityFP) + string(nd.MessageHash)
+}
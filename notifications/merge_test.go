@@ -0,0 +1,51 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Tests that MergeNotificationBatches orders entries by round, removes
+// duplicates, and preserves the order of entries within a round.
+func TestMergeNotificationBatches(t *testing.T) {
+	newData := func(round uint64, fp, hash string) *Data {
+		return &Data{RoundID: round,
+			IdentityFP: []byte(fp), MessageHash: []byte(hash)}
+	}
+
+	a1, a2 := newData(1, "a", "1"), newData(1, "b", "2")
+	b1, c1 := newData(2, "a", "3"), newData(3, "c", "4")
+	d1 := newData(1, "c", "5")
+
+	batch1 := []*Data{a1, b1, c1}
+	batch2 := []*Data{newData(1, "a", "1"), a2, nil, newData(3, "c", "4")}
+	batch3 := []*Data{d1, newData(2, "a", "3")}
+
+	expected := []*Data{a1, a2, d1, b1, c1}
+	received := MergeNotificationBatches(batch1, batch2, batch3)
+
+	if !reflect.DeepEqual(expected, received) {
+		t.Errorf("Unexpected merged list.\nexpected: %v\nreceived: %v",
+			expected, received)
+	}
+
+	for i := range expected {
+		if expected[i] != received[i] {
+			t.Errorf("Entry %d is not the first occurrence.", i)
+		}
+	}
+}
+
+// Tests that MergeNotificationBatches returns an empty list for no batches.
+func TestMergeNotificationBatches_Empty(t *testing.T) {
+	if merged := MergeNotificationBatches(); len(merged) != 0 {
+		t.Errorf("Expected empty list, received: %v", merged)
+	}
+}
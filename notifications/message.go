@@ -0,0 +1,35 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"golang.org/x/crypto/blake2b"
+
+	"gitlab.com/elixxir/primitives/format"
+)
+
+// NewDataFromMessage creates the notification Data for a message received in
+// the given round for the given ephemeral ID. The identity fingerprint is the
+// message's SIH and the message hash is computed by MessageHash.
+func NewDataFromMessage(msg *format.Message, rid uint64, ephID int64) *Data {
+	return &Data{
+		EphemeralID: ephID,
+		RoundID:     rid,
+		IdentityFP:  msg.GetSIH(),
+		MessageHash: MessageHash(msg),
+	}
+}
+
+// MessageHash returns the canonical hash of the message used in notifications.
+// It is the blake2b-256 hash of the message as marshalled by
+// format.Message.MarshalImmutable, which excludes the ephemeral recipient ID
+// and SIH because they change on every send attempt.
+func MessageHash(msg *format.Message) []byte {
+	h := blake2b.Sum256(msg.MarshalImmutable())
+	return h[:]
+}
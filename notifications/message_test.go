@@ -0,0 +1,58 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"gitlab.com/elixxir/primitives/format"
+)
+
+// Tests that NewDataFromMessage takes the identity fingerprint from the SIH,
+// and that the message hash has the expected length and is unaffected by
+// changes to the ephemeral recipient ID and SIH but not the contents.
+func TestNewDataFromMessage(t *testing.T) {
+	prng := rand.New(rand.NewSource(42))
+	msg := format.NewMessage(format.MinimumPrimeSize * 4)
+	contents := make([]byte, msg.ContentsSize())
+	prng.Read(contents)
+	msg.SetContents(contents)
+	sih := make([]byte, format.SIHLen)
+	prng.Read(sih)
+	msg.SetSIH(sih)
+
+	nd := NewDataFromMessage(&msg, 42, -7)
+	if nd.RoundID != 42 || nd.EphemeralID != -7 {
+		t.Errorf("Unexpected IDs: round %d, ephemeral %d",
+			nd.RoundID, nd.EphemeralID)
+	}
+	if !bytes.Equal(nd.IdentityFP, sih) || len(nd.IdentityFP) != IdentityFPLen {
+		t.Errorf("Unexpected IdentityFP.\nexpected: %v\nreceived: %v",
+			sih, nd.IdentityFP)
+	}
+	if len(nd.MessageHash) != MessageHashLen {
+		t.Errorf("Unexpected MessageHash length.\nexpected: %d\nreceived: %d",
+			MessageHashLen, len(nd.MessageHash))
+	}
+
+	ephRID := make([]byte, format.EphemeralRIDLen)
+	prng.Read(ephRID)
+	msg.SetEphemeralRID(ephRID)
+	msg.SetSIH(make([]byte, format.SIHLen))
+	if !bytes.Equal(MessageHash(&msg), nd.MessageHash) {
+		t.Error("MessageHash changed with the ephemeral RID and SIH.")
+	}
+
+	contents[0]++
+	msg.SetContents(contents)
+	if bytes.Equal(MessageHash(&msg), nd.MessageHash) {
+		t.Error("MessageHash did not change with the contents.")
+	}
+}
@@ -0,0 +1,182 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"strconv"
+
+	"github.com/pkg/errors"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+// Column names of the header row of a named CSV.
+const (
+	CSVMessageHash = "messageHash"
+	CSVIdentityFP  = "identityFP"
+	CSVRoundID     = "roundID"
+	CSVEphemeralID = "ephemeralID"
+	CSVPriority    = "priority"
+)
+
+// namedCSVHeader is the header row written by BuildNamedNotificationCSV. New
+// columns must only be appended so that the rows remain readable by decoders
+// that predate them.
+var namedCSVHeader = []string{
+	CSVMessageHash, CSVIdentityFP, CSVRoundID, CSVEphemeralID, CSVPriority}
+
+// BuildNamedNotificationCSV converts the [Data] list into a CSV of the
+// specified max size and returns it along with the excluded [Data] entries.
+//
+// Unlike BuildNotificationCSV, the first row of the CSV is a header naming each
+// column, and every field of [Data] is included:
+//
+//	messageHash,identityFP,roundID,ephemeralID,priority
+//	U4x/lrFkvxuXu59LtHLon1sUhPJSCcnZND6SugndnVI=,39ebTXZCm2F6DJ+fDT==,42,-7,0
+//
+// The hashes are base 64 encoded and the remaining columns are decimal. If the
+// header does not fit in the max size, no entries are written.
+func BuildNamedNotificationCSV(ndList []*Data, maxSize int) ([]byte, []*Data) {
+	var buf bytes.Buffer
+	writeNamedCSVRecord(&buf, namedCSVHeader)
+	if buf.Len() > maxSize {
+		return nil, ndList
+	}
+
+	for i, nd := range ndList {
+		var line bytes.Buffer
+		writeNamedCSVRecord(&line, []string{
+			base64.StdEncoding.EncodeToString(nd.MessageHash),
+			base64.StdEncoding.EncodeToString(nd.IdentityFP),
+			strconv.FormatUint(nd.RoundID, 10),
+			strconv.FormatInt(nd.EphemeralID, 10),
+			strconv.FormatUint(uint64(nd.Priority), 10),
+		})
+
+		if buf.Len()+line.Len() > maxSize {
+			return buf.Bytes(), ndList[i:]
+		}
+		buf.Write(line.Bytes())
+	}
+
+	return buf.Bytes(), nil
+}
+
+// DecodeNamedNotificationsCSV decodes a CSV produced by
+// BuildNamedNotificationCSV into a slice of Data. CSVs compressed with
+// CompressPayload are transparently decompressed.
+//
+// Columns are located by their name in the header row, so they may appear in
+// any order. Columns with unknown names are ignored, which allows new columns
+// to be added without breaking existing decoders. The messageHash and
+// identityFP columns are required. Any other known column that is missing or
+// has an empty value leaves its field at the zero value.
+func DecodeNamedNotificationsCSV(data string) ([]*Data, error) {
+	decompressed, err := DecompressPayload([]byte(data))
+	if err != nil {
+		return nil, errors.WithMessage(err,
+			"Failed to decompress notifications CSV.")
+	}
+
+	r := csv.NewReader(bytes.NewReader(decompressed))
+	header, err := r.Read()
+	if err != nil {
+		return nil, errors.Wrap(err,
+			"Failed to read notifications CSV header.")
+	}
+
+	columns := make(map[string]int, len(namedCSVHeader))
+	for i, name := range header {
+		if _, exists := columns[name]; exists {
+			return nil, errors.Errorf("Duplicate column %q in header", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{CSVMessageHash, CSVIdentityFP} {
+		if _, exists := columns[name]; !exists {
+			return nil, errors.Errorf("Missing required column %q", name)
+		}
+	}
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read notifications CSV records.")
+	}
+
+	list := make([]*Data, len(records))
+	for i, record := range records {
+		list[i], err = decodeNamedCSVRecord(record, columns)
+		if err != nil {
+			return nil, errors.WithMessagef(err,
+				"Failed to decode record %d of %d", i, len(records))
+		}
+	}
+
+	return list, nil
+}
+
+// decodeNamedCSVRecord decodes the record into Data using the column indexes
+// from the header.
+func decodeNamedCSVRecord(record []string, columns map[string]int) (
+	*Data, error) {
+	// Returns the value in the named column or an empty string if the column
+	// is not in the header
+	field := func(name string) string {
+		if i, exists := columns[name]; exists {
+			return record[i]
+		}
+		return ""
+	}
+
+	var nd Data
+	var err error
+	nd.MessageHash, err = base64.StdEncoding.DecodeString(field(CSVMessageHash))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode MessageHash")
+	}
+
+	nd.IdentityFP, err = base64.StdEncoding.DecodeString(field(CSVIdentityFP))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode IdentityFP")
+	}
+
+	if s := field(CSVRoundID); s != "" {
+		nd.RoundID, err = strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode RoundID")
+		}
+	}
+
+	if s := field(CSVEphemeralID); s != "" {
+		nd.EphemeralID, err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode EphemeralID")
+		}
+	}
+
+	if s := field(CSVPriority); s != "" {
+		nd.Priority, err = parsePriority(s)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to decode Priority")
+		}
+	}
+
+	return &nd, nil
+}
+
+// writeNamedCSVRecord writes the record as a single CSV row to the buffer.
+func writeNamedCSVRecord(buf *bytes.Buffer, record []string) {
+	w := csv.NewWriter(buf)
+	if err := w.Write(record); err != nil {
+		jww.FATAL.Panicf("Failed to write record to notifications CSV "+
+			"buffer: %+v", err)
+	}
+	w.Flush()
+}
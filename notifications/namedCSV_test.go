@@ -0,0 +1,130 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Tests that a list of Data encoded by BuildNamedNotificationCSV and decoded by
+// DecodeNamedNotificationsCSV matches the original, including the fields that
+// BuildNotificationCSV omits.
+func TestBuildNamedNotificationCSV_DecodeNamedNotificationsCSV(t *testing.T) {
+	expected := GenerateTestData(25, rand.New(rand.NewSource(42)))
+	expected[3].Priority = Silent
+	expected[7].EphemeralID = -expected[7].EphemeralID
+
+	csvData, rest := BuildNamedNotificationCSV(expected, 9999)
+	if len(rest) != 0 {
+		t.Errorf("Unexpected overflow: %v", rest)
+	}
+
+	dataList, err := DecodeNamedNotificationsCSV(string(csvData))
+	if err != nil {
+		t.Fatalf("Failed to decode named notifications CSV: %+v", err)
+	}
+
+	if !reflect.DeepEqual(expected, dataList) {
+		t.Errorf("The decoded Data list does not match the original."+
+			"\nexpected: %v\nreceived: %v", expected, dataList)
+	}
+}
+
+// Consistency test of BuildNamedNotificationCSV.
+func TestBuildNamedNotificationCSV(t *testing.T) {
+	ndList := []*Data{
+		{EphemeralID: -7, RoundID: 42, IdentityFP: []byte{1, 2, 3},
+			MessageHash: []byte{4, 5, 6}},
+		{EphemeralID: 8, RoundID: 43, IdentityFP: []byte{7},
+			MessageHash: []byte{8, 9}, Priority: Batched},
+	}
+	expected := "messageHash,identityFP,roundID,ephemeralID,priority\n" +
+		"BAUG,AQID,42,-7,0\n" +
+		"CAk=,Bw==,43,8,1\n"
+
+	csvData, _ := BuildNamedNotificationCSV(ndList, 9999)
+	if string(csvData) != expected {
+		t.Errorf("Unexpected CSV.\nexpected: %q\nreceived: %q",
+			expected, csvData)
+	}
+}
+
+// Tests that BuildNamedNotificationCSV excludes entries that do not fit in the
+// max size and writes nothing if the header does not fit.
+func TestBuildNamedNotificationCSV_MaxSize(t *testing.T) {
+	ndList := GenerateTestData(50, rand.New(rand.NewSource(42)))
+
+	csvData, rest := BuildNamedNotificationCSV(ndList, 512)
+	if len(csvData) > 512 {
+		t.Errorf("CSV of %d bytes exceeds max.", len(csvData))
+	}
+	decoded, err := DecodeNamedNotificationsCSV(string(csvData))
+	if err != nil {
+		t.Fatalf("Failed to decode named notifications CSV: %+v", err)
+	}
+	if len(decoded)+len(rest) != len(ndList) || len(rest) == 0 {
+		t.Errorf("Unexpected split: %d written, %d excluded",
+			len(decoded), len(rest))
+	}
+
+	csvData, rest = BuildNamedNotificationCSV(ndList, 10)
+	if csvData != nil || len(rest) != len(ndList) {
+		t.Errorf("Expected no CSV when header does not fit: %q", csvData)
+	}
+}
+
+// Tests that DecodeNamedNotificationsCSV locates columns by name, ignores
+// unknown columns, and leaves missing or empty optional columns at their zero
+// value.
+func TestDecodeNamedNotificationsCSV_Columns(t *testing.T) {
+	csvData := "futureField,priority,identityFP,messageHash,ephemeralID\n" +
+		"\"a,b\",2,AQID,BAUG,-7\n" +
+		",,Bw==,CAk=,\n"
+	expected := []*Data{
+		{EphemeralID: -7, IdentityFP: []byte{1, 2, 3},
+			MessageHash: []byte{4, 5, 6}, Priority: Silent},
+		{IdentityFP: []byte{7}, MessageHash: []byte{8, 9}},
+	}
+
+	dataList, err := DecodeNamedNotificationsCSV(csvData)
+	if err != nil {
+		t.Fatalf("Failed to decode named notifications CSV: %+v", err)
+	}
+
+	if !reflect.DeepEqual(expected, dataList) {
+		t.Errorf("Unexpected Data list.\nexpected: %v\nreceived: %v",
+			expected, dataList)
+	}
+}
+
+// Error path: Tests that DecodeNamedNotificationsCSV returns an error for
+// invalid headers and records.
+func TestDecodeNamedNotificationsCSV_Error(t *testing.T) {
+	header := strings.Join(namedCSVHeader, ",") + "\n"
+	for i, csvData := range []string{
+		"",
+		"identityFP,priority\nAQID,0\n",
+		"messageHash,roundID\nAQID,0\n",
+		"messageHash,identityFP,messageHash\nAQID,AQID,AQID\n",
+		header + "AQID,AQID,1,1\n",
+		header + "AQID,AQID,1,1,0,9\n",
+		header + "A,AQID,1,1,0\n",
+		header + "AQID,A,1,1,0\n",
+		header + "AQID,AQID,-1,1,0\n",
+		header + "AQID,AQID,1,a,0\n",
+		header + "AQID,AQID,1,1,9\n",
+		header + "\"AQID,AQID,1,1,0\n",
+	} {
+		if _, err := DecodeNamedNotificationsCSV(csvData); err == nil {
+			t.Errorf("No error for invalid CSV (%d): %q", i, csvData)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// ndjsonDelimiter separates the entries of an NDJSON stream.
+const ndjsonDelimiter = '\n'
+
+// EncodeNDJSON writes the [Data] list to w in the JSON Lines (NDJSON) format,
+// with each entry as a JSON object with explicit field names on its own line.
+// Nil entries are skipped.
+func EncodeNDJSON(w io.Writer, ndList []*Data) error {
+	for i, nd := range ndList {
+		if nd == nil {
+			continue
+		}
+
+		line, err := json.Marshal(nd)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to JSON marshal entry %d of %d",
+				i, len(ndList))
+		}
+
+		if _, err = w.Write(append(line, ndjsonDelimiter)); err != nil {
+			return errors.Wrapf(err, "Failed to write entry %d of %d",
+				i, len(ndList))
+		}
+	}
+
+	return nil
+}
+
+// DecodeNDJSON decodes every entry of the NDJSON data produced by EncodeNDJSON.
+// Blank lines are skipped. Unlike NDJSONDecoder, a trailing partial line is an
+// error.
+func DecodeNDJSON(data []byte) ([]*Data, error) {
+	d := NewNDJSONDecoder(bytes.NewReader(data))
+
+	var ndList []*Data
+	for {
+		nd, err := d.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		ndList = append(ndList, nd)
+	}
+
+	if len(d.Partial()) > 0 {
+		return nil, errors.Errorf("Failed to decode partial line %d: %q",
+			d.line, d.Partial())
+	}
+
+	return ndList, nil
+}
+
+// NDJSONDecoder reads [Data] entries from an NDJSON stream one line at a time.
+// A stream that ends with a line that is not terminated and cannot be decoded,
+// such as a queue file that is still being written, is treated as ending before
+// that line; the partial line is available from NDJSONDecoder.Partial.
+type NDJSONDecoder struct {
+	r       *bufio.Reader
+	line    int
+	partial []byte
+}
+
+// NewNDJSONDecoder returns a new NDJSONDecoder that reads from r.
+func NewNDJSONDecoder(r io.Reader) *NDJSONDecoder {
+	return &NDJSONDecoder{r: bufio.NewReader(r)}
+}
+
+// Next returns the next entry in the stream. Blank lines are skipped. Returns
+// io.EOF when there are no more complete entries.
+func (d *NDJSONDecoder) Next() (*Data, error) {
+	for {
+		line, readErr := d.r.ReadBytes(ndjsonDelimiter)
+		if readErr != nil && readErr != io.EOF {
+			return nil, errors.Wrapf(readErr, "Failed to read line %d", d.line)
+		}
+
+		d.line++
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) == 0 {
+			if readErr == io.EOF {
+				return nil, io.EOF
+			}
+			continue
+		}
+
+		var nd Data
+		if err := json.Unmarshal(trimmed, &nd); err != nil {
+			if readErr == io.EOF {
+				// The last line is not terminated and is incomplete
+				d.partial = line
+				return nil, io.EOF
+			}
+			return nil, errors.Wrapf(err, "Failed to decode line %d", d.line)
+		}
+
+		return &nd, nil
+	}
+}
+
+// Partial returns the unterminated line at the end of the stream that could
+// not be decoded, or nil if there is none. It is only set once Next returns
+// io.EOF.
+func (d *NDJSONDecoder) Partial() []byte {
+	return d.partial
+}
@@ -0,0 +1,108 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Tests that a Data list encoded with EncodeNDJSON and decoded with
+// DecodeNDJSON matches the original and that each entry is on its own line.
+func TestEncodeNDJSON_DecodeNDJSON(t *testing.T) {
+	ndList := GenerateTestData(10, rand.New(rand.NewSource(42)))
+	ndList[3].Priority = Batched
+
+	var buf bytes.Buffer
+	if err := EncodeNDJSON(&buf, append(ndList, nil)); err != nil {
+		t.Fatalf("Failed to encode: %+v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(ndList) {
+		t.Errorf("Unexpected number of lines.\nexpected: %d\nreceived: %d",
+			len(ndList), len(lines))
+	}
+	if !strings.Contains(lines[0], `"EphemeralID":`) {
+		t.Errorf("Line does not have explicit field names: %s", lines[0])
+	}
+
+	decoded, err := DecodeNDJSON(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to decode: %+v", err)
+	}
+	if !reflect.DeepEqual(ndList, decoded) {
+		t.Errorf("Decoded list does not match original."+
+			"\nexpected: %v\nreceived: %v", ndList, decoded)
+	}
+}
+
+// Tests that NDJSONDecoder skips blank lines, decodes an unterminated final
+// entry, and stops before a trailing partial line, which is returned by
+// NDJSONDecoder.Partial.
+func TestNDJSONDecoder_Next(t *testing.T) {
+	ndList := GenerateTestData(3, rand.New(rand.NewSource(42)))
+	var buf bytes.Buffer
+	if err := EncodeNDJSON(&buf, ndList); err != nil {
+		t.Fatalf("Failed to encode: %+v", err)
+	}
+	encoded := buf.Bytes()
+
+	tests := []struct {
+		data     []byte
+		expected []*Data
+		partial  string
+	}{
+		{encoded, ndList, ""},
+		{append([]byte("\n  \n"), encoded...), ndList, ""},
+		{bytes.TrimSuffix(encoded, []byte("\n")), ndList, ""},
+		{append(encoded, `{"EphemeralID":5,"Rou`...), ndList,
+			`{"EphemeralID":5,"Rou`},
+	}
+
+	for i, tt := range tests {
+		d := NewNDJSONDecoder(bytes.NewReader(tt.data))
+		var decoded []*Data
+		for {
+			nd, err := d.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("Failed to decode (%d): %+v", i, err)
+			}
+			decoded = append(decoded, nd)
+		}
+
+		if !reflect.DeepEqual(tt.expected, decoded) {
+			t.Errorf("Unexpected list (%d).\nexpected: %v\nreceived: %v",
+				i, tt.expected, decoded)
+		}
+		if string(d.Partial()) != tt.partial {
+			t.Errorf("Unexpected partial line (%d).\nexpected: %q"+
+				"\nreceived: %q", i, tt.partial, d.Partial())
+		}
+	}
+}
+
+// Error path: Tests that NDJSONDecoder.Next returns an error for an invalid
+// line that is terminated and that DecodeNDJSON returns an error for a trailing
+// partial line.
+func TestNDJSONDecoder_Next_InvalidLine(t *testing.T) {
+	d := NewNDJSONDecoder(strings.NewReader("{invalid\n{}\n"))
+	if _, err := d.Next(); err == nil || err == io.EOF {
+		t.Errorf("Expected error for invalid line; received: %v", err)
+	}
+
+	if _, err := DecodeNDJSON([]byte("{}\n{\"Ephem")); err == nil {
+		t.Error("Expected error for trailing partial line.")
+	}
+}
@@ -0,0 +1,79 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// NonceLen is the length, in bytes, of a Nonce.
+const NonceLen = 16
+
+// Nonce is a random value that uniquely identifies a push notification
+// payload. Providers may deliver the same payload more than once, so clients
+// record the nonces they have processed in a ReplayCache and drop duplicates.
+//
+// A Nonce is encoded as unpadded base 64 URL encoding in text and JSON.
+type Nonce [NonceLen]byte
+
+// NewNonce returns a new random Nonce.
+func NewNonce() (Nonce, error) {
+	return newNonce(rand.Reader)
+}
+
+// newNonce returns a Nonce read from the given reader.
+func newNonce(csprng io.Reader) (Nonce, error) {
+	var n Nonce
+	if _, err := io.ReadFull(csprng, n[:]); err != nil {
+		return Nonce{}, errors.Wrap(err, "failed to generate nonce")
+	}
+	return n, nil
+}
+
+// ParseNonce decodes a Nonce from its string representation.
+func ParseNonce(s string) (Nonce, error) {
+	var n Nonce
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Nonce{}, errors.Wrapf(err, "failed to decode nonce %q", s)
+	} else if len(b) != NonceLen {
+		return Nonce{}, errors.Errorf("nonce length %d does not match "+
+			"expected length %d", len(b), NonceLen)
+	}
+
+	copy(n[:], b)
+	return n, nil
+}
+
+// String returns the unpadded base 64 URL encoding of the Nonce. This function
+// adheres to the fmt.Stringer interface.
+func (n Nonce) String() string {
+	return base64.RawURLEncoding.EncodeToString(n[:])
+}
+
+// MarshalText encodes the Nonce as text. This function adheres to the
+// encoding.TextMarshaler interface.
+func (n Nonce) MarshalText() ([]byte, error) {
+	return []byte(n.String()), nil
+}
+
+// UnmarshalText decodes the text into the Nonce. This function adheres to the
+// encoding.TextUnmarshaler interface.
+func (n *Nonce) UnmarshalText(text []byte) error {
+	decoded, err := ParseNonce(string(text))
+	if err != nil {
+		return err
+	}
+
+	*n = decoded
+	return nil
+}
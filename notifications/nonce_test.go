@@ -0,0 +1,73 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// Tests that NewNonce returns different nonces on each call.
+func TestNewNonce(t *testing.T) {
+	seen := make(map[Nonce]bool)
+	for i := 0; i < 100; i++ {
+		n, err := NewNonce()
+		if err != nil {
+			t.Fatalf("Failed to generate nonce (%d): %+v", i, err)
+		}
+		if seen[n] {
+			t.Errorf("Duplicate nonce %s (%d).", n, i)
+		}
+		seen[n] = true
+	}
+}
+
+// Error path: Tests that newNonce returns an error when the reader runs out of
+// data.
+func Test_newNonce_ReadError(t *testing.T) {
+	if _, err := newNonce(strings.NewReader("short")); err == nil {
+		t.Error("No error for reader without enough data.")
+	}
+}
+
+// Tests that a Nonce encoded with Nonce.String and decoded with ParseNonce, or
+// JSON marshalled and unmarshalled, matches the original.
+func TestNonce_String_ParseNonce(t *testing.T) {
+	n, _ := newNonce(rand.New(rand.NewSource(42)))
+
+	parsed, err := ParseNonce(n.String())
+	if err != nil {
+		t.Fatalf("Failed to parse nonce %q: %+v", n, err)
+	}
+	if parsed != n {
+		t.Errorf("Unexpected nonce.\nexpected: %s\nreceived: %s", n, parsed)
+	}
+
+	data, err := json.Marshal(map[Nonce]Nonce{n: n})
+	if err != nil {
+		t.Fatalf("Failed to JSON marshal nonce: %+v", err)
+	}
+	var m map[Nonce]Nonce
+	if err = json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("Failed to JSON unmarshal nonce %s: %+v", data, err)
+	}
+	if m[n] != n {
+		t.Errorf("Unexpected JSON nonce.\nexpected: %s\nreceived: %v", n, m)
+	}
+}
+
+// Error path: Tests that ParseNonce rejects invalid encodings and lengths.
+func TestParseNonce_Error(t *testing.T) {
+	for i, s := range []string{"", "!!!", "AAAA", strings.Repeat("A", 23)} {
+		if _, err := ParseNonce(s); err == nil {
+			t.Errorf("No error parsing %q (%d).", s, i)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// NotificationDataKey is the key in all provider payloads under which the
+	// notifications CSV is stored.
+	NotificationDataKey = "notificationData"
+
+	// NotificationPayloadKey is the key in all provider payloads under which a
+	// payload wrapped with WrapPayload is stored, base 64 encoded.
+	NotificationPayloadKey = "notificationPayload"
+
+	// NonceKey is the key in all provider payloads under which the payload's
+	// Nonce is stored.
+	NonceKey = "nonce"
+
+	// MaxAPNSPayload is the maximum payload size, in bytes, accepted by the
+	// Apple Push Notification service.
+	MaxAPNSPayload = 4096
+
+	// MaxFCMPayload is the maximum payload size, in bytes, accepted by
+	// Firebase Cloud Messaging for data messages.
+	MaxFCMPayload = 4096
+)
+
+// ErrPayloadTooLarge is returned, wrapped with details, by ValidatePayloadSize
+// when a payload exceeds the maximum size of its provider.
+var ErrPayloadTooLarge = errors.New("payload exceeds provider maximum size")
+
+// Provider is a push notification service.
+type Provider uint8
+
+const (
+	// APNS is the Apple Push Notification service.
+	APNS Provider = iota
+
+	// FCM is Firebase Cloud Messaging.
+	FCM
+)
+
+// String returns the string representation of the Provider. This functions
+// adheres to the fmt.Stringer interface.
+func (p Provider) String() string {
+	switch p {
+	case APNS:
+		return "APNS"
+	case FCM:
+		return "FCM"
+	default:
+		return "INVALID PROVIDER " + strconv.Itoa(int(p))
+	}
+}
+
+// MaxPayloadSize returns the maximum payload size, in bytes, accepted by the
+// provider. Returns zero for an invalid provider.
+func (p Provider) MaxPayloadSize() int {
+	switch p {
+	case APNS:
+		return MaxAPNSPayload
+	case FCM:
+		return MaxFCMPayload
+	default:
+		return 0
+	}
+}
+
+// ValidatePayloadSize returns an error if the payload is larger than the
+// maximum payload size of the provider or if the provider is invalid. Errors
+// for oversized payloads wrap ErrPayloadTooLarge.
+func ValidatePayloadSize(payload []byte, provider Provider) error {
+	maxSize := provider.MaxPayloadSize()
+	if maxSize == 0 {
+		return errors.Errorf("invalid provider %s", provider)
+	} else if len(payload) > maxSize {
+		return errors.WithMessagef(ErrPayloadTooLarge, "payload of %d bytes "+
+			"exceeds the %s maximum of %d bytes", len(payload), provider,
+			maxSize)
+	}
+
+	return nil
+}
+
+// Payload builds a provider-specific push notification payload from a list of
+// [Data]. Each payload includes a new random Nonce so that clients can detect
+// payloads that the provider delivers more than once; see ReplayCache.
+type Payload interface {
+	// Build encodes as many of the [Data] entries as fit within the provider's
+	// maximum payload size. It returns the payload and the entries that were
+	// excluded. An error is returned if no entries fit.
+	Build(ndList []*Data) ([]byte, []*Data, error)
+
+	// MaxSize returns the maximum size of the payload, in bytes.
+	MaxSize() int
+}
+
+// APNSPayload builds payloads for the Apple Push Notification service. The
+// notifications CSV and the Nonce are placed next to the aps dictionary. If
+// Compressor is set, the CSV is compressed with CompressPayload so that more
+// entries fit.
+//
+// JSON example:
+//
+//	{
+//	  "aps": {"content-available": 1},
+//	  "notificationData": "<CSV>",
+//	  "nonce": "<Nonce>"
+//	}
+type APNSPayload struct {
+	// Compressor, if not nil, compresses the notifications CSV.
+	Compressor Compressor
+}
+
+// apnsMessage is the JSON structure of an APNS payload.
+type apnsMessage struct {
+	Aps              apnsAps `json:"aps"`
+	NotificationData string  `json:"notificationData"`
+	Nonce            string  `json:"nonce"`
+
+	// NotificationPayload is encoded as base 64 by encoding/json
+	NotificationPayload []byte `json:"notificationPayload,omitempty"`
+}
+
+// apnsAps is the aps dictionary of an APNS payload.
+type apnsAps struct {
+	ContentAvailable int `json:"content-available"`
+}
+
+// Build encodes the [Data] list into an APNS payload. This function adheres to
+// the Payload interface.
+func (p APNSPayload) Build(ndList []*Data) ([]byte, []*Data, error) {
+	nonce, err := NewNonce()
+	if err != nil {
+		return nil, ndList, err
+	}
+
+	wrap := func(csv string) any {
+		return apnsMessage{Aps: apnsAps{1}, NotificationData: csv,
+			Nonce: nonce.String()}
+	}
+	return buildPayload(ndList, MaxAPNSPayload, p.Compressor, wrap)
+}
+
+// MaxSize returns the maximum APNS payload size. This function adheres to the
+// Payload interface.
+func (APNSPayload) MaxSize() int { return MaxAPNSPayload }
+
+// FCMPayload builds data message payloads for Firebase Cloud Messaging. If
+// Compressor is set, the CSV is compressed with CompressPayload so that more
+// entries fit.
+//
+// JSON example:
+//
+//	{
+//	  "data": {"notificationData": "<CSV>", "nonce": "<Nonce>"}
+//	}
+type FCMPayload struct {
+	// Compressor, if not nil, compresses the notifications CSV.
+	Compressor Compressor
+}
+
+// fcmMessage is the JSON structure of an FCM data message.
+type fcmMessage struct {
+	Data map[string]string `json:"data"`
+}
+
+// Build encodes the [Data] list into an FCM payload. This function adheres to
+// the Payload interface.
+func (p FCMPayload) Build(ndList []*Data) ([]byte, []*Data, error) {
+	nonce, err := NewNonce()
+	if err != nil {
+		return nil, ndList, err
+	}
+
+	wrap := func(csv string) any {
+		return fcmMessage{map[string]string{
+			NotificationDataKey: csv,
+			NonceKey:            nonce.String(),
+		}}
+	}
+	return buildPayload(ndList, MaxFCMPayload, p.Compressor, wrap)
+}
+
+// MaxSize returns the maximum FCM payload size. This function adheres to the
+// Payload interface.
+func (FCMPayload) MaxSize() int { return MaxFCMPayload }
+
+// buildPayload generates a JSON payload, using the wrap function to place the
+// CSV into the provider's structure, that is no larger than maxSize. The CSV is
+// compressed if the Compressor is not nil.
+func buildPayload(ndList []*Data, maxSize int, c Compressor,
+	wrap func(csv string) any) ([]byte, []*Data, error) {
+	empty, err := json.Marshal(wrap(""))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// JSON escaping can grow the CSV, so shrink the budget until it fits
+	budget := maxSize - len(empty)
+	for budget > 0 {
+		var csv []byte
+		var rest []*Data
+		if c == nil {
+			csv, rest = BuildNotificationCSV(ndList, budget)
+		} else {
+			csv, rest, err = BuildCompressedNotificationCSV(ndList, budget, c)
+			if err != nil {
+				return nil, ndList, err
+			}
+		}
+		if len(ndList) > 0 && len(rest) == len(ndList) {
+			break
+		}
+
+		payload, err := json.Marshal(wrap(string(csv)))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(payload) <= maxSize {
+			return payload, rest, nil
+		}
+		budget -= len(payload) - maxSize
+	}
+
+	return nil, ndList, errors.Errorf("no notifications fit in the maximum "+
+		"payload size of %d bytes", maxSize)
+}
+
+// WrapPayload places an already encoded payload, such as one produced by
+// BuildVersionedPayload, CompressPayload, or EncryptPayload, into the JSON
+// structure of the provider with a new random Nonce. The payload is base 64
+// encoded under NotificationPayloadKey, since these payloads are binary and
+// JSON strings cannot carry invalid UTF-8. Returns an error, wrapping
+// ErrPayloadTooLarge, if the result exceeds the provider's maximum size.
+//
+// JSON example for APNS:
+//
+//	{
+//	  "aps": {"content-available": 1},
+//	  "notificationData": "",
+//	  "nonce": "<Nonce>",
+//	  "notificationPayload": "<base 64 payload>"
+//	}
+//
+// JSON example for FCM:
+//
+//	{
+//	  "data": {"nonce": "<Nonce>", "notificationPayload": "<base 64 payload>"}
+//	}
+func WrapPayload(payload []byte, provider Provider) ([]byte, error) {
+	nonce, err := NewNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	var msg any
+	switch provider {
+	case APNS:
+		msg = apnsMessage{Aps: apnsAps{1}, Nonce: nonce.String(),
+			NotificationPayload: payload}
+	case FCM:
+		msg = fcmMessage{map[string]string{
+			NotificationPayloadKey: base64.StdEncoding.EncodeToString(payload),
+			NonceKey:               nonce.String(),
+		}}
+	default:
+		return nil, errors.Errorf("unknown provider %s", provider)
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	} else if err = ValidatePayloadSize(data, provider); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// ParseProviderPayload extracts the notifications payload and the Nonce from a
+// payload built by the Payload of the given Provider or by WrapPayload. For
+// payloads from WrapPayload, notificationData holds the decoded binary payload,
+// which can be passed to DecodeAny or DecryptPayload. Returns an error if the
+// payload is not valid JSON or is missing the Nonce.
+func ParseProviderPayload(payload []byte, provider Provider) (
+	notificationData string, nonce Nonce, err error) {
+	var encodedNonce string
+	switch provider {
+	case APNS:
+		var msg apnsMessage
+		if err = json.Unmarshal(payload, &msg); err != nil {
+			return "", Nonce{}, errors.Wrapf(err, "failed to unmarshal %s "+
+				"payload", provider)
+		}
+		notificationData, encodedNonce = msg.NotificationData, msg.Nonce
+		if msg.NotificationPayload != nil {
+			notificationData = string(msg.NotificationPayload)
+		}
+	case FCM:
+		var msg fcmMessage
+		if err = json.Unmarshal(payload, &msg); err != nil {
+			return "", Nonce{}, errors.Wrapf(err, "failed to unmarshal %s "+
+				"payload", provider)
+		}
+		notificationData, encodedNonce =
+			msg.Data[NotificationDataKey], msg.Data[NonceKey]
+		if encoded, exists := msg.Data[NotificationPayloadKey]; exists {
+			decoded, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return "", Nonce{}, errors.Wrapf(err, "failed to decode %s "+
+					"%s", provider, NotificationPayloadKey)
+			}
+			notificationData = string(decoded)
+		}
+	default:
+		return "", Nonce{}, errors.Errorf("unknown provider %s", provider)
+	}
+
+	if encodedNonce == "" {
+		return "", Nonce{}, errors.Errorf("%s payload is missing the nonce",
+			provider)
+	}
+	nonce, err = ParseNonce(encodedNonce)
+	if err != nil {
+		return "", Nonce{}, err
+	}
+
+	return notificationData, nonce, nil
+}
@@ -0,0 +1,291 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// Tests that APNSPayload.Build produces a payload within the size limit that
+// decodes to the included Data.
+func TestAPNSPayload_Build(t *testing.T) {
+	dataList := newTestDataList(200, 42)
+
+	p := APNSPayload{}
+	payload, rest, err := p.Build(dataList)
+	if err != nil {
+		t.Fatalf("Failed to build payload: %+v", err)
+	}
+
+	if len(payload) > p.MaxSize() {
+		t.Errorf("Payload size %d larger than maximum %d.",
+			len(payload), p.MaxSize())
+	}
+
+	var msg apnsMessage
+	if err = json.Unmarshal(payload, &msg); err != nil {
+		t.Fatalf("Failed to unmarshal payload: %+v", err)
+	}
+	if msg.Aps.ContentAvailable != 1 {
+		t.Errorf("Unexpected content-available: %d", msg.Aps.ContentAvailable)
+	}
+
+	checkPayloadCSV(msg.NotificationData, dataList, rest, t)
+}
+
+// Tests that FCMPayload.Build produces a payload within the size limit that
+// decodes to the included Data.
+func TestFCMPayload_Build(t *testing.T) {
+	dataList := newTestDataList(200, 42)
+
+	p := FCMPayload{}
+	payload, rest, err := p.Build(dataList)
+	if err != nil {
+		t.Fatalf("Failed to build payload: %+v", err)
+	}
+
+	if len(payload) > p.MaxSize() {
+		t.Errorf("Payload size %d larger than maximum %d.",
+			len(payload), p.MaxSize())
+	}
+
+	var msg fcmMessage
+	if err = json.Unmarshal(payload, &msg); err != nil {
+		t.Fatalf("Failed to unmarshal payload: %+v", err)
+	}
+
+	checkPayloadCSV(msg.Data[NotificationDataKey], dataList, rest, t)
+}
+
+// Tests that payloads built with a Compressor survive JSON encoding and that
+// the extracted CSV decodes to the included Data, which outnumber those in an
+// uncompressed payload.
+func TestPayload_Build_Compressed(t *testing.T) {
+	// Use repeated identity fingerprints, as seen in heavy rounds
+	dataList := newTestDataList(300, 42)
+	for i := range dataList {
+		dataList[i].IdentityFP = dataList[i%3].IdentityFP
+	}
+
+	for _, provider := range []Provider{APNS, FCM} {
+		var p, plain Payload = APNSPayload{GzipCompressor{}}, APNSPayload{}
+		if provider == FCM {
+			p, plain = FCMPayload{GzipCompressor{}}, FCMPayload{}
+		}
+
+		payload, rest, err := p.Build(dataList)
+		if err != nil {
+			t.Fatalf("Failed to build %s payload: %+v", provider, err)
+		} else if len(payload) > p.MaxSize() {
+			t.Errorf("%s payload size %d larger than maximum %d.",
+				provider, len(payload), p.MaxSize())
+		}
+
+		csv, _, err := ParseProviderPayload(payload, provider)
+		if err != nil {
+			t.Fatalf("Failed to parse %s payload: %+v", provider, err)
+		}
+		checkPayloadCSV(csv, dataList, rest, t)
+
+		_, plainRest, _ := plain.Build(dataList)
+		if len(rest) >= len(plainRest) {
+			t.Errorf("Compression did not increase the number of %s "+
+				"entries: %d excluded compressed vs %d uncompressed.",
+				provider, len(rest), len(plainRest))
+		}
+	}
+}
+
+// Error path: Tests that buildPayload returns an error when no Data fits.
+func Test_buildPayload_TooSmallError(t *testing.T) {
+	_, rest, err := buildPayload(newTestDataList(5, 42), 64, nil,
+		func(csv string) any { return fcmMessage{map[string]string{"a": csv}} })
+	if err == nil {
+		t.Error("Did not receive an error when no Data fits.")
+	}
+	if len(rest) != 5 {
+		t.Errorf("Expected all Data to be returned, got %d", len(rest))
+	}
+}
+
+// Tests that ValidatePayloadSize accepts payloads up to the maximum size of
+// each provider and that payloads built for a provider pass validation.
+func TestValidatePayloadSize(t *testing.T) {
+	tests := []struct {
+		provider Provider
+		maxSize  int
+		payload  Payload
+	}{
+		{APNS, MaxAPNSPayload, APNSPayload{}},
+		{FCM, MaxFCMPayload, FCMPayload{}},
+	}
+
+	for _, tt := range tests {
+		if tt.provider.MaxPayloadSize() != tt.maxSize ||
+			tt.payload.MaxSize() != tt.maxSize {
+			t.Errorf("Inconsistent maximum size for %s.", tt.provider)
+		}
+
+		err := ValidatePayloadSize(make([]byte, tt.maxSize), tt.provider)
+		if err != nil {
+			t.Errorf("Failed to validate %s payload of maximum size: %+v",
+				tt.provider, err)
+		}
+
+		payload, _, err := tt.payload.Build(newTestDataList(200, 42))
+		if err != nil {
+			t.Fatalf("Failed to build %s payload: %+v", tt.provider, err)
+		}
+		if err = ValidatePayloadSize(payload, tt.provider); err != nil {
+			t.Errorf("Built %s payload failed validation: %+v",
+				tt.provider, err)
+		}
+	}
+}
+
+// Error path: Tests that ValidatePayloadSize returns ErrPayloadTooLarge for
+// oversized payloads and an error for an invalid provider.
+func TestValidatePayloadSize_Error(t *testing.T) {
+	for _, provider := range []Provider{APNS, FCM} {
+		payload := bytes.Repeat([]byte{'a'}, provider.MaxPayloadSize()+1)
+		err := ValidatePayloadSize(payload, provider)
+		if !errors.Is(err, ErrPayloadTooLarge) {
+			t.Errorf("Unexpected error for oversized %s payload: %+v",
+				provider, err)
+		}
+	}
+
+	if err := ValidatePayloadSize(nil, Provider(2)); err == nil {
+		t.Error("No error for invalid provider.")
+	}
+}
+
+// Consistency test of Provider.String.
+func TestProvider_String(t *testing.T) {
+	expected := []string{"APNS", "FCM", "INVALID PROVIDER 2"}
+	for i, str := range expected {
+		if s := Provider(i).String(); s != str {
+			t.Errorf("Unexpected string.\nexpected: %s\nreceived: %s",
+				str, s)
+		}
+	}
+}
+
+// checkPayloadCSV checks that the CSV decodes to the Data included in the
+// payload.
+func checkPayloadCSV(csv string, dataList, rest []*Data, t *testing.T) {
+	decoded, err := DecodeNotificationsCSV(csv)
+	if err != nil {
+		t.Fatalf("Failed to decode CSV: %+v", err)
+	}
+
+	if len(decoded) == 0 || len(decoded)+len(rest) != len(dataList) {
+		t.Errorf("Unexpected number of included Data.\nincluded: %d"+
+			"\nexcluded: %d\ntotal:    %d",
+			len(decoded), len(rest), len(dataList))
+	}
+
+	if !reflect.DeepEqual(dataList[:len(decoded)], decoded) {
+		t.Errorf("Decoded Data does not match original.")
+	}
+}
+
+// newTestDataList generates a list of Data with random IdentityFP and
+// MessageHash values.
+func newTestDataList(n int, seed int64) []*Data {
+	rng := rand.New(rand.NewSource(seed))
+	dataList := make([]*Data, n)
+	for i := range dataList {
+		identityFP, messageHash := make([]byte, 25), make([]byte, 32)
+		rng.Read(messageHash)
+		rng.Read(identityFP)
+		dataList[i] = &Data{IdentityFP: identityFP, MessageHash: messageHash}
+	}
+	return dataList
+}
+
+// Tests that payloads built by each Provider include a unique Nonce that
+// ParseProviderPayload extracts along with the notifications CSV.
+func TestParseProviderPayload(t *testing.T) {
+	dataList := newTestDataList(20, 42)
+	for _, provider := range []Provider{APNS, FCM} {
+		var p Payload = APNSPayload{}
+		if provider == FCM {
+			p = FCMPayload{}
+		}
+
+		seen := make(map[Nonce]bool)
+		for i := 0; i < 10; i++ {
+			payload, rest, err := p.Build(dataList)
+			if err != nil {
+				t.Fatalf("Failed to build %s payload: %+v", provider, err)
+			}
+
+			csv, nonce, err := ParseProviderPayload(payload, provider)
+			if err != nil {
+				t.Fatalf("Failed to parse %s payload: %+v", provider, err)
+			}
+			if seen[nonce] {
+				t.Errorf("Duplicate %s nonce %s.", provider, nonce)
+			}
+			seen[nonce] = true
+			checkPayloadCSV(csv, dataList, rest, t)
+		}
+	}
+}
+
+// Error path: Tests that ParseProviderPayload rejects invalid JSON, missing or
+// invalid nonces, and unknown providers.
+func TestParseProviderPayload_Error(t *testing.T) {
+	tests := []struct {
+		payload  string
+		provider Provider
+	}{
+		{"invalid", APNS},
+		{"invalid", FCM},
+		{`{"aps":{"content-available":1},"notificationData":""}`, APNS},
+		{`{"data":{"notificationData":""}}`, FCM},
+		{`{"data":{"notificationData":"","nonce":"AAAA"}}`, FCM},
+		{`{"data":{"notificationPayload":"!","nonce":"` +
+			Nonce{}.String() + `"}}`, FCM},
+		{`{"aps":{},"notificationPayload":"!","nonce":"` +
+			Nonce{}.String() + `"}`, APNS},
+		{`{"data":{}}`, Provider(5)},
+	}
+
+	for i, tt := range tests {
+		_, _, err := ParseProviderPayload([]byte(tt.payload), tt.provider)
+		if err == nil {
+			t.Errorf("No error parsing %s payload %s (%d).",
+				tt.provider, tt.payload, i)
+		}
+	}
+}
+
+// Error path: Tests that WrapPayload returns ErrPayloadTooLarge for payloads
+// that do not fit in the provider's maximum size and an error for an unknown
+// provider.
+func TestWrapPayload_Error(t *testing.T) {
+	for _, provider := range []Provider{APNS, FCM} {
+		_, err := WrapPayload(make([]byte, provider.MaxPayloadSize()), provider)
+		if !errors.Is(err, ErrPayloadTooLarge) {
+			t.Errorf("Unexpected error for oversized %s payload: %+v",
+				provider, err)
+		}
+	}
+
+	if _, err := WrapPayload([]byte{1}, Provider(5)); err == nil {
+		t.Error("No error for unknown provider.")
+	}
+}
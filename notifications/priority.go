@@ -0,0 +1,72 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Priority describes how urgently a notification should be pushed to the
+// device.
+type Priority uint8
+
+const (
+	// Immediate notifications are pushed as soon as possible. This is the
+	// default priority.
+	Immediate Priority = iota
+
+	// Batched notifications may be delayed and combined with others.
+	Batched
+
+	// Silent notifications are delivered without alerting the user.
+	Silent
+)
+
+// String returns the string representation of the Priority. This functions
+// adheres to the fmt.Stringer interface.
+func (p Priority) String() string {
+	switch p {
+	case Immediate:
+		return "Immediate"
+	case Batched:
+		return "Batched"
+	case Silent:
+		return "Silent"
+	default:
+		return "INVALID PRIORITY " + strconv.Itoa(int(p))
+	}
+}
+
+// IsValid determines if the Priority is one of the defined priorities.
+func (p Priority) IsValid() bool {
+	return p <= Silent
+}
+
+// parsePriority parses a Priority from its decimal CSV form.
+func parsePriority(s string) (Priority, error) {
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse priority %q", s)
+	} else if !Priority(n).IsValid() {
+		return 0, errors.Errorf("invalid priority %d", n)
+	}
+	return Priority(n), nil
+}
+
+// SplitByPriority groups the Data list by priority. The relative order of
+// entries within each group is preserved. Priorities with no entries are not
+// included in the map.
+func SplitByPriority(ndList []*Data) map[Priority][]*Data {
+	split := make(map[Priority][]*Data)
+	for _, nd := range ndList {
+		split[nd.Priority] = append(split[nd.Priority], nd)
+	}
+	return split
+}
@@ -0,0 +1,97 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Consistency test of Priority.String.
+func TestPriority_String(t *testing.T) {
+	expected := []string{"Immediate", "Batched", "Silent", "INVALID PRIORITY 3"}
+	for p := Immediate; p <= Silent+1; p++ {
+		if p.String() != expected[p] {
+			t.Errorf("Incorrect string for Priority %d."+
+				"\nexpected: %s\nreceived: %s", p, expected[p], p)
+		}
+	}
+}
+
+// Tests that BuildNotificationCSV does not carry the priority so that every
+// row has two columns and can be read by a csv.Reader with its default fixed
+// number of fields, as used by clients that predate versioning.
+func TestBuildNotificationCSV_Priority(t *testing.T) {
+	ndList := GenerateTestData(6, rand.New(rand.NewSource(42)))
+	for i, nd := range ndList {
+		nd.Priority = Priority(i % 3)
+	}
+
+	csvData, rest := BuildNotificationCSV(ndList, 4096)
+	if len(rest) != 0 {
+		t.Fatalf("Unexpected overflow: %v", rest)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(csvData)).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %+v", err)
+	}
+	for i, record := range records {
+		if len(record) != 2 {
+			t.Errorf("Record %d with priority %s has %d fields.",
+				i, ndList[i].Priority, len(record))
+		}
+	}
+}
+
+// Tests that the priority is only included in the JSON when it is not
+// Immediate.
+func TestData_JSON_Priority(t *testing.T) {
+	data, err := json.Marshal(&Data{})
+	if err != nil {
+		t.Fatalf("Failed to JSON marshal: %+v", err)
+	} else if strings.Contains(string(data), "Priority") {
+		t.Errorf("Default priority included in JSON: %s", data)
+	}
+
+	expected := &Data{RoundID: 5, Priority: Silent}
+	data, err = json.Marshal(expected)
+	if err != nil {
+		t.Fatalf("Failed to JSON marshal: %+v", err)
+	}
+
+	var received Data
+	if err = json.Unmarshal(data, &received); err != nil {
+		t.Fatalf("Failed to JSON unmarshal: %+v", err)
+	} else if !reflect.DeepEqual(*expected, received) {
+		t.Errorf("Unexpected Data.\nexpected: %+v\nreceived: %+v",
+			*expected, received)
+	}
+}
+
+// Tests that SplitByPriority groups entries by priority in order.
+func TestSplitByPriority(t *testing.T) {
+	ndList := []*Data{{RoundID: 1, Priority: Silent}, {RoundID: 2},
+		{RoundID: 3, Priority: Batched}, {RoundID: 4, Priority: Silent}}
+
+	expected := map[Priority][]*Data{
+		Immediate: {ndList[1]},
+		Batched:   {ndList[2]},
+		Silent:    {ndList[0], ndList[3]},
+	}
+
+	if split := SplitByPriority(ndList); !reflect.DeepEqual(expected, split) {
+		t.Errorf("Unexpected split.\nexpected: %v\nreceived: %v",
+			expected, split)
+	}
+}
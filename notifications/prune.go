@@ -0,0 +1,30 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+// PruneDataBefore drops the entries for rounds before oldestRound, which are
+// for messages the gateway has already expired, so that they are not pushed.
+// Entries are kept in order. Returns the kept entries and the number of pruned
+// entries. Nil entries are dropped without being counted.
+func PruneDataBefore(ndList []*Data, oldestRound uint64) ([]*Data, int) {
+	kept := make([]*Data, 0, len(ndList))
+	var pruned int
+
+	for _, nd := range ndList {
+		if nd == nil {
+			continue
+		} else if nd.RoundID < oldestRound {
+			pruned++
+			continue
+		}
+
+		kept = append(kept, nd)
+	}
+
+	return kept, pruned
+}
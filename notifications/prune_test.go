@@ -0,0 +1,45 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Tests that PruneDataBefore drops the entries before the oldest round,
+// preserves the order of the kept entries, and counts the pruned entries.
+func TestPruneDataBefore(t *testing.T) {
+	r5, r7, r9 := &Data{RoundID: 5}, &Data{RoundID: 7}, &Data{RoundID: 9}
+	r7b := &Data{RoundID: 7, EphemeralID: 2}
+	ndList := []*Data{r9, r5, nil, r7, r7b}
+
+	tests := []struct {
+		oldest   uint64
+		expected []*Data
+		pruned   int
+	}{
+		{0, []*Data{r9, r5, r7, r7b}, 0},
+		{5, []*Data{r9, r5, r7, r7b}, 0},
+		{6, []*Data{r9, r7, r7b}, 1},
+		{8, []*Data{r9}, 3},
+		{10, []*Data{}, 4},
+	}
+
+	for _, tt := range tests {
+		kept, pruned := PruneDataBefore(ndList, tt.oldest)
+		if !reflect.DeepEqual(tt.expected, kept) {
+			t.Errorf("Unexpected kept entries for oldest round %d."+
+				"\nexpected: %v\nreceived: %v", tt.oldest, tt.expected, kept)
+		}
+		if pruned != tt.pruned {
+			t.Errorf("Unexpected pruned count for oldest round %d."+
+				"\nexpected: %d\nreceived: %d", tt.oldest, tt.pruned, pruned)
+		}
+	}
+}
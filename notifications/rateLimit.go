@@ -0,0 +1,34 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+// RateLimitData limits the number of entries in a batch for each ephemeral ID
+// to maxPerEphID, protecting devices from notification storms. Entries are
+// kept in order and the earliest entries for each ephemeral ID take
+// precedence. Returns the kept entries and the number of dropped entries. A
+// non-positive maxPerEphID drops every entry. Nil entries are dropped without
+// being counted.
+func RateLimitData(ndList []*Data, maxPerEphID int) ([]*Data, int) {
+	kept := make([]*Data, 0, len(ndList))
+	counts := make(map[int64]int)
+	var dropped int
+
+	for _, nd := range ndList {
+		if nd == nil {
+			continue
+		} else if counts[nd.EphemeralID] >= maxPerEphID {
+			dropped++
+			continue
+		}
+
+		counts[nd.EphemeralID]++
+		kept = append(kept, nd)
+	}
+
+	return kept, dropped
+}
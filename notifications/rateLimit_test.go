@@ -0,0 +1,49 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Tests that RateLimitData keeps the earliest entries for each ephemeral ID up
+// to the limit, preserves their order, and counts the dropped entries.
+func TestRateLimitData(t *testing.T) {
+	newData := func(ephID int64, round uint64) *Data {
+		return &Data{EphemeralID: ephID, RoundID: round}
+	}
+
+	a1, a2, a3 := newData(1, 1), newData(1, 2), newData(1, 3)
+	b1, b2 := newData(2, 1), newData(2, 2)
+	c1 := newData(3, 1)
+	ndList := []*Data{a1, b1, a2, nil, c1, a3, b2}
+
+	tests := []struct {
+		max      int
+		expected []*Data
+		dropped  int
+	}{
+		{0, []*Data{}, 6},
+		{1, []*Data{a1, b1, c1}, 3},
+		{2, []*Data{a1, b1, a2, c1, b2}, 1},
+		{3, []*Data{a1, b1, a2, c1, a3, b2}, 0},
+	}
+
+	for _, tt := range tests {
+		kept, dropped := RateLimitData(ndList, tt.max)
+		if !reflect.DeepEqual(tt.expected, kept) {
+			t.Errorf("Unexpected kept entries for max %d."+
+				"\nexpected: %v\nreceived: %v", tt.max, tt.expected, kept)
+		}
+		if dropped != tt.dropped {
+			t.Errorf("Unexpected dropped count for max %d."+
+				"\nexpected: %d\nreceived: %d", tt.max, tt.dropped, dropped)
+		}
+	}
+}
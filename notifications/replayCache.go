@@ -0,0 +1,174 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+// MaxReplayCacheCapacity is the largest capacity of a ReplayCache. It bounds
+// the memory allocated when loading a snapshot from untrusted storage.
+const MaxReplayCacheCapacity = 1 << 20
+
+// ReplayCache is a bounded, thread-safe set of the most recently received
+// payload Nonce values, each remembered for a fixed time to live (TTL).
+// Clients use it to drop push notifications that a provider delivers more
+// than once.
+//
+// When the cache is full, the oldest nonce is evicted, so a duplicate arriving
+// after capacity newer payloads is no longer detected. The capacity should be
+// chosen to cover the number of payloads received within the TTL.
+type ReplayCache struct {
+	ttl      time.Duration
+	capacity int
+	seen     map[Nonce]time.Time // Time each nonce was first received
+	order    []Nonce             // Nonces from oldest to newest
+	mux      sync.Mutex
+}
+
+// replayCacheDisk is the JSON representation of a ReplayCache snapshot.
+type replayCacheDisk struct {
+	Capacity int                `json:"capacity"`
+	TTL      time.Duration      `json:"ttl"`
+	Entries  []replayCacheEntry `json:"entries"`
+}
+
+// replayCacheEntry is a single nonce in a replayCacheDisk, in the order it was
+// received.
+type replayCacheEntry struct {
+	Nonce    Nonce `json:"nonce"`
+	Received int64 `json:"received"` // Unix nanoseconds
+}
+
+// NewReplayCache creates an empty ReplayCache that holds up to capacity
+// nonces for the given TTL. Panics if the capacity is less than one or greater
+// than MaxReplayCacheCapacity or if the TTL is not positive.
+func NewReplayCache(capacity int, ttl time.Duration) *ReplayCache {
+	if capacity < 1 || capacity > MaxReplayCacheCapacity {
+		jww.FATAL.Panicf("Cannot create ReplayCache with capacity %d; "+
+			"capacity must be between 1 and %d.",
+			capacity, MaxReplayCacheCapacity)
+	} else if ttl <= 0 {
+		jww.FATAL.Panicf("Cannot create ReplayCache with TTL %s; TTL must "+
+			"be positive.", ttl)
+	}
+
+	return &ReplayCache{
+		ttl:      ttl,
+		capacity: capacity,
+		seen:     make(map[Nonce]time.Time, capacity),
+		order:    make([]Nonce, 0, capacity),
+	}
+}
+
+// Accept records the nonce as received at the given time and returns true if
+// it has not been received within the TTL. Returns false if the nonce is a
+// replay, in which case the payload should be dropped.
+func (rc *ReplayCache) Accept(nonce Nonce, now time.Time) bool {
+	rc.mux.Lock()
+	defer rc.mux.Unlock()
+
+	rc.expire(now)
+	if _, exists := rc.seen[nonce]; exists {
+		return false
+	}
+
+	if len(rc.order) == rc.capacity {
+		delete(rc.seen, rc.order[0])
+		rc.order = append(rc.order[:0], rc.order[1:]...)
+	}
+
+	rc.seen[nonce] = now
+	rc.order = append(rc.order, nonce)
+	return true
+}
+
+// Len returns the number of nonces in the cache, including any that have
+// expired but not yet been removed.
+func (rc *ReplayCache) Len() int {
+	rc.mux.Lock()
+	defer rc.mux.Unlock()
+	return len(rc.order)
+}
+
+// Snapshot returns the JSON encoding of the cache's capacity, TTL, and nonces
+// from oldest to newest so that it can be persisted across restarts. The cache
+// is not modified.
+func (rc *ReplayCache) Snapshot() ([]byte, error) {
+	rc.mux.Lock()
+	defer rc.mux.Unlock()
+
+	entries := make([]replayCacheEntry, len(rc.order))
+	for i, nonce := range rc.order {
+		entries[i] = replayCacheEntry{nonce, rc.seen[nonce].UnixNano()}
+	}
+
+	return json.Marshal(replayCacheDisk{
+		Capacity: rc.capacity,
+		TTL:      rc.ttl,
+		Entries:  entries,
+	})
+}
+
+// LoadReplayCache creates a new ReplayCache from a snapshot produced by
+// ReplayCache.Snapshot. Returns an error if the capacity in the snapshot is
+// greater than MaxReplayCacheCapacity.
+func LoadReplayCache(data []byte) (*ReplayCache, error) {
+	var disk replayCacheDisk
+	if err := json.Unmarshal(data, &disk); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal ReplayCache snapshot")
+	}
+
+	if disk.Capacity < 1 {
+		return nil, errors.Errorf(
+			"invalid ReplayCache snapshot capacity %d", disk.Capacity)
+	} else if disk.Capacity > MaxReplayCacheCapacity {
+		return nil, errors.Errorf("ReplayCache snapshot capacity %d exceeds "+
+			"maximum %d", disk.Capacity, MaxReplayCacheCapacity)
+	} else if disk.TTL <= 0 {
+		return nil, errors.Errorf(
+			"invalid ReplayCache snapshot TTL %s", disk.TTL)
+	} else if len(disk.Entries) > disk.Capacity {
+		return nil, errors.Errorf("ReplayCache snapshot has %d entries, "+
+			"which exceeds its capacity %d", len(disk.Entries), disk.Capacity)
+	}
+
+	rc := NewReplayCache(disk.Capacity, disk.TTL)
+	for _, e := range disk.Entries {
+		if _, exists := rc.seen[e.Nonce]; exists {
+			return nil, errors.Errorf(
+				"ReplayCache snapshot has duplicate nonce %s", e.Nonce)
+		}
+		rc.seen[e.Nonce] = time.Unix(0, e.Received)
+		rc.order = append(rc.order, e.Nonce)
+	}
+
+	return rc, nil
+}
+
+// expire removes the nonces received more than the TTL before now. Nonces are
+// removed from oldest to newest, stopping at the first that has not expired.
+// The lock must be held by the caller.
+func (rc *ReplayCache) expire(now time.Time) {
+	var i int
+	for i < len(rc.order) && now.Sub(rc.seen[rc.order[i]]) > rc.ttl {
+		delete(rc.seen, rc.order[i])
+		i++
+	}
+
+	// Copy the remaining nonces to the start so that the slice does not grow
+	// past its capacity
+	if i > 0 {
+		rc.order = append(rc.order[:0], rc.order[i:]...)
+	}
+}
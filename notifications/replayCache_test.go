@@ -0,0 +1,185 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestNonces returns n random nonces from the seed.
+func newTestNonces(n int, seed int64) []Nonce {
+	prng := rand.New(rand.NewSource(seed))
+	nonces := make([]Nonce, n)
+	for i := range nonces {
+		nonces[i], _ = newNonce(prng)
+	}
+	return nonces
+}
+
+// Tests that ReplayCache.Accept accepts a nonce once and rejects it until the
+// TTL has passed.
+func TestReplayCache_Accept(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	rc := NewReplayCache(10, time.Minute)
+	nonces := newTestNonces(2, 42)
+
+	if !rc.Accept(nonces[0], now) {
+		t.Error("New nonce rejected.")
+	}
+	if rc.Accept(nonces[0], now.Add(time.Second)) {
+		t.Error("Replayed nonce accepted.")
+	}
+	if !rc.Accept(nonces[1], now.Add(time.Second)) {
+		t.Error("Second new nonce rejected.")
+	}
+	if rc.Accept(nonces[0], now.Add(time.Minute)) {
+		t.Error("Replayed nonce accepted at the TTL.")
+	}
+	if !rc.Accept(nonces[0], now.Add(time.Minute+1)) {
+		t.Error("Nonce rejected after the TTL.")
+	}
+	if rc.Len() != 2 {
+		t.Errorf("Unexpected length.\nexpected: %d\nreceived: %d", 2, rc.Len())
+	}
+}
+
+// Tests that ReplayCache.Accept evicts the oldest nonce when the cache is
+// full.
+func TestReplayCache_Accept_Full(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	rc := NewReplayCache(3, time.Hour)
+	nonces := newTestNonces(4, 42)
+
+	for i, n := range nonces {
+		if !rc.Accept(n, now) {
+			t.Errorf("New nonce %d rejected.", i)
+		}
+	}
+
+	if rc.Len() != 3 {
+		t.Errorf("Unexpected length.\nexpected: %d\nreceived: %d", 3, rc.Len())
+	}
+	for i, n := range nonces[1:] {
+		if rc.Accept(n, now) {
+			t.Errorf("Replayed nonce %d accepted.", i+1)
+		}
+	}
+	if !rc.Accept(nonces[0], now) {
+		t.Error("Evicted nonce rejected.")
+	}
+}
+
+// Tests that concurrent calls to ReplayCache.Accept accept each nonce exactly
+// once.
+func TestReplayCache_Accept_Concurrent(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	rc := NewReplayCache(100, time.Hour)
+	nonces := newTestNonces(50, 42)
+
+	var wg sync.WaitGroup
+	var mux sync.Mutex
+	accepted := make(map[Nonce]int)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, n := range nonces {
+				if rc.Accept(n, now) {
+					mux.Lock()
+					accepted[n]++
+					mux.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, n := range nonces {
+		if accepted[n] != 1 {
+			t.Errorf("Nonce %d accepted %d times.", i, accepted[n])
+		}
+	}
+}
+
+// Tests that a ReplayCache loaded from ReplayCache.Snapshot with
+// LoadReplayCache rejects the same nonces and expires them at the same time.
+func TestReplayCache_Snapshot_LoadReplayCache(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	rc := NewReplayCache(5, time.Minute)
+	nonces := newTestNonces(3, 42)
+	for i, n := range nonces {
+		rc.Accept(n, now.Add(time.Duration(i)*time.Second))
+	}
+
+	data, err := rc.Snapshot()
+	if err != nil {
+		t.Fatalf("Failed to snapshot ReplayCache: %+v", err)
+	}
+	loaded, err := LoadReplayCache(data)
+	if err != nil {
+		t.Fatalf("Failed to load ReplayCache: %+v", err)
+	}
+
+	if loaded.capacity != rc.capacity || loaded.ttl != rc.ttl ||
+		loaded.Len() != rc.Len() {
+		t.Errorf("Loaded cache does not match.\nexpected: %d, %s, %d"+
+			"\nreceived: %d, %s, %d", rc.capacity, rc.ttl, rc.Len(),
+			loaded.capacity, loaded.ttl, loaded.Len())
+	}
+
+	// At this time only the first nonce has expired
+	later := now.Add(time.Minute + time.Second/2)
+	for i, n := range nonces {
+		if accepted := loaded.Accept(n, later); accepted != (i == 0) {
+			t.Errorf("Unexpected result for nonce %d.\nexpected: %t"+
+				"\nreceived: %t", i, i == 0, accepted)
+		}
+	}
+}
+
+// Error path: Tests that LoadReplayCache rejects invalid snapshots.
+func TestLoadReplayCache_Error(t *testing.T) {
+	n := newTestNonces(1, 42)[0]
+	entry := `{"nonce":"` + n.String() + `","received":0}`
+	for i, data := range []string{
+		"invalid",
+		`{"capacity":0,"ttl":1,"entries":[]}`,
+		`{"capacity":1,"ttl":0,"entries":[]}`,
+		`{"capacity":1,"ttl":1,"entries":[` + entry + `,` + entry + `]}`,
+		`{"capacity":2,"ttl":1,"entries":[` + entry + `,` + entry + `]}`,
+		`{"capacity":1,"ttl":1,"entries":[{"nonce":"AAAA"}]}`,
+		`{"capacity":1048577,"ttl":1,"entries":[]}`,
+		`{"capacity":9223372036854775807,"ttl":1,"entries":[]}`,
+	} {
+		if _, err := LoadReplayCache([]byte(data)); err == nil {
+			t.Errorf("No error loading invalid snapshot %s (%d).", data, i)
+		}
+	}
+}
+
+// Error path: Tests that NewReplayCache panics for an invalid capacity or TTL.
+func TestNewReplayCache_Panic(t *testing.T) {
+	for i, tt := range []struct {
+		capacity int
+		ttl      time.Duration
+	}{{0, time.Minute}, {MaxReplayCacheCapacity + 1, time.Minute}, {1, 0},
+		{1, -time.Minute}} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("Failed to panic for capacity %d and TTL %s "+
+						"(%d).", tt.capacity, tt.ttl, i)
+				}
+			}()
+			NewReplayCache(tt.capacity, tt.ttl)
+		}()
+	}
+}
@@ -0,0 +1,63 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"github.com/pkg/errors"
+)
+
+// EphemeralResolver looks up the ephemeral ID that a notification was sent to.
+// Notification CSVs do not carry the ephemeral ID, so after decoding, the
+// receiver matches each notification's identity fingerprint against the
+// identities it is tracking to recover it.
+type EphemeralResolver interface {
+	// ResolveEphemeralID returns the ephemeral ID for the notification with
+	// the given identity fingerprint and message hash. Returns false if the
+	// notification is not for a tracked identity. An error aborts resolution
+	// of the remaining notifications.
+	ResolveEphemeralID(identityFP, messageHash []byte) (int64, bool, error)
+}
+
+// EphemeralResolverFunc adapts a function to an EphemeralResolver.
+type EphemeralResolverFunc func(
+	identityFP, messageHash []byte) (int64, bool, error)
+
+// ResolveEphemeralID calls f(identityFP, messageHash).
+func (f EphemeralResolverFunc) ResolveEphemeralID(
+	identityFP, messageHash []byte) (int64, bool, error) {
+	return f(identityFP, messageHash)
+}
+
+// AttachEphemeralIDs sets the EphemeralID of each Data in the list to the ID
+// returned by the resolver. Entries that the resolver cannot resolve are left
+// unchanged and are returned in order; nil entries are skipped. If the resolver
+// returns an error, AttachEphemeralIDs stops and returns it; entries before the
+// failed entry have already been updated.
+func AttachEphemeralIDs(
+	ndList []*Data, resolver EphemeralResolver) ([]*Data, error) {
+	var unresolved []*Data
+	for i, nd := range ndList {
+		if nd == nil {
+			continue
+		}
+
+		ephID, found, err := resolver.ResolveEphemeralID(
+			nd.IdentityFP, nd.MessageHash)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to resolve ephemeral "+
+				"ID for notification %d of %d", i, len(ndList))
+		} else if !found {
+			unresolved = append(unresolved, nd)
+			continue
+		}
+
+		nd.EphemeralID = ephID
+	}
+
+	return unresolved, nil
+}
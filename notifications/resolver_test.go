@@ -0,0 +1,92 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// mapResolver is an EphemeralResolver that resolves identity fingerprints
+// from a map.
+type mapResolver map[string]int64
+
+func (m mapResolver) ResolveEphemeralID(
+	identityFP, _ []byte) (int64, bool, error) {
+	ephID, exists := m[string(identityFP)]
+	return ephID, exists, nil
+}
+
+// Tests that AttachEphemeralIDs sets the ephemeral IDs of decoded
+// notifications and returns the entries that could not be resolved.
+func TestAttachEphemeralIDs(t *testing.T) {
+	original := []*Data{
+		{EphemeralID: 5, IdentityFP: []byte("fp1"), MessageHash: []byte("a")},
+		{EphemeralID: -3, IdentityFP: []byte("fp2"), MessageHash: []byte("b")},
+		{EphemeralID: 9, IdentityFP: []byte("fp3"), MessageHash: []byte("c")},
+	}
+
+	csv, _ := BuildNotificationCSV(original, 4096)
+	decoded, err := DecodeNotificationsCSV(string(csv))
+	if err != nil {
+		t.Fatalf("Failed to decode CSV: %+v", err)
+	}
+	decoded = append(decoded, nil)
+
+	resolver := mapResolver{"fp1": 5, "fp2": -3}
+	unresolved, err := AttachEphemeralIDs(decoded, resolver)
+	if err != nil {
+		t.Fatalf("Failed to attach ephemeral IDs: %+v", err)
+	}
+
+	for i, nd := range original[:2] {
+		if !reflect.DeepEqual(nd, decoded[i]) {
+			t.Errorf("Unexpected Data (%d).\nexpected: %+v\nreceived: %+v",
+				i, nd, decoded[i])
+		}
+	}
+
+	if len(unresolved) != 1 || unresolved[0] != decoded[2] {
+		t.Errorf("Unexpected unresolved entries: %v", unresolved)
+	} else if decoded[2].EphemeralID != 0 {
+		t.Errorf("Unresolved entry modified: %+v", decoded[2])
+	}
+}
+
+// Error path: Tests that AttachEphemeralIDs stops at and returns the first
+// error from the resolver.
+func TestAttachEphemeralIDs_ResolverError(t *testing.T) {
+	expectedErr := errors.New("storage unavailable")
+	var calls int
+	resolver := EphemeralResolverFunc(
+		func(identityFP, _ []byte) (int64, bool, error) {
+			calls++
+			if string(identityFP) == "fp2" {
+				return 0, false, expectedErr
+			}
+			return 7, true, nil
+		})
+
+	ndList := []*Data{
+		{IdentityFP: []byte("fp1")},
+		{IdentityFP: []byte("fp2")},
+		{IdentityFP: []byte("fp3")},
+	}
+	_, err := AttachEphemeralIDs(ndList, resolver)
+	if !errors.Is(err, expectedErr) {
+		t.Errorf("Unexpected error.\nexpected: %v\nreceived: %+v",
+			expectedErr, err)
+	}
+
+	if calls != 2 || ndList[0].EphemeralID != 7 || ndList[2].EphemeralID != 0 {
+		t.Errorf("Unexpected resolution after error: calls=%d, list=%v",
+			calls, ndList)
+	}
+}
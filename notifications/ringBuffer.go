@@ -0,0 +1,173 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/pkg/errors"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+// MaxRingBufferCapacity is the largest capacity of a RingBuffer. It bounds the
+// memory allocated when loading a snapshot from untrusted storage.
+const MaxRingBufferCapacity = 1 << 20
+
+// RingBuffer is a fixed-capacity, thread-safe queue of [Data]. When the buffer
+// is full, pushing a new entry overwrites the oldest entry and increments the
+// dropped counter, which keeps memory usage predictable.
+type RingBuffer struct {
+	buff    []*Data
+	head    int    // Index of the oldest entry
+	count   int    // Number of entries in the buffer
+	dropped uint64 // Number of entries overwritten before being popped
+	mux     sync.Mutex
+}
+
+// ringBufferDisk is the JSON representation of a RingBuffer snapshot.
+type ringBufferDisk struct {
+	Capacity int     `json:"capacity"`
+	Dropped  uint64  `json:"dropped"`
+	Entries  []*Data `json:"entries"`
+}
+
+// NewRingBuffer creates an empty RingBuffer that holds up to capacity entries.
+// Panics if the capacity is less than one or greater than
+// MaxRingBufferCapacity.
+func NewRingBuffer(capacity int) *RingBuffer {
+	if capacity < 1 || capacity > MaxRingBufferCapacity {
+		jww.FATAL.Panicf("Cannot create RingBuffer with capacity %d; "+
+			"capacity must be between 1 and %d.",
+			capacity, MaxRingBufferCapacity)
+	}
+
+	return &RingBuffer{buff: make([]*Data, capacity)}
+}
+
+// Push adds the entry to the buffer. If the buffer is full, the oldest entry is
+// overwritten and Push returns true.
+func (rb *RingBuffer) Push(d *Data) bool {
+	rb.mux.Lock()
+	defer rb.mux.Unlock()
+
+	tail := (rb.head + rb.count) % len(rb.buff)
+	rb.buff[tail] = d
+
+	if rb.count == len(rb.buff) {
+		rb.head = (rb.head + 1) % len(rb.buff)
+		rb.dropped++
+		return true
+	}
+
+	rb.count++
+	return false
+}
+
+// Pop removes and returns the oldest entry in the buffer. Returns false if the
+// buffer is empty.
+func (rb *RingBuffer) Pop() (*Data, bool) {
+	rb.mux.Lock()
+	defer rb.mux.Unlock()
+
+	if rb.count == 0 {
+		return nil, false
+	}
+
+	d := rb.buff[rb.head]
+	rb.buff[rb.head] = nil
+	rb.head = (rb.head + 1) % len(rb.buff)
+	rb.count--
+
+	return d, true
+}
+
+// Drain removes and returns all entries in the buffer from oldest to newest.
+func (rb *RingBuffer) Drain() []*Data {
+	rb.mux.Lock()
+	defer rb.mux.Unlock()
+
+	entries := rb.entries()
+	for i := range rb.buff {
+		rb.buff[i] = nil
+	}
+	rb.head, rb.count = 0, 0
+
+	return entries
+}
+
+// Len returns the number of entries in the buffer.
+func (rb *RingBuffer) Len() int {
+	rb.mux.Lock()
+	defer rb.mux.Unlock()
+	return rb.count
+}
+
+// Cap returns the maximum number of entries the buffer can hold.
+func (rb *RingBuffer) Cap() int {
+	return len(rb.buff)
+}
+
+// Dropped returns the number of entries that were overwritten before they were
+// popped.
+func (rb *RingBuffer) Dropped() uint64 {
+	rb.mux.Lock()
+	defer rb.mux.Unlock()
+	return rb.dropped
+}
+
+// Snapshot returns the JSON encoding of the buffer's capacity, dropped count,
+// and entries from oldest to newest. The buffer is not modified.
+func (rb *RingBuffer) Snapshot() ([]byte, error) {
+	rb.mux.Lock()
+	defer rb.mux.Unlock()
+
+	return json.Marshal(ringBufferDisk{
+		Capacity: len(rb.buff),
+		Dropped:  rb.dropped,
+		Entries:  rb.entries(),
+	})
+}
+
+// LoadRingBuffer creates a new RingBuffer from a snapshot produced by
+// RingBuffer.Snapshot. Returns an error if the capacity in the snapshot is
+// greater than MaxRingBufferCapacity.
+func LoadRingBuffer(data []byte) (*RingBuffer, error) {
+	var disk ringBufferDisk
+	if err := json.Unmarshal(data, &disk); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal RingBuffer snapshot")
+	}
+
+	if disk.Capacity < 1 {
+		return nil, errors.Errorf(
+			"invalid RingBuffer snapshot capacity %d", disk.Capacity)
+	} else if disk.Capacity > MaxRingBufferCapacity {
+		return nil, errors.Errorf("RingBuffer snapshot capacity %d exceeds "+
+			"maximum %d", disk.Capacity, MaxRingBufferCapacity)
+	} else if len(disk.Entries) > disk.Capacity {
+		return nil, errors.Errorf("RingBuffer snapshot has %d entries, which "+
+			"exceeds its capacity %d", len(disk.Entries), disk.Capacity)
+	}
+
+	rb := NewRingBuffer(disk.Capacity)
+	copy(rb.buff, disk.Entries)
+	rb.count = len(disk.Entries)
+	rb.dropped = disk.Dropped
+
+	return rb, nil
+}
+
+// entries returns the entries in the buffer from oldest to newest. The lock
+// must be held by the caller.
+func (rb *RingBuffer) entries() []*Data {
+	entries := make([]*Data, rb.count)
+	for i := range entries {
+		entries[i] = rb.buff[(rb.head+i)%len(rb.buff)]
+	}
+	return entries
+}
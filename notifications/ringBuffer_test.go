@@ -0,0 +1,110 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Tests that RingBuffer.Push overwrites the oldest entries once full and that
+// RingBuffer.Pop returns the remaining entries in order.
+func TestRingBuffer_Push_Pop(t *testing.T) {
+	rb := NewRingBuffer(3)
+	dataList := newTestDataList(5, 42)
+
+	for i, d := range dataList {
+		if overwritten := rb.Push(d); overwritten != (i >= 3) {
+			t.Errorf("Unexpected overwrite result for entry %d: %t",
+				i, overwritten)
+		}
+	}
+
+	if rb.Len() != 3 || rb.Dropped() != 2 {
+		t.Errorf("Unexpected length %d or dropped count %d.",
+			rb.Len(), rb.Dropped())
+	}
+
+	for i := 2; i < 5; i++ {
+		d, ok := rb.Pop()
+		if !ok || d != dataList[i] {
+			t.Errorf("Popped unexpected entry (%d).\nexpected: %s"+
+				"\nreceived: %s", i, dataList[i], d)
+		}
+	}
+
+	if _, ok := rb.Pop(); ok {
+		t.Error("Pop returned an entry from an empty buffer.")
+	}
+}
+
+// Tests that RingBuffer.Drain returns all entries and empties the buffer.
+func TestRingBuffer_Drain(t *testing.T) {
+	rb := NewRingBuffer(4)
+	dataList := newTestDataList(6, 42)
+	for _, d := range dataList {
+		rb.Push(d)
+	}
+
+	if entries := rb.Drain(); !reflect.DeepEqual(dataList[2:], entries) {
+		t.Errorf("Unexpected drained entries.\nexpected: %v\nreceived: %v",
+			dataList[2:], entries)
+	}
+
+	if rb.Len() != 0 {
+		t.Errorf("Buffer not empty after drain: %d", rb.Len())
+	}
+}
+
+// Tests that a RingBuffer restored by LoadRingBuffer from RingBuffer.Snapshot
+// matches the original.
+func TestRingBuffer_Snapshot_LoadRingBuffer(t *testing.T) {
+	rb := NewRingBuffer(5)
+	for _, d := range newTestDataList(7, 42) {
+		rb.Push(d)
+	}
+
+	snapshot, err := rb.Snapshot()
+	if err != nil {
+		t.Fatalf("Failed to snapshot: %+v", err)
+	}
+
+	loaded, err := LoadRingBuffer(snapshot)
+	if err != nil {
+		t.Fatalf("Failed to load snapshot: %+v", err)
+	}
+
+	if loaded.Cap() != rb.Cap() || loaded.Dropped() != rb.Dropped() {
+		t.Errorf("Loaded buffer capacity %d or dropped %d does not match "+
+			"original %d and %d.",
+			loaded.Cap(), loaded.Dropped(), rb.Cap(), rb.Dropped())
+	}
+
+	if expected, received := rb.Drain(), loaded.Drain(); !reflect.DeepEqual(
+		expected, received) {
+		t.Errorf("Loaded entries do not match.\nexpected: %v\nreceived: %v",
+			expected, received)
+	}
+}
+
+// Error path: Tests that LoadRingBuffer rejects invalid snapshots.
+func TestLoadRingBuffer_Error(t *testing.T) {
+	invalid := []string{
+		"not json",
+		`{"capacity":0}`,
+		`{"capacity":1,"entries":[{},{}]}`,
+		`{"capacity":1048577}`,
+		`{"capacity":9223372036854775807}`,
+	}
+
+	for i, s := range invalid {
+		if _, err := LoadRingBuffer([]byte(s)); err == nil {
+			t.Errorf("No error for invalid snapshot %q (%d).", s, i)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"encoding/binary"
+	"io"
+
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+const (
+	// IdentityFPLen is the length of Data.IdentityFP.
+	IdentityFPLen = 25
+
+	// MessageHashLen is the length of Data.MessageHash.
+	MessageHashLen = 32
+
+	// maxTestRoundStep is the largest gap between the rounds of consecutive
+	// entries generated by GenerateTestData.
+	maxTestRoundStep = 4
+)
+
+// GenerateTestData generates n notification Data entries with valid field
+// lengths and monotonically increasing round IDs, reading all randomness from
+// rng. The same rng state always produces the same list, making it suitable
+// for reproducible fixtures in tests. Panics if rng cannot be read.
+//
+// Do not use this function outside of tests.
+func GenerateTestData(n int, rng io.Reader) []*Data {
+	ndList := make([]*Data, n)
+	roundID := uint64(readTestUint32(rng))
+	for i := range ndList {
+		ephemeralID := make([]byte, 8)
+		identityFP := make([]byte, IdentityFPLen)
+		messageHash := make([]byte, MessageHashLen)
+		readTestData(rng, ephemeralID)
+		readTestData(rng, identityFP)
+		readTestData(rng, messageHash)
+
+		roundID += uint64(readTestUint32(rng)%maxTestRoundStep) + 1
+
+		ndList[i] = &Data{
+			EphemeralID: int64(binary.BigEndian.Uint64(ephemeralID)),
+			RoundID:     roundID,
+			IdentityFP:  identityFP,
+			MessageHash: messageHash,
+		}
+	}
+
+	return ndList
+}
+
+// readTestUint32 reads a uint32 from rng.
+func readTestUint32(rng io.Reader) uint32 {
+	b := make([]byte, 4)
+	readTestData(rng, b)
+	return binary.BigEndian.Uint32(b)
+}
+
+// readTestData fills b from rng and panics on failure.
+func readTestData(rng io.Reader, b []byte) {
+	if _, err := io.ReadFull(rng, b); err != nil {
+		jww.FATAL.Panicf("Failed to read %d random bytes for test data: %+v",
+			len(b), err)
+	}
+}
@@ -0,0 +1,67 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// Tests that GenerateTestData produces entries with valid field lengths and
+// strictly increasing rounds.
+func TestGenerateTestData(t *testing.T) {
+	ndList := GenerateTestData(100, rand.New(rand.NewSource(42)))
+
+	if len(ndList) != 100 {
+		t.Fatalf("Unexpected number of entries.\nexpected: %d\nreceived: %d",
+			100, len(ndList))
+	}
+
+	for i, nd := range ndList {
+		if len(nd.IdentityFP) != IdentityFPLen {
+			t.Errorf("IdentityFP %d has length %d; expected %d",
+				i, len(nd.IdentityFP), IdentityFPLen)
+		}
+		if len(nd.MessageHash) != MessageHashLen {
+			t.Errorf("MessageHash %d has length %d; expected %d",
+				i, len(nd.MessageHash), MessageHashLen)
+		}
+		if i > 0 && nd.RoundID <= ndList[i-1].RoundID {
+			t.Errorf("Round %d (%d) is not greater than previous round (%d).",
+				i, nd.RoundID, ndList[i-1].RoundID)
+		}
+	}
+}
+
+// Tests that GenerateTestData produces the same list for the same seed and
+// different lists for different seeds.
+func TestGenerateTestData_Reproducible(t *testing.T) {
+	a := GenerateTestData(20, rand.New(rand.NewSource(7)))
+	b := GenerateTestData(20, rand.New(rand.NewSource(7)))
+	c := GenerateTestData(20, rand.New(rand.NewSource(8)))
+
+	if !reflect.DeepEqual(a, b) {
+		t.Error("Lists generated from the same seed differ.")
+	}
+	if reflect.DeepEqual(a, c) {
+		t.Error("Lists generated from different seeds are the same.")
+	}
+}
+
+// Tests that GenerateTestData panics when the reader runs out of data.
+func TestGenerateTestData_ShortReaderPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Failed to panic when the reader runs out of data.")
+		}
+	}()
+
+	GenerateTestData(1, bytes.NewReader(make([]byte, 10)))
+}
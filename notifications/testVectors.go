@@ -0,0 +1,61 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	_ "embed"
+	"encoding/json"
+
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+// testVectorsJSON is the JSON encoding of the canonical test vectors. The file
+// is the cross-language specification of the payload formats; clients on other
+// platforms can read it directly instead of calling TestVectors.
+//
+//go:embed testVectors.json
+var testVectorsJSON []byte
+
+// TestVector is a canonical input to BuildVersionedPayload and the exact
+// payload it produces. Clients that extract notifications on other platforms
+// use the vectors to verify byte-exact compatibility with the Go encoders. The
+// vectors never change; new vectors are added for new formats. The only
+// exception is the removal of the priority column from the LegacyCSV and
+// VersionCSV vectors, which had made them unreadable by clients that predate
+// versioning.
+type TestVector struct {
+	// Name describes the vector
+	Name string
+
+	// Input is the list of notification Data to encode
+	Input []*Data
+
+	// MaxSize is the maximum size of the payload
+	MaxSize int
+
+	// Version is the payload format version
+	Version Version
+
+	// Payload is the expected encoded payload
+	Payload []byte
+
+	// NumIncluded is the number of entries at the start of Input that fit in
+	// the payload
+	NumIncluded int
+}
+
+// TestVectors returns the canonical notification payload test vectors. Each
+// call returns new copies that may be modified by the caller.
+func TestVectors() []TestVector {
+	var vectors []TestVector
+	if err := json.Unmarshal(testVectorsJSON, &vectors); err != nil {
+		jww.FATAL.Panicf("Failed to JSON unmarshal test vectors: %+v", err)
+	}
+
+	return vectors
+}
@@ -0,0 +1,92 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+)
+
+// Tests that BuildVersionedPayload produces the exact payload of every test
+// vector and that DecodeAny decodes each payload back to the included entries.
+func TestTestVectors(t *testing.T) {
+	vectors := TestVectors()
+	if len(vectors) == 0 {
+		t.Fatal("No test vectors.")
+	}
+
+	for _, v := range vectors {
+		payload, rest, err := BuildVersionedPayload(
+			v.Input, v.MaxSize, v.Version)
+		if err != nil {
+			t.Errorf("Failed to build payload for %s: %+v", v.Name, err)
+			continue
+		}
+
+		if !bytes.Equal(v.Payload, payload) {
+			t.Errorf("Unexpected payload for %s.\nexpected: %q\nreceived: %q",
+				v.Name, v.Payload, payload)
+		}
+		if n := len(v.Input) - len(rest); n != v.NumIncluded {
+			t.Errorf("Unexpected number of entries included for %s."+
+				"\nexpected: %d\nreceived: %d", v.Name, v.NumIncluded, n)
+		}
+
+		decoded, version, err := DecodeAny(v.Payload)
+		if err != nil {
+			t.Errorf("Failed to decode payload for %s: %+v", v.Name, err)
+		} else if version != v.Version {
+			t.Errorf("Unexpected version for %s.\nexpected: %s\nreceived: %s",
+				v.Name, v.Version, version)
+		} else if len(decoded) != v.NumIncluded {
+			t.Errorf("Unexpected number of decoded entries for %s."+
+				"\nexpected: %d\nreceived: %d", v.Name, v.NumIncluded,
+				len(decoded))
+		}
+	}
+}
+
+// Tests that every row of the LegacyCSV and VersionCSV test vectors has two
+// columns so that the payloads are readable by a csv.Reader with its default
+// fixed number of fields, as used by clients that predate versioning.
+func TestTestVectors_CSVColumns(t *testing.T) {
+	for _, v := range TestVectors() {
+		body := v.Payload
+		if v.Version == VersionCSV {
+			body = body[1:]
+		} else if v.Version != LegacyCSV {
+			continue
+		}
+
+		records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+		if err != nil {
+			t.Errorf("Failed to read CSV of %s: %+v", v.Name, err)
+		}
+		for i, record := range records {
+			if len(record) != 2 {
+				t.Errorf("Record %d of %s has %d columns.",
+					i, v.Name, len(record))
+			}
+		}
+	}
+}
+
+// Tests that each call to TestVectors returns new copies.
+func TestTestVectors_Copies(t *testing.T) {
+	vectors := TestVectors()
+	expected := TestVectors()
+	vectors[0].Payload[0]++
+	vectors[0].Input[0].RoundID++
+
+	if reflect.DeepEqual(vectors, expected) ||
+		!reflect.DeepEqual(expected, TestVectors()) {
+		t.Error("Modifying test vectors changed later calls.")
+	}
+}
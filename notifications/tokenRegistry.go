@@ -0,0 +1,153 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TokenEntry is a push token and the time it expires.
+type TokenEntry struct {
+	Token  string    `json:"token"`
+	Expiry time.Time `json:"expiry"`
+}
+
+// TokenRegistry maps identity fingerprints to push tokens. Each registration
+// expires after the registry's TTL unless it is registered again. Expired
+// entries are never returned and are removed by TokenRegistry.Prune.
+type TokenRegistry struct {
+	ttl     time.Duration
+	entries map[string]TokenEntry // Keyed on the identity fingerprint
+	now     func() time.Time
+	mux     sync.RWMutex
+}
+
+// tokenRegistryDisk is the JSON representation of a TokenRegistry. Identity
+// fingerprints are base 64 encoded.
+type tokenRegistryDisk struct {
+	TTL     time.Duration         `json:"ttl"`
+	Entries map[string]TokenEntry `json:"entries"`
+}
+
+// NewTokenRegistry creates an empty TokenRegistry whose entries expire after
+// the given TTL.
+func NewTokenRegistry(ttl time.Duration) *TokenRegistry {
+	return &TokenRegistry{
+		ttl:     ttl,
+		entries: make(map[string]TokenEntry),
+		now:     time.Now,
+	}
+}
+
+// Register sets the push token for the identity and resets its expiry.
+func (tr *TokenRegistry) Register(identityFP []byte, token string) {
+	tr.mux.Lock()
+	defer tr.mux.Unlock()
+
+	tr.entries[string(identityFP)] = TokenEntry{
+		Token:  token,
+		Expiry: tr.now().Add(tr.ttl),
+	}
+}
+
+// Get returns the push token for the identity. Returns false if there is no
+// token or if it has expired.
+func (tr *TokenRegistry) Get(identityFP []byte) (string, bool) {
+	tr.mux.RLock()
+	defer tr.mux.RUnlock()
+
+	entry, exists := tr.entries[string(identityFP)]
+	if !exists || !tr.now().Before(entry.Expiry) {
+		return "", false
+	}
+	return entry.Token, true
+}
+
+// Matches determines if the token is the unexpired push token registered for
+// the identity. The tokens are compared in constant time.
+func (tr *TokenRegistry) Matches(identityFP []byte, token string) bool {
+	registered, exists := tr.Get(identityFP)
+	if !exists {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(registered), []byte(token)) == 1
+}
+
+// Remove deletes the push token for the identity.
+func (tr *TokenRegistry) Remove(identityFP []byte) {
+	tr.mux.Lock()
+	defer tr.mux.Unlock()
+	delete(tr.entries, string(identityFP))
+}
+
+// Prune deletes all expired entries and returns the number deleted.
+func (tr *TokenRegistry) Prune() int {
+	tr.mux.Lock()
+	defer tr.mux.Unlock()
+
+	now := tr.now()
+	var pruned int
+	for fp, entry := range tr.entries {
+		if !now.Before(entry.Expiry) {
+			delete(tr.entries, fp)
+			pruned++
+		}
+	}
+	return pruned
+}
+
+// Len returns the number of entries, including expired entries that have not
+// yet been pruned.
+func (tr *TokenRegistry) Len() int {
+	tr.mux.RLock()
+	defer tr.mux.RUnlock()
+	return len(tr.entries)
+}
+
+// Marshal returns the JSON encoding of the TokenRegistry.
+func (tr *TokenRegistry) Marshal() ([]byte, error) {
+	tr.mux.RLock()
+	defer tr.mux.RUnlock()
+
+	disk := tokenRegistryDisk{
+		TTL:     tr.ttl,
+		Entries: make(map[string]TokenEntry, len(tr.entries)),
+	}
+	for fp, entry := range tr.entries {
+		disk.Entries[base64.StdEncoding.EncodeToString([]byte(fp))] = entry
+	}
+
+	return json.Marshal(disk)
+}
+
+// UnmarshalTokenRegistry creates a TokenRegistry from data produced by
+// TokenRegistry.Marshal.
+func UnmarshalTokenRegistry(data []byte) (*TokenRegistry, error) {
+	var disk tokenRegistryDisk
+	if err := json.Unmarshal(data, &disk); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal TokenRegistry")
+	}
+
+	tr := NewTokenRegistry(disk.TTL)
+	for encoded, entry := range disk.Entries {
+		fp, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode identity "+
+				"fingerprint %q in TokenRegistry", encoded)
+		}
+		tr.entries[string(fp)] = entry
+	}
+
+	return tr, nil
+}
@@ -0,0 +1,96 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+// Tests that TokenRegistry.Get and TokenRegistry.Matches return the registered
+// token until it expires and that TokenRegistry.Prune removes it.
+func TestTokenRegistry_Register_Get_Expiry(t *testing.T) {
+	now := time.Unix(1000, 0)
+	tr := NewTokenRegistry(time.Minute)
+	tr.now = func() time.Time { return now }
+
+	fp := []byte("identityFP")
+	tr.Register(fp, "token")
+
+	if token, ok := tr.Get(fp); !ok || token != "token" {
+		t.Errorf("Failed to get registered token: %q, %t", token, ok)
+	}
+	if !tr.Matches(fp, "token") {
+		t.Error("Registered token does not match.")
+	}
+	if tr.Matches(fp, "other") || tr.Matches([]byte("other"), "token") {
+		t.Error("Unregistered token or identity matches.")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := tr.Get(fp); ok {
+		t.Error("Got expired token.")
+	}
+	if tr.Matches(fp, "token") {
+		t.Error("Expired token matches.")
+	}
+
+	if pruned := tr.Prune(); pruned != 1 || tr.Len() != 0 {
+		t.Errorf("Unexpected prune result: %d pruned, %d remaining",
+			pruned, tr.Len())
+	}
+}
+
+// Tests that TokenRegistry.Remove deletes the token.
+func TestTokenRegistry_Remove(t *testing.T) {
+	tr := NewTokenRegistry(time.Hour)
+	fp := []byte("identityFP")
+	tr.Register(fp, "token")
+	tr.Remove(fp)
+
+	if _, ok := tr.Get(fp); ok {
+		t.Error("Got removed token.")
+	}
+}
+
+// Tests that a TokenRegistry marshalled via TokenRegistry.Marshal and
+// unmarshalled via UnmarshalTokenRegistry matches the original.
+func TestTokenRegistry_Marshal_UnmarshalTokenRegistry(t *testing.T) {
+	tr := NewTokenRegistry(time.Hour)
+	for _, d := range newTestDataList(10, 42) {
+		token := base64.StdEncoding.EncodeToString(d.MessageHash)
+		tr.Register(d.IdentityFP, token)
+	}
+
+	data, err := tr.Marshal()
+	if err != nil {
+		t.Fatalf("Failed to marshal: %+v", err)
+	}
+
+	loaded, err := UnmarshalTokenRegistry(data)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal: %+v", err)
+	}
+
+	if loaded.ttl != tr.ttl || loaded.Len() != tr.Len() {
+		t.Errorf("Unmarshalled registry does not match original.")
+	}
+	for fp, entry := range tr.entries {
+		if loaded.entries[fp].Token != entry.Token ||
+			!loaded.entries[fp].Expiry.Equal(entry.Expiry) {
+			t.Errorf("Entry mismatch.\nexpected: %+v\nreceived: %+v",
+				entry, loaded.entries[fp])
+		}
+	}
+
+	_, err = UnmarshalTokenRegistry([]byte(`{"entries":{"!":{}}}`))
+	if err == nil {
+		t.Error("No error for invalid identity fingerprint.")
+	}
+}
@@ -0,0 +1,242 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Version is the schema version of a notification payload.
+type Version uint8
+
+const (
+	// LegacyCSV is the CSV produced by BuildNotificationCSV. It carries no
+	// version byte so that it can be sent to clients that predate versioning.
+	LegacyCSV Version = iota
+
+	// VersionCSV is the CSV produced by BuildNotificationCSV prefixed with a
+	// version byte.
+	VersionCSV
+
+	// VersionBinary is the binary encoding produced by encodeBinaryEntry,
+	// prefixed with a version byte. Unlike the CSV, it includes the ephemeral
+	// and round IDs.
+	VersionBinary
+
+	// VersionNamedCSV is the CSV produced by BuildNamedNotificationCSV
+	// prefixed with a version byte. Its header row names each column so that
+	// columns can be added without breaking existing decoders.
+	VersionNamedCSV
+
+	// LatestVersion is the newest payload version.
+	LatestVersion = VersionNamedCSV
+)
+
+// versionMarker is set on the version byte of versioned payloads. Bytes with
+// the high bit set never start a CSV, which is printable base 64, or a
+// compressed payload, whose markers are below 0x20.
+const versionMarker byte = 0x80
+
+// String returns the string representation of the Version. This functions
+// adheres to the fmt.Stringer interface.
+func (v Version) String() string {
+	switch v {
+	case LegacyCSV:
+		return "LegacyCSV"
+	case VersionCSV:
+		return "CSV"
+	case VersionBinary:
+		return "Binary"
+	case VersionNamedCSV:
+		return "NamedCSV"
+	default:
+		return "INVALID VERSION " + strconv.Itoa(int(v))
+	}
+}
+
+// IsValid determines if the Version is one of the defined versions.
+func (v Version) IsValid() bool {
+	return v <= LatestVersion
+}
+
+// NegotiateVersion returns the newest Version that both this package and the
+// client support. If the client advertises no versions it is assumed to
+// predate versioning and LegacyCSV is returned.
+func NegotiateVersion(clientVersions ...Version) Version {
+	negotiated := LegacyCSV
+	for _, v := range clientVersions {
+		if v.IsValid() && v > negotiated {
+			negotiated = v
+		}
+	}
+	return negotiated
+}
+
+// BuildVersionedPayload encodes the [Data] list using the given Version into a
+// payload of the specified max size and returns it along with the excluded
+// [Data] entries. All versions except LegacyCSV start with a version byte,
+// which is not valid UTF-8, so use WrapPayload to place them in a provider
+// payload.
+func BuildVersionedPayload(ndList []*Data, maxSize int, v Version) (
+	[]byte, []*Data, error) {
+	switch v {
+	case LegacyCSV:
+		csv, rest := BuildNotificationCSV(ndList, maxSize)
+		return csv, rest, nil
+	case VersionCSV:
+		if maxSize < 1 {
+			return nil, ndList, nil
+		}
+		csv, rest := BuildNotificationCSV(ndList, maxSize-1)
+		return append([]byte{versionMarker | byte(v)}, csv...), rest, nil
+	case VersionBinary:
+		payload, rest, err := buildNotificationBinary(ndList, maxSize)
+		return payload, rest, err
+	case VersionNamedCSV:
+		if maxSize < 1 {
+			return nil, ndList, nil
+		}
+		csv, rest := BuildNamedNotificationCSV(ndList, maxSize-1)
+		if csv == nil {
+			return nil, ndList, nil
+		}
+		return append([]byte{versionMarker | byte(v)}, csv...), rest, nil
+	default:
+		return nil, nil, errors.Errorf("cannot encode payload with %s", v)
+	}
+}
+
+// DecodeAny decodes a notification payload of any Version, including
+// compressed payloads, and returns the [Data] list with the Version it was
+// encoded with.
+func DecodeAny(data []byte) ([]*Data, Version, error) {
+	decompressed, err := DecompressPayload(data)
+	if err != nil {
+		return nil, 0, errors.WithMessage(err,
+			"Failed to decompress notifications payload.")
+	}
+
+	if len(decompressed) == 0 || decompressed[0]&versionMarker == 0 {
+		list, err := DecodeNotificationsCSV(string(decompressed))
+		return list, LegacyCSV, err
+	}
+
+	v := Version(decompressed[0] &^ versionMarker)
+	body := decompressed[1:]
+	switch v {
+	case VersionCSV:
+		list, err := DecodeNotificationsCSV(string(body))
+		return list, v, err
+	case VersionBinary:
+		list, err := decodeNotificationBinary(body)
+		return list, v, err
+	case VersionNamedCSV:
+		list, err := DecodeNamedNotificationsCSV(string(body))
+		return list, v, err
+	default:
+		return nil, v, errors.Errorf("unsupported payload %s", v)
+	}
+}
+
+// buildNotificationBinary encodes as many of the [Data] entries as fit in
+// maxSize, including the version byte, using the VersionBinary format.
+func buildNotificationBinary(ndList []*Data, maxSize int) (
+	[]byte, []*Data, error) {
+	if maxSize < 1 {
+		return nil, ndList, nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(versionMarker | byte(VersionBinary))
+
+	for i, nd := range ndList {
+		entry, err := encodeBinaryEntry(nd)
+		if err != nil {
+			return nil, nil, errors.WithMessagef(err,
+				"failed to encode entry %d of %d", i, len(ndList))
+		} else if buf.Len()+len(entry) > maxSize {
+			return buf.Bytes(), ndList[i:], nil
+		}
+		buf.Write(entry)
+	}
+
+	return buf.Bytes(), nil, nil
+}
+
+// encodeBinaryEntry encodes the Data into the following structure, with all
+// integers big-endian:
+//
+//	+-------------+---------+----------+------------+-------------+
+//	| EphemeralID | RoundID | Priority | IdentityFP | MessageHash |
+//	|   8 bytes   | 8 bytes |  1 byte  |  variable  |  variable   |
+//	+-------------+---------+----------+------------+-------------+
+//
+// Each variable length field is prefixed with its 2-byte length.
+func encodeBinaryEntry(nd *Data) ([]byte, error) {
+	if len(nd.IdentityFP) > math.MaxUint16 {
+		return nil, errors.Errorf("IdentityFP length %d exceeds maximum %d",
+			len(nd.IdentityFP), math.MaxUint16)
+	} else if len(nd.MessageHash) > math.MaxUint16 {
+		return nil, errors.Errorf("MessageHash length %d exceeds maximum %d",
+			len(nd.MessageHash), math.MaxUint16)
+	}
+
+	b := make([]byte, 17, 21+len(nd.IdentityFP)+len(nd.MessageHash))
+	binary.BigEndian.PutUint64(b[:8], uint64(nd.EphemeralID))
+	binary.BigEndian.PutUint64(b[8:16], nd.RoundID)
+	b[16] = byte(nd.Priority)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(nd.IdentityFP)))
+	b = append(b, nd.IdentityFP...)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(nd.MessageHash)))
+	b = append(b, nd.MessageHash...)
+
+	return b, nil
+}
+
+// decodeNotificationBinary decodes the entries of a VersionBinary payload,
+// excluding its version byte.
+func decodeNotificationBinary(data []byte) ([]*Data, error) {
+	buf := bytes.NewBuffer(data)
+	var list []*Data
+	for buf.Len() > 0 {
+		fixed := buf.Next(17)
+		if len(fixed) != 17 {
+			return nil, errors.Errorf("Entry %d is truncated", len(list))
+		}
+		nd := &Data{
+			EphemeralID: int64(binary.BigEndian.Uint64(fixed[:8])),
+			RoundID:     binary.BigEndian.Uint64(fixed[8:16]),
+			Priority:    Priority(fixed[16]),
+		}
+		if !nd.Priority.IsValid() {
+			return nil, errors.Errorf("Entry %d has invalid priority %d",
+				len(list), nd.Priority)
+		}
+
+		for _, field := range []*[]byte{&nd.IdentityFP, &nd.MessageHash} {
+			lenBytes := buf.Next(2)
+			if len(lenBytes) != 2 {
+				return nil, errors.Errorf("Entry %d is truncated", len(list))
+			}
+			fieldLen := int(binary.BigEndian.Uint16(lenBytes))
+			if buf.Len() < fieldLen {
+				return nil, errors.Errorf("Entry %d is truncated", len(list))
+			}
+			*field = append([]byte{}, buf.Next(fieldLen)...)
+		}
+
+		list = append(list, nd)
+	}
+
+	return list, nil
+}
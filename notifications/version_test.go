@@ -0,0 +1,165 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package notifications
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// Tests that NegotiateVersion picks the newest supported version and falls
+// back to LegacyCSV for clients that advertise none.
+func TestNegotiateVersion(t *testing.T) {
+	tests := []struct {
+		client   []Version
+		expected Version
+	}{
+		{nil, LegacyCSV},
+		{[]Version{VersionCSV}, VersionCSV},
+		{[]Version{VersionCSV, VersionBinary}, VersionBinary},
+		{[]Version{VersionCSV, LatestVersion + 1}, VersionCSV},
+	}
+
+	for i, tt := range tests {
+		if v := NegotiateVersion(tt.client...); v != tt.expected {
+			t.Errorf("Incorrect version (%d).\nexpected: %s\nreceived: %s",
+				i, tt.expected, v)
+		}
+	}
+}
+
+// Tests that a payload built with BuildVersionedPayload for each version is
+// decoded by DecodeAny with the same version, both with and without
+// compression.
+func TestBuildVersionedPayload_DecodeAny(t *testing.T) {
+	ndList := GenerateTestData(10, rand.New(rand.NewSource(42)))
+	ndList[3].Priority = Silent
+
+	// The unnamed CSV formats do not carry the ephemeral IDs, round IDs, or
+	// priorities
+	csvList := make([]*Data, len(ndList))
+	for i, nd := range ndList {
+		csvList[i] = &Data{IdentityFP: nd.IdentityFP,
+			MessageHash: nd.MessageHash}
+	}
+
+	expected := map[Version][]*Data{
+		LegacyCSV:       csvList,
+		VersionCSV:      csvList,
+		VersionBinary:   ndList,
+		VersionNamedCSV: ndList,
+	}
+
+	for v := LegacyCSV; v <= LatestVersion; v++ {
+		payload, rest, err := BuildVersionedPayload(ndList, 4096, v)
+		if err != nil {
+			t.Fatalf("Failed to build %s payload: %+v", v, err)
+		} else if len(rest) != 0 {
+			t.Fatalf("Unexpected overflow for %s: %v", v, rest)
+		}
+
+		compressed, err := CompressPayload(payload, GzipCompressor{})
+		if err != nil {
+			t.Fatalf("Failed to compress %s payload: %+v", v, err)
+		}
+
+		for _, data := range [][]byte{payload, compressed} {
+			decoded, decodedV, err := DecodeAny(data)
+			if err != nil {
+				t.Errorf("Failed to decode %s payload: %+v", v, err)
+			} else if decodedV != v {
+				t.Errorf("Incorrect version.\nexpected: %s\nreceived: %s",
+					v, decodedV)
+			} else if !reflect.DeepEqual(expected[v], decoded) {
+				t.Errorf("Decoded %s list does not match original."+
+					"\nexpected: %v\nreceived: %v", v, expected[v], decoded)
+			}
+		}
+	}
+}
+
+// Tests that BuildVersionedPayload keeps a LegacyCSV payload identical to the
+// output of BuildNotificationCSV and that versioned payloads respect the max
+// size.
+func TestBuildVersionedPayload_MaxSize(t *testing.T) {
+	ndList := GenerateTestData(50, rand.New(rand.NewSource(42)))
+
+	csv, csvRest := BuildNotificationCSV(ndList, 512)
+	legacy, legacyRest, _ := BuildVersionedPayload(ndList, 512, LegacyCSV)
+	if !bytes.Equal(csv, legacy) || len(csvRest) != len(legacyRest) {
+		t.Errorf("LegacyCSV payload differs from BuildNotificationCSV.")
+	}
+
+	for v := VersionCSV; v <= LatestVersion; v++ {
+		payload, rest, err := BuildVersionedPayload(ndList, 512, v)
+		if err != nil {
+			t.Fatalf("Failed to build %s payload: %+v", v, err)
+		} else if len(payload) > 512 {
+			t.Errorf("%s payload of %d bytes exceeds max.", v, len(payload))
+		} else if len(rest) == 0 {
+			t.Errorf("Expected %s entries to overflow.", v)
+		}
+	}
+}
+
+// Error path: Tests that DecodeAny returns an error for an unknown version and
+// for a truncated binary payload.
+func TestDecodeAny_Error(t *testing.T) {
+	if _, _, err := DecodeAny([]byte{versionMarker | 0x7F}); err == nil {
+		t.Error("Expected error for unknown version.")
+	}
+
+	ndList := GenerateTestData(2, rand.New(rand.NewSource(42)))
+	payload, _, _ := BuildVersionedPayload(ndList, 4096, VersionBinary)
+	if _, _, err := DecodeAny(payload[:len(payload)-1]); err == nil {
+		t.Error("Expected error for truncated binary payload.")
+	}
+}
+
+// Tests that a payload of each version built with BuildVersionedPayload, placed
+// in a provider payload with WrapPayload, and extracted with
+// ParseProviderPayload is decoded by DecodeAny to the original entries.
+func TestBuildVersionedPayload_WrapPayload_DecodeAny(t *testing.T) {
+	ndList := GenerateTestData(10, rand.New(rand.NewSource(42)))
+	for v := LegacyCSV; v <= LatestVersion; v++ {
+		payload, _, err := BuildVersionedPayload(ndList, 2048, v)
+		if err != nil {
+			t.Fatalf("Failed to build %s payload: %+v", v, err)
+		}
+		expected, _, _ := DecodeAny(payload)
+
+		for _, provider := range []Provider{APNS, FCM} {
+			wrapped, err := WrapPayload(payload, provider)
+			if err != nil {
+				t.Fatalf("Failed to wrap %s payload for %s: %+v",
+					v, provider, err)
+			}
+
+			data, _, err := ParseProviderPayload(wrapped, provider)
+			if err != nil {
+				t.Fatalf("Failed to parse %s payload from %s: %+v",
+					v, provider, err)
+			}
+
+			decoded, decodedV, err := DecodeAny([]byte(data))
+			if err != nil {
+				t.Errorf("Failed to decode %s payload from %s: %+v",
+					v, provider, err)
+			} else if decodedV != v {
+				t.Errorf("Incorrect version from %s."+
+					"\nexpected: %s\nreceived: %s", provider, v, decodedV)
+			} else if !reflect.DeepEqual(expected, decoded) {
+				t.Errorf("Decoded %s list from %s does not match original."+
+					"\nexpected: %v\nreceived: %v",
+					v, provider, expected, decoded)
+			}
+		}
+	}
+}
@@ -0,0 +1,187 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+// Package rateLimiting implements the leaky bucket used by gateways to rate
+// limit client polls and message sends, along with the format used to persist
+// the state of the buckets, so that every service computes limits the same
+// way.
+package rateLimiting
+
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Bucket is a leaky bucket. Each request adds tokens to the bucket and is
+// rejected if the bucket does not have room for them. Tokens leak out of the
+// bucket at the rate given by its Params. A Bucket is safe for concurrent use.
+//
+// All methods take the current time so that buckets can be tested and
+// replayed deterministically. Times before the last update are treated as
+// the time of the last update.
+type Bucket struct {
+	params     Params
+	level      uint32    // Number of tokens in the bucket
+	lastUpdate time.Time // Time the level was last leaked
+	mux        sync.Mutex
+}
+
+// NewBucket returns an empty Bucket with the given Params. Returns an error if
+// the Params are invalid.
+func NewBucket(params Params, now time.Time) (*Bucket, error) {
+	if err := params.Validate(); err != nil {
+		return nil, errors.WithMessage(err, "invalid bucket params")
+	}
+
+	return &Bucket{params: params, lastUpdate: now}, nil
+}
+
+// Params returns the Params of the Bucket.
+func (b *Bucket) Params() Params {
+	return b.params
+}
+
+// Add adds the tokens to the bucket if there is room for all of them and
+// returns true. Otherwise, it returns false and the bucket is unchanged.
+func (b *Bucket) Add(tokens uint32, now time.Time) bool {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.update(now)
+	if tokens > b.params.Capacity-b.level {
+		return false
+	}
+
+	b.level += tokens
+	return true
+}
+
+// Level returns the number of tokens in the bucket.
+func (b *Bucket) Level(now time.Time) uint32 {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.update(now)
+	return b.level
+}
+
+// Remaining returns the number of tokens that can be added to the bucket.
+func (b *Bucket) Remaining(now time.Time) uint32 {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.update(now)
+	return b.params.Capacity - b.level
+}
+
+// IsEmpty determines if every token has leaked out of the bucket.
+func (b *Bucket) IsEmpty(now time.Time) bool {
+	return b.Level(now) == 0
+}
+
+// update leaks the tokens that have leaked since the last update. Time that
+// has not yet leaked a whole token is carried over to the next update.
+func (b *Bucket) update(now time.Time) {
+	if !now.After(b.lastUpdate) {
+		return
+	}
+
+	leaked, rem := b.params.leak(now.Sub(b.lastUpdate))
+	if leaked >= uint64(b.level) {
+		b.level = 0
+		b.lastUpdate = now
+	} else {
+		b.level -= uint32(leaked)
+		b.lastUpdate = now.Add(-rem)
+	}
+}
+
+// BucketState is the persisted state of a Bucket. The Params are not included
+// because they are configuration and are provided when the state is loaded.
+// This structure can be JSON marshalled and unmarshalled.
+//
+// JSON example:
+//
+//	{
+//	  "level": 42,
+//	  "lastUpdate": 1700000000000000000
+//	}
+type BucketState struct {
+	// Level is the number of tokens in the bucket
+	Level uint32 `json:"level"`
+
+	// LastUpdate is the time the level was last leaked in Unix nanoseconds
+	LastUpdate int64 `json:"lastUpdate"`
+}
+
+// stateVersion is the version of the binary encoding of BucketState.
+const stateVersion = 0
+
+// stateLen is the length of the binary encoding of BucketState.
+const stateLen = 1 + 4 + 8
+
+// State returns the current state of the Bucket.
+func (b *Bucket) State() BucketState {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	return BucketState{
+		Level:      b.level,
+		LastUpdate: b.lastUpdate.UnixNano(),
+	}
+}
+
+// NewBucketFromState returns a Bucket with the given Params restored to the
+// state. A level above the capacity, such as when the capacity has been
+// lowered since the state was saved, is reduced to the capacity. Returns an
+// error if the Params are invalid.
+func NewBucketFromState(params Params, state BucketState) (*Bucket, error) {
+	b, err := NewBucket(params, time.Unix(0, state.LastUpdate))
+	if err != nil {
+		return nil, err
+	}
+
+	b.level = state.Level
+	if b.level > params.Capacity {
+		b.level = params.Capacity
+	}
+
+	return b, nil
+}
+
+// Marshal returns the binary encoding of the BucketState.
+//
+//	+---------+--------+------------+
+//	| version | level  | lastUpdate |
+//	| 1 byte  | 4 byte |   8 byte   |
+//	+---------+--------+------------+
+func (bs BucketState) Marshal() []byte {
+	b := make([]byte, 1, stateLen)
+	b[0] = stateVersion
+	b = binary.BigEndian.AppendUint32(b, bs.Level)
+	return binary.BigEndian.AppendUint64(b, uint64(bs.LastUpdate))
+}
+
+// UnmarshalBucketState decodes the binary encoding of a BucketState produced by
+// BucketState.Marshal.
+func UnmarshalBucketState(data []byte) (BucketState, error) {
+	if len(data) != stateLen {
+		return BucketState{}, errors.Errorf("bucket state length %d does "+
+			"not match expected length %d", len(data), stateLen)
+	} else if data[0] != stateVersion {
+		return BucketState{}, errors.Errorf(
+			"unknown bucket state version %d", data[0])
+	}
+
+	return BucketState{
+		Level:      binary.BigEndian.Uint32(data[1:5]),
+		LastUpdate: int64(binary.BigEndian.Uint64(data[5:])),
+	}, nil
+}
@@ -0,0 +1,128 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package rateLimiting
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// BucketMap holds a Bucket for each key, such as a client ID or IP address,
+// all with the same Params. Buckets are created when a key is first looked up
+// and should be periodically removed with Prune once empty. A BucketMap is
+// safe for concurrent use.
+type BucketMap struct {
+	params  Params
+	buckets map[string]*Bucket
+	mux     sync.Mutex
+}
+
+// NewBucketMap returns an empty BucketMap whose buckets use the Params.
+// Returns an error if the Params are invalid.
+func NewBucketMap(params Params) (*BucketMap, error) {
+	if err := params.Validate(); err != nil {
+		return nil, errors.WithMessage(err, "invalid bucket params")
+	}
+
+	return &BucketMap{params: params, buckets: make(map[string]*Bucket)}, nil
+}
+
+// NewBucketMapFromStates returns a BucketMap whose buckets use the Params and
+// are restored from the states returned by BucketMap.States. Returns an error
+// if the Params are invalid.
+func NewBucketMapFromStates(
+	params Params, states map[string]BucketState) (*BucketMap, error) {
+	bm, err := NewBucketMap(params)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, state := range states {
+		bm.buckets[key], err = NewBucketFromState(params, state)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bm, nil
+}
+
+// Lookup returns the Bucket for the key, creating an empty one if the key has
+// no Bucket. The returned Bucket may be removed by a later call to Prune once
+// empty; use BucketMap.Add to add tokens so that none are lost to a concurrent
+// Prune.
+func (bm *BucketMap) Lookup(key string, now time.Time) *Bucket {
+	bm.mux.Lock()
+	defer bm.mux.Unlock()
+
+	return bm.lookup(key, now)
+}
+
+// Add adds the tokens to the Bucket for the key, like Bucket.Add. Returns true
+// if there was room for them. The tokens are added while holding the map lock
+// so that Prune cannot remove the Bucket between the lookup and the add.
+func (bm *BucketMap) Add(key string, tokens uint32, now time.Time) bool {
+	bm.mux.Lock()
+	defer bm.mux.Unlock()
+
+	return bm.lookup(key, now).Add(tokens, now)
+}
+
+// lookup returns the Bucket for the key, creating an empty one if the key has
+// no Bucket. The caller must hold the map lock.
+func (bm *BucketMap) lookup(key string, now time.Time) *Bucket {
+	b, exists := bm.buckets[key]
+	if !exists {
+		// The Params were validated by NewBucketMap, so this cannot fail
+		b = &Bucket{params: bm.params, lastUpdate: now}
+		bm.buckets[key] = b
+	}
+
+	return b
+}
+
+// Prune removes every Bucket that is empty, which behaves the same as a new
+// Bucket, and returns the number removed.
+func (bm *BucketMap) Prune(now time.Time) int {
+	bm.mux.Lock()
+	defer bm.mux.Unlock()
+
+	var removed int
+	for key, b := range bm.buckets {
+		if b.IsEmpty(now) {
+			delete(bm.buckets, key)
+			removed++
+		}
+	}
+
+	return removed
+}
+
+// Len returns the number of buckets in the map.
+func (bm *BucketMap) Len() int {
+	bm.mux.Lock()
+	defer bm.mux.Unlock()
+
+	return len(bm.buckets)
+}
+
+// States returns the state of every Bucket keyed on its key for persistence.
+// Use NewBucketMapFromStates to restore them.
+func (bm *BucketMap) States() map[string]BucketState {
+	bm.mux.Lock()
+	defer bm.mux.Unlock()
+
+	states := make(map[string]BucketState, len(bm.buckets))
+	for key, b := range bm.buckets {
+		states[key] = b.State()
+	}
+
+	return states
+}
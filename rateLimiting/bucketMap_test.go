@@ -0,0 +1,124 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package rateLimiting
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Tests that BucketMap.Lookup returns the same Bucket for the same key and
+// separate Buckets for different keys.
+func TestBucketMap_Lookup(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	bm, err := NewBucketMap(testParams)
+	if err != nil {
+		t.Fatalf("Failed to create BucketMap: %+v", err)
+	}
+
+	if bm.Lookup("a", now) != bm.Lookup("a", now) {
+		t.Error("Lookup returned different buckets for the same key.")
+	}
+	if bm.Lookup("a", now) == bm.Lookup("b", now) {
+		t.Error("Lookup returned the same bucket for different keys.")
+	}
+	if bm.Len() != 2 {
+		t.Errorf("Unexpected length.\nexpected: %d\nreceived: %d",
+			2, bm.Len())
+	}
+}
+
+// Tests that BucketMap.Add limits each key independently.
+func TestBucketMap_Add(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	bm, _ := NewBucketMap(testParams)
+
+	if !bm.Add("a", testParams.Capacity, now) {
+		t.Error("Failed to fill bucket a.")
+	}
+	if bm.Add("a", 1, now) {
+		t.Error("Full bucket a accepted more tokens.")
+	}
+	if !bm.Add("b", 1, now) {
+		t.Error("Bucket b rejected tokens because bucket a is full.")
+	}
+	if !bm.Add("a", 1, now.Add(testParams.LeakDuration)) {
+		t.Error("Bucket a rejected tokens after leaking.")
+	}
+}
+
+// Tests that BucketMap.Prune removes only empty buckets.
+func TestBucketMap_Prune(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	bm, _ := NewBucketMap(testParams)
+	bm.Add("a", 1, now)
+	bm.Add("b", 5, now)
+	bm.Lookup("c", now)
+
+	if removed := bm.Prune(now.Add(testParams.LeakDuration)); removed != 2 {
+		t.Errorf("Unexpected number of buckets pruned."+
+			"\nexpected: %d\nreceived: %d", 2, removed)
+	}
+	if _, exists := bm.States()["b"]; !exists || bm.Len() != 1 {
+		t.Errorf("Unexpected buckets after pruning: %v", bm.States())
+	}
+}
+
+// Tests that tokens added with BucketMap.Add are never lost to a concurrent
+// BucketMap.Prune removing the Bucket between the lookup and the add.
+func TestBucketMap_Add_ConcurrentPrune(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	for i := 0; i < 1000; i++ {
+		bm, _ := NewBucketMap(testParams)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bm.Prune(now)
+		}()
+		bm.Add("a", 1, now)
+		wg.Wait()
+
+		if level := bm.Lookup("a", now).Level(now); level != 1 {
+			t.Fatalf("Tokens lost to concurrent prune (%d)."+
+				"\nexpected: %d\nreceived: %d", i, 1, level)
+		}
+	}
+}
+
+// Tests that a BucketMap restored from BucketMap.States with
+// NewBucketMapFromStates has the same states.
+func TestNewBucketMapFromStates(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	bm, _ := NewBucketMap(testParams)
+	bm.Add("a", 1, now)
+	bm.Add("b", 5, now.Add(time.Second))
+
+	restored, err := NewBucketMapFromStates(testParams, bm.States())
+	if err != nil {
+		t.Fatalf("Failed to restore BucketMap: %+v", err)
+	}
+	if !reflect.DeepEqual(bm.States(), restored.States()) {
+		t.Errorf("Unexpected states.\nexpected: %v\nreceived: %v",
+			bm.States(), restored.States())
+	}
+}
+
+// Error path: Tests that NewBucketMap and NewBucketMapFromStates reject
+// invalid Params.
+func TestNewBucketMap_InvalidParams(t *testing.T) {
+	if _, err := NewBucketMap(Params{}); err == nil {
+		t.Error("NewBucketMap accepted invalid params.")
+	}
+	_, err := NewBucketMapFromStates(Params{}, map[string]BucketState{})
+	if err == nil {
+		t.Error("NewBucketMapFromStates accepted invalid params.")
+	}
+}
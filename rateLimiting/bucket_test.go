@@ -0,0 +1,201 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package rateLimiting
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testParams are Params that leak one token every 100 ms.
+var testParams = Params{
+	Capacity:     10,
+	LeakedTokens: 1,
+	LeakDuration: 100 * time.Millisecond,
+}
+
+// Tests that Bucket.Add accepts tokens until the bucket is full and then
+// rejects them without changing the level.
+func TestBucket_Add(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	b, err := NewBucket(testParams, now)
+	if err != nil {
+		t.Fatalf("Failed to create Bucket: %+v", err)
+	}
+
+	if !b.IsEmpty(now) || b.Remaining(now) != testParams.Capacity {
+		t.Errorf("New bucket is not empty: %+v", b.State())
+	}
+
+	for i := uint32(0); i < 3; i++ {
+		if !b.Add(3, now) {
+			t.Errorf("Add %d rejected at level %d.", i, b.Level(now))
+		}
+	}
+	if b.Add(2, now) {
+		t.Errorf("Add accepted above capacity.")
+	}
+	if b.Level(now) != 9 {
+		t.Errorf("Unexpected level.\nexpected: %d\nreceived: %d",
+			9, b.Level(now))
+	}
+	if !b.Add(1, now) || b.Remaining(now) != 0 {
+		t.Errorf("Failed to fill bucket to capacity: %+v", b.State())
+	}
+	if b.Add(1, now) {
+		t.Errorf("Add accepted when full.")
+	}
+}
+
+// Tests that tokens leak out of the Bucket at the rate of the Params and that
+// time that has not leaked a whole token is not lost between updates.
+func TestBucket_Leak(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	b, _ := NewBucket(testParams, now)
+	b.Add(10, now)
+
+	// Check every 30 ms so that each update falls between whole tokens
+	for elapsed := time.Duration(0); elapsed <= time.Second; elapsed += 30 *
+		time.Millisecond {
+		expected := 10 - uint32(elapsed/testParams.LeakDuration)
+		if level := b.Level(now.Add(elapsed)); level != expected {
+			t.Errorf("Unexpected level after %s.\nexpected: %d\nreceived: %d",
+				elapsed, expected, level)
+		}
+	}
+
+	if !b.IsEmpty(now.Add(time.Hour)) {
+		t.Errorf("Bucket not empty after an hour: %+v", b.State())
+	}
+
+	// Time going backwards does not refill or leak the bucket
+	b.Add(5, now.Add(time.Hour))
+	if level := b.Level(now); level != 5 {
+		t.Errorf("Unexpected level for earlier time.\nexpected: %d"+
+			"\nreceived: %d", 5, level)
+	}
+}
+
+// Tests that concurrent calls to Bucket.Add never accept more tokens than the
+// capacity.
+func TestBucket_Add_Concurrent(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	b, _ := NewBucket(testParams, now)
+
+	var wg sync.WaitGroup
+	var mux sync.Mutex
+	var accepted uint32
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if b.Add(1, now) {
+				mux.Lock()
+				accepted++
+				mux.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if accepted != testParams.Capacity {
+		t.Errorf("Unexpected accepted count.\nexpected: %d\nreceived: %d",
+			testParams.Capacity, accepted)
+	}
+}
+
+// Error path: Tests that NewBucket and NewBucketFromState reject invalid
+// Params.
+func TestNewBucket_InvalidParams(t *testing.T) {
+	if _, err := NewBucket(Params{}, time.Time{}); err == nil {
+		t.Error("NewBucket accepted invalid params.")
+	}
+	if _, err := NewBucketFromState(Params{}, BucketState{}); err == nil {
+		t.Error("NewBucketFromState accepted invalid params.")
+	}
+}
+
+// Tests that a Bucket restored from its state with NewBucketFromState behaves
+// the same as the original and that the level is capped to the capacity.
+func TestNewBucketFromState(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	b, _ := NewBucket(testParams, now)
+	b.Add(7, now)
+	b.Level(now.Add(250 * time.Millisecond))
+
+	restored, err := NewBucketFromState(testParams, b.State())
+	if err != nil {
+		t.Fatalf("Failed to restore Bucket: %+v", err)
+	}
+	later := now.Add(time.Second / 2)
+	if b.Level(later) != restored.Level(later) {
+		t.Errorf("Restored bucket level differs.\nexpected: %d\nreceived: %d",
+			b.Level(later), restored.Level(later))
+	}
+
+	smaller := testParams
+	smaller.Capacity = 3
+	restored, err = NewBucketFromState(smaller, BucketState{Level: 9})
+	if err != nil {
+		t.Fatalf("Failed to restore Bucket: %+v", err)
+	}
+	if restored.State().Level != smaller.Capacity {
+		t.Errorf("Level not capped to capacity.\nexpected: %d\nreceived: %d",
+			smaller.Capacity, restored.State().Level)
+	}
+}
+
+// Tests that a BucketState that is marshalled with BucketState.Marshal and
+// unmarshalled with UnmarshalBucketState, or JSON marshalled and
+// unmarshalled, matches the original.
+func TestBucketState_Marshal_Unmarshal(t *testing.T) {
+	bs := BucketState{Level: 42, LastUpdate: -1700000000123456789}
+
+	data := bs.Marshal()
+	if len(data) != stateLen {
+		t.Errorf("Unexpected length.\nexpected: %d\nreceived: %d",
+			stateLen, len(data))
+	}
+	newBs, err := UnmarshalBucketState(data)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal BucketState: %+v", err)
+	}
+	if !reflect.DeepEqual(bs, newBs) {
+		t.Errorf("Unexpected BucketState.\nexpected: %+v\nreceived: %+v",
+			bs, newBs)
+	}
+
+	data, err = json.Marshal(bs)
+	if err != nil {
+		t.Fatalf("Failed to JSON marshal BucketState: %+v", err)
+	}
+	newBs = BucketState{}
+	if err = json.Unmarshal(data, &newBs); err != nil {
+		t.Fatalf("Failed to JSON unmarshal BucketState: %+v", err)
+	}
+	if !reflect.DeepEqual(bs, newBs) {
+		t.Errorf("Unexpected JSON BucketState.\nexpected: %+v\nreceived: %+v",
+			bs, newBs)
+	}
+}
+
+// Error path: Tests that UnmarshalBucketState rejects data of the wrong length
+// or version.
+func TestUnmarshalBucketState_Error(t *testing.T) {
+	data := BucketState{Level: 1, LastUpdate: 2}.Marshal()
+	badVersion := append([]byte{stateVersion + 1}, data[1:]...)
+	for i, d := range [][]byte{nil, data[:stateLen-1], append(data, 0),
+		badVersion} {
+		if _, err := UnmarshalBucketState(d); err == nil {
+			t.Errorf("No error for invalid data %v (%d).", d, i)
+		}
+	}
+}
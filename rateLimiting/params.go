@@ -0,0 +1,70 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package rateLimiting
+
+import (
+	"math"
+	"math/bits"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Params describes the size of a Bucket and how quickly it leaks. This
+// structure can be JSON marshalled and unmarshalled; durations are in
+// nanoseconds.
+//
+// JSON example:
+//
+//	{
+//	  "capacity": 100,
+//	  "leakedTokens": 1,
+//	  "leakDuration": 100000000
+//	}
+type Params struct {
+	// Capacity is the maximum number of tokens the bucket holds
+	Capacity uint32 `json:"capacity"`
+
+	// LeakedTokens is the number of tokens that leak out of the bucket every
+	// LeakDuration
+	LeakedTokens uint32 `json:"leakedTokens"`
+
+	// LeakDuration is the time it takes for LeakedTokens to leak out
+	LeakDuration time.Duration `json:"leakDuration"`
+}
+
+// Validate returns an error if the Params cannot describe a Bucket.
+func (p Params) Validate() error {
+	if p.Capacity == 0 {
+		return errors.New("capacity must be positive")
+	} else if p.LeakedTokens == 0 {
+		return errors.New("leaked tokens must be positive")
+	} else if p.LeakDuration <= 0 {
+		return errors.Errorf(
+			"leak duration %s must be positive", p.LeakDuration)
+	}
+	return nil
+}
+
+// leak returns the number of whole tokens that leak out over the elapsed time
+// and the part of the elapsed time that has not yet leaked a whole token. The
+// calculation does not overflow for any elapsed time.
+func (p Params) leak(elapsed time.Duration) (uint64, time.Duration) {
+	if elapsed <= 0 {
+		return 0, 0
+	}
+
+	hi, lo := bits.Mul64(uint64(elapsed), uint64(p.LeakedTokens))
+	if hi >= uint64(p.LeakDuration) {
+		// The quotient does not fit in 64 bits, so every bucket is empty
+		return math.MaxUint64, 0
+	}
+
+	leaked, rem := bits.Div64(hi, lo, uint64(p.LeakDuration))
+	return leaked, time.Duration(rem / uint64(p.LeakedTokens))
+}
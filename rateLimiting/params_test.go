@@ -0,0 +1,86 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package rateLimiting
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Tests that Params.Validate accepts valid Params and rejects each invalid
+// field.
+func TestParams_Validate(t *testing.T) {
+	valid := Params{Capacity: 10, LeakedTokens: 1, LeakDuration: time.Second}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Valid params rejected: %+v", err)
+	}
+
+	for i, p := range []Params{
+		{0, 1, time.Second},
+		{10, 0, time.Second},
+		{10, 1, 0},
+		{10, 1, -time.Second},
+	} {
+		if err := p.Validate(); err == nil {
+			t.Errorf("No error for invalid params %+v (%d).", p, i)
+		}
+	}
+}
+
+// Tests that Params can be JSON marshalled and unmarshalled.
+func TestParams_JSON(t *testing.T) {
+	p := Params{Capacity: 100, LeakedTokens: 3, LeakDuration: time.Minute}
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Failed to JSON marshal Params: %+v", err)
+	}
+
+	var newP Params
+	if err = json.Unmarshal(data, &newP); err != nil {
+		t.Fatalf("Failed to JSON unmarshal Params: %+v", err)
+	}
+	if !reflect.DeepEqual(p, newP) {
+		t.Errorf("Unexpected Params.\nexpected: %+v\nreceived: %+v", p, newP)
+	}
+}
+
+// Tests that Params.leak returns the whole tokens leaked and the leftover time
+// and does not overflow for large elapsed times.
+func TestParams_leak(t *testing.T) {
+	p := Params{Capacity: 10, LeakedTokens: 3, LeakDuration: time.Second}
+	tests := []struct {
+		elapsed time.Duration
+		leaked  uint64
+		rem     time.Duration
+	}{
+		{-time.Second, 0, 0},
+		{0, 0, 0},
+		{time.Second, 3, 0},
+		{time.Second / 3, 0, time.Second / 3},
+		{time.Second/3 + 1, 1, 0},
+		{10*time.Second + 500*time.Millisecond, 31, 500 * time.Millisecond / 3},
+		{math.MaxInt64, 27670116110, 564327421 / 3},
+	}
+
+	for i, tt := range tests {
+		leaked, rem := p.leak(tt.elapsed)
+		if leaked != tt.leaked || rem != tt.rem {
+			t.Errorf("Unexpected leak for %s (%d).\nexpected: %d, %s"+
+				"\nreceived: %d, %s", tt.elapsed, i, tt.leaked, tt.rem,
+				leaked, rem)
+		}
+	}
+
+	huge := Params{Capacity: 10, LeakedTokens: math.MaxUint32, LeakDuration: 1}
+	if leaked, _ := huge.leak(math.MaxInt64); leaked != math.MaxUint64 {
+		t.Errorf("Expected overflowing leak to saturate: %d", leaked)
+	}
+}
@@ -0,0 +1,145 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+// Package region defines the geographic bins used for node scheduling and NDF
+// tooling. The bins and the country-to-bin mapping are taken from
+// gitlab.com/xx_network/primitives/region so that both share a single
+// definition; this package adds text marshaling and ordering on top.
+package region
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/xx_network/primitives/id"
+	xxRegion "gitlab.com/xx_network/primitives/region"
+)
+
+// GeoBin is the numerical representation of a geographical region. Its values
+// match xxRegion.GeoBin.
+type GeoBin uint8
+
+// List of geographical regions.
+const (
+	NorthAmerica           = GeoBin(xxRegion.NorthAmerica)
+	SouthAndCentralAmerica = GeoBin(xxRegion.SouthAndCentralAmerica)
+	WesternEurope          = GeoBin(xxRegion.WesternEurope)
+	CentralEurope          = GeoBin(xxRegion.CentralEurope)
+	EasternEurope          = GeoBin(xxRegion.EasternEurope)
+	MiddleEast             = GeoBin(xxRegion.MiddleEast)
+	NorthernAfrica         = GeoBin(xxRegion.NorthernAfrica)
+	SouthernAfrica         = GeoBin(xxRegion.SouthernAfrica)
+	Russia                 = GeoBin(xxRegion.Russia)
+	EasternAsia            = GeoBin(xxRegion.EasternAsia)
+	WesternAsia            = GeoBin(xxRegion.WesternAsia)
+	Oceania                = GeoBin(xxRegion.Oceania)
+	NumGeoBins             = Oceania + 1
+)
+
+// String returns the string representation of the GeoBin. This functions
+// adheres to the fmt.Stringer interface.
+func (b GeoBin) String() string {
+	return xxRegion.GeoBin(b).String()
+}
+
+// IsValid determines if the GeoBin is one of the defined regions.
+func (b GeoBin) IsValid() bool {
+	return b < NumGeoBins
+}
+
+// XX returns the GeoBin as an xxRegion.GeoBin.
+func (b GeoBin) XX() xxRegion.GeoBin {
+	return xxRegion.GeoBin(b)
+}
+
+// ParseGeoBin returns the GeoBin with the given name.
+func ParseGeoBin(name string) (GeoBin, error) {
+	bin, err := xxRegion.GetRegion(name)
+	if err != nil {
+		return 0, err
+	}
+	return GeoBin(bin), nil
+}
+
+// AllGeoBins returns every GeoBin in order.
+func AllGeoBins() []GeoBin {
+	bins := make([]GeoBin, NumGeoBins)
+	for i := range bins {
+		bins[i] = GeoBin(i)
+	}
+	return bins
+}
+
+// GetCountryBin returns the GeoBin for the given country alpha-2 code. The
+// code is not case-sensitive. Returns false if the country is unknown.
+func GetCountryBin(countryCode string) (GeoBin, bool) {
+	bin, exists := xxRegion.GetCountryBin(countryCode)
+	return GeoBin(bin), exists
+}
+
+// MarshalText marshals the GeoBin into its name. This function adheres to the
+// encoding.TextMarshaler interface.
+func (b GeoBin) MarshalText() ([]byte, error) {
+	if !b.IsValid() {
+		return nil, errors.Errorf("cannot marshal invalid GeoBin %d", b)
+	}
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText unmarshalls the name of a GeoBin. This function adheres to the
+// encoding.TextUnmarshaler interface.
+func (b *GeoBin) UnmarshalText(text []byte) error {
+	bin, err := ParseGeoBin(string(text))
+	if err != nil {
+		return err
+	}
+	*b = bin
+	return nil
+}
+
+// MarshalJSON marshals the GeoBin into a JSON string of its name. This function
+// adheres to the json.Marshaler interface.
+func (b GeoBin) MarshalJSON() ([]byte, error) {
+	text, err := b.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON unmarshalls a JSON string of the name of a GeoBin. This
+// function adheres to the json.Unmarshaler interface.
+func (b *GeoBin) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	return b.UnmarshalText([]byte(name))
+}
+
+// SequenceTeam returns a copy of the nodes ordered by the GeoBin of their
+// country so that nodes in the same region are adjacent in the team. Nodes
+// with an unknown country are placed last. Nodes in the same bin keep their
+// relative order, so the result is deterministic.
+func SequenceTeam(nodes []*id.ID, countries map[id.ID]string) []*id.ID {
+	binOf := func(nid *id.ID) GeoBin {
+		if bin, exists := GetCountryBin(countries[*nid]); exists {
+			return bin
+		}
+		return NumGeoBins
+	}
+
+	ordered := make([]*id.ID, len(nodes))
+	copy(ordered, nodes)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return binOf(ordered[i]) < binOf(ordered[j])
+	})
+
+	return ordered
+}
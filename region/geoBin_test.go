@@ -0,0 +1,108 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package region
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// Tests that every GeoBin can be text and JSON marshalled and unmarshalled.
+func TestGeoBin_MarshalText_UnmarshalText(t *testing.T) {
+	for _, bin := range AllGeoBins() {
+		text, err := bin.MarshalText()
+		if err != nil {
+			t.Errorf("Failed to marshal %s: %+v", bin, err)
+		}
+
+		var newBin GeoBin
+		if err = newBin.UnmarshalText(text); err != nil {
+			t.Errorf("Failed to unmarshal %q: %+v", text, err)
+		} else if newBin != bin {
+			t.Errorf("Unexpected GeoBin.\nexpected: %s\nreceived: %s",
+				bin, newBin)
+		}
+
+		data, err := json.Marshal(map[GeoBin]GeoBin{bin: bin})
+		if err != nil {
+			t.Errorf("Failed to JSON marshal %s: %+v", bin, err)
+		}
+
+		var m map[GeoBin]GeoBin
+		if err = json.Unmarshal(data, &m); err != nil {
+			t.Errorf("Failed to JSON unmarshal %s: %+v", data, err)
+		} else if m[bin] != bin {
+			t.Errorf("Unexpected JSON map for %s: %v", bin, m)
+		}
+	}
+}
+
+// Tests that marshalling an invalid GeoBin and unmarshalling an unknown name
+// return errors.
+func TestGeoBin_MarshalText_Error(t *testing.T) {
+	if _, err := NumGeoBins.MarshalText(); err == nil {
+		t.Error("Expected error for invalid GeoBin.")
+	}
+
+	var b GeoBin
+	if err := b.UnmarshalText([]byte("Atlantis")); err == nil {
+		t.Error("Expected error for unknown region name.")
+	}
+	if err := json.Unmarshal([]byte(`"Atlantis"`), &b); err == nil {
+		t.Error("Expected error for unknown region name in JSON.")
+	}
+}
+
+// Tests that GetCountryBin maps countries to the expected bins.
+func TestGetCountryBin(t *testing.T) {
+	tests := map[string]GeoBin{
+		"US": NorthAmerica,
+		"br": SouthAndCentralAmerica,
+		"DE": CentralEurope,
+		"JP": EasternAsia,
+		"AU": Oceania,
+	}
+
+	for code, expected := range tests {
+		bin, exists := GetCountryBin(code)
+		if !exists || bin != expected {
+			t.Errorf("Unexpected bin for %s.\nexpected: %s\nreceived: %s (%t)",
+				code, expected, bin, exists)
+		}
+	}
+
+	if _, exists := GetCountryBin("ZZ"); exists {
+		t.Error("Unknown country found.")
+	}
+}
+
+// Tests that SequenceTeam groups nodes by region in bin order and places nodes
+// with unknown countries last.
+func TestSequenceTeam(t *testing.T) {
+	nodes := make([]*id.ID, 5)
+	for i := range nodes {
+		nodes[i] = id.NewIdFromUInt(uint64(i), id.Node, t)
+	}
+	countries := map[id.ID]string{
+		*nodes[0]: "AU",
+		*nodes[1]: "US",
+		*nodes[2]: "ZZ",
+		*nodes[3]: "JP",
+		*nodes[4]: "CA",
+	}
+
+	expected := []*id.ID{nodes[1], nodes[4], nodes[3], nodes[0], nodes[2]}
+	received := SequenceTeam(nodes, countries)
+	if !reflect.DeepEqual(expected, received) {
+		t.Errorf("Unexpected team order.\nexpected: %v\nreceived: %v",
+			expected, received)
+	}
+}
@@ -0,0 +1,123 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package roundResults
+
+import (
+	"sort"
+	"sync"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// Aggregator folds RoundResult reports from many gateways into a consensus
+// verdict per round. A verdict is reached for a round once at least quorum
+// gateways agree on a Status. Each gateway has a single vote per round; a
+// later report from the same gateway replaces its earlier one.
+//
+// If more than one Status reaches quorum, the Status with the most votes wins.
+// Ties are broken in favour of the more severe outcome in the order Failure,
+// Timeout, Success.
+type Aggregator struct {
+	quorum  int
+	reports map[id.Round]map[id.ID]RoundResult
+	mux     sync.RWMutex
+}
+
+// NewAggregator creates a new Aggregator that requires the given number of
+// agreeing reports to reach a verdict. A quorum less than one is treated as
+// one.
+func NewAggregator(quorum int) *Aggregator {
+	if quorum < 1 {
+		quorum = 1
+	}
+
+	return &Aggregator{
+		quorum:  quorum,
+		reports: make(map[id.Round]map[id.ID]RoundResult),
+	}
+}
+
+// Add records the report from the gateway.
+func (a *Aggregator) Add(gateway *id.ID, rr RoundResult) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	roundReports, exists := a.reports[rr.RoundID]
+	if !exists {
+		roundReports = make(map[id.ID]RoundResult)
+		a.reports[rr.RoundID] = roundReports
+	}
+	roundReports[*gateway] = rr
+}
+
+// Verdict returns the consensus RoundResult for the round. Returns false if
+// there is not yet a quorum. The timestamp of the result is the latest
+// timestamp of the reports agreeing with the verdict.
+func (a *Aggregator) Verdict(rid id.Round) (RoundResult, bool) {
+	a.mux.RLock()
+	defer a.mux.RUnlock()
+
+	return a.verdict(rid)
+}
+
+// Results returns the consensus RoundResult for every round that has reached
+// quorum, sorted by round ID.
+func (a *Aggregator) Results() []RoundResult {
+	a.mux.RLock()
+	defer a.mux.RUnlock()
+
+	results := make([]RoundResult, 0, len(a.reports))
+	for rid := range a.reports {
+		if rr, ok := a.verdict(rid); ok {
+			results = append(results, rr)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].RoundID < results[j].RoundID
+	})
+
+	return results
+}
+
+// Remove deletes all reports for the round.
+func (a *Aggregator) Remove(rid id.Round) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	delete(a.reports, rid)
+}
+
+// verdict determines the consensus for the round. The lock must be held by the
+// caller.
+func (a *Aggregator) verdict(rid id.Round) (RoundResult, bool) {
+	var counts [NumStatuses]int
+	var latest [NumStatuses]RoundResult
+	for _, rr := range a.reports[rid] {
+		if !rr.Status.IsValid() {
+			continue
+		}
+		counts[rr.Status]++
+		if rr.Timestamp.After(latest[rr.Status].Timestamp) ||
+			counts[rr.Status] == 1 {
+			latest[rr.Status] = rr
+		}
+	}
+
+	best, found := Status(0), false
+	for _, s := range []Status{Failure, Timeout, Success} {
+		if counts[s] >= a.quorum && (!found || counts[s] > counts[best]) {
+			best, found = s, true
+		}
+	}
+
+	if !found {
+		return RoundResult{}, false
+	}
+
+	return latest[best], true
+}
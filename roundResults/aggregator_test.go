@@ -0,0 +1,72 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package roundResults
+
+import (
+	"testing"
+	"time"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// Tests that Aggregator.Verdict only reaches a verdict once quorum is met and
+// that repeated reports from the same gateway replace each other.
+func TestAggregator_Verdict(t *testing.T) {
+	a := NewAggregator(2)
+	gw1 := id.NewIdFromUInt(1, id.Gateway, t)
+	gw2 := id.NewIdFromUInt(2, id.Gateway, t)
+
+	a.Add(gw1, RoundResult{5, Success, time.Unix(10, 0)})
+	a.Add(gw1, RoundResult{5, Success, time.Unix(11, 0)})
+	if _, ok := a.Verdict(5); ok {
+		t.Error("Reached verdict without quorum.")
+	}
+
+	a.Add(gw2, RoundResult{5, Success, time.Unix(12, 0)})
+	rr, ok := a.Verdict(5)
+	if !ok {
+		t.Fatal("Failed to reach verdict with quorum.")
+	}
+	if rr.Status != Success || !rr.Timestamp.Equal(time.Unix(12, 0)) {
+		t.Errorf("Unexpected verdict: %+v", rr)
+	}
+}
+
+// Tests that Aggregator.Verdict breaks ties in favour of the more severe
+// outcome and otherwise picks the Status with the most votes.
+func TestAggregator_Verdict_Tie(t *testing.T) {
+	a := NewAggregator(1)
+	a.Add(id.NewIdFromUInt(1, id.Gateway, t), RoundResult{Status: Success})
+	a.Add(id.NewIdFromUInt(2, id.Gateway, t), RoundResult{Status: Failure})
+
+	if rr, _ := a.Verdict(0); rr.Status != Failure {
+		t.Errorf("Unexpected tie break.\nexpected: %s\nreceived: %s",
+			Failure, rr.Status)
+	}
+
+	a.Add(id.NewIdFromUInt(3, id.Gateway, t), RoundResult{Status: Success})
+	if rr, _ := a.Verdict(0); rr.Status != Success {
+		t.Errorf("Unexpected majority.\nexpected: %s\nreceived: %s",
+			Success, rr.Status)
+	}
+}
+
+// Tests that Aggregator.Results returns only rounds with a verdict in order.
+func TestAggregator_Results(t *testing.T) {
+	a := NewAggregator(1)
+	gw := id.NewIdFromUInt(1, id.Gateway, t)
+	for _, rid := range []id.Round{9, 3, 7} {
+		a.Add(gw, RoundResult{RoundID: rid, Status: Timeout})
+	}
+	a.Remove(7)
+
+	results := a.Results()
+	if len(results) != 2 || results[0].RoundID != 3 || results[1].RoundID != 9 {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+}
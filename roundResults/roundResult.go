@@ -0,0 +1,152 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+// Package roundResults defines the outcome of a round as reported by a gateway
+// and the logic used by both clients and gateways to fold many reports into a
+// single consensus verdict.
+package roundResults
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// Status describes the outcome of a round.
+type Status uint8
+
+// List of round outcomes.
+const (
+	Success = Status(iota)
+	Failure
+	Timeout
+	NumStatuses
+)
+
+// String returns the string representation of the Status. This functions
+// adheres to the fmt.Stringer interface.
+func (s Status) String() string {
+	switch s {
+	case Success:
+		return "Success"
+	case Failure:
+		return "Failure"
+	case Timeout:
+		return "Timeout"
+	default:
+		return "UNKNOWN STATUS: " + strconv.FormatUint(uint64(s), 10)
+	}
+}
+
+// IsValid determines if the Status is one of the defined statuses.
+func (s Status) IsValid() bool {
+	return s < NumStatuses
+}
+
+// RoundResultLen is the length of a marshalled RoundResult.
+const RoundResultLen = 8 + 1 + 8
+
+// RoundResult is the outcome of a single round at a given time.
+type RoundResult struct {
+	RoundID   id.Round
+	Status    Status
+	Timestamp time.Time
+}
+
+// Marshal serialises the RoundResult into a byte slice of length
+// RoundResultLen. The timestamp is stored with nanosecond precision.
+//
+// +----------+--------+-----------+
+// | round ID | status | timestamp |
+// | 8 bytes  | 1 byte |  8 bytes  |
+// +----------+--------+-----------+
+func (rr RoundResult) Marshal() []byte {
+	b := make([]byte, RoundResultLen)
+	binary.BigEndian.PutUint64(b[:8], uint64(rr.RoundID))
+	b[8] = byte(rr.Status)
+	binary.BigEndian.PutUint64(b[9:], uint64(rr.Timestamp.UnixNano()))
+	return b
+}
+
+// UnmarshalRoundResult deserializes the byte slice into a RoundResult. An error
+// is returned if the data is of the wrong length or has an invalid Status.
+func UnmarshalRoundResult(b []byte) (RoundResult, error) {
+	if len(b) != RoundResultLen {
+		return RoundResult{}, errors.Errorf("RoundResult data length %d "+
+			"must be %d", len(b), RoundResultLen)
+	}
+
+	rr := RoundResult{
+		RoundID:   id.Round(binary.BigEndian.Uint64(b[:8])),
+		Status:    Status(b[8]),
+		Timestamp: time.Unix(0, int64(binary.BigEndian.Uint64(b[9:]))),
+	}
+
+	if !rr.Status.IsValid() {
+		return RoundResult{}, errors.Errorf("invalid RoundResult status %d",
+			rr.Status)
+	}
+
+	return rr, nil
+}
+
+// MarshalRoundResults serialises a list of RoundResult, such as those returned
+// in a historical rounds response.
+//
+// +---------+-----------------+-----+-----------------+
+// |  count  |  RoundResult 1  | ... |  RoundResult N  |
+// | 4 bytes |    17 bytes     |     |    17 bytes     |
+// +---------+-----------------+-----+-----------------+
+func MarshalRoundResults(results []RoundResult) []byte {
+	var buf bytes.Buffer
+	buf.Grow(4 + len(results)*RoundResultLen)
+
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(len(results)))
+	buf.Write(b)
+
+	for _, rr := range results {
+		buf.Write(rr.Marshal())
+	}
+
+	return buf.Bytes()
+}
+
+// UnmarshalRoundResults deserializes a list of RoundResult produced by
+// MarshalRoundResults.
+func UnmarshalRoundResults(b []byte) ([]RoundResult, error) {
+	if len(b) < 4 {
+		return nil, errors.Errorf("RoundResult list data length %d smaller "+
+			"than minimum %d", len(b), 4)
+	}
+
+	count := int(binary.BigEndian.Uint32(b[:4]))
+	b = b[4:]
+	if len(b) != count*RoundResultLen {
+		return nil, errors.Errorf("RoundResult list data length %d does not "+
+			"match expected %d for %d results", len(b), count*RoundResultLen,
+			count)
+	}
+
+	results := make([]RoundResult, count)
+	for i := range results {
+		var err error
+		results[i], err = UnmarshalRoundResult(
+			b[i*RoundResultLen : (i+1)*RoundResultLen])
+		if err != nil {
+			return nil, errors.WithMessagef(err,
+				"failed to unmarshal RoundResult %d of %d", i, count)
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,93 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package roundResults
+
+import (
+	"testing"
+	"time"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// Consistency test of Status.String.
+func TestStatus_String(t *testing.T) {
+	expected := []string{"Success", "Failure", "Timeout", "UNKNOWN STATUS: 3"}
+
+	for s := Success; s <= NumStatuses; s++ {
+		if s.String() != expected[s] {
+			t.Errorf("Incorrect string for Status %d."+
+				"\nexpected: %s\nreceived: %s", s, expected[s], s.String())
+		}
+	}
+}
+
+// Tests that a RoundResult marshalled via RoundResult.Marshal and unmarshalled
+// via UnmarshalRoundResult matches the original.
+func TestRoundResult_Marshal_UnmarshalRoundResult(t *testing.T) {
+	expected := RoundResult{
+		RoundID:   id.Round(4242),
+		Status:    Timeout,
+		Timestamp: time.Unix(0, 1700000000123456789),
+	}
+
+	rr, err := UnmarshalRoundResult(expected.Marshal())
+	if err != nil {
+		t.Fatalf("Failed to unmarshal RoundResult: %+v", err)
+	}
+
+	if rr.RoundID != expected.RoundID || rr.Status != expected.Status ||
+		!rr.Timestamp.Equal(expected.Timestamp) {
+		t.Errorf("Unmarshalled RoundResult does not match original."+
+			"\nexpected: %+v\nreceived: %+v", expected, rr)
+	}
+}
+
+// Error path: Tests that UnmarshalRoundResult rejects data of the wrong length
+// and an invalid Status.
+func TestUnmarshalRoundResult_Error(t *testing.T) {
+	if _, err := UnmarshalRoundResult(make([]byte, 5)); err == nil {
+		t.Error("No error for data of the wrong length.")
+	}
+
+	b := RoundResult{RoundID: 5, Status: NumStatuses}.Marshal()
+	if _, err := UnmarshalRoundResult(b); err == nil {
+		t.Error("No error for invalid status.")
+	}
+}
+
+// Tests that a list marshalled via MarshalRoundResults and unmarshalled via
+// UnmarshalRoundResults matches the original.
+func TestMarshalRoundResults_UnmarshalRoundResults(t *testing.T) {
+	expected := []RoundResult{
+		{5, Success, time.Unix(0, 500)},
+		{6, Failure, time.Unix(0, 600)},
+		{7, Timeout, time.Unix(0, 700)},
+	}
+
+	results, err := UnmarshalRoundResults(MarshalRoundResults(expected))
+	if err != nil {
+		t.Fatalf("Failed to unmarshal list: %+v", err)
+	}
+
+	if len(results) != len(expected) {
+		t.Fatalf("Unexpected number of results.\nexpected: %d\nreceived: %d",
+			len(expected), len(results))
+	}
+	for i := range expected {
+		if results[i].RoundID != expected[i].RoundID ||
+			results[i].Status != expected[i].Status ||
+			!results[i].Timestamp.Equal(expected[i].Timestamp) {
+			t.Errorf("Result %d does not match.\nexpected: %+v\nreceived: %+v",
+				i, expected[i], results[i])
+		}
+	}
+
+	if _, err = UnmarshalRoundResults(MarshalRoundResults(expected)[:20]); err == nil {
+		t.Error("No error for truncated list.")
+	}
+}
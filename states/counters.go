@@ -0,0 +1,81 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package states
+
+import (
+	"expvar"
+	"sync/atomic"
+)
+
+// unknownLabel is the metric label of every invalid Round state; it matches
+// Round.MetricLabel.
+const unknownLabel = "unknown"
+
+// StateCounters counts rounds per Round state so that the gateway, server,
+// and other services report metrics with the same label set. Counters are
+// updated atomically and may be used from multiple goroutines. The zero value
+// is ready to use. A StateCounters must not be copied after first use.
+type StateCounters struct {
+	// One counter per valid state, followed by one for all invalid states
+	counts [NUM_STATES + 1]atomic.Uint64
+}
+
+// Increment adds one to the counter of the state. Invalid states are all
+// counted under the "unknown" label.
+func (sc *StateCounters) Increment(state Round) {
+	sc.Add(state, 1)
+}
+
+// Add adds n to the counter of the state. Invalid states are all counted under
+// the "unknown" label.
+func (sc *StateCounters) Add(state Round, n uint64) {
+	sc.counts[counterIndex(state)].Add(n)
+}
+
+// Get returns the current count of the state. All invalid states return the
+// count of the "unknown" label.
+func (sc *StateCounters) Get(state Round) uint64 {
+	return sc.counts[counterIndex(state)].Load()
+}
+
+// Snapshot returns the current count of every state keyed on its
+// Round.MetricLabel, including states with a count of zero and the "unknown"
+// label, so that the set of keys never changes. Each counter is read
+// atomically, but counters incremented while Snapshot runs may or may not be
+// included.
+func (sc *StateCounters) Snapshot() map[string]uint64 {
+	snapshot := make(map[string]uint64, len(sc.counts))
+	for _, state := range AllStates() {
+		snapshot[state.MetricLabel()] = sc.Get(state)
+	}
+	snapshot[unknownLabel] = sc.counts[NUM_STATES].Load()
+	return snapshot
+}
+
+// Var returns an expvar.Var that reports the Snapshot of the counters as a
+// JSON object each time it is read. Use it to add the counters to an existing
+// expvar.Map.
+func (sc *StateCounters) Var() expvar.Var {
+	return expvar.Func(func() any { return sc.Snapshot() })
+}
+
+// Publish publishes the counters under the given name in the expvar package so
+// that they are served at /debug/vars. Like expvar.Publish, it panics if the
+// name is already in use.
+func (sc *StateCounters) Publish(name string) {
+	expvar.Publish(name, sc.Var())
+}
+
+// counterIndex returns the index of the state's counter in
+// StateCounters.counts.
+func counterIndex(state Round) int {
+	if state >= NUM_STATES {
+		return int(NUM_STATES)
+	}
+	return int(state)
+}
@@ -0,0 +1,112 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package states
+
+import (
+	"encoding/json"
+	"expvar"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// Tests that StateCounters.Increment and StateCounters.Add update only the
+// counter of the given state and that invalid states share one counter.
+func TestStateCounters_Increment_Add(t *testing.T) {
+	var sc StateCounters
+	sc.Increment(PENDING)
+	sc.Increment(REALTIME)
+	sc.Add(REALTIME, 4)
+	sc.Increment(NUM_STATES)
+	sc.Increment(NUM_STATES + 10)
+
+	expected := map[Round]uint64{PENDING: 1, REALTIME: 5, NUM_STATES: 2}
+	for state := PENDING; state <= NUM_STATES; state++ {
+		if count := sc.Get(state); count != expected[state] {
+			t.Errorf("Unexpected count for %s.\nexpected: %d\nreceived: %d",
+				state, expected[state], count)
+		}
+	}
+	if count := sc.Get(100); count != 2 {
+		t.Errorf("Unexpected count for invalid state.\nexpected: %d"+
+			"\nreceived: %d", 2, count)
+	}
+}
+
+// Tests that StateCounters.Snapshot includes every state label, even with a
+// count of zero.
+func TestStateCounters_Snapshot(t *testing.T) {
+	var sc StateCounters
+	sc.Add(COMPLETED, 3)
+	sc.Increment(FAILED)
+	sc.Increment(NUM_STATES)
+
+	expected := map[string]uint64{
+		"pending":      0,
+		"precomputing": 0,
+		"standby":      0,
+		"queued":       0,
+		"realtime":     0,
+		"completed":    3,
+		"failed":       1,
+		"unknown":      1,
+	}
+	if snapshot := sc.Snapshot(); !reflect.DeepEqual(expected, snapshot) {
+		t.Errorf("Unexpected snapshot.\nexpected: %v\nreceived: %v",
+			expected, snapshot)
+	}
+}
+
+// Tests that concurrent calls to StateCounters.Increment are all counted.
+func TestStateCounters_Increment_Concurrent(t *testing.T) {
+	const goroutines, increments = 8, 1000
+	var sc StateCounters
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(state Round) {
+			defer wg.Done()
+			for j := 0; j < increments; j++ {
+				sc.Increment(state)
+				sc.Increment(QUEUED)
+			}
+		}(Round(i) % NUM_STATES)
+	}
+	wg.Wait()
+
+	var total uint64
+	for _, count := range sc.Snapshot() {
+		total += count
+	}
+	if total != 2*goroutines*increments {
+		t.Errorf("Unexpected total count.\nexpected: %d\nreceived: %d",
+			2*goroutines*increments, total)
+	}
+}
+
+// Tests that StateCounters.Publish publishes the current counts in expvar as a
+// JSON object.
+func TestStateCounters_Publish(t *testing.T) {
+	var sc StateCounters
+	sc.Publish("TestStateCounters_Publish")
+	sc.Add(REALTIME, 7)
+
+	v := expvar.Get("TestStateCounters_Publish")
+	if v == nil {
+		t.Fatal("Counters not published.")
+	}
+
+	var received map[string]uint64
+	if err := json.Unmarshal([]byte(v.String()), &received); err != nil {
+		t.Fatalf("Failed to parse published counters %q: %+v", v, err)
+	}
+	if !reflect.DeepEqual(sc.Snapshot(), received) {
+		t.Errorf("Unexpected published counters.\nexpected: %v\nreceived: %v",
+			sc.Snapshot(), received)
+	}
+}
@@ -0,0 +1,64 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package states
+
+import (
+	"time"
+)
+
+// Timeouts is the maximum time a round may spend in each non-terminal state. A
+// zero or negative duration means the state has no timeout. COMPLETED and
+// FAILED are terminal and never time out.
+type Timeouts struct {
+	Pending      time.Duration `json:"pending"`
+	Precomputing time.Duration `json:"precomputing"`
+	Standby      time.Duration `json:"standby"`
+	Queued       time.Duration `json:"queued"`
+	Realtime     time.Duration `json:"realtime"`
+}
+
+// For returns the timeout of the given state. Returns zero for states without
+// a timeout.
+func (cfg Timeouts) For(state Round) time.Duration {
+	var timeout time.Duration
+	switch state {
+	case PENDING:
+		timeout = cfg.Pending
+	case PRECOMPUTING:
+		timeout = cfg.Precomputing
+	case STANDBY:
+		timeout = cfg.Standby
+	case QUEUED:
+		timeout = cfg.Queued
+	case REALTIME:
+		timeout = cfg.Realtime
+	}
+
+	if timeout < 0 {
+		return 0
+	}
+	return timeout
+}
+
+// ComputeDeadline returns the time by which a round that entered the state at
+// start must leave it. Returns the zero time if the state has no timeout.
+func ComputeDeadline(state Round, start time.Time, cfg Timeouts) time.Time {
+	timeout := cfg.For(state)
+	if timeout == 0 {
+		return time.Time{}
+	}
+	return start.Add(timeout)
+}
+
+// IsOverdue determines if, at time now, a round that entered the state at start
+// has passed its deadline. A round in a state without a timeout is never
+// overdue.
+func IsOverdue(state Round, start, now time.Time, cfg Timeouts) bool {
+	deadline := ComputeDeadline(state, start, cfg)
+	return !deadline.IsZero() && now.After(deadline)
+}
@@ -0,0 +1,70 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package states
+
+import (
+	"testing"
+	"time"
+)
+
+// Tests that ComputeDeadline adds the timeout of each state and returns the
+// zero time for states without a timeout.
+func TestComputeDeadline(t *testing.T) {
+	cfg := Timeouts{
+		Pending:      -time.Second,
+		Precomputing: 2 * time.Minute,
+		Queued:       5 * time.Second,
+		Realtime:     15 * time.Second,
+	}
+	start := time.Unix(1700000000, 0)
+
+	expected := map[Round]time.Time{
+		PENDING:      {},
+		PRECOMPUTING: start.Add(2 * time.Minute),
+		STANDBY:      {},
+		QUEUED:       start.Add(5 * time.Second),
+		REALTIME:     start.Add(15 * time.Second),
+		COMPLETED:    {},
+		FAILED:       {},
+		NUM_STATES:   {},
+	}
+
+	for state, deadline := range expected {
+		received := ComputeDeadline(state, start, cfg)
+		if !received.Equal(deadline) {
+			t.Errorf("Unexpected deadline for %s.\nexpected: %s\nreceived: %s",
+				state, deadline, received)
+		}
+	}
+}
+
+// Tests that IsOverdue is only true after the deadline of a state with a
+// timeout.
+func TestIsOverdue(t *testing.T) {
+	cfg := Timeouts{Realtime: 15 * time.Second}
+	start := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		state    Round
+		now      time.Time
+		expected bool
+	}{
+		{REALTIME, start.Add(14 * time.Second), false},
+		{REALTIME, start.Add(15 * time.Second), false},
+		{REALTIME, start.Add(16 * time.Second), true},
+		{QUEUED, start.Add(time.Hour), false},
+		{COMPLETED, start.Add(time.Hour), false},
+	}
+
+	for i, tt := range tests {
+		if IsOverdue(tt.state, start, tt.now, cfg) != tt.expected {
+			t.Errorf("Unexpected IsOverdue for %s (%d).\nexpected: %t",
+				tt.state, i, tt.expected)
+		}
+	}
+}
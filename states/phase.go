@@ -0,0 +1,87 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package states
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Phase describes the sub-phase of precomputation that a round is in while it
+// is in the PRECOMPUTING state. Phases are run in order, one after the other.
+type Phase uint32
+
+// List of precomputation phases, in the order they are run.
+const (
+	GENERATION = Phase(iota)
+	SHARE
+	DECRYPT
+	PERMUTE
+	REVEAL
+	STRIP
+	NUM_PHASES
+)
+
+// String returns the string representation of the Phase. This functions
+// adheres to the fmt.Stringer interface.
+func (p Phase) String() string {
+	switch p {
+	case GENERATION:
+		return "GENERATION"
+	case SHARE:
+		return "SHARE"
+	case DECRYPT:
+		return "DECRYPT"
+	case PERMUTE:
+		return "PERMUTE"
+	case REVEAL:
+		return "REVEAL"
+	case STRIP:
+		return "STRIP"
+	default:
+		return "UNKNOWN PHASE: " + strconv.FormatUint(uint64(p), 10)
+	}
+}
+
+// IsValid determines if the Phase is one of the defined phases.
+func (p Phase) IsValid() bool {
+	return p < NUM_PHASES
+}
+
+// Before determines if the Phase runs before the other phase.
+func (p Phase) Before(other Phase) bool {
+	return p < other
+}
+
+// Next returns the phase that runs after this one. Returns false if this is
+// the last phase or is not a valid phase.
+func (p Phase) Next() (Phase, bool) {
+	if p+1 >= NUM_PHASES {
+		return 0, false
+	}
+	return p + 1, true
+}
+
+// ValidatePhaseTransition returns an error if a round cannot move directly
+// from one phase to the other. Phases may only advance to the phase
+// immediately after them.
+func ValidatePhaseTransition(from, to Phase) error {
+	if !from.IsValid() {
+		return errors.Errorf("invalid source phase %s", from)
+	} else if !to.IsValid() {
+		return errors.Errorf("invalid destination phase %s", to)
+	}
+
+	if next, exists := from.Next(); !exists || next != to {
+		return errors.Errorf("cannot transition from phase %s to phase %s",
+			from, to)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package states
+
+import "testing"
+
+// Consistency test of Phase.String.
+func TestPhase_String(t *testing.T) {
+	expected := []string{"GENERATION", "SHARE", "DECRYPT", "PERMUTE",
+		"REVEAL", "STRIP", "UNKNOWN PHASE: 6"}
+
+	for p := GENERATION; p <= NUM_PHASES; p++ {
+		if p.String() != expected[p] {
+			t.Errorf("Incorrect string for Phase %d."+
+				"\nexpected: %s\nreceived: %s", p, expected[p], p.String())
+		}
+	}
+}
+
+// Tests that Phase.Next walks every phase in order and stops at the last.
+func TestPhase_Next(t *testing.T) {
+	p, count := GENERATION, 1
+	for next, ok := p.Next(); ok; next, ok = p.Next() {
+		if !p.Before(next) {
+			t.Errorf("Phase %s is not before next phase %s.", p, next)
+		}
+		p = next
+		count++
+	}
+
+	if p != STRIP || count != int(NUM_PHASES) {
+		t.Errorf("Next stopped at %s after %d phases; expected %s after %d.",
+			p, count, STRIP, NUM_PHASES)
+	}
+}
+
+// Tests that ValidatePhaseTransition only allows advancing to the next phase.
+func TestValidatePhaseTransition(t *testing.T) {
+	for from := GENERATION; from <= NUM_PHASES; from++ {
+		for to := GENERATION; to <= NUM_PHASES; to++ {
+			err := ValidatePhaseTransition(from, to)
+			valid := to < NUM_PHASES && to == from+1
+			if valid && err != nil {
+				t.Errorf("Unexpected error for %s -> %s: %+v", from, to, err)
+			} else if !valid && err == nil {
+				t.Errorf("Expected error for %s -> %s.", from, to)
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package states
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// ReasonCode describes why a round changed state. Unlike the free-text reason
+// of a Transition, it can be aggregated for failure analytics.
+type ReasonCode uint8
+
+// List of reason codes.
+const (
+	// NO_REASON is the zero value and indicates that no reason code was
+	// recorded. It is not legal for any transition.
+	NO_REASON = ReasonCode(iota)
+
+	// SCHEDULED is the reason for a round advancing to its next state as
+	// planned.
+	SCHEDULED
+
+	// NODE_FAILURE is the reason for a round failing because a node in its
+	// team crashed or reported an error.
+	NODE_FAILURE
+
+	// TIMEOUT is the reason for a round failing because it did not reach its
+	// next state in time.
+	TIMEOUT
+
+	// OPERATOR is the reason for a round failing because it was killed by an
+	// operator.
+	OPERATOR
+
+	// CLIENT_ERROR is the reason for a round failing because of invalid
+	// messages sent by clients once the round was queued.
+	CLIENT_ERROR
+	NUM_REASONS
+)
+
+// String returns the string representation of the ReasonCode. This functions
+// adheres to the fmt.Stringer interface.
+func (rc ReasonCode) String() string {
+	switch rc {
+	case NO_REASON:
+		return "NO_REASON"
+	case SCHEDULED:
+		return "SCHEDULED"
+	case NODE_FAILURE:
+		return "NODE_FAILURE"
+	case TIMEOUT:
+		return "TIMEOUT"
+	case OPERATOR:
+		return "OPERATOR"
+	case CLIENT_ERROR:
+		return "CLIENT_ERROR"
+	default:
+		return "UNKNOWN REASON: " + strconv.FormatUint(uint64(rc), 10)
+	}
+}
+
+// IsValid determines if the ReasonCode is one of the defined reason codes.
+func (rc ReasonCode) IsValid() bool {
+	return rc < NUM_REASONS
+}
+
+// ValidateReason returns an error if the ReasonCode is not legal for a round
+// moving from one state to the other.
+//
+// SCHEDULED is only legal when a round advances to the state immediately after
+// it, up to COMPLETED. NODE_FAILURE, TIMEOUT, and OPERATOR are legal when any
+// state before COMPLETED moves to FAILED. CLIENT_ERROR is only legal when a
+// QUEUED or REALTIME round moves to FAILED, since clients can only send to a
+// round once it is queued.
+func ValidateReason(from, to Round, reason ReasonCode) error {
+	if from >= NUM_STATES {
+		return errors.Errorf("invalid source state %s", from)
+	} else if to >= NUM_STATES {
+		return errors.Errorf("invalid destination state %s", to)
+	} else if !reason.IsValid() {
+		return errors.Errorf("invalid reason %s", reason)
+	}
+
+	var legal bool
+	switch reason {
+	case SCHEDULED:
+		legal = to == from+1 && to <= COMPLETED
+	case NODE_FAILURE, TIMEOUT, OPERATOR:
+		legal = from < COMPLETED && to == FAILED
+	case CLIENT_ERROR:
+		legal = (from == QUEUED || from == REALTIME) && to == FAILED
+	}
+
+	if !legal {
+		return errors.Errorf("reason %s is not legal for transition from "+
+			"state %s to state %s", reason, from, to)
+	}
+
+	return nil
+}
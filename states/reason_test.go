@@ -0,0 +1,81 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package states
+
+import (
+	"testing"
+)
+
+// Consistency test of ReasonCode.String.
+func TestReasonCode_String(t *testing.T) {
+	expected := []string{"NO_REASON", "SCHEDULED", "NODE_FAILURE", "TIMEOUT",
+		"OPERATOR", "CLIENT_ERROR", "UNKNOWN REASON: 6"}
+
+	for rc := NO_REASON; rc <= NUM_REASONS; rc++ {
+		if rc.String() != expected[rc] {
+			t.Errorf("Unexpected string for reason %d.\nexpected: %s"+
+				"\nreceived: %s", rc, expected[rc], rc.String())
+		}
+	}
+}
+
+// Tests that ValidateReason only allows each ReasonCode for the expected
+// transitions, checking every combination of states and reasons.
+func TestValidateReason(t *testing.T) {
+	anyToFailed := failedFrom(PENDING, PRECOMPUTING, STANDBY, QUEUED, REALTIME)
+	legal := map[ReasonCode]map[[2]Round]bool{
+		SCHEDULED: {
+			{PENDING, PRECOMPUTING}: true, {PRECOMPUTING, STANDBY}: true,
+			{STANDBY, QUEUED}: true, {QUEUED, REALTIME}: true,
+			{REALTIME, COMPLETED}: true,
+		},
+		NODE_FAILURE: anyToFailed,
+		TIMEOUT:      anyToFailed,
+		OPERATOR:     anyToFailed,
+		CLIENT_ERROR: failedFrom(QUEUED, REALTIME),
+	}
+
+	for reason := NO_REASON; reason < NUM_REASONS; reason++ {
+		for from := PENDING; from < NUM_STATES; from++ {
+			for to := PENDING; to < NUM_STATES; to++ {
+				err := ValidateReason(from, to, reason)
+				if expected := legal[reason][[2]Round{from, to}]; expected &&
+					err != nil {
+					t.Errorf("Reason %s not legal from %s to %s: %+v",
+						reason, from, to, err)
+				} else if !expected && err == nil {
+					t.Errorf("Reason %s legal from %s to %s.",
+						reason, from, to)
+				}
+			}
+		}
+	}
+}
+
+// Error path: Tests that ValidateReason returns an error for undefined states
+// and reason codes.
+func TestValidateReason_Invalid(t *testing.T) {
+	if err := ValidateReason(NUM_STATES, FAILED, TIMEOUT); err == nil {
+		t.Error("No error for invalid source state.")
+	}
+	if err := ValidateReason(PENDING, NUM_STATES, TIMEOUT); err == nil {
+		t.Error("No error for invalid destination state.")
+	}
+	if err := ValidateReason(PENDING, FAILED, NUM_REASONS); err == nil {
+		t.Error("No error for invalid reason.")
+	}
+}
+
+// failedFrom returns the set of transitions from each of the states to FAILED.
+func failedFrom(states ...Round) map[[2]Round]bool {
+	transitions := make(map[[2]Round]bool, len(states))
+	for _, from := range states {
+		transitions[[2]Round{from, FAILED}] = true
+	}
+	return transitions
+}
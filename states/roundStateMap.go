@@ -0,0 +1,182 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package states
+
+import (
+	"encoding"
+	"encoding/binary"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// roundStateMapVersion is the version of the binary encoding of RoundStateMap.
+const roundStateMapVersion = 0
+
+var (
+	_ encoding.BinaryMarshaler   = (*RoundStateMap)(nil)
+	_ encoding.BinaryUnmarshaler = (*RoundStateMap)(nil)
+)
+
+// RoundStateMap stores the last known state of recent rounds so that a gateway
+// can answer round result queries from clients. Only the most recent maxRounds
+// rounds are kept; when a new round is set beyond that, the round that was
+// first set earliest is evicted. It is safe for concurrent use.
+type RoundStateMap struct {
+	maxRounds int
+	states    map[id.Round]Round
+	order     []id.Round // Rounds in the order they were first set
+	mux       sync.RWMutex
+}
+
+// NewRoundStateMap creates an empty RoundStateMap that holds the states of up
+// to maxRounds rounds. A maxRounds less than one is treated as one.
+func NewRoundStateMap(maxRounds int) *RoundStateMap {
+	if maxRounds < 1 {
+		maxRounds = 1
+	}
+
+	return &RoundStateMap{
+		maxRounds: maxRounds,
+		states:    make(map[id.Round]Round),
+	}
+}
+
+// Set sets the last known state of the round. Updating a round already in the
+// map does not change when it is evicted.
+func (rsm *RoundStateMap) Set(rid id.Round, state Round) {
+	rsm.mux.Lock()
+	defer rsm.mux.Unlock()
+	rsm.set(rid, state)
+}
+
+// set sets the state of the round, evicting the oldest round if the map is
+// full. The caller must hold the lock.
+func (rsm *RoundStateMap) set(rid id.Round, state Round) {
+	if _, exists := rsm.states[rid]; !exists {
+		if len(rsm.order) >= rsm.maxRounds {
+			delete(rsm.states, rsm.order[0])
+			rsm.order = rsm.order[1:]
+		}
+		rsm.order = append(rsm.order, rid)
+	}
+
+	rsm.states[rid] = state
+}
+
+// Get returns the last known state of the round. Returns false if the round is
+// not in the map.
+func (rsm *RoundStateMap) Get(rid id.Round) (Round, bool) {
+	rsm.mux.RLock()
+	defer rsm.mux.RUnlock()
+
+	state, exists := rsm.states[rid]
+	return state, exists
+}
+
+// Len returns the number of rounds in the map.
+func (rsm *RoundStateMap) Len() int {
+	rsm.mux.RLock()
+	defer rsm.mux.RUnlock()
+	return len(rsm.order)
+}
+
+// MarshalBinary encodes the rounds, in the order they were first set, with
+// their states. This function adheres to the encoding.BinaryMarshaler
+// interface.
+//
+// The encoding starts with a version byte and the number of rounds as a
+// uvarint. Each round follows as the difference from the previous round ID (or
+// from zero for the first) as a varint and its state as a single byte, so that
+// consecutive rounds take two bytes each.
+func (rsm *RoundStateMap) MarshalBinary() ([]byte, error) {
+	rsm.mux.RLock()
+	defer rsm.mux.RUnlock()
+
+	data := make([]byte, 1, 1+binary.MaxVarintLen64+3*len(rsm.order))
+	data[0] = roundStateMapVersion
+	data = binary.AppendUvarint(data, uint64(len(rsm.order)))
+
+	var last id.Round
+	for _, rid := range rsm.order {
+		state := rsm.states[rid]
+		if state >= NUM_STATES {
+			return nil, errors.Errorf(
+				"round %d has invalid state %d", rid, state)
+		}
+		data = binary.AppendVarint(data, int64(rid-last))
+		data = append(data, byte(state))
+		last = rid
+	}
+
+	return data, nil
+}
+
+// UnmarshalBinary imports the rounds encoded by RoundStateMap.MarshalBinary.
+// Rounds beyond the map's maximum are evicted as if they were set in order. If
+// the RoundStateMap is the zero value, its maximum is the number of rounds in
+// the data. This function adheres to the encoding.BinaryUnmarshaler interface.
+func (rsm *RoundStateMap) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("round state map data is empty")
+	} else if data[0] != roundStateMapVersion {
+		return errors.Errorf("unknown round state map version %d", data[0])
+	}
+	data = data[1:]
+
+	n, size := binary.Uvarint(data)
+	if size <= 0 || n > uint64(len(data)/2) {
+		return errors.New("invalid number of rounds in round state map")
+	}
+	data = data[size:]
+
+	rounds := make([]id.Round, n)
+	states := make([]Round, n)
+	seen := make(map[id.Round]struct{}, n)
+	var last id.Round
+	for i := range rounds {
+		delta, size := binary.Varint(data)
+		if size <= 0 || len(data) <= size {
+			return errors.Errorf("round %d of %d is truncated", i, n)
+		}
+		rounds[i] = last + id.Round(delta)
+		states[i] = Round(data[size])
+		data = data[size+1:]
+
+		if states[i] >= NUM_STATES {
+			return errors.Errorf("round %d has invalid state %d",
+				rounds[i], states[i])
+		} else if _, exists := seen[rounds[i]]; exists {
+			return errors.Errorf("round %d appears more than once", rounds[i])
+		}
+		seen[rounds[i]] = struct{}{}
+		last = rounds[i]
+	}
+
+	if len(data) != 0 {
+		return errors.Errorf(
+			"round state map has %d trailing bytes", len(data))
+	}
+
+	rsm.mux.Lock()
+	defer rsm.mux.Unlock()
+	if rsm.states == nil {
+		rsm.maxRounds = len(rounds)
+		if rsm.maxRounds < 1 {
+			rsm.maxRounds = 1
+		}
+		rsm.states = make(map[id.Round]Round, len(rounds))
+	}
+	for i, rid := range rounds {
+		rsm.set(rid, states[i])
+	}
+
+	return nil
+}
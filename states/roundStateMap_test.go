@@ -0,0 +1,132 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package states
+
+import (
+	"bytes"
+	"testing"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// Tests that RoundStateMap.Get returns the last state set for each round and
+// that the round first set earliest is evicted when the map is full, even if
+// it was updated since.
+func TestRoundStateMap_Set_Get(t *testing.T) {
+	rsm := NewRoundStateMap(2)
+	rsm.Set(1, PENDING)
+	rsm.Set(2, QUEUED)
+	rsm.Set(1, COMPLETED)
+
+	if state, exists := rsm.Get(1); !exists || state != COMPLETED {
+		t.Errorf("Unexpected state for round 1.\nexpected: %s\nreceived: %s",
+			COMPLETED, state)
+	}
+
+	rsm.Set(3, FAILED)
+	if _, exists := rsm.Get(1); exists {
+		t.Error("Oldest round was not evicted.")
+	}
+	for rid, expected := range map[id.Round]Round{2: QUEUED, 3: FAILED} {
+		if state, exists := rsm.Get(rid); !exists || state != expected {
+			t.Errorf("Unexpected state for round %d."+
+				"\nexpected: %s\nreceived: %s", rid, expected, state)
+		}
+	}
+	if rsm.Len() != 2 {
+		t.Errorf("Unexpected length.\nexpected: %d\nreceived: %d",
+			2, rsm.Len())
+	}
+
+	if NewRoundStateMap(0).maxRounds != 1 {
+		t.Error("Max rounds less than one not treated as one.")
+	}
+}
+
+// Tests that a RoundStateMap marshalled and unmarshalled into a zero value and
+// into a smaller RoundStateMap has the expected rounds.
+func TestRoundStateMap_MarshalBinary_UnmarshalBinary(t *testing.T) {
+	rsm := NewRoundStateMap(10)
+	rounds := []id.Round{1000, 1001, 1003, 999, 1 << 40}
+	for i, rid := range rounds {
+		rsm.Set(rid, Round(i)%NUM_STATES)
+	}
+
+	data, err := rsm.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Failed to marshal: %+v", err)
+	}
+
+	var newRsm RoundStateMap
+	if err = newRsm.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Failed to unmarshal: %+v", err)
+	}
+	newData, _ := newRsm.MarshalBinary()
+	if !bytes.Equal(data, newData) {
+		t.Errorf("Unmarshalled map does not match.\nexpected: %v"+
+			"\nreceived: %v", data, newData)
+	}
+
+	small := NewRoundStateMap(2)
+	if err = small.UnmarshalBinary(data); err != nil {
+		t.Fatalf("Failed to unmarshal into smaller map: %+v", err)
+	}
+	if small.Len() != 2 {
+		t.Errorf("Unexpected length.\nexpected: %d\nreceived: %d",
+			2, small.Len())
+	}
+	if state, _ := small.Get(1 << 40); state != Round(4) {
+		t.Errorf("Unexpected state.\nexpected: %s\nreceived: %s",
+			Round(4), state)
+	}
+}
+
+// Consistency test of RoundStateMap.MarshalBinary.
+func TestRoundStateMap_MarshalBinary_Consistency(t *testing.T) {
+	rsm := NewRoundStateMap(10)
+	rsm.Set(100, COMPLETED)
+	rsm.Set(101, FAILED)
+	rsm.Set(99, REALTIME)
+
+	// Version, count, then deltas 100, 1, -2 as zig-zag varints with states
+	expected := []byte{0, 3, 200, 1, 5, 2, 6, 3, 4}
+	data, err := rsm.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Failed to marshal: %+v", err)
+	}
+	if !bytes.Equal(expected, data) {
+		t.Errorf("Unexpected encoding.\nexpected: %v\nreceived: %v",
+			expected, data)
+	}
+}
+
+// Error path: Tests that RoundStateMap.UnmarshalBinary rejects invalid data and
+// RoundStateMap.MarshalBinary rejects invalid states.
+func TestRoundStateMap_Binary_Error(t *testing.T) {
+	for i, data := range [][]byte{
+		nil,
+		{1, 0},
+		{0},
+		{0, 2, 2, 0},
+		{0, 1, 2},
+		{0, 1, 2, byte(NUM_STATES)},
+		{0, 2, 2, 0, 0, 0},
+		{0, 1, 2, 0, 0},
+	} {
+		var rsm RoundStateMap
+		if err := rsm.UnmarshalBinary(data); err == nil {
+			t.Errorf("No error for invalid data %v (%d).", data, i)
+		}
+	}
+
+	rsm := NewRoundStateMap(1)
+	rsm.Set(1, NUM_STATES)
+	if _, err := rsm.MarshalBinary(); err == nil {
+		t.Error("No error marshalling invalid state.")
+	}
+}
@@ -0,0 +1,135 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package states
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/pkg/errors"
+	jww "github.com/spf13/jwalterweatherman"
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// SimulatorParams configures how a Simulator drives rounds.
+type SimulatorParams struct {
+	// Durations is the time a round spends in each state before it advances
+	// or fails.
+	Durations Timeouts
+
+	// Jitter is the maximum random time added to each duration. A jitter of
+	// zero makes the timing deterministic.
+	Jitter time.Duration
+
+	// FailureProbability is the probability, between 0 and 1, that a round
+	// fails in each state instead of advancing. States missing from the map
+	// never fail.
+	FailureProbability map[Round]float64
+}
+
+// Simulator drives rounds through randomized legal state transitions for use
+// in tests. Each round starts in PENDING and advances one state at a time with
+// the reason SCHEDULED until it completes or, according to the failure
+// probability of its current state, fails with a randomly chosen reason that is
+// legal for that state. Every transition is recorded in the TransitionLog.
+//
+// The simulator has its own clock, which starts at the given time and advances
+// by the duration spent in each state, so rounds are simulated one after the
+// other. With the same parameters and seed, a Simulator produces the same
+// transitions. A Simulator is not safe for concurrent use.
+type Simulator struct {
+	params SimulatorParams
+	rng    *rand.Rand
+	now    time.Time
+	log    *TransitionLog
+}
+
+// NewSimulator creates a Simulator that starts its clock at the given time and
+// records transitions in the log. Returns an error if the log is nil or if a
+// failure probability is not between 0 and 1 or is given for a terminal state.
+func NewSimulator(params SimulatorParams, seed int64, start time.Time,
+	log *TransitionLog) (*Simulator, error) {
+	if log == nil {
+		return nil, errors.New("simulator requires a TransitionLog")
+	}
+
+	for state, p := range params.FailureProbability {
+		if state >= COMPLETED {
+			return nil, errors.Errorf("cannot set failure probability of "+
+				"terminal or invalid state %s", state)
+		} else if p < 0 || p > 1 {
+			return nil, errors.Errorf("failure probability %f of state %s "+
+				"must be between 0 and 1", p, state)
+		}
+	}
+
+	return &Simulator{
+		params: params,
+		rng:    rand.New(rand.NewSource(seed)),
+		now:    start,
+		log:    log,
+	}, nil
+}
+
+// Run simulates the round from PENDING until it reaches COMPLETED or FAILED
+// and returns its transitions in order.
+func (s *Simulator) Run(rid id.Round) []Transition {
+	var transitions []Transition
+	for state := PENDING; state < COMPLETED; {
+		s.now = s.now.Add(s.duration(state))
+
+		to, code := state+1, SCHEDULED
+		if s.rng.Float64() < s.params.FailureProbability[state] {
+			reasons := failureReasons(state)
+			to, code = FAILED, reasons[s.rng.Intn(len(reasons))]
+		}
+
+		err := s.log.RecordWithCode(rid, state, to, s.now, code, "simulated")
+		if err != nil {
+			jww.FATAL.Panicf("Simulator produced an illegal transition for "+
+				"round %d: %+v", rid, err)
+		}
+
+		transitions = append(transitions,
+			Transition{state, to, s.now, "simulated", code})
+		state = to
+	}
+
+	return transitions
+}
+
+// Now returns the current time of the simulator's clock.
+func (s *Simulator) Now() time.Time {
+	return s.now
+}
+
+// Log returns the TransitionLog the simulator records to.
+func (s *Simulator) Log() *TransitionLog {
+	return s.log
+}
+
+// duration returns the time to spend in the state, including jitter.
+func (s *Simulator) duration(state Round) time.Duration {
+	d := s.params.Durations.For(state)
+	if s.params.Jitter > 0 {
+		d += time.Duration(s.rng.Int63n(int64(s.params.Jitter)))
+	}
+	return d
+}
+
+// failureReasons returns the reason codes that are legal for a round failing
+// from the state.
+func failureReasons(state Round) []ReasonCode {
+	var reasons []ReasonCode
+	for rc := NO_REASON; rc < NUM_REASONS; rc++ {
+		if ValidateReason(state, FAILED, rc) == nil {
+			reasons = append(reasons, rc)
+		}
+	}
+	return reasons
+}
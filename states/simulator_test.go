@@ -0,0 +1,139 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package states
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// Tests that a Simulator with no failures moves every round through every
+// state to COMPLETED with the configured durations and records the transitions
+// in the log.
+func TestSimulator_Run(t *testing.T) {
+	start := time.Unix(1000, 0)
+	params := SimulatorParams{Durations: Timeouts{
+		Pending:      1 * time.Second,
+		Precomputing: 2 * time.Second,
+		Standby:      3 * time.Second,
+		Queued:       4 * time.Second,
+		Realtime:     5 * time.Second,
+	}}
+	s, err := NewSimulator(params, 42, start, NewTransitionLog(10))
+	if err != nil {
+		t.Fatalf("Failed to create Simulator: %+v", err)
+	}
+
+	transitions := s.Run(7)
+	expected := []Transition{
+		{PENDING, PRECOMPUTING, start.Add(1 * time.Second), "simulated",
+			SCHEDULED},
+		{PRECOMPUTING, STANDBY, start.Add(3 * time.Second), "simulated",
+			SCHEDULED},
+		{STANDBY, QUEUED, start.Add(6 * time.Second), "simulated", SCHEDULED},
+		{QUEUED, REALTIME, start.Add(10 * time.Second), "simulated",
+			SCHEDULED},
+		{REALTIME, COMPLETED, start.Add(15 * time.Second), "simulated",
+			SCHEDULED},
+	}
+	if !reflect.DeepEqual(expected, transitions) {
+		t.Errorf("Unexpected transitions.\nexpected: %+v\nreceived: %+v",
+			expected, transitions)
+	}
+
+	if !reflect.DeepEqual(transitions, s.Log().Get(7)) {
+		t.Errorf("Transitions not recorded in log.\nexpected: %+v"+
+			"\nreceived: %+v", transitions, s.Log().Get(7))
+	}
+
+	if !s.Now().Equal(start.Add(15 * time.Second)) {
+		t.Errorf("Unexpected clock.\nexpected: %s\nreceived: %s",
+			start.Add(15*time.Second), s.Now())
+	}
+}
+
+// Tests that every transition produced by a Simulator with random failures and
+// jitter is legal, that failures only occur from states with a failure
+// probability, and that the same seed produces the same transitions.
+func TestSimulator_Run_Failures(t *testing.T) {
+	params := SimulatorParams{
+		Durations: Timeouts{Pending: time.Second, Realtime: time.Second},
+		Jitter:    time.Second,
+		FailureProbability: map[Round]float64{
+			PRECOMPUTING: 0.3,
+			QUEUED:       0.2,
+			REALTIME:     0.2,
+		},
+	}
+	start := time.Unix(1000, 0)
+	s1, _ := NewSimulator(params, 42, start, NewTransitionLog(100))
+	s2, _ := NewSimulator(params, 42, start, NewTransitionLog(100))
+
+	failed := make(map[Round]int)
+	var prev time.Time
+	for rid := id.Round(0); rid < 100; rid++ {
+		transitions := s1.Run(rid)
+		if !reflect.DeepEqual(transitions, s2.Run(rid)) {
+			t.Errorf("Same seed produced different transitions for round %d.",
+				rid)
+		}
+
+		last := transitions[len(transitions)-1]
+		if last.To != COMPLETED && last.To != FAILED {
+			t.Errorf("Round %d ended in non-terminal state %s.", rid, last.To)
+		} else if last.To == FAILED {
+			failed[last.From]++
+		}
+
+		for _, tr := range transitions {
+			if err := ValidateReason(tr.From, tr.To, tr.Code); err != nil {
+				t.Errorf("Illegal transition for round %d: %+v", rid, err)
+			}
+			if tr.Timestamp.Before(prev) {
+				t.Errorf("Transition for round %d went back in time.", rid)
+			}
+			prev = tr.Timestamp
+		}
+	}
+
+	for state, n := range failed {
+		if params.FailureProbability[state] == 0 {
+			t.Errorf("%d rounds failed from state %s without a failure "+
+				"probability.", n, state)
+		}
+	}
+	if failed[PRECOMPUTING] == 0 || failed[QUEUED] == 0 {
+		t.Errorf("Expected failures from PRECOMPUTING and QUEUED: %v", failed)
+	}
+}
+
+// Error path: Tests that NewSimulator rejects a nil log and invalid failure
+// probabilities.
+func TestNewSimulator_Error(t *testing.T) {
+	tests := []struct {
+		params SimulatorParams
+		log    *TransitionLog
+	}{
+		{SimulatorParams{}, nil},
+		{SimulatorParams{FailureProbability: map[Round]float64{QUEUED: 1.5}},
+			NewTransitionLog(1)},
+		{SimulatorParams{FailureProbability: map[Round]float64{QUEUED: -0.1}},
+			NewTransitionLog(1)},
+		{SimulatorParams{FailureProbability: map[Round]float64{FAILED: 0.5}},
+			NewTransitionLog(1)},
+	}
+
+	for i, tt := range tests {
+		if _, err := NewSimulator(tt.params, 0, time.Time{}, tt.log); err == nil {
+			t.Errorf("No error for invalid parameters (%d).", i)
+		}
+	}
+}
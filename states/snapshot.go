@@ -0,0 +1,80 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package states
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// RoundSnapshot is the state of a round at a point in time as published by
+// permissioning. The bytes covered by the signature are defined by
+// RoundSnapshot.SignableBytes so that every repo signs and verifies the same
+// layout. This structure can be JSON marshalled and unmarshalled.
+//
+// JSON example:
+//
+//	{
+//	  "roundID": 42,
+//	  "state": 5,
+//	  "topologyHash": "8P3QV2bY1Pj9E3hkzB2a5w==",
+//	  "timestamps": [1700000000000000000, 1700000001000000000],
+//	  "signature": "c2lnbmF0dXJl"
+//	}
+type RoundSnapshot struct {
+	RoundID id.Round `json:"roundID"`
+	State   Round    `json:"state"`
+
+	// Hash of the ordered list of nodes in the round's team
+	TopologyHash []byte `json:"topologyHash"`
+
+	// Time, in Unix nanoseconds, that the round entered each state, indexed by
+	// Round state
+	Timestamps []uint64 `json:"timestamps"`
+
+	// Signature over SignableBytes
+	Signature []byte `json:"signature"`
+}
+
+// SignableBytes returns the canonical serialization of every field of the
+// RoundSnapshot except the signature. All integers are big endian.
+//
+// +----------+---------+-------------+---------------+------------+-------------+
+// | round ID |  state  | topo length | topology hash | num stamps | timestamps  |
+// | 8 bytes  | 4 bytes |   4 bytes   |   variable    |  4 bytes   | 8 bytes each|
+// +----------+---------+-------------+---------------+------------+-------------+
+func (rs RoundSnapshot) SignableBytes() []byte {
+	var buf bytes.Buffer
+	buf.Grow(8 + 4 + 4 + len(rs.TopologyHash) + 4 + 8*len(rs.Timestamps))
+
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(rs.RoundID))
+	buf.Write(b)
+
+	b = make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(rs.State))
+	buf.Write(b)
+
+	b = make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(len(rs.TopologyHash)))
+	buf.Write(b)
+	buf.Write(rs.TopologyHash)
+
+	b = make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(len(rs.Timestamps)))
+	buf.Write(b)
+	for _, ts := range rs.Timestamps {
+		b = make([]byte, 8)
+		binary.BigEndian.PutUint64(b, ts)
+		buf.Write(b)
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,66 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package states
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// Consistency test of RoundSnapshot.SignableBytes.
+func TestRoundSnapshot_SignableBytes(t *testing.T) {
+	rs := RoundSnapshot{
+		RoundID:      42,
+		State:        COMPLETED,
+		TopologyHash: []byte{1, 2, 3},
+		Timestamps:   []uint64{7, 8},
+		Signature:    []byte("signature"),
+	}
+	expected := []byte{0, 0, 0, 0, 0, 0, 0, 42, 0, 0, 0, 5, 0, 0, 0, 3, 1, 2,
+		3, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 7, 0, 0, 0, 0, 0, 0, 0, 8}
+
+	if !bytes.Equal(expected, rs.SignableBytes()) {
+		t.Errorf("Unexpected signable bytes.\nexpected: %v\nreceived: %v",
+			expected, rs.SignableBytes())
+	}
+
+	// The signature must not affect the signable bytes
+	rs.Signature = []byte("other")
+	if !bytes.Equal(expected, rs.SignableBytes()) {
+		t.Error("Signature changed the signable bytes.")
+	}
+}
+
+// Tests that a RoundSnapshot JSON marshalled and unmarshalled matches the
+// original.
+func TestRoundSnapshot_JSON(t *testing.T) {
+	expected := RoundSnapshot{
+		RoundID:      42,
+		State:        FAILED,
+		TopologyHash: []byte{1, 2, 3},
+		Timestamps:   []uint64{7, 8, 9},
+		Signature:    []byte("signature"),
+	}
+
+	data, err := json.Marshal(expected)
+	if err != nil {
+		t.Fatalf("Failed to JSON marshal: %+v", err)
+	}
+
+	var rs RoundSnapshot
+	if err = json.Unmarshal(data, &rs); err != nil {
+		t.Fatalf("Failed to JSON unmarshal: %+v", err)
+	}
+
+	if !reflect.DeepEqual(expected, rs) {
+		t.Errorf("Unmarshalled RoundSnapshot does not match original."+
+			"\nexpected: %+v\nreceived: %+v", expected, rs)
+	}
+}
@@ -7,7 +7,10 @@
 
 package states
 
-import "strconv"
+import (
+	"strconv"
+	"strings"
+)
 
 // This holds the enum for the states of a round. It is in primitives so
 // other repos such as registration/permissioning, gateway, and client can
@@ -50,3 +53,99 @@ func (r Round) String() string {
 		return "UNKNOWN STATE: " + strconv.FormatUint(uint64(r), 10)
 	}
 }
+
+// Description returns a sentence explaining the Round state to users, with a
+// troubleshooting hint where there is one. It is maintained alongside String so
+// that front-ends do not need their own mapping.
+func (r Round) Description() string {
+	switch r {
+	case PENDING:
+		return "The round is waiting for its team of nodes to be ready."
+	case PRECOMPUTING:
+		return "The nodes are precomputing the round so that messages can be " +
+			"mixed quickly."
+	case STANDBY:
+		return "The round is precomputed and waiting to be scheduled."
+	case QUEUED:
+		return "The round is scheduled and waiting for its start time; " +
+			"messages can be sent to it now."
+	case REALTIME:
+		return "The nodes are mixing the messages in the round."
+	case COMPLETED:
+		return "The round finished and its messages were delivered."
+	case FAILED:
+		return "The round failed and its messages were not delivered; they " +
+			"will be resent in a later round."
+	default:
+		return "The round is in an unknown state; the client may need to be " +
+			"updated."
+	}
+}
+
+// UserFacingStatus is a simplified status of a round for client UIs.
+type UserFacingStatus uint8
+
+// List of user facing statuses.
+const (
+	// Waiting rounds have not started mixing messages.
+	Waiting UserFacingStatus = iota
+
+	// Running rounds are mixing messages.
+	Running
+
+	// Done rounds finished successfully.
+	Done
+
+	// Error rounds failed or are in an unknown state.
+	Error
+)
+
+// String returns the string representation of the UserFacingStatus. This
+// functions adheres to the fmt.Stringer interface.
+func (s UserFacingStatus) String() string {
+	switch s {
+	case Waiting:
+		return "Waiting"
+	case Running:
+		return "Running"
+	case Done:
+		return "Done"
+	case Error:
+		return "Error"
+	default:
+		return "UNKNOWN STATUS: " + strconv.FormatUint(uint64(s), 10)
+	}
+}
+
+// UserFacing returns the simplified status of the Round state for client UIs.
+// Unknown states are reported as Error.
+func (r Round) UserFacing() UserFacingStatus {
+	switch r {
+	case PENDING, PRECOMPUTING, STANDBY, QUEUED:
+		return Waiting
+	case REALTIME:
+		return Running
+	case COMPLETED:
+		return Done
+	default:
+		return Error
+	}
+}
+
+// MetricLabel returns the lowercase snake case name of the Round state for use
+// as a stable metric label value. Unknown states return "unknown".
+func (r Round) MetricLabel() string {
+	if r >= NUM_STATES {
+		return "unknown"
+	}
+	return strings.ToLower(r.String())
+}
+
+// AllStates returns every valid Round state in order.
+func AllStates() []Round {
+	states := make([]Round, NUM_STATES)
+	for i := range states {
+		states[i] = Round(i)
+	}
+	return states
+}
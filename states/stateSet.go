@@ -0,0 +1,70 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package states
+
+import "strings"
+
+// StateSet is a set of Round states stored as a bitmask, where the bit at the
+// index of each state is set if the state is in the set. The zero value is the
+// empty set.
+type StateSet uint32
+
+// NewStateSet returns a StateSet containing the given states. Invalid states
+// are ignored.
+func NewStateSet(states ...Round) StateSet {
+	return StateSet(0).Add(states...)
+}
+
+// Has determines if the state is in the set.
+func (s StateSet) Has(state Round) bool {
+	return state < NUM_STATES && s&(1<<state) != 0
+}
+
+// Add returns a copy of the set with the given states added. Invalid states
+// are ignored.
+func (s StateSet) Add(states ...Round) StateSet {
+	for _, state := range states {
+		if state < NUM_STATES {
+			s |= 1 << state
+		}
+	}
+	return s
+}
+
+// Remove returns a copy of the set with the given states removed.
+func (s StateSet) Remove(states ...Round) StateSet {
+	for _, state := range states {
+		if state < NUM_STATES {
+			s &^= 1 << state
+		}
+	}
+	return s
+}
+
+// States returns the states in the set in order.
+func (s StateSet) States() []Round {
+	var states []Round
+	for state := PENDING; state < NUM_STATES; state++ {
+		if s.Has(state) {
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
+// String returns the states in the set in order, comma separated and enclosed
+// in braces (e.g., "{COMPLETED, FAILED}"). This functions adheres to the
+// fmt.Stringer interface.
+func (s StateSet) String() string {
+	states := s.States()
+	names := make([]string, len(states))
+	for i, state := range states {
+		names[i] = state.String()
+	}
+	return "{" + strings.Join(names, ", ") + "}"
+}
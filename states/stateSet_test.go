@@ -0,0 +1,54 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package states
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Tests that StateSet.Add, StateSet.Remove, and StateSet.Has agree and that
+// invalid states are ignored.
+func TestStateSet_AddRemoveHas(t *testing.T) {
+	s := NewStateSet(COMPLETED, FAILED, NUM_STATES)
+
+	for _, state := range AllStates() {
+		expected := state == COMPLETED || state == FAILED
+		if s.Has(state) != expected {
+			t.Errorf("Unexpected Has for %s.\nexpected: %t\nreceived: %t",
+				state, expected, s.Has(state))
+		}
+	}
+	if s.Has(NUM_STATES) {
+		t.Errorf("Set has invalid state %s.", NUM_STATES)
+	}
+
+	s2 := s.Remove(FAILED).Add(PENDING)
+	if !reflect.DeepEqual(s2.States(), []Round{PENDING, COMPLETED}) {
+		t.Errorf("Unexpected states: %v", s2.States())
+	}
+	if !s.Has(FAILED) || s.Has(PENDING) {
+		t.Errorf("Original set modified: %s", s)
+	}
+}
+
+// Consistency test of StateSet.String.
+func TestStateSet_String(t *testing.T) {
+	tests := map[StateSet]string{
+		StateSet(0):                    "{}",
+		NewStateSet(COMPLETED, FAILED): "{COMPLETED, FAILED}",
+		NewStateSet(REALTIME):          "{REALTIME}",
+	}
+
+	for s, expected := range tests {
+		if s.String() != expected {
+			t.Errorf("Incorrect string.\nexpected: %s\nreceived: %s",
+				expected, s.String())
+		}
+	}
+}
@@ -21,3 +21,64 @@ func TestRound_String(t *testing.T) {
 		}
 	}
 }
+
+// Consistency test of Round.MetricLabel.
+func TestRound_MetricLabel(t *testing.T) {
+	expected := []string{"pending", "precomputing", "standby", "queued",
+		"realtime", "completed", "failed", "unknown"}
+
+	for st := PENDING; st <= NUM_STATES; st++ {
+		if st.MetricLabel() != expected[st] {
+			t.Errorf("Incorrect metric label for Round state %d."+
+				"\nexpected: %s\nreceived: %s", st, expected[st],
+				st.MetricLabel())
+		}
+	}
+}
+
+// Tests that every Round state has a unique, sentence-long description.
+func TestRound_Description(t *testing.T) {
+	descriptions := make(map[string]Round)
+	for st := PENDING; st <= NUM_STATES; st++ {
+		d := st.Description()
+		if len(d) == 0 || d[len(d)-1] != '.' {
+			t.Errorf("Description of %s is not a sentence: %q", st, d)
+		} else if prev, exists := descriptions[d]; exists {
+			t.Errorf("Description of %s matches %s: %q", st, prev, d)
+		}
+		descriptions[d] = st
+	}
+}
+
+// Consistency test of Round.UserFacing and UserFacingStatus.String.
+func TestRound_UserFacing(t *testing.T) {
+	expected := []string{"Waiting", "Waiting", "Waiting", "Waiting",
+		"Running", "Done", "Error", "Error"}
+
+	for st := PENDING; st <= NUM_STATES; st++ {
+		if st.UserFacing().String() != expected[st] {
+			t.Errorf("Incorrect user facing status for Round state %s."+
+				"\nexpected: %s\nreceived: %s", st, expected[st],
+				st.UserFacing())
+		}
+	}
+
+	if s := UserFacingStatus(4).String(); s != "UNKNOWN STATUS: 4" {
+		t.Errorf("Unexpected string for unknown status: %s", s)
+	}
+}
+
+// Tests that AllStates returns every valid state in order.
+func TestAllStates(t *testing.T) {
+	states := AllStates()
+	if len(states) != int(NUM_STATES) {
+		t.Fatalf("Unexpected number of states.\nexpected: %d\nreceived: %d",
+			NUM_STATES, len(states))
+	}
+
+	for i, st := range states {
+		if st != Round(i) {
+			t.Errorf("Unexpected state at index %d: %s", i, st)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package states
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// Transition records a single change of a round's state.
+type Transition struct {
+	From      Round     `json:"from"`
+	To        Round     `json:"to"`
+	Timestamp time.Time `json:"timestamp"`
+	Reason    string    `json:"reason,omitempty"`
+
+	// Code is the structured reason for the transition. It is NO_REASON for
+	// transitions added with TransitionLog.Record.
+	Code ReasonCode `json:"code,omitempty"`
+}
+
+// TransitionLog records the state transitions of rounds so that a gateway can
+// explain how a round reached its current state. Only the most recent
+// maxRounds rounds are kept; when a new round is recorded beyond that, the
+// round that was first recorded earliest is evicted.
+type TransitionLog struct {
+	maxRounds int
+	rounds    map[id.Round][]Transition
+	order     []id.Round // Rounds in the order they were first recorded
+	mux       sync.RWMutex
+}
+
+// roundTransitions is the JSON representation of the transitions of a round.
+type roundTransitions struct {
+	RoundID     id.Round     `json:"roundID"`
+	Transitions []Transition `json:"transitions"`
+}
+
+// NewTransitionLog creates an empty TransitionLog that holds the transitions
+// of up to maxRounds rounds. A maxRounds less than one is treated as one.
+func NewTransitionLog(maxRounds int) *TransitionLog {
+	if maxRounds < 1 {
+		maxRounds = 1
+	}
+
+	return &TransitionLog{
+		maxRounds: maxRounds,
+		rounds:    make(map[id.Round][]Transition),
+	}
+}
+
+// Record adds the transition of the round from one state to another.
+func (tl *TransitionLog) Record(
+	rid id.Round, from, to Round, timestamp time.Time, reason string) {
+	tl.Add(rid, Transition{from, to, timestamp, reason, NO_REASON})
+}
+
+// RecordWithCode adds the transition of the round from one state to another
+// with a structured ReasonCode. Returns an error, without adding the
+// transition, if the ReasonCode is not legal for the transition; see
+// ValidateReason.
+func (tl *TransitionLog) RecordWithCode(rid id.Round, from, to Round,
+	timestamp time.Time, code ReasonCode, reason string) error {
+	if err := ValidateReason(from, to, code); err != nil {
+		return err
+	}
+
+	tl.Add(rid, Transition{from, to, timestamp, reason, code})
+	return nil
+}
+
+// Add adds the transition to the history of the round.
+func (tl *TransitionLog) Add(rid id.Round, t Transition) {
+	tl.mux.Lock()
+	defer tl.mux.Unlock()
+
+	if _, exists := tl.rounds[rid]; !exists {
+		if len(tl.order) >= tl.maxRounds {
+			delete(tl.rounds, tl.order[0])
+			tl.order = tl.order[1:]
+		}
+		tl.order = append(tl.order, rid)
+	}
+
+	tl.rounds[rid] = append(tl.rounds[rid], t)
+}
+
+// Get returns a copy of the transitions recorded for the round in the order
+// they were recorded. Returns nil if the round is not in the log.
+func (tl *TransitionLog) Get(rid id.Round) []Transition {
+	tl.mux.RLock()
+	defer tl.mux.RUnlock()
+
+	transitions, exists := tl.rounds[rid]
+	if !exists {
+		return nil
+	}
+	return append([]Transition{}, transitions...)
+}
+
+// Len returns the number of rounds in the log.
+func (tl *TransitionLog) Len() int {
+	tl.mux.RLock()
+	defer tl.mux.RUnlock()
+	return len(tl.order)
+}
+
+// MarshalJSON exports the log as a list of rounds, in the order they were
+// first recorded, with their transitions. This function adheres to the
+// json.Marshaler interface.
+func (tl *TransitionLog) MarshalJSON() ([]byte, error) {
+	tl.mux.RLock()
+	defer tl.mux.RUnlock()
+
+	export := make([]roundTransitions, len(tl.order))
+	for i, rid := range tl.order {
+		export[i] = roundTransitions{rid, tl.rounds[rid]}
+	}
+
+	return json.Marshal(export)
+}
+
+// UnmarshalJSON imports a log exported by TransitionLog.MarshalJSON. Rounds
+// beyond the log's maximum are evicted as if they were added in order. This
+// function adheres to the json.Unmarshaler interface.
+func (tl *TransitionLog) UnmarshalJSON(data []byte) error {
+	var export []roundTransitions
+	if err := json.Unmarshal(data, &export); err != nil {
+		return err
+	}
+
+	if tl.rounds == nil {
+		*tl = *NewTransitionLog(len(export))
+	}
+
+	for _, rt := range export {
+		for _, t := range rt.Transitions {
+			tl.Add(rt.RoundID, t)
+		}
+	}
+
+	return nil
+}
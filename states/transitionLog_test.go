@@ -0,0 +1,102 @@
+////////////////////////////////////////////////////////////////////////////////
+// Copyright © 2024 xx foundation                                             //
+//                                                                            //
+// Use of this source code is governed by a license that can be found in the  //
+// LICENSE file.                                                              //
+////////////////////////////////////////////////////////////////////////////////
+
+package states
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"gitlab.com/xx_network/primitives/id"
+)
+
+// Tests that TransitionLog.Get returns the recorded transitions in order and
+// that the oldest round is evicted when the log is full.
+func TestTransitionLog_Record_Get(t *testing.T) {
+	tl := NewTransitionLog(2)
+	ts := time.Unix(100, 0)
+
+	tl.Record(1, PENDING, PRECOMPUTING, ts, "")
+	tl.Record(1, PRECOMPUTING, FAILED, ts, "node timeout")
+	tl.Record(2, PENDING, PRECOMPUTING, ts, "")
+
+	expected := []Transition{
+		{PENDING, PRECOMPUTING, ts, "", NO_REASON},
+		{PRECOMPUTING, FAILED, ts, "node timeout", NO_REASON},
+	}
+	if received := tl.Get(1); !reflect.DeepEqual(expected, received) {
+		t.Errorf("Unexpected transitions.\nexpected: %+v\nreceived: %+v",
+			expected, received)
+	}
+
+	tl.Record(3, PENDING, PRECOMPUTING, ts, "")
+	if tl.Get(1) != nil || tl.Get(2) == nil || tl.Get(3) == nil {
+		t.Error("Oldest round was not evicted.")
+	}
+	if tl.Len() != 2 {
+		t.Errorf("Unexpected length.\nexpected: %d\nreceived: %d", 2, tl.Len())
+	}
+}
+
+// Tests that a TransitionLog JSON marshalled and unmarshalled matches the
+// original.
+func TestTransitionLog_JSON(t *testing.T) {
+	tl := NewTransitionLog(10)
+	for rid := id.Round(5); rid < 8; rid++ {
+		tl.Record(rid, PENDING, PRECOMPUTING, time.Unix(int64(rid), 0).UTC(),
+			"")
+		tl.Record(rid, PRECOMPUTING, FAILED, time.Unix(int64(rid)+1, 0).UTC(),
+			"reason")
+	}
+
+	data, err := json.Marshal(tl)
+	if err != nil {
+		t.Fatalf("Failed to JSON marshal: %+v", err)
+	}
+
+	var loaded TransitionLog
+	if err = json.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("Failed to JSON unmarshal: %+v", err)
+	}
+
+	if !reflect.DeepEqual(tl.order, loaded.order) ||
+		!reflect.DeepEqual(tl.rounds, loaded.rounds) {
+		t.Errorf("Unmarshalled log does not match original."+
+			"\nexpected: %+v\nreceived: %+v", tl.rounds, loaded.rounds)
+	}
+}
+
+// Tests that TransitionLog.RecordWithCode adds transitions with legal reason
+// codes and rejects those with illegal ones.
+func TestTransitionLog_RecordWithCode(t *testing.T) {
+	tl := NewTransitionLog(10)
+	ts := time.Unix(100, 0)
+
+	err := tl.RecordWithCode(1, PENDING, PRECOMPUTING, ts, SCHEDULED, "")
+	if err != nil {
+		t.Errorf("Failed to record legal transition: %+v", err)
+	}
+	err = tl.RecordWithCode(1, PRECOMPUTING, FAILED, ts, TIMEOUT, "slow node")
+	if err != nil {
+		t.Errorf("Failed to record legal transition: %+v", err)
+	}
+	err = tl.RecordWithCode(1, PRECOMPUTING, FAILED, ts, CLIENT_ERROR, "")
+	if err == nil {
+		t.Error("No error for illegal reason code.")
+	}
+
+	expected := []Transition{
+		{PENDING, PRECOMPUTING, ts, "", SCHEDULED},
+		{PRECOMPUTING, FAILED, ts, "slow node", TIMEOUT},
+	}
+	if received := tl.Get(1); !reflect.DeepEqual(expected, received) {
+		t.Errorf("Unexpected transitions.\nexpected: %+v\nreceived: %+v",
+			expected, received)
+	}
+}